@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/tengo"
+)
+
+func init() {
+	summary := "Output all *.sql files as a single SQL script, without connecting to any database"
+	desc := "Writes the CREATE statements from the *.sql files in the current directory and " +
+		"its subdirectories to STDOUT, as a single SQL script which may be piped into the " +
+		"mysql client to create all of the directory's schemas and their objects. This " +
+		"command never connects to a database, and does not write any files.\n\n" +
+		"Each schema's statements are preceded by a CREATE DATABASE IF NOT EXISTS and a USE " +
+		"command. Within each schema, statements are ordered so that they may be executed " +
+		"sequentially: tables are created before routines, triggers, views, and events; a " +
+		"foreign key's parent table is created before its child table; and views referencing " +
+		"other views are created after them. The script begins by disabling " +
+		"foreign_key_checks, since circular foreign key references cannot be satisfied by " +
+		"any order.\n\n" +
+		"Dirs whose schema option requires a database instance to resolve, such as a " +
+		"wildcard, regular expression, or shell-out, cannot be processed by this command.\n\n" +
+		"You may optionally pass an environment name as a CLI arg. This will affect " +
+		"which section of .skeema config files is used. If no environment name is " +
+		"supplied, the default is \"production\"."
+
+	cmd := mybase.NewCommand("cat", summary, desc, CatHandler)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// CatHandler is the handler method for `skeema cat`
+func CatHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return err
+	}
+	if err := dir.ValidateEnvironment(); err != nil {
+		return NewExitValue(CodeBadConfig, err.Error())
+	}
+	fmt.Fprint(os.Stdout, "SET foreign_key_checks=0;\n")
+	return catWalker(dir, os.Stdout, 5)
+}
+
+func catWalker(dir *fs.Dir, w io.Writer, maxDepth int) error {
+	if dir.ParseError != nil {
+		return NewExitValue(CodeBadConfig, "Unable to process %s: %s", dir.RelPath(), dir.ParseError)
+	}
+	if err := catDir(dir, w); err != nil {
+		return err
+	}
+	subdirs, err := dir.Subdirs()
+	if err != nil {
+		return NewExitValue(CodeFatalError, "Cannot list subdirs of %s: %s", dir, err)
+	} else if len(subdirs) > 0 && maxDepth <= 0 {
+		return NewExitValue(CodeFatalError, "Not walking subdirs of %s: max depth reached", dir)
+	}
+	for _, sub := range subdirs {
+		if err := catWalker(sub, w, maxDepth-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// catDir writes the statements of all logical schemas in dir to w, in an order
+// which may be executed sequentially. This function does not recurse into
+// subdirs, and does not interact with any database.
+func catDir(dir *fs.Dir, w io.Writer) error {
+	for _, logicalSchema := range dir.LogicalSchemas {
+		schemaNames := []string{logicalSchema.Name}
+		if logicalSchema.Name == "" {
+			var err error
+			if schemaNames, err = dir.SchemaNames(nil); err != nil {
+				return NewExitValue(CodeBadConfig, "Unable to process %s: %s", dir.RelPath(), err)
+			} else if len(schemaNames) == 0 {
+				log.Warnf("Skipping %s: no schema name specified for *.sql files", dir.RelPath())
+				continue
+			}
+		}
+		creates := logicalSchema.OrderedCreates()
+		for _, schemaName := range schemaNames {
+			schema := &tengo.Schema{
+				Name:      schemaName,
+				CharSet:   logicalSchema.CharSet,
+				Collation: logicalSchema.Collation,
+			}
+			createDB := strings.Replace(schema.CreateStatement(), "CREATE DATABASE ", "CREATE DATABASE IF NOT EXISTS ", 1)
+			fmt.Fprintf(w, "\n%s;\nUSE %s;\n", createDB, tengo.EscapeIdentifier(schemaName))
+			for _, stmt := range creates {
+				fmt.Fprintf(w, "\n%s", fs.AddDelimiter(stmt.Body()))
+			}
+			for _, stmt := range logicalSchema.Alters {
+				fmt.Fprintf(w, "\n%s", fs.AddDelimiter(stmt.Body()))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/tengo"
+)
+
+func TestCatWalker(t *testing.T) {
+	cfg := mybase.ParseFakeCLI(t, CommandSuite, "skeema cat")
+	dir, err := fs.ParseDir("testdata/cat", cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseDir: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := catWalker(dir, &buf, 5); err != nil {
+		t.Fatalf("Unexpected error from catWalker: %v", err)
+	}
+	output := buf.String()
+
+	// The output should be parseable, with each schema's objects following the
+	// corresponding USE command
+	tmpDir, err := ioutil.TempDir("", "skeema-cat-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	outputPath := filepath.Join(tmpDir, "cat.sql")
+	fs.WriteTestFile(t, outputPath, output)
+	dump, err := fs.ParseDump(outputPath)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing output: %v", err)
+	}
+	for _, stmt := range dump.IgnoredStatements {
+		if !strings.HasPrefix(stmt.Text, "CREATE DATABASE IF NOT EXISTS") {
+			t.Errorf("Unexpected unparseable statement in output: %s", stmt.Text)
+		}
+	}
+	expectCounts := map[string]int{"product": 6, "analytics": 1}
+	for schemaName, expectCount := range expectCounts {
+		if ls := dump.LogicalSchemas[schemaName]; ls == nil || len(ls.Creates) != expectCount {
+			t.Errorf("Expected output to contain %d objects in schema %s, instead found %+v", expectCount, schemaName, ls)
+		}
+	}
+	if !strings.Contains(output, "CREATE DATABASE IF NOT EXISTS `product` CHARACTER SET latin1 COLLATE latin1_swedish_ci;\nUSE `product`;\n") {
+		t.Errorf("Output does not contain expected CREATE DATABASE and USE commands:\n%s", output)
+	}
+	if !strings.Contains(output, "DELIMITER //\nCREATE PROCEDURE `purge_comments`") {
+		t.Errorf("Output does not wrap multi-statement routine in DELIMITER commands:\n%s", output)
+	}
+
+	// Parent tables must precede child tables, tables must precede routines and
+	// views, and a view must follow any other view that it references
+	expectOrder := []tengo.ObjectKey{
+		{Type: tengo.ObjectTypeTable, Name: "users"},
+		{Type: tengo.ObjectTypeTable, Name: "posts"},
+		{Type: tengo.ObjectTypeTable, Name: "comments"},
+		{Type: tengo.ObjectTypeProc, Name: "purge_comments"},
+		{Type: tengo.ObjectTypeView, Name: "recent_posts"},
+		{Type: tengo.ObjectTypeView, Name: "active_users"},
+	}
+	lastPos := -1
+	for _, key := range expectOrder {
+		pos := strings.Index(output, "CREATE "+strings.ToUpper(string(key.Type))+" "+tengo.EscapeIdentifier(key.Name))
+		if pos < 0 {
+			t.Errorf("Output does not contain %s", key)
+		} else if pos < lastPos {
+			t.Errorf("Output contains %s in an unexpected position:\n%s", key, output)
+		}
+		lastPos = pos
+	}
+
+	// A schema option requiring a database instance to resolve is an error
+	cfg = mybase.ParseFakeCLI(t, CommandSuite, "skeema cat --schema=*")
+	if dir, err = fs.ParseDir("testdata/cat", cfg); err != nil {
+		t.Fatalf("Unexpected error from ParseDir: %v", err)
+	}
+	if err := catWalker(dir, ioutil.Discard, 5); ExitCode(err) != CodeBadConfig {
+		t.Errorf("Expected exit code %d, instead found %d (err=%v)", CodeBadConfig, ExitCode(err), err)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/skeema/mybase"
+)
+
+func init() {
+	summary := "Output a shell completion script"
+	desc := "Outputs a shell completion script for Skeema's commands and options to STDOUT. " +
+		"The shell must be supplied as an arg, and may be one of " + strings.Join(mybase.CompletionShells, ", ") + ".\n\n" +
+		"For example, to enable completion in the current bash session, run " +
+		"`source <(skeema completion bash)`. To enable it persistently, add that line to " +
+		"your ~/.bashrc. Similarly, for zsh use `source <(skeema completion zsh)`; for fish " +
+		"use `skeema completion fish | source`."
+
+	cmd := mybase.NewCommand("completion", summary, desc, CompletionHandler)
+	cmd.AddArg("shell", "", true)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// CompletionHandler is the handler method for `skeema completion`
+func CompletionHandler(cfg *mybase.Config) error {
+	script, err := cfg.CLI.Command.Root().CompletionScript(cfg.Get("shell"))
+	if err != nil {
+		return NewExitValue(CodeBadUsage, err.Error())
+	}
+	fmt.Print(script)
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+)
+
+func TestCompletionScriptGolden(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "1.0", "")
+	cmdSuite.AddOption(mybase.BoolOption("debug", 0, false, "Enable debug logging"))
+	cmdSuite.AddOption(mybase.StringOption("host", 0, "", "Database hostname").Hidden())
+	cmd := mybase.NewCommand("push", "Alter tables", "", nil)
+	cmd.AddOption(mybase.BoolOption("verify", 0, true, "Test all generated ALTER statements"))
+	cmd.AddOption(mybase.StringOption("alter-wrapper", 'x', "", "External bin to shell out to for ALTER TABLE"))
+	cmd.AddOption(mybase.StringOption("password", 'p', "", "Password; omit value to prompt").ValueOptional())
+	cmd.AddArg("environment", "production", false)
+	cmdSuite.AddSubCommand(cmd)
+
+	for _, shell := range mybase.CompletionShells {
+		actual, err := cmdSuite.CompletionScript(shell)
+		if err != nil {
+			t.Fatalf("Unexpected error from CompletionScript(%q): %v", shell, err)
+		}
+		goldenPath := fmt.Sprintf("testdata/completion/skeematest.%s", shell)
+		expected, err := ioutil.ReadFile(goldenPath)
+		if err != nil {
+			t.Fatalf("Unable to read %s: %v", goldenPath, err)
+		}
+		if actual != string(expected) {
+			t.Errorf("Completion script for %s does not match %s; actual output:\n%s", shell, goldenPath, actual)
+		}
+	}
+
+	if _, err := cmdSuite.CompletionScript("powershell"); err == nil {
+		t.Error("Expected unsupported shell to return an error, but it did not")
+	}
+}
+
+func TestCompletionScriptContents(t *testing.T) {
+	for _, shell := range mybase.CompletionShells {
+		script, err := CommandSuite.CompletionScript(shell)
+		if err != nil {
+			t.Fatalf("Unexpected error from CompletionScript(%q): %v", shell, err)
+		}
+		for name, cmd := range CommandSuite.SubCommands {
+			if !strings.Contains(script, name) {
+				t.Errorf("Expected %s completion script to contain command %s, but it does not", shell, name)
+			}
+			for optName, opt := range cmd.Options() {
+				if !opt.HiddenOnCLI && !strings.Contains(script, optName) {
+					t.Errorf("Expected %s completion script to contain option %s of command %s, but it does not", shell, optName, name)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/util"
+)
+
+func init() {
+	summary := "Display resolved option values, without connecting to any database"
+	desc := "Displays the value of each option, as resolved for the current directory " +
+		"from the command-line, environment variables, and all applicable option files. " +
+		"This is useful for understanding which of several sources supplied the value " +
+		"that is being used. This command never connects to a database.\n\n" +
+		"With --show-sources, each value is annotated with the source that supplied it: " +
+		"an option file path and line number, \"CLI\" for the command-line, \"env\" along " +
+		"with the variable name for SKEEMA_-prefixed environment variables, or \"default\" " +
+		"if no source supplied the option. Passwords and other sensitive values are redacted.\n\n" +
+		"Only options which are available to all commands are displayed.\n\n" +
+		"You may optionally pass an environment name as a CLI arg. This will affect " +
+		"which section of .skeema config files is used. If no environment name is " +
+		"supplied, the default is \"production\"."
+
+	cmd := mybase.NewCommand("config", summary, desc, ConfigHandler)
+	cmd.AddOption(mybase.BoolOption("show-sources", 0, false, "Annotate each value with the source that supplied it"))
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// ConfigHandler is the handler method for `skeema config`
+func ConfigHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if err != nil {
+		return NewExitValue(CodeBadConfig, err.Error())
+	}
+	if err := dir.ValidateEnvironment(); err != nil {
+		return NewExitValue(CodeBadConfig, err.Error())
+	}
+	writeConfigReport(os.Stdout, dir.Config, cfg.GetBool("show-sources"))
+	return nil
+}
+
+// writeConfigReport writes the resolved value of each option in cfg to w, in
+// option file syntax, sorted by option name. If showSources is true, each
+// line is followed by a comment describing the source that supplied the value.
+func writeConfigReport(w io.Writer, cfg *mybase.Config, showSources bool) {
+	options := cfg.CLI.Command.Options()
+	names := make([]string, 0, len(options))
+	for name := range options {
+		if name != "help" && name != "version" && name != "show-sources" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	lines := make([]string, len(names))
+	var maxLen int
+	for n, name := range names {
+		value := cfg.GetRaw(name)
+		if options[name].Type == mybase.OptionTypeBool {
+			value = fmt.Sprint(cfg.GetBool(name))
+		} else if value != "" && (name == "password" || options[name].FileOptionName != "") {
+			value = util.RedactedValue
+		}
+		lines[n] = name + "=" + value
+		if len(lines[n]) > maxLen {
+			maxLen = len(lines[n])
+		}
+	}
+	for n, line := range lines {
+		if showSources {
+			line = fmt.Sprintf("%-*s  # %s", maxLen, line, optionSourceDescription(cfg, names[n]))
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// optionSourceDescription returns a short description of which source supplied
+// the value for the named option in cfg.
+func optionSourceDescription(cfg *mybase.Config, name string) string {
+	switch source := cfg.Source(name).(type) {
+	case *mybase.Command:
+		return "default"
+	case *mybase.CommandLine:
+		return "CLI"
+	case *mybase.EnvProvider:
+		return "env " + source.VarName(name)
+	case *mybase.File:
+		if location, ok := source.OptionLocation(name); ok {
+			return location
+		}
+		return source.Path()
+	case fmt.Stringer:
+		return source.String()
+	default:
+		return strings.TrimPrefix(fmt.Sprintf("%T", source), "*")
+	}
+}
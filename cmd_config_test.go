@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/util"
+)
+
+func TestWriteConfigReport(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "skeema-config-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	contents := "user=fileuser\nconnect-retries=3\n\n[staging]\nconnect-retries=5\npassword=secret\n"
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, ".skeema"), []byte(contents), 0666); err != nil {
+		t.Fatalf("Unable to write option file: %v", err)
+	}
+	optionFilePath := filepath.Join(tmpDir, ".skeema")
+	if realDir, err := filepath.EvalSymlinks(tmpDir); err == nil {
+		optionFilePath = filepath.Join(realDir, ".skeema")
+	}
+
+	// report parses tmpDir using the supplied command-line and returns the
+	// resulting report
+	report := func(commandLine string) string {
+		t.Helper()
+		cfg := mybase.ParseFakeCLI(t, CommandSuite, commandLine)
+		dir, err := fs.ParseDir(tmpDir, cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error from ParseDir: %v", err)
+		}
+		var buf bytes.Buffer
+		writeConfigReport(&buf, dir.Config, cfg.GetBool("show-sources"))
+		return buf.String()
+	}
+	assertLine := func(output, expectedPattern string) {
+		t.Helper()
+		if !regexp.MustCompile(`(?m)^` + expectedPattern + `$`).MatchString(output) {
+			t.Errorf("Expected output to contain line matching %q, but it did not. Output:\n%s", expectedPattern, output)
+		}
+	}
+
+	// An option set in both the option file and the CLI should be attributed to
+	// the CLI
+	output := report("skeema config --show-sources --user=cliuser")
+	assertLine(output, `user=cliuser\s+# CLI`)
+	assertLine(output, `connect-retries=3\s+# `+regexp.QuoteMeta(optionFilePath)+` line 2`)
+	assertLine(output, `port=3306\s+# default`)
+	assertLine(output, `debug=false\s+# default`)
+
+	// Environment sections should be reflected, with password values masked
+	output = report("skeema config staging --show-sources")
+	assertLine(output, `user=fileuser\s+# `+regexp.QuoteMeta(optionFilePath)+` line 1`)
+	assertLine(output, `connect-retries=5\s+# `+regexp.QuoteMeta(optionFilePath)+` line 5`)
+	assertLine(output, `password=`+util.RedactedValue+`\s+# `+regexp.QuoteMeta(optionFilePath)+` line 6`)
+
+	// Without show-sources, just the values should be displayed
+	output = report("skeema config --user=cliuser")
+	assertLine(output, `user=cliuser`)
+	assertLine(output, `connect-retries=3`)
+}
@@ -17,8 +17,24 @@ func init() {
 		"top of the file. If no environment name is supplied, the default is " +
 		"\"production\".\n\n" +
 		"The `skeema diff` command is equivalent to running `skeema push` with its --dry-run option enabled.\n\n" +
+		"With the --brief option, no DDL is output; instead, each object with at least " +
+		"one difference is listed on its own line in the form \"schema.object type\", " +
+		"grouped under a header line for each instance.\n\n" +
+		"With the --from-dump option, the filesystem is instead compared to a schema " +
+		"previously captured by mysqldump or SHOW CREATE, without connecting to any " +
+		"database instance. The *.sql files must still be executed in a workspace, so " +
+		"this requires --workspace=docker, which needs access to a local Docker daemon; " +
+		"any other workspace setting is rejected.\n\n" +
+		"With the --output-migration-dir option, the generated DDL is also written to " +
+		"a timestamp-prefixed up.sql file in the specified directory, along with a " +
+		"down.sql file containing statements which undo it. Changes which cannot be " +
+		"undone precisely, such as dropping a column, are represented by commented-out " +
+		"placeholders in down.sql.\n\n" +
 		"An exit code of 0 will be returned if no differences were found; 1 if some " +
-		"differences were found; or 2+ if an error occurred."
+		"differences were found, including differences which cannot be expressed as DDL " +
+		"due to use of unsupported features; 3 if some differences were found, but they require " +
+		"unsafe statements which were not permitted by --allow-unsafe or " +
+		"--safe-below-size; or 2 or 4+ if an error occurred."
 
 	cmd := mybase.NewCommand("diff", summary, desc, DiffHandler)
 	cmd.AddArg("environment", "production", false)
@@ -45,15 +61,32 @@ func clonePushOptionsToDiff() {
 	}
 
 	descRewrites := map[string]string{
-		"allow-unsafe":    "Permit generating ALTER or DROP operations that are potentially destructive",
-		"alter-wrapper":   "Output ALTER TABLEs as shell commands rather than just raw DDL; see manual for template vars",
-		"brief":           "Don't output DDL to STDOUT; instead output list of instances with at least one difference",
-		"safe-below-size": "Always permit generating destructive operations for tables below this size in bytes",
+		"allow-unsafe":         "Permit generating ALTER or DROP operations that are potentially destructive",
+		"alter-wrapper":        "Output ALTER TABLEs as shell commands rather than just raw DDL; see manual for template vars",
+		"brief":                "Don't output DDL to STDOUT; instead output list of objects with at least one difference, grouped by instance",
+		"dry-run-validate":     "Also check live data for problems that would cause ALTER TABLE to fail",
+		"from-dump":            "Compare the filesystem to the schema in this mysqldump or SHOW CREATE file, instead of a DB instance (requires workspace=docker)",
+		"gh-ost":               "Output ALTER TABLEs as gh-ost commands rather than just raw DDL, subject to --alter-wrapper-min-size",
+		"output-format":        `Format of DDL output to STDOUT (valid values: "text", "json")`,
+		"output-migration-dir": "Also write DDL and its inverse to timestamp-prefixed up/down .sql files in this dir",
+		"safe-below-size":      "Always permit generating destructive operations for tables below this size in bytes",
 	}
 	hiddenRewrites := map[string]bool{
-		"brief":              false,
-		"dry-run":            true,
-		"foreign-key-checks": true,
+		"brief":                false,
+		"from-dump":            false,
+		"output-format":        false,
+		"output-migration-dir": false,
+		"atomic":               true,
+		"dry-run":              true,
+		"foreign-key-checks":   true,
+		"interactive":          true,
+		"lock-wait-timeout":    true,
+		"max-replica-lag":      true,
+		"post-push-command":    true,
+		"pre-push-command":     true,
+		"progress":             true,
+		"replica":              true,
+		"statement-timeout":    true,
 	}
 
 	diffOptions := diff.Options()
@@ -10,6 +10,7 @@ import (
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/dumper"
 	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/util"
 	"github.com/skeema/tengo"
 )
 
@@ -148,7 +149,7 @@ func createHostOptionFile(cfg *mybase.Config, hostDir *fs.Dir, inst *tengo.Insta
 	if flavor := inst.Flavor(); flavor.Known() {
 		hostOptionFile.SetOptionValue(environment, "flavor", flavor.Family().String())
 	}
-	for _, persistOpt := range []string{"user", "ignore-schema", "ignore-table", "connect-options"} {
+	for _, persistOpt := range []string{"user", "ignore-schema", "ignore-table", "object-types", "connect-options"} {
 		if cfg.OnCLI(persistOpt) {
 			hostOptionFile.SetOptionValue(environment, persistOpt, cfg.Get(persistOpt))
 		}
@@ -163,6 +164,11 @@ func createHostOptionFile(cfg *mybase.Config, hostDir *fs.Dir, inst *tengo.Insta
 		hostOptionFile.SetOptionValue("", "default-character-set", schemas[0].CharSet)
 		hostOptionFile.SetOptionValue("", "default-collation", schemas[0].Collation)
 	}
+	for _, layoutOpt := range []string{"split-by", "views-dir"} {
+		if cfg.OnCLI(layoutOpt) {
+			hostOptionFile.SetOptionValue("", layoutOpt, cfg.Get(layoutOpt))
+		}
+	}
 
 	// Write the option file
 	if err := hostDir.CreateOptionFile(hostOptionFile); err != nil {
@@ -203,6 +209,13 @@ func PopulateSchemaDir(s *tengo.Schema, parentDir *fs.Dir, makeSubdir bool) erro
 		optionFile.SetOptionValue("", "schema", s.Name)
 		optionFile.SetOptionValue("", "default-character-set", s.CharSet)
 		optionFile.SetOptionValue("", "default-collation", s.Collation)
+		for _, layoutOpt := range []string{"split-by", "views-dir"} {
+			if parentDir.Config.OnCLI(layoutOpt) && parentDir.OptionFile != nil {
+				if _, already := parentDir.OptionFile.OptionValue(layoutOpt); !already {
+					optionFile.SetOptionValue("", layoutOpt, parentDir.Config.Get(layoutOpt))
+				}
+			}
+		}
 		dir, err = parentDir.CreateSubdir(s.Name, optionFile)
 		if err != nil {
 			return NewExitValue(CodeCantCreate, "Unable to create subdirectory for schema %s: %s", s.Name, err)
@@ -219,6 +232,9 @@ func PopulateSchemaDir(s *tengo.Schema, parentDir *fs.Dir, makeSubdir bool) erro
 	if err != nil {
 		return NewExitValue(CodeBadConfig, err.Error())
 	}
+	if dumpOpts.ObjectTypes, err = util.ObjectTypes(dir.Config); err != nil {
+		return NewExitValue(CodeBadConfig, err.Error())
+	}
 	if dir.Config.GetBool("strip-partitioning") {
 		dumpOpts.Partitioning = tengo.PartitioningRemove
 	}
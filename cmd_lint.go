@@ -1,7 +1,10 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"runtime"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
@@ -31,12 +34,16 @@ func init() {
 		"supplied, the default is \"production\".\n\n" +
 		"An exit code of 0 will be returned if no errors or warnings were emitted and all " +
 		"files were already formatted properly; 1 if any warnings were emitted and/or " +
-		"some files were reformatted; or 2+ if any errors were emitted for any reason."
+		"some files were reformatted; or 2+ if any errors were emitted for any reason.\n\n" +
+		"With --output-format=sarif, annotations are additionally written to STDOUT as a " +
+		"SARIF 2.1.0 document, for consumption by code review and scanning tools."
 
 	cmd := mybase.NewCommand("lint", summary, desc, LintHandler)
 	linter.AddCommandOptions(cmd)
 	cmd.AddOption(mybase.BoolOption("format", 0, true, "Reformat SQL statements to match canonical SHOW CREATE"))
 	cmd.AddOption(mybase.BoolOption("strip-partitioning", 0, false, "Remove PARTITION BY clauses from *.sql files").Hidden())
+	cmd.AddOption(mybase.EnumOption("output-format", 0, "text", `Format of linter output to STDOUT (valid values: "text", "sarif")`, "text", "sarif"))
+	cmd.AddOption(mybase.StringOption("concurrency", 0, "0", "Max number of objects to check concurrently; 0 means use all available CPUs"))
 	workspace.AddCommandOptions(cmd)
 	cmd.AddArg("environment", "production", false)
 	CommandSuite.AddSubCommand(cmd)
@@ -48,8 +55,21 @@ func LintHandler(cfg *mybase.Config) error {
 	if err != nil {
 		return err
 	}
+	if err := dir.ValidateEnvironment(); err != nil {
+		return NewExitValue(CodeBadConfig, err.Error())
+	}
+
+	outputFormat, err := dir.Config.GetEnum("output-format", "text", "sarif")
+	if err != nil {
+		return NewExitValue(CodeBadConfig, err.Error())
+	}
 
 	result := lintWalker(dir, 5)
+	if outputFormat == "sarif" {
+		if err := result.WriteSARIF(os.Stdout, version, dir.RepoBase()); err != nil {
+			return NewExitValue(CodeFatalError, "Unable to write SARIF output: %s", err)
+		}
+	}
 	switch {
 	case len(result.Exceptions) > 0:
 		exitCode := CodeFatalError
@@ -127,6 +147,9 @@ func lintDir(dir *fs.Dir) *linter.Result {
 	if err != nil && len(dir.LogicalSchemas) > 0 {
 		return linter.BadConfigResult(dir, err)
 	}
+	if opts.Concurrency, err = lintConcurrency(dir); err != nil && len(dir.LogicalSchemas) > 0 {
+		return linter.BadConfigResult(dir, err)
+	}
 
 	// Get workspace options for dir. This involves connecting to the first
 	// defined instance, unless configured to use local Docker.
@@ -201,6 +224,21 @@ func lintDir(dir *fs.Dir) *linter.Result {
 	return result
 }
 
+// lintConcurrency returns the number of concurrent workers to use when
+// checking objects in dir, based on the concurrency option. A value of 0 means
+// to use one worker per available CPU.
+func lintConcurrency(dir *fs.Dir) (int, error) {
+	concurrency, err := dir.Config.GetInt("concurrency")
+	if err != nil {
+		return 0, err
+	} else if concurrency < 0 {
+		return 0, errors.New("concurrency cannot be negative")
+	} else if concurrency == 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	return concurrency, nil
+}
+
 func countAndNoun(n int, singular, plural string) string {
 	if n == 1 {
 		return fmt.Sprintf("1 %s", singular)
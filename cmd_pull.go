@@ -10,6 +10,7 @@ import (
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/dumper"
 	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/util"
 	"github.com/skeema/skeema/internal/workspace"
 	"github.com/skeema/tengo"
 )
@@ -33,6 +34,8 @@ func init() {
 	cmd.AddOption(mybase.BoolOption("normalize", 0, true, "(deprecated alias for format)").Hidden())
 	cmd.AddOption(mybase.BoolOption("new-schemas", 0, true, "Detect any new schemas and populate new dirs for them"))
 	cmd.AddOption(mybase.BoolOption("update-partitioning", 0, false, "Update PARTITION BY clauses in existing table files"))
+	cmd.AddOption(mybase.BoolOption("ignore-definer", 0, false, "Don't update stored program files whose only difference is DEFINER"))
+	cmd.AddOption(mybase.BoolOption("ignore-view-definer", 0, false, "Don't update view files whose only difference is DEFINER"))
 	cmd.AddOption(mybase.BoolOption("strip-partitioning", 0, false, "Omit PARTITION BY clause when writing partitioned tables to filesystem").Hidden())
 	workspace.AddCommandOptions(cmd)
 	cmd.AddArg("environment", "production", false)
@@ -45,6 +48,9 @@ func PullHandler(cfg *mybase.Config) error {
 	if err != nil {
 		return err
 	}
+	if err := dir.ValidateEnvironment(); err != nil {
+		return NewExitValue(CodeBadConfig, err.Error())
+	}
 
 	// pullWalker returns the "worst" (highest) exit code it encounters. We care
 	// about the exit code, but not the error message, since any error will already
@@ -133,6 +139,10 @@ func pullSchemaDir(dir *fs.Dir, instance *tengo.Instance) (schemaNames []string,
 		log.Warnf("Skipping %s: %s", dir, dir.ParseError)
 		return nil, NewExitValue(CodePartialError, "")
 	}
+	if err = updateLayout(dir); err != nil {
+		log.Warnf("Skipping %s: %s\n", dir, err)
+		return nil, NewExitValue(CodePartialError, "")
+	}
 	if len(dir.LogicalSchemas) > 0 {
 		// TODO: support multiple logical schemas per dir
 		logicalSchema := dir.LogicalSchemas[0]
@@ -182,6 +192,9 @@ func pullLogicalSchema(dir *fs.Dir, instance *tengo.Instance, logicalSchema *fs.
 	if dumpOpts.IgnoreTable, err = dir.Config.GetRegexp("ignore-table"); err != nil {
 		return nil, NewExitValue(CodeBadConfig, err.Error())
 	}
+	if dumpOpts.ObjectTypes, err = util.ObjectTypes(dir.Config); err != nil {
+		return nil, NewExitValue(CodeBadConfig, err.Error())
+	}
 	if !dir.Config.GetBool("update-partitioning") {
 		if dir.Config.GetBool("strip-partitioning") {
 			// Undocumented due to potential confusion, but supported just like in init
@@ -196,20 +209,30 @@ func pullLogicalSchema(dir *fs.Dir, instance *tengo.Instance, logicalSchema *fs.
 	// When --skip-format is in use, we only want to update objects that have
 	// actual functional modifications, NOT just cosmetic/formatting differences.
 	// To make this distinction, we need to actually execute the *.sql files in a
-	// Workspace and run a diff against it.
-	if !dir.Config.GetBool("format") || !dir.Config.GetBool("normalize") {
-		mods := statementModifiersForPull(dir.Config, instance, dumpOpts.IgnoreTable)
+	// Workspace and run a diff against it. The same is true if differences in
+	// definer are being ignored, since objects only differing in this way
+	// should not be updated even if formatting.
+	format := dir.Config.GetBool("format") && dir.Config.GetBool("normalize")
+	mods := statementModifiersForPull(dir.Config, instance, dumpOpts.IgnoreTable)
+	if !format || mods.IgnoreDefiner || mods.IgnoreViewDefiner {
 		opts, err := workspace.OptionsForDir(dir, instance)
 		if err != nil {
 			return nil, NewExitValue(CodeBadConfig, err.Error())
 		}
-		inDiff, err := objectsInDiff(logicalSchema, instSchema, opts, mods)
+		inDiff, ignored, err := objectsInDiff(logicalSchema, instSchema, opts, mods)
 		if err != nil {
 			return nil, err
 		}
-		dumpOpts.OnlyKeys(inDiff)
+		if !format {
+			dumpOpts.OnlyKeys(inDiff)
+		}
+		dumpOpts.IgnoreKeys(ignored)
 	}
 
+	// Objects annotated with skeema:ignore are managed out-of-band, so leave
+	// their filesystem definitions (and the annotations themselves) untouched
+	dumpOpts.IgnoreKeys(logicalSchema.IgnoredKeys())
+
 	_, err = dumper.DumpSchema(instSchema, dir, dumpOpts)
 	if err == nil {
 		os.Stderr.WriteString("\n")
@@ -231,6 +254,8 @@ func statementModifiersForPull(config *mybase.Config, instance *tengo.Instance,
 		mods.NextAutoInc = tengo.NextAutoIncIfAlready
 	}
 	mods.IgnoreTable = ignoreTable
+	mods.IgnoreDefiner = config.GetBool("ignore-definer")
+	mods.IgnoreViewDefiner = config.GetBool("ignore-view-definer")
 	instFlavor, confFlavor := instance.Flavor(), tengo.NewFlavor(config.Get("flavor"))
 	if !instFlavor.Known() && confFlavor.Known() {
 		mods.Flavor = confFlavor
@@ -247,33 +272,40 @@ func statementModifiersForPull(config *mybase.Config, instance *tengo.Instance,
 	return mods
 }
 
-// objectsInDiff returns a map whose keys are tengo.ObjectKeys of objects that
-// have modifications in instSchema that aren't reflected in their filesystem
-// representation yet. This also includes objects whose filesystem Statement has
-// a SQL syntax error. The return value does not include tables whose
-// differences are cosmetic / formatting-related, or are otherwise ignored by
-// mods.
-func objectsInDiff(logicalSchema *fs.LogicalSchema, instSchema *tengo.Schema, opts workspace.Options, mods tengo.StatementModifiers) ([]tengo.ObjectKey, error) {
+// objectsInDiff returns tengo.ObjectKeys of objects that have modifications in
+// instSchema that aren't reflected in their filesystem representation yet. This
+// also includes objects whose filesystem Statement has a SQL syntax error. The
+// inDiff return value does not include tables whose differences are cosmetic /
+// formatting-related, or are otherwise ignored by mods. Objects whose only
+// differences are deliberately ignored by mods, such as with IgnoreDefiner, are
+// returned separately in ignored.
+func objectsInDiff(logicalSchema *fs.LogicalSchema, instSchema *tengo.Schema, opts workspace.Options, mods tengo.StatementModifiers) (inDiff, ignored []tengo.ObjectKey, err error) {
 	wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, opts)
 	if err != nil {
-		return nil, fmt.Errorf("Error introspecting filesystem version of schema %s: %s", instSchema.Name, err)
+		return nil, nil, fmt.Errorf("Error introspecting filesystem version of schema %s: %s", instSchema.Name, err)
 	}
 
 	// Run a diff, and create a map to track objects in the diff
 	diff := tengo.NewSchemaDiff(wsSchema.Schema, instSchema)
-	inDiff := make([]tengo.ObjectKey, 0)
+	inDiff = make([]tengo.ObjectKey, 0)
+	unignoredMods := mods
+	unignoredMods.IgnoreDefiner = false
+	unignoredMods.IgnoreViewDefiner = false
 	for _, od := range diff.ObjectDiffs() {
 		odStatement, odStatementErr := od.Statement(mods)
 		// Errors are fatal, except for UnsupportedDiffError which we can safely
 		// ignore (since pull doesn't actually run ALTERs; it just needs to know
 		// what was altered)
 		if odStatementErr != nil && !tengo.IsUnsupportedDiff(odStatementErr) {
-			return nil, odStatementErr
+			return nil, nil, odStatementErr
 		}
 		// mods may cause the diff to be a no-op; only include it in result if this
-		// isn't the case
+		// isn't the case. Otherwise, track whether the diff is only a no-op due to
+		// mods that intentionally ignore a type of difference.
 		if odStatement != "" {
 			inDiff = append(inDiff, od.ObjectKey())
+		} else if stmt, _ := od.Statement(unignoredMods); stmt != "" {
+			ignored = append(ignored, od.ObjectKey())
 		}
 	}
 
@@ -281,7 +313,7 @@ func objectsInDiff(logicalSchema *fs.LogicalSchema, instSchema *tengo.Schema, op
 	// the filesystem definition to match the live definition in this case.
 	inDiff = append(inDiff, wsSchema.FailedKeys()...)
 
-	return inDiff, nil
+	return inDiff, ignored, nil
 }
 
 // updateFlavor updates the dir's .skeema option file if the instance's current
@@ -303,9 +335,10 @@ func updateFlavor(dir *fs.Dir, instance *tengo.Instance) {
 }
 
 // updateCharSetCollation updates the dir's .skeema option file if the schema's
-// current default charset or collation does not match what's in the file.
+// current default charset or collation does not match what's in the file. Names
+// which only differ by use of the utf8 vs utf8mb3 alias are considered matching.
 func updateCharSetCollation(dir *fs.Dir, instSchema *tengo.Schema) error {
-	if dir.Config.Get("default-character-set") != instSchema.CharSet || dir.Config.Get("default-collation") != instSchema.Collation {
+	if !tengo.CharSetNamesEquivalent(dir.Config.Get("default-character-set"), instSchema.CharSet) || !tengo.CharSetNamesEquivalent(dir.Config.Get("default-collation"), instSchema.Collation) {
 		dir.OptionFile.SetOptionValue("", "default-character-set", instSchema.CharSet)
 		dir.OptionFile.SetOptionValue("", "default-collation", instSchema.Collation)
 		if err := dir.OptionFile.Write(true); err != nil {
@@ -316,6 +349,43 @@ func updateCharSetCollation(dir *fs.Dir, instSchema *tengo.Schema) error {
 	return nil
 }
 
+// updateLayout updates the dir's .skeema option file if a different split-by
+// or views-dir value was supplied on the command-line, and then moves the
+// dir's *.sql files as needed to match the resulting layout.
+func updateLayout(dir *fs.Dir) error {
+	if dir.OptionFile == nil || !dir.HasSchema() {
+		return nil
+	}
+	// Options not supplied on the command-line aren't being changed, but may be
+	// inherited from a parent dir, so the current value is the previous value
+	from, to := dir.OptionFileLayout(), dir.Layout()
+	if !dir.Config.OnCLI("split-by") {
+		from.SplitBy = to.SplitBy
+	}
+	if !dir.Config.OnCLI("views-dir") {
+		from.ViewsDir = to.ViewsDir
+	}
+	changes := []struct{ optionName, oldValue, newValue string }{
+		{"split-by", from.SplitBy, to.SplitBy},
+		{"views-dir", from.ViewsDir, to.ViewsDir},
+	}
+	for _, change := range changes {
+		if change.oldValue == change.newValue {
+			continue
+		}
+		if change.newValue == "" {
+			dir.OptionFile.UnsetOptionValue("", change.optionName)
+		} else {
+			dir.OptionFile.SetOptionValue("", change.optionName, change.newValue)
+		}
+		if err := dir.OptionFile.Write(true); err != nil {
+			return fmt.Errorf("Unable to update %s for %s: %s", change.optionName, dir.OptionFile.Path(), err)
+		}
+		log.Infof("Wrote %s -- updated %s to %q", dir.OptionFile.Path(), change.optionName, change.newValue)
+	}
+	return dir.Relayout(from)
+}
+
 func findNewSchemas(dir *fs.Dir, instance *tengo.Instance, seenNames []string) error {
 	subdirHasSchema := make(map[string]bool)
 	for _, name := range seenNames {
@@ -3,7 +3,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"time"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/applier"
 	"github.com/skeema/skeema/internal/fs"
@@ -26,23 +28,84 @@ func init() {
 		"An exit code of 0 will be returned if the operation was fully successful; 1 if " +
 		"at least one table could not be updated due to use of unsupported features, or if " +
 		"the --dry-run option was used and differences were found; or 2+ if a fatal error " +
-		"occurred."
+		"occurred.\n\n" +
+		"With --pre-push-command or --post-push-command, a shell command is run before or " +
+		"after changes are pushed to each target. Information about the target is " +
+		"supplied to the command via environment variables: SKEEMA_HOOK_NAME, " +
+		"SKEEMA_HOOK_HOST, SKEEMA_HOOK_PORT, SKEEMA_HOOK_SOCKET, SKEEMA_HOOK_SCHEMA, " +
+		"SKEEMA_HOOK_ENVIRONMENT, SKEEMA_HOOK_DIRNAME, SKEEMA_HOOK_DIRPATH, " +
+		"SKEEMA_HOOK_STATEMENT_COUNT, and (for post-push only) SKEEMA_HOOK_STATUS. These commands " +
+		"are not run for targets without any differences.\n\n" +
+		"With --max-replica-lag, the replication lag of each host listed in --replica " +
+		"is checked before each DDL statement is run, pausing until the lag of every " +
+		"replica is at or below the specified duration. If the lag of any replica " +
+		"cannot be determined, the remaining statements for the target are skipped. " +
+		"Statements run via --alter-wrapper, --ddl-wrapper, or --gh-ost are not delayed, " +
+		"since external tools typically perform their own throttling.\n\n" +
+		"With --progress, each DDL statement is logged before it runs, along with its " +
+		"position among the target's statements, and again once it completes, along with " +
+		"its elapsed time. Statements that are still running are noted periodically. A " +
+		"summary of the number of statements run and total elapsed time is logged for " +
+		"each target.\n\n" +
+		"With --interactive, each unsafe statement is displayed along with a [y/N] " +
+		"prompt, instead of causing the entire target to be skipped. Only statements " +
+		"approved by answering \"y\" are run; declined statements are skipped, and the " +
+		"final summary includes the number of statements approved and declined. If " +
+		"STDIN is not a TTY, no prompts are displayed, and any unsafe statement causes its " +
+		"entire target to be skipped, the same as without --interactive.\n\n" +
+		"With --atomic, each target's changes are only pushed if they can be applied in a " +
+		"single atomic DDL statement, so that a failure does not leave the schema partially " +
+		"changed. This requires a server with atomic DDL support (MySQL 8.0+ or MariaDB " +
+		"10.6+), and only applies to InnoDB tables and non-table objects. Since the " +
+		"server cannot group multiple DDL statements into one transaction, this is only " +
+		"possible when a target has a single change, or when all of its changes are " +
+		"DROP TABLEs on MySQL 8.0+, which are combined into one statement. Other targets " +
+		"are skipped, and the final summary states whether atomicity was achieved.\n\n" +
+		"The --lock-wait-timeout and --statement-timeout options limit how long each " +
+		"DDL statement may wait for metadata locks or run, respectively. " +
+		"--statement-timeout is only supported by MariaDB 10.1+, via its " +
+		"max_statement_time variable; MySQL's max_execution_time only applies to " +
+		"SELECT statements, so with other flavors this option results in an error.\n\n" +
+		"With --show-table-size, each ALTER TABLE or DROP TABLE is preceded by a comment " +
+		"noting the table's approximate row count and combined data and index size, as " +
+		"estimated from the server's table metadata. The size is listed as \"unknown\" " +
+		"if it cannot be determined, for example when using --from-dump with `skeema diff`.\n\n" +
+		"With --dry-run-validate, DDL is output but not run, as with --dry-run, even " +
+		"if the option is only enabled in a subdirectory's option file. " +
+		"Additionally, each ALTER TABLE is checked for problems with the table's existing " +
+		"data which would cause it to fail: NULL values in a column being changed to NOT " +
+		"NULL, or duplicate values for a new unique or primary key. These checks query " +
+		"the live table, which may be slow for large tables. No supported database " +
+		"server can roll back DDL in a transaction, so other data-dependent changes, such " +
+		"as column type changes or new check constraints, cannot be checked cheaply; these " +
+		"are noted in the output as not validated. Statements predicted to fail are noted " +
+		"in the output, and cause an exit code of 2+."
 
 	cmd := mybase.NewCommand("push", summary, desc, PushHandler)
 
 	cmd.AddOptions("DDL generation",
 		mybase.BoolOption("exact-match", 0, false, "Follow *.sql table definitions exactly, even for differences with no functional impact"),
 		mybase.BoolOption("compare-metadata", 0, false, "For stored programs, detect changes to creation-time sql_mode or DB collation"),
+		mybase.BoolOption("ignore-definer", 0, false, "For stored programs, ignore differences in DEFINER"),
+		mybase.BoolOption("ignore-view-definer", 0, false, "For views, ignore differences in DEFINER"),
+		mybase.BoolOption("include-auto-inc", 0, false, "Include next auto-inc values from table files, if higher than the table's current value"),
+		mybase.BoolOption("compare-sequence-value", 0, false, "For sequences, detect changes to next value, restarting the sequence if needed"),
 		mybase.BoolOption("alter-validate-virtual", 0, false, "Apply a WITH VALIDATION clause to ALTER TABLEs affecting virtual columns"),
-		mybase.StringOption("alter-lock", 0, "", `Apply a LOCK clause to all ALTER TABLEs (valid values: "none", "shared", "exclusive")`),
-		mybase.StringOption("alter-algorithm", 0, "", `Apply an ALGORITHM clause to all ALTER TABLEs (valid values: "inplace", "copy", "instant")`),
-		mybase.StringOption("partitioning", 0, "keep", `Specify handling of partitioning status on the database side (valid values: "keep", "remove", "modify")`),
+		mybase.EnumOption("alter-lock", 0, "", `Apply a LOCK clause to all ALTER TABLEs (valid values: "none", "shared", "exclusive")`, "none", "shared", "exclusive", "default"),
+		mybase.EnumOption("alter-algorithm", 0, "", `Apply an ALGORITHM clause to all ALTER TABLEs (valid values: "inplace", "copy", "instant")`, "inplace", "copy", "instant", "default"),
+		mybase.EnumOption("partitioning", 0, "keep", `Specify handling of partitioning status on the database side (valid values: "keep", "remove", "modify")`, "keep", "remove", "modify"),
+		mybase.BoolOption("partition-list", 0, false, "Apply partition list differences (ADD or DROP PARTITION) to RANGE or LIST partitioned tables"),
 	)
 
 	cmd.AddOptions("External tool",
 		mybase.StringOption("alter-wrapper", 'x', "", "External bin to shell out to for ALTER TABLE; see manual for template vars"),
 		mybase.StringOption("alter-wrapper-min-size", 0, "0", "Ignore --alter-wrapper for tables smaller than this size in bytes"),
 		mybase.StringOption("ddl-wrapper", 'X', "", "Like --alter-wrapper, but applies to all DDL types (CREATE, DROP, ALTER)"),
+		mybase.BoolOption("gh-ost", 0, false, "Run ALTER TABLEs using gh-ost, subject to --alter-wrapper-min-size"),
+		mybase.StringOption("gh-ost-bin", 0, "gh-ost", "Path to gh-ost binary for use with --gh-ost"),
+		mybase.StringOption("gh-ost-flags", 0, "", "Additional flags to pass through to gh-ost for use with --gh-ost"),
+		mybase.StringOption("pre-push-command", 0, "", "Shell command to run before pushing changes to each target; target is skipped if it fails"),
+		mybase.StringOption("post-push-command", 0, "", "Shell command to run after pushing changes to each target"),
 	)
 
 	cmd.AddOptions("linter rule",
@@ -53,15 +116,27 @@ func init() {
 	cmd.AddOptions("safety",
 		mybase.BoolOption("verify", 0, true, "Test all generated ALTER statements on temp schema to verify correctness"),
 		mybase.BoolOption("allow-unsafe", 0, false, "Permit running ALTER or DROP operations that are potentially destructive"),
+		mybase.BoolOption("interactive", 0, false, "Prompt for confirmation of each unsafe statement, instead of skipping the target"),
+		mybase.BoolOption("atomic", 0, false, "Skip targets whose changes cannot be applied in a single atomic DDL statement"),
 		mybase.BoolOption("dry-run", 0, false, "Output DDL but don't run it; equivalent to `skeema diff`"),
+		mybase.BoolOption("dry-run-validate", 0, false, "Like --dry-run, but also check live data for problems that would cause ALTER TABLE to fail"),
 		mybase.BoolOption("foreign-key-checks", 0, false, "Force the server to check referential integrity of any new foreign key"),
 		mybase.StringOption("safe-below-size", 0, "0", "Always permit destructive operations for tables below this size in bytes"),
+		mybase.BoolOption("show-table-size", 0, false, "Annotate each ALTER TABLE or DROP TABLE with the table's approximate row count and size"),
+		mybase.StringOption("lock-wait-timeout", 0, "", "Limit how long each DDL statement may wait for metadata locks, e.g. \"30s\""),
+		mybase.StringOption("statement-timeout", 0, "", "Limit how long each DDL statement may run, e.g. \"10m\"; MariaDB 10.1+ only"),
+		mybase.StringOption("max-replica-lag", 0, "", "Before each DDL statement, wait while lag of any --replica exceeds this duration, e.g. \"30s\""),
+		mybase.StringOption("replica", 0, "", "Replica host to check for --max-replica-lag; may be repeated or comma-separated").Repeatable(","),
 	)
 
 	cmd.AddOptions("sharding",
 		mybase.BoolOption("first-only", '1', false, "For dirs mapping to multiple instances or schemas, just run against the first per dir"),
 		mybase.BoolOption("brief", 'q', false, "<overridden by diff command>").Hidden(),
+		mybase.EnumOption("output-format", 0, "text", "<overridden by diff command>", "text", "json").Hidden(),
+		mybase.StringOption("from-dump", 0, "", "<overridden by diff command>").Hidden(),
+		mybase.StringOption("output-migration-dir", 0, "", "<overridden by diff command>").Hidden(),
 		mybase.StringOption("concurrent-instances", 'c', "1", "Perform operations on this number of instances concurrently"),
+		mybase.BoolOption("progress", 0, false, "Log each DDL statement as it runs, along with its elapsed time"),
 	)
 
 	workspace.AddCommandOptions(cmd)
@@ -76,12 +151,54 @@ func PushHandler(cfg *mybase.Config) error {
 	if err != nil {
 		return err
 	}
+	if err := dir.ValidateEnvironment(); err != nil {
+		return NewExitValue(CodeBadConfig, err.Error())
+	}
 
-	briefMode := dir.Config.GetBool("dry-run") && dir.Config.GetBool("brief")
-	printer := applier.NewPrinter(briefMode)
-	g, ctx := errgroup.WithContext(context.Background())
-	tgchan, skipCount := applier.TargetGroupChanForDir(dir)
-	results := make(chan applier.Result)
+	// Validation is only performed without applying any changes. This is checked
+	// here for the top-level dir, and also by each target, in case a subdir's
+	// option file enables validation.
+	dryRun := dir.Config.GetBool("dry-run") || dir.Config.GetBool("dry-run-validate")
+
+	if dir.Config.Get("from-dump") != "" {
+		if !dryRun {
+			return NewExitValue(CodeBadConfig, "Option from-dump can only be used with `skeema diff`")
+		}
+		for _, name := range []string{"alter-wrapper", "ddl-wrapper", "gh-ost", "safe-below-size", "alter-wrapper-min-size", "dry-run-validate"} {
+			if dir.Config.Changed(name) {
+				return NewExitValue(CodeBadConfig, "Option from-dump cannot be combined with option "+name)
+			}
+		}
+		// The *.sql files must still be executed in a workspace, and there is no
+		// instance available for a temp-schema
+		if ws, err := dir.Config.GetEnum("workspace", "temp-schema", "docker"); err != nil {
+			return NewExitValue(CodeBadConfig, err.Error())
+		} else if ws != "docker" {
+			return NewExitValue(CodeBadConfig, "Option from-dump requires workspace=docker, since no database instance is available for a temp-schema")
+		}
+	}
+
+	briefMode := dryRun && dir.Config.GetBool("brief")
+	outputFormat, err := dir.Config.GetEnum("output-format", "text", "json")
+	if err != nil {
+		return NewExitValue(CodeBadConfig, err.Error())
+	}
+	var printer *applier.Printer
+	if dryRun && outputFormat == "json" {
+		if briefMode {
+			return NewExitValue(CodeBadConfig, "Option brief cannot be combined with output-format=json")
+		}
+		printer = applier.NewJSONPrinter()
+	} else {
+		printer = applier.NewPrinter(briefMode)
+	}
+
+	if migrationDir := dir.Config.Get("output-migration-dir"); migrationDir != "" {
+		if !dryRun {
+			return NewExitValue(CodeBadConfig, "Option output-migration-dir can only be used with `skeema diff`")
+		}
+		printer.OutputMigration(migrationDir, time.Now())
+	}
 
 	workerCount, err := dir.Config.GetInt("concurrent-instances")
 	if err == nil && workerCount < 1 {
@@ -89,7 +206,13 @@ func PushHandler(cfg *mybase.Config) error {
 	}
 	if err != nil {
 		return NewExitValue(CodeBadConfig, err.Error())
+	} else if workerCount > 1 {
+		printer.BufferPerTarget()
 	}
+
+	g, ctx := errgroup.WithContext(context.Background())
+	tgchan, skipCount := applier.TargetGroupChanForDir(dir)
+	results := make(chan applier.Result)
 	for n := 0; n < workerCount; n++ {
 		g.Go(func() error {
 			return applier.Worker(ctx, tgchan, results, printer)
@@ -110,17 +233,42 @@ func PushHandler(cfg *mybase.Config) error {
 		}
 		return err
 	}
+	if err := printer.Flush(); err != nil {
+		return err
+	}
 	sum := applier.SumResults(allResults)
 	sum.SkipCount += skipCount
+	if sum.SkipCount+sum.UnsupportedCount == 0 && sum.UnsafeApprovedCount+sum.AtomicTargets > 0 {
+		log.Info(sum.Summary())
+	}
+	return pushExitValue(sum, dryRun)
+}
 
+// pushExitValue returns the appropriate exit value for the supplied combined
+// result. In dry-run mode (i.e. `skeema diff`), differences that would require
+// unsafe statements are reported with a distinct exit code, and any other
+// skipped operations are considered fatal errors, so that callers can
+// distinguish between the outcomes. Differences which are unsupported for
+// diff operations are still reported as differences in dry-run mode. Statements
+// predicted to fail by dry-run-validate are also considered fatal errors.
+func pushExitValue(sum applier.Result, dryRun bool) error {
+	if sum.PredictedFailureCount > 0 {
+		return NewExitValue(CodeFatalError, sum.Summary())
+	}
 	if sum.SkipCount+sum.UnsupportedCount == 0 {
-		if dir.Config.GetBool("dry-run") && sum.Differences {
+		if dryRun && sum.Differences {
 			return NewExitValue(CodeDifferencesFound, "")
 		}
 		return nil
 	}
 	code := CodeFatalError
-	if sum.SkipCount == 0 {
+	if dryRun {
+		if sum.SkipCount == 0 {
+			code = CodeDifferencesFound
+		} else if sum.SkipCount == sum.UnsafeSkipCount {
+			code = CodeUnsafeDifferences
+		}
+	} else if sum.SkipCount == 0 {
 		code = CodePartialError
 	}
 	return NewExitValue(code, sum.Summary())
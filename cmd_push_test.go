@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/skeema/mybase"
+)
+
+func TestPushHandlerFromDumpWorkspace(t *testing.T) {
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Unable to obtain working directory: %v", err)
+	}
+	defer os.Chdir(origDir)
+	if err := os.Chdir("testdata/cat"); err != nil {
+		t.Fatalf("Unable to change directory: %v", err)
+	}
+
+	// Without workspace=docker, from-dump should be rejected prior to any attempt
+	// to execute the dir's *.sql files
+	for _, cmdLine := range []string{"skeema diff --from-dump=../dump.sql", "skeema diff --from-dump=../dump.sql --workspace=temp-schema"} {
+		cfg := mybase.ParseFakeCLI(t, CommandSuite, cmdLine)
+		if err := cfg.HandleCommand(); ExitCode(err) != CodeBadConfig {
+			t.Errorf("Expected %q to return exit code %d, instead found %d (err=%v)", cmdLine, CodeBadConfig, ExitCode(err), err)
+		}
+	}
+}
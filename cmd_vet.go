@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/linter"
+	"github.com/skeema/tengo"
+)
+
+func init() {
+	summary := "Check *.sql files for problems, without connecting to any database"
+	desc := "Checks the *.sql files in the current directory and its subdirectories for " +
+		"problems which can be detected without a database server. Every statement is " +
+		"examined using Skeema's built-in SQL parser, reporting statements which cannot " +
+		"be parsed, objects which are defined multiple times, and foreign keys or " +
+		"triggers which refer to tables that are not defined in the same directory.\n\n" +
+		"Unlike `skeema lint`, this command never connects to a database, so it is " +
+		"suitable for use in environments without database access, such as a " +
+		"pre-commit hook. However, it cannot detect problems which require executing " +
+		"the SQL, such as invalid column types or most semantic errors. References to " +
+		"tables in other schemas are not checked.\n\n" +
+		"You may optionally pass an environment name as a CLI arg. This will affect " +
+		"which section of .skeema config files is used. If no environment name is " +
+		"supplied, the default is \"production\".\n\n" +
+		"An exit code of 0 will be returned if no problems were found, or 2+ if any " +
+		"problems were found or a fatal error occurred."
+
+	cmd := mybase.NewCommand("vet", summary, desc, VetHandler)
+	cmd.AddArg("environment", "production", false)
+	CommandSuite.AddSubCommand(cmd)
+}
+
+// VetHandler is the handler method for `skeema vet`
+func VetHandler(cfg *mybase.Config) error {
+	dir, err := fs.ParseDir(".", cfg)
+	if _, ok := err.(fs.DuplicateDefinitionError); err != nil && !ok {
+		return err
+	}
+	if err := dir.ValidateEnvironment(); err != nil {
+		return NewExitValue(CodeBadConfig, err.Error())
+	}
+
+	result := vetWalker(dir, 5)
+	switch {
+	case len(result.Exceptions) > 0:
+		exitCode := CodeFatalError
+		for _, err := range result.Exceptions {
+			if _, ok := err.(linter.ConfigError); ok {
+				exitCode = CodeBadConfig
+			}
+		}
+		return NewExitValue(exitCode, "Skipped %s due to fatal errors",
+			countAndNoun(len(result.Exceptions), "operation", "operations"),
+		)
+	case result.ErrorCount > 0:
+		return NewExitValue(CodeFatalError, "Found %s",
+			countAndNoun(result.ErrorCount, "problem", "problems"),
+		)
+	}
+	return nil
+}
+
+func vetWalker(dir *fs.Dir, maxDepth int) *linter.Result {
+	// Duplicate definitions halt parsing of the dir's logical schemas, but these
+	// are reported by vetDir as annotations instead of being fatal
+	if _, ok := dir.ParseError.(fs.DuplicateDefinitionError); dir.ParseError != nil && !ok {
+		log.Error(fmt.Sprintf("Skipping directory %s due to error: %s", dir.RelPath(), dir.ParseError))
+		return linter.BadConfigResult(dir, dir.ParseError)
+	}
+	log.Infof("Vetting %s", dir)
+	result := vetDir(dir)
+	for _, annotation := range result.Annotations {
+		annotation.Log()
+	}
+
+	var subdirErr error
+	if subdirs, err := dir.Subdirs(); err != nil {
+		subdirErr = fmt.Errorf("Cannot list subdirs of %s: %s", dir, err)
+	} else if len(subdirs) > 0 && maxDepth <= 0 {
+		subdirErr = fmt.Errorf("Not walking subdirs of %s: max depth reached", dir)
+	} else {
+		for _, sub := range subdirs {
+			result.Merge(vetWalker(sub, maxDepth-1))
+		}
+	}
+	if subdirErr != nil {
+		log.Error(subdirErr)
+		result.Fatal(subdirErr)
+	}
+	return result
+}
+
+// vetDir examines the statements in all of dir's *.sql files, returning a
+// result with an error annotation for each unparseable statement, duplicate
+// object definition, or reference to an undefined table. This function does
+// not recurse into subdirs, and does not interact with any database.
+func vetDir(dir *fs.Dir) *linter.Result {
+	result := &linter.Result{}
+
+	// Tokenize the files again, rather than relying on dir.LogicalSchemas, since
+	// parsing of the dir stops at the first duplicate definition
+	creates := make(map[string]map[tengo.ObjectKey]*fs.Statement)
+	var createStatements []*fs.Statement
+	for _, sf := range dir.SQLFiles {
+		tokenizedFile, err := sf.Tokenize()
+		if err != nil {
+			// The unterminated quote or comment is always in the final statement
+			if len(tokenizedFile.Statements) > 0 {
+				lastStmt := tokenizedFile.Statements[len(tokenizedFile.Statements)-1]
+				note := linter.Note{
+					Summary: "Unterminated quote or comment",
+					Message: err.Error(),
+				}
+				result.Annotate(lastStmt, linter.SeverityError, "syntax", note)
+			} else {
+				result.Fatal(err)
+			}
+			continue
+		}
+		for _, stmt := range tokenizedFile.Statements {
+			switch stmt.Type {
+			case fs.StatementTypeUnknown:
+				note := linter.Note{
+					Summary: "Unable to parse statement",
+					Message: "Statement contains a syntax error, or is not a type of statement supported by Skeema",
+				}
+				result.Annotate(stmt, linter.SeverityError, "syntax", note)
+			case fs.StatementTypeCreate:
+				schemaName := stmt.Schema()
+				if creates[schemaName] == nil {
+					creates[schemaName] = make(map[tengo.ObjectKey]*fs.Statement)
+				}
+				if origStmt, already := creates[schemaName][stmt.ObjectKey()]; already {
+					note := linter.Note{
+						Summary: "Duplicate definition",
+						Message: fmt.Sprintf("%s is already defined at %s", stmt.ObjectKey(), origStmt.Location()),
+					}
+					result.Annotate(stmt, linter.SeverityError, "duplicate", note)
+					continue
+				}
+				creates[schemaName][stmt.ObjectKey()] = stmt
+				createStatements = append(createStatements, stmt)
+			}
+		}
+	}
+
+	// References can only be checked once all files have been examined, since a
+	// table may be referenced in a file preceding the one that defines it.
+	// References to schemas which aren't defined in this dir are not checked.
+	for _, stmt := range createStatements {
+		for _, ref := range stmt.References() {
+			schemaName := ref.Schema
+			if schemaName == "" {
+				schemaName = stmt.Schema()
+			}
+			if schemaCreates, ok := creates[schemaName]; !ok || schemaCreates[ref.ObjectKey] != nil {
+				continue
+			}
+			var message string
+			if stmt.ObjectType == tengo.ObjectTypeTrigger {
+				message = fmt.Sprintf("Trigger %s is on table %s, which is not defined in this directory", tengo.EscapeIdentifier(stmt.ObjectName), tengo.EscapeIdentifier(ref.Name))
+			} else {
+				message = fmt.Sprintf("Foreign key in table %s references table %s, which is not defined in this directory", tengo.EscapeIdentifier(stmt.ObjectName), tengo.EscapeIdentifier(ref.Name))
+			}
+			note := linter.Note{
+				LineOffset: ref.LineOffset,
+				Summary:    "Reference to undefined table",
+				Message:    message,
+			}
+			result.Annotate(stmt, linter.SeverityError, "reference", note)
+		}
+	}
+
+	// Make sure the problem messages have a deterministic order.
+	result.SortByFile()
+	return result
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/linter"
+)
+
+func TestVetDir(t *testing.T) {
+	cfg := mybase.ParseFakeCLI(t, CommandSuite, "skeema vet")
+	dir, err := fs.ParseDir("testdata/vet", cfg)
+	if _, ok := err.(fs.DuplicateDefinitionError); !ok {
+		t.Fatalf("Expected ParseDir to return a DuplicateDefinitionError, instead found %v", err)
+	}
+	result := vetDir(dir)
+	expected := []struct {
+		file     string
+		lineNo   int
+		ruleName string
+	}{
+		{"posts.sql", 8, "reference"},
+		{"posts.sql", 15, "reference"},
+		{"syntaxerror.sql", 2, "syntax"},
+		{"users_dupe.sql", 2, "duplicate"},
+	}
+	if len(result.Annotations) != len(expected) {
+		for _, a := range result.Annotations {
+			t.Logf("%s [%s]", a.MessageWithLocation(), a.RuleName)
+		}
+		t.Fatalf("Expected %d annotations, instead found %d", len(expected), len(result.Annotations))
+	}
+	for n, a := range result.Annotations {
+		exp := expected[n]
+		if filepath.Base(a.Statement.File) != exp.file || a.LineNo() != exp.lineNo || a.RuleName != exp.ruleName || a.Severity != linter.SeverityError {
+			t.Errorf("Annotation[%d]: expected %s line %d rule %s, instead found %s [%s, severity %s]", n, exp.file, exp.lineNo, exp.ruleName, a.MessageWithLocation(), a.RuleName, a.Severity)
+		}
+	}
+	if result.ErrorCount != len(expected) || result.WarningCount != 0 || len(result.Exceptions) != 0 {
+		t.Errorf("Unexpected counts in result: %+v", result)
+	}
+}
+
+func TestVetHandler(t *testing.T) {
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Unable to obtain working directory: %v", err)
+	}
+	defer os.Chdir(origDir)
+	if err := os.Chdir("testdata/vet"); err != nil {
+		t.Fatalf("Unable to change directory: %v", err)
+	}
+	cfg := mybase.ParseFakeCLI(t, CommandSuite, "skeema vet")
+	if err := cfg.HandleCommand(); ExitCode(err) != CodeFatalError {
+		t.Errorf("Expected exit code %d, instead found %d (err=%v)", CodeFatalError, ExitCode(err), err)
+	}
+
+	// A dir without any problems should not return an error
+	if err := os.Chdir(filepath.Join(origDir, "testdata", "golden")); err != nil {
+		t.Fatalf("Unable to change directory: %v", err)
+	}
+	cfg = mybase.ParseFakeCLI(t, CommandSuite, "skeema vet")
+	if err := cfg.HandleCommand(); err != nil {
+		t.Errorf("Expected no error vetting testdata/golden, instead found %v", err)
+	}
+}
@@ -22,15 +22,16 @@ type ExitValue struct {
 // Constants representing some predefined exit codes used by Skeema. A few of
 // these are loosely adapted from BSD's `man sysexits`.
 const (
-	CodeSuccess          = 0
-	CodeDifferencesFound = 1
-	CodePartialError     = 1
-	CodeFatalError       = 2
-	CodeBadUsage         = 64
-	CodeBadInput         = 65
-	CodeNoInput          = 66
-	CodeCantCreate       = 73
-	CodeBadConfig        = 78
+	CodeSuccess           = 0
+	CodeDifferencesFound  = 1
+	CodePartialError      = 1
+	CodeFatalError        = 2
+	CodeUnsafeDifferences = 3
+	CodeBadUsage          = 64
+	CodeBadInput          = 65
+	CodeNoInput           = 66
+	CodeCantCreate        = 73
+	CodeBadConfig         = 78
 )
 
 // NewExitValue is a constructor for ExitValue.
@@ -94,6 +95,7 @@ func Exit(err error) {
 	// Gracefully close all connection pools, to avoid aborted connection counter/
 	// logging in some versions of MySQL
 	util.CloseCachedConnectionPools()
+	util.CloseSSHTunnels()
 
 	os.Exit(exitCode)
 }
@@ -3,6 +3,8 @@ package main
 import (
 	"errors"
 	"testing"
+
+	"github.com/skeema/skeema/internal/applier"
 )
 
 func TestExitCode(t *testing.T) {
@@ -56,3 +58,31 @@ func TestHighestExitCode(t *testing.T) {
 		}
 	}
 }
+
+func TestPushExitValue(t *testing.T) {
+	cases := []struct {
+		sum      applier.Result
+		dryRun   bool
+		expected int
+	}{
+		{applier.Result{}, false, CodeSuccess},
+		{applier.Result{}, true, CodeSuccess},
+		{applier.Result{Differences: true}, false, CodeSuccess},
+		{applier.Result{Differences: true}, true, CodeDifferencesFound},
+		{applier.Result{Differences: true, UnsupportedCount: 1}, false, CodePartialError},
+		{applier.Result{Differences: true, UnsupportedCount: 1}, true, CodeDifferencesFound},
+		{applier.Result{Differences: true, SkipCount: 2}, false, CodeFatalError},
+		{applier.Result{Differences: true, SkipCount: 2}, true, CodeFatalError},
+		{applier.Result{Differences: true, SkipCount: 2, UnsafeSkipCount: 2}, false, CodeFatalError},
+		{applier.Result{Differences: true, SkipCount: 2, UnsafeSkipCount: 2}, true, CodeUnsafeDifferences},
+		{applier.Result{Differences: true, SkipCount: 3, UnsafeSkipCount: 2}, true, CodeFatalError},
+		{applier.Result{Differences: true, SkipCount: 2, UnsafeSkipCount: 2, UnsupportedCount: 1}, true, CodeUnsafeDifferences},
+		{applier.Result{Differences: true, SkipCount: 3, UnsafeSkipCount: 2, UnsupportedCount: 1}, true, CodeFatalError},
+		{applier.Result{Differences: true, PredictedFailureCount: 1}, true, CodeFatalError},
+	}
+	for _, c := range cases {
+		if actual := ExitCode(pushExitValue(c.sum, c.dryRun)); actual != c.expected {
+			t.Errorf("Expected pushExitValue(%+v, %t) to return code %d, instead found %d", c.sum, c.dryRun, c.expected, actual)
+		}
+	}
+}
@@ -5,6 +5,7 @@ go 1.14
 require (
 	github.com/VividCortex/mysqlerr v0.0.0-20170204212430-6c6b55f8796f
 	github.com/alecthomas/participle v0.3.0
+	github.com/go-sql-driver/mysql v1.5.1-0.20210202043019-fe2230a8b20c
 	github.com/jmoiron/sqlx v1.2.0
 	github.com/mattn/goveralls v0.0.6
 	github.com/mitchellh/go-wordwrap v1.0.0
@@ -10,6 +10,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/linter"
+	"github.com/skeema/skeema/internal/util"
 	"github.com/skeema/tengo"
 )
 
@@ -18,39 +19,103 @@ type Result struct {
 	Differences      bool
 	SkipCount        int
 	UnsupportedCount int
+	UnsafeSkipCount  int // portion of SkipCount due to unsafe statements not being permitted
+	TargetCount      int // number of targets processed
+	AffectedTargets  int // number of targets with any skipped or unsupported operations
+
+	// With the interactive option, number of unsafe statements approved or
+	// declined by the user. Declined statements are included in UnsafeSkipCount.
+	UnsafeApprovedCount int
+	UnsafeDeclinedCount int
+
+	// With the atomic option, number of targets whose changes were applied in a
+	// single atomic statement, or skipped because atomicity was not possible.
+	// Skipped targets' operations are included in SkipCount.
+	AtomicTargets    int
+	NonAtomicTargets int
+
+	// With the dry-run-validate option, number of statements predicted to fail
+	// due to the existing data in the table
+	PredictedFailureCount int
 }
 
 // Summary returns a string reflecting the contents of the result.
 func (r Result) Summary() string {
-	if r.SkipCount+r.UnsupportedCount == 0 {
-		return ""
+	var summary string
+	if r.SkipCount+r.UnsupportedCount > 0 {
+		var plural, reason string
+		if r.SkipCount+r.UnsupportedCount > 1 {
+			plural = "s"
+		}
+		if r.SkipCount == 0 {
+			reason = "unsupported feature"
+		} else if r.UnsupportedCount == 0 {
+			reason = "problem"
+		} else {
+			reason = "problems or unsupported feature"
+		}
+		summary = fmt.Sprintf("Skipped %d operation%s due to %s%s", r.SkipCount+r.UnsupportedCount, plural, reason, plural)
+		if r.TargetCount > 1 {
+			summary += fmt.Sprintf(" (%d of %d targets affected)", r.AffectedTargets, r.TargetCount)
+		}
+	}
+	if r.UnsafeApprovedCount+r.UnsafeDeclinedCount > 0 {
+		confirmation := fmt.Sprintf("Interactive confirmation of unsafe statements: %d approved, %d declined", r.UnsafeApprovedCount, r.UnsafeDeclinedCount)
+		if summary == "" {
+			return confirmation
+		}
+		summary += ". " + confirmation
 	}
-	var plural, reason string
-	if r.SkipCount+r.UnsupportedCount > 1 {
-		plural = "s"
+	if r.AtomicTargets+r.NonAtomicTargets > 0 {
+		var atomicity string
+		if r.NonAtomicTargets == 0 {
+			atomicity = fmt.Sprintf("Atomicity achieved for all %s with changes", countAndNoun(r.AtomicTargets, "target", "targets"))
+		} else {
+			atomicity = fmt.Sprintf("Atomicity not achieved: %s skipped since changes could not be applied atomically", countAndNoun(r.NonAtomicTargets, "target", "targets"))
+			if r.AtomicTargets > 0 {
+				atomicity += fmt.Sprintf("; %s applied atomically", countAndNoun(r.AtomicTargets, "target", "targets"))
+			}
+		}
+		if summary == "" {
+			return atomicity
+		}
+		summary += ". " + atomicity
 	}
-	if r.SkipCount == 0 {
-		reason = "unsupported feature"
-	} else if r.UnsupportedCount == 0 {
-		reason = "problem"
-	} else {
-		reason = "problems or unsupported feature"
+	if r.PredictedFailureCount > 0 {
+		validation := fmt.Sprintf("Validation predicted %s to fail", countAndNoun(r.PredictedFailureCount, "statement", "statements"))
+		if summary == "" {
+			return validation
+		}
+		summary += ". " + validation
 	}
-	return fmt.Sprintf("Skipped %d operation%s due to %s%s", r.SkipCount+r.UnsupportedCount, plural, reason, plural)
+	return summary
 }
 
 // Worker reads TargetGroups from the input channel and performs the appropriate
 // diff/push operation on each target per TargetGroup. When there are no more
 // TargetGroups to read, it writes its aggregate Result to the output channel.
 // If a fatal error occurs, it will be returned immediately; Worker is meant to
-// be called via an errgroup (see golang.org/x/sync/errgroup).
+// be called via an errgroup (see golang.org/x/sync/errgroup). Problems specific
+// to a single target are not fatal, and are instead reflected in that target's
+// Result.
 func Worker(ctx context.Context, targetGroups <-chan TargetGroup, results chan<- Result, printer *Printer) error {
+	return worker(ctx, targetGroups, results, printer, applyTarget)
+}
+
+// applyFunc performs the diff/push operation on a single target.
+type applyFunc func(t *Target, printer *Printer) (Result, error)
+
+func worker(ctx context.Context, targetGroups <-chan TargetGroup, results chan<- Result, printer *Printer, apply applyFunc) error {
 	for tg := range targetGroups {
 		for _, t := range tg {
-			result, err := applyTarget(t, printer)
+			result, err := apply(t, printer)
 			if err != nil {
 				return err
 			}
+			result.TargetCount = 1
+			if result.SkipCount+result.UnsupportedCount > 0 {
+				result.AffectedTargets = 1
+			}
 			results <- result
 
 			// Exit early if context cancelled
@@ -70,8 +135,8 @@ func applyTarget(t *Target, printer *Printer) (Result, error) {
 	schemaFromInstance, err := t.SchemaFromInstance()
 	if err != nil {
 		result.SkipCount++
-		log.Errorf("Skipping %s schema %s for %s: %s\n", t.Instance, t.SchemaName, t.Dir, err)
-		return result, err
+		log.Errorf("Skipping %s schema %s for %s: %s\n", t.source(), t.SchemaName, t.Dir, err)
+		return result, nil
 	}
 
 	t.logApplyStart()
@@ -82,7 +147,10 @@ func applyTarget(t *Target, printer *Printer) (Result, error) {
 	if err != nil {
 		return result, ConfigError(err.Error())
 	}
-	mods.Flavor = t.Instance.Flavor()
+	mods.Flavor = t.flavor()
+	if err := checkAlterClauseSupport(mods); err != nil {
+		return result, err
+	}
 	if mods.Partitioning == tengo.PartitioningRemove {
 		// With partitioning=remove, forcibly treat all filesystem definitions as if
 		// they didn't have a partitioning clause. This is designed to aid in the
@@ -93,37 +161,28 @@ func applyTarget(t *Target, printer *Printer) (Result, error) {
 	}
 
 	diff := tengo.NewSchemaDiff(schemaFromInstance, schemaFromDir)
+	objectTypes, err := util.ObjectTypes(t.Dir.Config)
+	if err != nil {
+		return result, ConfigError(err.Error())
+	}
+	removeIgnoredDiffs(diff, t.DesiredSchema.LogicalSchema.IgnoredKeys(), objectTypes)
 	if err := VerifyDiff(diff, t); err != nil {
 		return result, err
 	}
 
+	// With the interactive option, unsafe statements are individually confirmed
+	// by the user, instead of causing the entire target to be skipped
+	if !t.dryRun() && t.Dir.Config.GetBool("interactive") {
+		t.confirm = stdinConfirmer
+	}
+
 	// Build DDLStatements for each ObjectDiff, handling pre-execution errors
 	// accordingly. Also track ObjectKeys for modified objects, for subsequent
 	// use in linting.
 	objDiffs := diff.ObjectDiffs()
-	ddls := make([]*DDLStatement, 0, len(objDiffs))
-	keys := make([]tengo.ObjectKey, 0, len(objDiffs))
-	for _, objDiff := range objDiffs {
-		ddl, err := NewDDLStatement(objDiff, mods, t)
-		if ddl == nil && err == nil {
-			continue // Skip entirely if mods made the statement a noop
-		}
-		result.Differences = true
-		if err == nil {
-			ddls = append(ddls, ddl)
-			keys = append(keys, objDiff.ObjectKey())
-		} else if unsupportedErr, ok := err.(*tengo.UnsupportedDiffError); ok {
-			result.UnsupportedCount++
-			log.Warnf("Skipping %s: unable to generate DDL due to use of unsupported features. Use --debug for more information.", unsupportedErr.ObjectKey)
-			DebugLogUnsupportedDiff(unsupportedErr)
-		} else {
-			result.SkipCount += len(objDiffs)
-			log.Errorf(err.Error())
-			if len(objDiffs) > 1 {
-				log.Warnf("Skipping %d additional operations for %s %s due to previous error\n", len(objDiffs)-1, t.Instance, t.SchemaName)
-			}
-			return result, nil
-		}
+	ddls, keys, ok := t.buildDDL(objDiffs, mods, &result)
+	if !ok {
+		return result, nil
 	}
 
 	// Lint any modified objects; output the result; skip target if any
@@ -141,17 +200,125 @@ func applyTarget(t *Target, printer *Printer) (Result, error) {
 		}
 		if lintResult.ErrorCount > 0 {
 			result.SkipCount += len(objDiffs)
-			log.Warnf("Skipping %s %s due to %s\n", t.Instance, t.SchemaName, countAndNoun(lintResult.ErrorCount, "linter error"))
+			log.Warnf("Skipping %s %s due to %s\n", t.source(), t.SchemaName, countAndNoun(lintResult.ErrorCount, "linter error"))
+			return result, nil
+		}
+	}
+
+	// If writing migration files, track the DDL along with its inverse
+	if printer.migration != nil {
+		printer.addMigration(t, ddls, inverseStatements(ddls, schemaFromInstance, schemaFromDir, mods))
+	}
+
+	// With the atomic option, all of the target's changes must be applied in a
+	// single atomic statement; otherwise the target is skipped
+	if t.Dir.Config.GetBool("atomic") && len(ddls) > 0 {
+		if ddls, err = atomicStatements(ddls, mods.Flavor, schemaFromInstance, schemaFromDir); err != nil {
+			result.SkipCount += len(objDiffs)
+			result.NonAtomicTargets++
+			log.Errorf("Skipping %s %s: option atomic cannot be satisfied: %s\n", t.source(), t.SchemaName, err)
+			return result, nil
+		}
+		result.AtomicTargets++
+	}
+
+	// Set up throttling based on replica lag, if configured
+	if !t.dryRun() && len(ddls) > 0 {
+		if t.throttle, err = newThrottler(t); err != nil {
+			return result, ConfigError(err.Error())
+		}
+	}
+
+	// Run the pre-push hook, if any; skip target if it fails
+	runHooks := !t.dryRun() && len(ddls) > 0
+	if runHooks {
+		if err := t.runPushHook("pre-push-command", len(ddls), ""); err != nil {
+			result.SkipCount += len(objDiffs)
+			log.Errorf("Skipping %s %s: %s\n", t.source(), t.SchemaName, err)
 			return result, nil
 		}
 	}
 
 	// Print DDL; if not dry-run, execute it; final logging; return result
-	result.SkipCount += t.processDDL(ddls, printer)
+	skipCount := t.processDDL(ddls, printer)
+	result.SkipCount += skipCount
+
+	// Run the post-push hook, if any. Failure is logged, but there is nothing to
+	// undo, since the DDL has already been executed.
+	if runHooks {
+		status := "success"
+		if skipCount > 0 {
+			status = "failure"
+		}
+		if err := t.runPushHook("post-push-command", len(ddls)-skipCount, status); err != nil {
+			log.Error(err)
+		}
+	}
 	t.logApplyEnd(result)
 	return result, nil
 }
 
+// buildDDL returns DDLStatements for objDiffs, along with the ObjectKeys of
+// the corresponding objects. Problems are tracked in result. If the returned
+// bool is false, a problem requires the entire target to be skipped.
+func (t *Target) buildDDL(objDiffs []tengo.ObjectDiff, mods tengo.StatementModifiers, result *Result) ([]*DDLStatement, []tengo.ObjectKey, bool) {
+	ddls := make([]*DDLStatement, 0, len(objDiffs))
+	keys := make([]tengo.ObjectKey, 0, len(objDiffs))
+	for _, objDiff := range objDiffs {
+		ddl, err := NewDDLStatement(objDiff, mods, t)
+		if ddl == nil && err == nil {
+			continue // Skip entirely if mods made the statement a noop
+		}
+		result.Differences = true
+
+		// If confirming unsafe statements interactively, regenerate the statement
+		// with unsafe operations permitted, and then let the user decide. Without a
+		// TTY, nothing can be confirmed, so the entire target is skipped below, the
+		// same as without the interactive option; otherwise, declining every unsafe
+		// statement would still apply the target's safe statements, resulting in a
+		// partial push.
+		if _, ok := err.(UnsafeStatementError); ok && t.confirm != nil && !t.confirm.isTTY {
+			log.Warnf("Unable to confirm unsafe statement for %s: STDIN is not a TTY", objDiff.ObjectKey())
+		} else if ok && t.confirm != nil {
+			unsafeMods := mods
+			unsafeMods.AllowUnsafe = true
+			if ddl, err = NewDDLStatement(objDiff, unsafeMods, t); err == nil {
+				if !t.confirm.confirm(t, ddl) {
+					result.SkipCount++
+					result.UnsafeSkipCount++
+					result.UnsafeDeclinedCount++
+					log.Warnf("Skipping %s: unsafe statement was not confirmed", objDiff.ObjectKey())
+					continue
+				}
+				result.UnsafeApprovedCount++
+			}
+		}
+
+		if err == nil {
+			ddls = append(ddls, ddl)
+			keys = append(keys, objDiff.ObjectKey())
+			if len(ddl.predictedFailures) > 0 {
+				result.PredictedFailureCount++
+			}
+		} else if unsupportedErr, ok := err.(*tengo.UnsupportedDiffError); ok {
+			result.UnsupportedCount++
+			log.Warnf("Skipping %s: unable to generate DDL due to use of unsupported features. Use --debug for more information.", unsupportedErr.ObjectKey)
+			DebugLogUnsupportedDiff(unsupportedErr)
+		} else {
+			result.SkipCount += len(objDiffs)
+			if _, ok := err.(UnsafeStatementError); ok {
+				result.UnsafeSkipCount += len(objDiffs)
+			}
+			log.Errorf(err.Error())
+			if len(objDiffs) > 1 {
+				log.Warnf("Skipping %d additional operations for %s %s due to previous error\n", len(objDiffs)-1, t.source(), t.SchemaName)
+			}
+			return nil, nil, false
+		}
+	}
+	return ddls, keys, true
+}
+
 func stripPartitionClauses(tables []*tengo.Table, flavor tengo.Flavor) {
 	for _, table := range tables {
 		if table.Partitioning != nil {
@@ -161,6 +328,61 @@ func stripPartitionClauses(tables []*tengo.Table, flavor tengo.Flavor) {
 	}
 }
 
+// removeIgnoredDiffs removes any object diffs for the supplied keys from diff.
+// This is used for objects annotated with skeema:ignore in the filesystem. If
+// objectTypes is non-nil, diffs for object types not in this set are removed
+// as well, as specified by the object-types option.
+func removeIgnoredDiffs(diff *tengo.SchemaDiff, keys []tengo.ObjectKey, objectTypes map[tengo.ObjectType]bool) {
+	if len(keys) == 0 && objectTypes == nil {
+		return
+	}
+	ignoredKeys := make(map[tengo.ObjectKey]bool, len(keys))
+	for _, key := range keys {
+		ignoredKeys[key] = true
+	}
+	ignored := func(key tengo.ObjectKey) bool {
+		return ignoredKeys[key] || (objectTypes != nil && !objectTypes[key.Type])
+	}
+	tableDiffs := diff.TableDiffs[:0]
+	for _, td := range diff.TableDiffs {
+		if !ignored(td.ObjectKey()) {
+			tableDiffs = append(tableDiffs, td)
+		}
+	}
+	routineDiffs := diff.RoutineDiffs[:0]
+	for _, rd := range diff.RoutineDiffs {
+		if !ignored(rd.ObjectKey()) {
+			routineDiffs = append(routineDiffs, rd)
+		}
+	}
+	viewDiffs := diff.ViewDiffs[:0]
+	for _, vd := range diff.ViewDiffs {
+		if !ignored(vd.ObjectKey()) {
+			viewDiffs = append(viewDiffs, vd)
+		}
+	}
+	eventDiffs := diff.EventDiffs[:0]
+	for _, ed := range diff.EventDiffs {
+		if !ignored(ed.ObjectKey()) {
+			eventDiffs = append(eventDiffs, ed)
+		}
+	}
+	triggerDiffs := diff.TriggerDiffs[:0]
+	for _, trd := range diff.TriggerDiffs {
+		if !ignored(trd.ObjectKey()) {
+			triggerDiffs = append(triggerDiffs, trd)
+		}
+	}
+	sequenceDiffs := diff.SequenceDiffs[:0]
+	for _, sqd := range diff.SequenceDiffs {
+		if !ignored(sqd.ObjectKey()) {
+			sequenceDiffs = append(sequenceDiffs, sqd)
+		}
+	}
+	diff.TableDiffs, diff.RoutineDiffs, diff.ViewDiffs = tableDiffs, routineDiffs, viewDiffs
+	diff.EventDiffs, diff.TriggerDiffs, diff.SequenceDiffs = eventDiffs, triggerDiffs, sequenceDiffs
+}
+
 // supply 1 noun if pluralization is just adding an s, or 2 nouns if using
 // another word entirely
 func countAndNoun(n int, nouns ...string) string {
@@ -183,6 +405,14 @@ func SumResults(results []Result) Result {
 		total.Differences = total.Differences || r.Differences
 		total.SkipCount += r.SkipCount
 		total.UnsupportedCount += r.UnsupportedCount
+		total.UnsafeSkipCount += r.UnsafeSkipCount
+		total.TargetCount += r.TargetCount
+		total.AffectedTargets += r.AffectedTargets
+		total.UnsafeApprovedCount += r.UnsafeApprovedCount
+		total.UnsafeDeclinedCount += r.UnsafeDeclinedCount
+		total.AtomicTargets += r.AtomicTargets
+		total.NonAtomicTargets += r.NonAtomicTargets
+		total.PredictedFailureCount += r.PredictedFailureCount
 	}
 	return total
 }
@@ -190,15 +420,26 @@ func SumResults(results []Result) Result {
 // StatementModifiersForDir returns a set of DDL modifiers, based on the
 // directory's configuration.
 func StatementModifiersForDir(dir *fs.Dir) (mods tengo.StatementModifiers, err error) {
-	mods.NextAutoInc = tengo.NextAutoIncIfIncreased
+	// Next auto-inc values change constantly on live tables, so they are ignored
+	// by default. With include-auto-inc, the value from a table file is used
+	// only if it is higher than the table's, since auto-inc values should never
+	// be lowered.
+	mods.NextAutoInc = tengo.NextAutoIncIgnore
+	if dir.Config.GetBool("include-auto-inc") {
+		mods.NextAutoInc = tengo.NextAutoIncIfIncreased
+	}
 	forceAllowUnsafe := dir.Config.GetBool("brief") && dir.Config.GetBool("dry-run")
 	mods.AllowUnsafe = forceAllowUnsafe || dir.Config.GetBool("allow-unsafe")
 	mods.CompareMetadata = dir.Config.GetBool("compare-metadata")
+	mods.IgnoreDefiner = dir.Config.GetBool("ignore-definer")
+	mods.IgnoreViewDefiner = dir.Config.GetBool("ignore-view-definer")
+	mods.CompareSequenceValue = dir.Config.GetBool("compare-sequence-value")
 	mods.VirtualColValidation = dir.Config.GetBool("alter-validate-virtual")
 	if dir.Config.GetBool("exact-match") {
 		mods.StrictIndexOrder = true
 		mods.StrictCheckOrder = true // only affects MariaDB
 		mods.StrictForeignKeyNaming = true
+		mods.StrictCheckNaming = true
 	}
 	if mods.AlgorithmClause, err = dir.Config.GetEnum("alter-algorithm", "inplace", "copy", "instant", "default"); err != nil {
 		return
@@ -219,9 +460,27 @@ func StatementModifiersForDir(dir *fs.Dir) (mods tengo.StatementModifiers, err e
 		"modify": tengo.PartitioningPermissive,
 	}
 	mods.Partitioning = partMap[partitioning]
+	mods.PartitionLists = dir.Config.GetBool("partition-list")
 	return
 }
 
+// checkAlterClauseSupport returns a ConfigError if mods include an ALGORITHM
+// or LOCK clause which is not supported by mods.Flavor. If the flavor is not
+// known, no error is returned, and any problem will instead surface when the
+// server rejects the statement.
+func checkAlterClauseSupport(mods tengo.StatementModifiers) error {
+	if !mods.Flavor.Known() || (mods.AlgorithmClause == "" && mods.LockClause == "") {
+		return nil
+	}
+	if !mods.Flavor.MySQLishMinVersion(5, 6) && !mods.Flavor.VendorMinVersion(tengo.VendorMariaDB, 10, 0) {
+		return ConfigError(fmt.Sprintf("Options alter-algorithm and alter-lock are not supported by flavor %s", mods.Flavor))
+	}
+	if mods.AlgorithmClause == "instant" && !mods.Flavor.MySQLishMinVersion(8, 0) && !mods.Flavor.VendorMinVersion(tengo.VendorMariaDB, 10, 3) {
+		return ConfigError(fmt.Sprintf("Option alter-algorithm=instant is not supported by flavor %s", mods.Flavor))
+	}
+	return nil
+}
+
 // DebugLogUnsupportedDiff logs (at Debug level) the reason why an object is
 // unsupported for diff/alter operations.
 func DebugLogUnsupportedDiff(err *tengo.UnsupportedDiffError) {
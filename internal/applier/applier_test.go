@@ -1,10 +1,14 @@
 package applier
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/skeema/skeema/internal/util"
 	"github.com/skeema/tengo"
@@ -27,19 +31,245 @@ func TestSumResults(t *testing.T) {
 			UnsupportedCount: 0,
 		},
 		{
-			Differences:      true,
-			SkipCount:        3,
-			UnsupportedCount: 5,
+			Differences:         true,
+			SkipCount:           3,
+			UnsupportedCount:    5,
+			UnsafeSkipCount:     2,
+			UnsafeApprovedCount: 1,
+			UnsafeDeclinedCount: 2,
+			AtomicTargets:       1,
+			NonAtomicTargets:    1,
 		},
 	}
 	expectSum := Result{
-		Differences:      true,
-		SkipCount:        4,
-		UnsupportedCount: 5,
+		Differences:         true,
+		SkipCount:           4,
+		UnsupportedCount:    5,
+		UnsafeSkipCount:     2,
+		UnsafeApprovedCount: 1,
+		UnsafeDeclinedCount: 2,
+		AtomicTargets:       1,
+		NonAtomicTargets:    1,
 	}
-	if actualSum := SumResults(input); actualSum != expectSum {
+	actualSum := SumResults(input)
+	if actualSum != expectSum {
 		t.Errorf("Unexpected result from SumResults: %+v", actualSum)
 	}
+	expected := "Skipped 9 operations due to problems or unsupported features. Interactive confirmation of unsafe statements: 1 approved, 2 declined. Atomicity not achieved: 1 target skipped since changes could not be applied atomically; 1 target applied atomically"
+	if actual := actualSum.Summary(); actual != expected {
+		t.Errorf("Unexpected summary: expected %q, found %q", expected, actual)
+	}
+
+	// Approved statements alone should still be summarized
+	onlyApproved := Result{Differences: true, UnsafeApprovedCount: 3}
+	if expected, actual := "Interactive confirmation of unsafe statements: 3 approved, 0 declined", onlyApproved.Summary(); actual != expected {
+		t.Errorf("Unexpected summary: expected %q, found %q", expected, actual)
+	}
+
+	// Atomicity should always be stated with the atomic option
+	atomicCases := []struct {
+		result   Result
+		expected string
+	}{
+		{Result{AtomicTargets: 1}, "Atomicity achieved for all 1 target with changes"},
+		{Result{SkipCount: 2, NonAtomicTargets: 1}, "Skipped 2 operations due to problems. Atomicity not achieved: 1 target skipped since changes could not be applied atomically"},
+		{Result{SkipCount: 3, AtomicTargets: 2, NonAtomicTargets: 2}, "Skipped 3 operations due to problems. Atomicity not achieved: 2 targets skipped since changes could not be applied atomically; 2 targets applied atomically"},
+	}
+	for _, c := range atomicCases {
+		if actual := c.result.Summary(); actual != c.expected {
+			t.Errorf("Unexpected summary: expected %q, found %q", c.expected, actual)
+		}
+	}
+
+	// Predicted failures from dry-run-validate should be stated, if any
+	validateCases := []struct {
+		result   Result
+		expected string
+	}{
+		{Result{PredictedFailureCount: 1}, "Validation predicted 1 statement to fail"},
+		{Result{SkipCount: 1, PredictedFailureCount: 2}, "Skipped 1 operation due to problem. Validation predicted 2 statements to fail"},
+	}
+	for _, c := range validateCases {
+		if actual := c.result.Summary(); actual != c.expected {
+			t.Errorf("Unexpected summary: expected %q, found %q", c.expected, actual)
+		}
+	}
+}
+
+func TestCheckAlterClauseSupport(t *testing.T) {
+	cases := []struct {
+		flavor    tengo.Flavor
+		algorithm string
+		lock      string
+		expectErr bool
+	}{
+		{tengo.FlavorMySQL55, "", "", false},
+		{tengo.FlavorMySQL55, "inplace", "", true},
+		{tengo.FlavorMySQL55, "", "none", true},
+		{tengo.FlavorMySQL56, "inplace", "none", false},
+		{tengo.FlavorMySQL57, "instant", "", true},
+		{tengo.FlavorMySQL80, "instant", "none", false},
+		{tengo.FlavorPercona80, "instant", "", false},
+		{tengo.FlavorMariaDB102, "instant", "", true},
+		{tengo.FlavorMariaDB103, "instant", "shared", false},
+		{tengo.FlavorUnknown, "instant", "exclusive", false},
+	}
+	for _, c := range cases {
+		mods := tengo.StatementModifiers{Flavor: c.flavor, AlgorithmClause: c.algorithm, LockClause: c.lock}
+		err := checkAlterClauseSupport(mods)
+		if c.expectErr && err == nil {
+			t.Errorf("Expected error for flavor %s with alter-algorithm=%q alter-lock=%q, but received nil", c.flavor, c.algorithm, c.lock)
+		} else if !c.expectErr && err != nil {
+			t.Errorf("Unexpected error for flavor %s with alter-algorithm=%q alter-lock=%q: %v", c.flavor, c.algorithm, c.lock, err)
+		}
+	}
+}
+
+func TestRemoveIgnoredDiffsObjectTypes(t *testing.T) {
+	makeTable := func(name string) *tengo.Table {
+		table := &tengo.Table{
+			Name:               name,
+			Engine:             "InnoDB",
+			CharSet:            "latin1",
+			Collation:          "latin1_swedish_ci",
+			CollationIsDefault: true,
+			Columns:            []*tengo.Column{{Name: "id", TypeInDB: "int(10) unsigned"}},
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL80)
+		return table
+	}
+
+	// The live schema has a table and routines not present in a tables-only dir
+	from := &tengo.Schema{
+		Name:   "appdb",
+		Tables: []*tengo.Table{makeTable("posts"), makeTable("users")},
+		Routines: []*tengo.Routine{
+			{Name: "addone", Type: tengo.ObjectTypeFunc, Body: "RETURN n + 1", ParamString: "n int", ReturnDataType: "int", CreateStatement: "CREATE FUNCTION addone(n int) RETURNS int RETURN n + 1"},
+			{Name: "noop", Type: tengo.ObjectTypeProc, Body: "BEGIN END", CreateStatement: "CREATE PROCEDURE noop() BEGIN END"},
+		},
+	}
+	to := &tengo.Schema{
+		Name:   "appdb",
+		Tables: []*tengo.Table{makeTable("posts"), makeTable("comments")},
+	}
+	diff := tengo.NewSchemaDiff(from, to)
+	if len(diff.RoutineDiffs) != 2 || len(diff.TableDiffs) != 2 {
+		t.Fatalf("Unexpected diff prior to filtering: %d table diffs, %d routine diffs", len(diff.TableDiffs), len(diff.RoutineDiffs))
+	}
+
+	removeIgnoredDiffs(diff, nil, map[tengo.ObjectType]bool{tengo.ObjectTypeDatabase: true, tengo.ObjectTypeTable: true})
+	if len(diff.RoutineDiffs) != 0 {
+		t.Errorf("Expected routine diffs to be removed, instead found %d", len(diff.RoutineDiffs))
+	}
+	if len(diff.TableDiffs) != 2 {
+		t.Errorf("Expected table diffs to be retained, instead found %d", len(diff.TableDiffs))
+	}
+	for _, od := range diff.ObjectDiffs() {
+		if od.ObjectKey().Type != tengo.ObjectTypeTable {
+			t.Errorf("Unexpected object diff remaining after filtering: %s", od.ObjectKey())
+		}
+	}
+
+	// Ignored keys should still be honored alongside object types
+	diff = tengo.NewSchemaDiff(from, to)
+	removeIgnoredDiffs(diff, []tengo.ObjectKey{{Type: tengo.ObjectTypeTable, Name: "users"}}, map[tengo.ObjectType]bool{tengo.ObjectTypeTable: true})
+	if len(diff.RoutineDiffs) != 0 || len(diff.TableDiffs) != 1 || diff.TableDiffs[0].ObjectKey().Name != "comments" {
+		t.Errorf("Unexpected diff after filtering ignored keys and object types: %v", diff.ObjectDiffs())
+	}
+
+	// A nil object type map should not remove anything
+	diff = tengo.NewSchemaDiff(from, to)
+	removeIgnoredDiffs(diff, nil, nil)
+	if len(diff.RoutineDiffs) != 2 || len(diff.TableDiffs) != 2 {
+		t.Errorf("Expected no diffs to be removed with nil object types; found %d table diffs, %d routine diffs", len(diff.TableDiffs), len(diff.RoutineDiffs))
+	}
+}
+
+func TestWorkerConcurrency(t *testing.T) {
+	// Build 4 target groups, each with 2 targets on a distinct fake instance
+	var groups []TargetGroup
+	for n := 0; n < 4; n++ {
+		inst, err := tengo.NewInstance("mysql", fmt.Sprintf("root@tcp(127.0.0.1:%d)/", 3306+n))
+		if err != nil {
+			t.Fatalf("Unable to create instance: %v", err)
+		}
+		groups = append(groups, TargetGroup{
+			{Instance: inst, SchemaName: "one"},
+			{Instance: inst, SchemaName: "two"},
+		})
+	}
+
+	// Fake apply function tracks max number of concurrent calls and order of
+	// targets per instance. One target reports a problem, which must not prevent
+	// other targets from being processed.
+	var active, maxActive int32
+	var mu sync.Mutex
+	seen := make(map[string][]string)
+	apply := func(target *Target, _ *Printer) (result Result, err error) {
+		now := atomic.AddInt32(&active, 1)
+		defer atomic.AddInt32(&active, -1)
+		for {
+			prev := atomic.LoadInt32(&maxActive)
+			if now <= prev || atomic.CompareAndSwapInt32(&maxActive, prev, now) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		seen[target.Instance.String()] = append(seen[target.Instance.String()], target.SchemaName)
+		mu.Unlock()
+		result.Differences = true
+		if target.Instance.Port == 3307 && target.SchemaName == "one" {
+			result.SkipCount = 2
+		}
+		return result, nil
+	}
+
+	tgchan := make(chan TargetGroup, len(groups))
+	for _, tg := range groups {
+		tgchan <- tg
+	}
+	close(tgchan)
+	results := make(chan Result)
+	g, ctx := errgroup.WithContext(context.Background())
+	for n := 0; n < 3; n++ {
+		g.Go(func() error {
+			return worker(ctx, tgchan, results, NewPrinter(false), apply)
+		})
+	}
+	go func() {
+		g.Wait()
+		close(results)
+	}()
+	var allResults []Result
+	for r := range results {
+		allResults = append(allResults, r)
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Unexpected error from worker: %v", err)
+	}
+
+	if maxActive < 2 || maxActive > 3 {
+		t.Errorf("Expected between 2 and 3 concurrent targets, instead found %d", maxActive)
+	}
+	for inst, schemaNames := range seen {
+		if len(schemaNames) != 2 || schemaNames[0] != "one" || schemaNames[1] != "two" {
+			t.Errorf("Unexpected ordering of targets for instance %s: %v", inst, schemaNames)
+		}
+	}
+	sum := SumResults(allResults)
+	expectSum := Result{
+		Differences:     true,
+		SkipCount:       2,
+		TargetCount:     8,
+		AffectedTargets: 1,
+	}
+	if sum != expectSum {
+		t.Errorf("Unexpected result from SumResults: %+v", sum)
+	}
+	if expected := "Skipped 2 operations due to problems (1 of 8 targets affected)"; sum.Summary() != expected {
+		t.Errorf("Unexpected summary: expected %q, found %q", expected, sum.Summary())
+	}
 }
 
 func TestIntegration(t *testing.T) {
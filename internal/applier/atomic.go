@@ -0,0 +1,65 @@
+package applier
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/skeema/tengo"
+)
+
+// atomicStatements returns a replacement for ddls which applies all of the
+// changes in a single atomic DDL statement, as required by the atomic option.
+// No flavor supports grouping multiple DDL statements into a transaction, so
+// this is only possible if there is just one statement, or if all statements
+// are DROP TABLEs which MySQL 8.0+ can combine into one atomic DROP TABLE. In
+// all other situations, an error is returned explaining why atomicity cannot
+// be guaranteed. from and to are used to confirm that affected tables use a
+// storage engine supporting atomic DDL.
+func atomicStatements(ddls []*DDLStatement, flavor tengo.Flavor, from, to *tengo.Schema) ([]*DDLStatement, error) {
+	if len(ddls) == 0 {
+		return ddls, nil
+	}
+	if !flavor.HasAtomicDDL() {
+		return nil, fmt.Errorf("%s does not support atomic DDL, which requires MySQL 8.0+ or MariaDB 10.6+", flavor.Family())
+	}
+	for _, ddl := range ddls {
+		if ddl.IsShellOut() {
+			return nil, fmt.Errorf("%s is modified by an external command, which cannot be applied atomically", ddl.key)
+		}
+		if ddl.key.Type != tengo.ObjectTypeTable {
+			continue
+		}
+		table := from.Table(ddl.key.Name)
+		if ddl.diffType == tengo.DiffTypeCreate {
+			table = to.Table(ddl.key.Name)
+		}
+		if table != nil && !strings.EqualFold(table.Engine, "InnoDB") {
+			return nil, fmt.Errorf("%s uses storage engine %s, which does not support atomic DDL", ddl.key, table.Engine)
+		}
+	}
+	if len(ddls) == 1 {
+		return ddls, nil
+	}
+
+	// MySQL 8.0 drops multiple tables atomically in a single statement. MariaDB
+	// treats each table in a multi-table DROP TABLE as a separate operation.
+	if flavor.MySQLishMinVersion(8, 0) {
+		names := make([]string, len(ddls))
+		combined := *ddls[0]
+		for n, ddl := range ddls {
+			if ddl.key.Type != tengo.ObjectTypeTable || ddl.diffType != tengo.DiffTypeDrop || ddl.connectParams != combined.connectParams {
+				names = nil
+				break
+			}
+			names[n] = tengo.EscapeIdentifier(ddl.key.Name)
+			combined.unsafe = combined.unsafe || ddl.unsafe
+		}
+		if names != nil {
+			sort.Strings(names)
+			combined.stmt = "DROP TABLE " + strings.Join(names, ", ")
+			return []*DDLStatement{&combined}, nil
+		}
+	}
+	return nil, fmt.Errorf("%d statements are required, but only a single DDL statement can be applied atomically", len(ddls))
+}
@@ -0,0 +1,144 @@
+package applier
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/tengo"
+)
+
+func TestAtomicStatements(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unable to create instance: %v", err)
+	}
+	configMap := map[string]string{
+		"safe-below-size":        "",
+		"alter-wrapper":          "",
+		"alter-wrapper-min-size": "",
+		"ddl-wrapper":            "",
+		"gh-ost":                 "",
+		"foreign-key-checks":     "",
+		"lock-wait-timeout":      "",
+		"statement-timeout":      "",
+		"show-table-size":        "",
+		"dry-run-validate":       "",
+	}
+	target := &Target{
+		Instance:   inst,
+		Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(configMap)},
+		SchemaName: "analytics",
+	}
+	makeTable := func(name, engine string, colTypes ...string) *tengo.Table {
+		table := &tengo.Table{
+			Name:               name,
+			Engine:             engine,
+			CharSet:            "latin1",
+			Collation:          "latin1_swedish_ci",
+			CollationIsDefault: true,
+		}
+		for _, colType := range colTypes {
+			table.Columns = append(table.Columns, &tengo.Column{Name: "col_" + strings.Fields(colType)[0], TypeInDB: colType})
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL80)
+		return table
+	}
+
+	// ddlsFor returns DDLStatements for changing from to to
+	ddlsFor := func(target *Target, from, to *tengo.Schema) []*DDLStatement {
+		t.Helper()
+		var ddls []*DDLStatement
+		mods := tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80, AllowUnsafe: true}
+		for _, objDiff := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+			ddl, err := NewDDLStatement(objDiff, mods, target)
+			if err != nil {
+				t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+			} else if ddl != nil {
+				ddls = append(ddls, ddl)
+			}
+		}
+		return ddls
+	}
+
+	empty := &tengo.Schema{Name: "analytics"}
+	twoTables := &tengo.Schema{
+		Name:   "analytics",
+		Tables: []*tengo.Table{makeTable("posts", "InnoDB", "int"), makeTable("users", "InnoDB", "int")},
+	}
+	altered := &tengo.Schema{
+		Name:   "analytics",
+		Tables: []*tengo.Table{makeTable("posts", "InnoDB", "int", "bigint"), makeTable("users", "InnoDB", "int")},
+	}
+	myisam := &tengo.Schema{
+		Name:   "analytics",
+		Tables: []*tengo.Table{makeTable("posts", "MyISAM", "int")},
+	}
+	myisamAltered := &tengo.Schema{
+		Name:   "analytics",
+		Tables: []*tengo.Table{makeTable("posts", "MyISAM", "int", "bigint")},
+	}
+
+	// Multiple DROP TABLEs are combined on MySQL 8.0, but not MariaDB
+	drops := ddlsFor(target, twoTables, empty)
+	grouped, err := atomicStatements(drops, tengo.FlavorMySQL80, twoTables, empty)
+	if err != nil || len(grouped) != 1 {
+		t.Fatalf("Expected multiple drops to be grouped on %s, instead found %d statements, err=%v", tengo.FlavorMySQL80, len(grouped), err)
+	}
+	if expected := "DROP TABLE `posts`, `users`"; grouped[0].stmt != expected {
+		t.Errorf("Expected grouped statement %q, instead found %q", expected, grouped[0].stmt)
+	}
+	if !grouped[0].unsafe {
+		t.Error("Expected grouped statement to be flagged as unsafe, but it was not")
+	}
+	if _, err := atomicStatements(drops, tengo.NewFlavor("mariadb:10.6"), twoTables, empty); err == nil {
+		t.Error("Expected multiple drops to be refused on MariaDB, but they were not")
+	}
+
+	// A single statement is atomic on flavors with atomic DDL, as long as the
+	// table uses InnoDB
+	alter := ddlsFor(target, twoTables, altered)
+	for _, flavor := range []tengo.Flavor{tengo.FlavorMySQL80, tengo.NewFlavor("mariadb:10.6")} {
+		if grouped, err := atomicStatements(alter, flavor, twoTables, altered); err != nil || len(grouped) != 1 || grouped[0] != alter[0] {
+			t.Errorf("Expected single ALTER to be returned as-is on %s, instead found %v, err=%v", flavor, grouped, err)
+		}
+	}
+	if _, err := atomicStatements(ddlsFor(target, myisam, myisamAltered), tengo.FlavorMySQL80, myisam, myisamAltered); err == nil {
+		t.Error("Expected ALTER of MyISAM table to be refused, but it was not")
+	}
+
+	// Flavors without atomic DDL must refuse, even for a single statement
+	for _, flavor := range []tengo.Flavor{tengo.FlavorMySQL57, tengo.FlavorMariaDB105, tengo.FlavorUnknown} {
+		if _, err := atomicStatements(alter, flavor, twoTables, altered); err == nil {
+			t.Errorf("Expected %s to be refused, but it was not", flavor)
+		}
+	}
+
+	// Other combinations of multiple statements cannot be made atomic
+	onlyPosts := &tengo.Schema{Name: "analytics", Tables: []*tengo.Table{makeTable("posts", "InnoDB", "int")}}
+	mixed := ddlsFor(target, altered, onlyPosts)
+	if _, err := atomicStatements(mixed, tengo.FlavorMySQL80, altered, onlyPosts); len(mixed) != 2 || err == nil || !strings.Contains(err.Error(), "2 statements") {
+		t.Errorf("Expected ALTER and DROP to be refused, instead err=%v", err)
+	}
+
+	// Statements run by an external command cannot be made atomic
+	configMap["ddl-wrapper"] = "/bin/echo {DDL}"
+	configMap["connect-options"] = ""
+	configMap["user"] = "root"
+	configMap["password"] = ""
+	configMap["environment"] = "production"
+	wrapperTarget := &Target{
+		Instance:   inst,
+		Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(configMap)},
+		SchemaName: "analytics",
+	}
+	if _, err := atomicStatements(ddlsFor(wrapperTarget, twoTables, altered), tengo.FlavorMySQL80, twoTables, altered); err == nil {
+		t.Error("Expected shell-out statement to be refused, but it was not")
+	}
+
+	// No statements means nothing to do
+	if grouped, err := atomicStatements(nil, tengo.FlavorMySQL57, empty, empty); err != nil || len(grouped) != 0 {
+		t.Errorf("Expected no statements to be trivially atomic, instead found %v, err=%v", grouped, err)
+	}
+}
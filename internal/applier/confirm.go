@@ -0,0 +1,57 @@
+package applier
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	terminal "golang.org/x/term"
+)
+
+// confirmer prompts the user to approve or decline individual unsafe
+// statements, as configured by the interactive option. Prompts are serialized,
+// since multiple targets may be processed concurrently.
+type confirmer struct {
+	sync.Mutex
+	in    *bufio.Reader
+	out   io.Writer
+	isTTY bool
+}
+
+// newConfirmer returns a confirmer which reads answers from in and writes
+// prompts to out. If isTTY is false, all statements are declined without
+// prompting, rather than blocking on input that may never arrive; Target.buildDDL
+// then skips the entire target.
+func newConfirmer(in io.Reader, out io.Writer, isTTY bool) *confirmer {
+	return &confirmer{
+		in:    bufio.NewReader(in),
+		out:   out,
+		isTTY: isTTY,
+	}
+}
+
+// stdinConfirmer is the confirmer used by the interactive option. It is shared
+// by all targets, since they all read from the same STDIN.
+var stdinConfirmer = newConfirmer(os.Stdin, os.Stdout, terminal.IsTerminal(int(os.Stdin.Fd())))
+
+// confirm displays ddl and asks whether it should be run on t, returning true
+// only if the user answers "y" or "yes". Any other answer, or failure to read
+// an answer, declines the statement.
+func (c *confirmer) confirm(t *Target, ddl *DDLStatement) bool {
+	if !c.isTTY {
+		return false
+	}
+	c.Lock()
+	defer c.Unlock()
+	fmt.Fprintf(c.out, "-- %s %s: unsafe statement requires confirmation\n%s\nRun this statement? [y/N] ", t.source(), t.SchemaName, ddl.stmt)
+	answer, err := c.in.ReadString('\n')
+	if err != nil && answer == "" {
+		fmt.Fprintln(c.out)
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
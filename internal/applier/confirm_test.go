@@ -0,0 +1,179 @@
+package applier
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/tengo"
+)
+
+func TestBuildDDLInteractive(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Fake ddl-wrapper requires /bin/sh")
+	}
+
+	// Use a ddl-wrapper which records the name of each object it runs DDL for
+	tmpDir, err := ioutil.TempDir("", "skeema-confirm-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	ranFile := filepath.Join(tmpDir, "ran")
+	cfg := mybase.SimpleConfig(map[string]string{
+		"dry-run":                "",
+		"progress":               "",
+		"safe-below-size":        "",
+		"alter-wrapper":          "",
+		"alter-wrapper-min-size": "",
+		"ddl-wrapper":            "echo {NAME} >> " + ranFile,
+		"gh-ost":                 "",
+		"lock-wait-timeout":      "",
+		"statement-timeout":      "",
+		"show-table-size":        "",
+		"dry-run-validate":       "",
+		"foreign-key-checks":     "",
+		"connect-options":        "",
+		"user":                   "root",
+		"password":               "",
+		"environment":            "production",
+	})
+	inst, err := tengo.NewInstance("mysql", "root@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unable to create instance: %v", err)
+	}
+	target := &Target{
+		Instance:   inst,
+		Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: cfg},
+		SchemaName: "appdb",
+	}
+	makeTable := func(name string) *tengo.Table {
+		table := &tengo.Table{
+			Name:               name,
+			Engine:             "InnoDB",
+			CharSet:            "latin1",
+			Collation:          "latin1_swedish_ci",
+			CollationIsDefault: true,
+			Columns:            []*tengo.Column{{Name: "id", TypeInDB: "int(10) unsigned"}},
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL80)
+		return table
+	}
+
+	// Dropping posts, users, and widgets is unsafe; creating comments is not.
+	// Scripted answers approve the first unsafe statement and decline the
+	// second; the third is declined due to reaching EOF without an answer.
+	from := &tengo.Schema{
+		Name:   "appdb",
+		Tables: []*tengo.Table{makeTable("posts"), makeTable("users"), makeTable("widgets")},
+	}
+	to := &tengo.Schema{
+		Name:   "appdb",
+		Tables: []*tengo.Table{makeTable("comments")},
+	}
+	objDiffs := tengo.NewSchemaDiff(from, to).ObjectDiffs()
+	var unsafeNames []string
+	for _, objDiff := range objDiffs {
+		if objDiff.DiffType() == tengo.DiffTypeDrop {
+			unsafeNames = append(unsafeNames, objDiff.ObjectKey().Name)
+		}
+	}
+	if len(objDiffs) != 4 || len(unsafeNames) != 3 {
+		t.Fatalf("Unexpected object diffs: %v", objDiffs)
+	}
+
+	var logBuf, prompts bytes.Buffer
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+	target.confirm = newConfirmer(strings.NewReader("y\nN\n"), &prompts, true)
+	var result Result
+	ddls, keys, ok := target.buildDDL(objDiffs, tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80}, &result)
+	if !ok {
+		t.Fatalf("Expected buildDDL to succeed, but it did not. Log output:\n%s", logBuf.String())
+	}
+	if len(ddls) != 2 || len(keys) != 2 {
+		t.Fatalf("Expected 2 DDL statements, instead found %d", len(ddls))
+	}
+	expectResult := Result{
+		Differences:         true,
+		SkipCount:           2,
+		UnsafeSkipCount:     2,
+		UnsafeApprovedCount: 1,
+		UnsafeDeclinedCount: 2,
+	}
+	if result != expectResult {
+		t.Errorf("Unexpected result from buildDDL: %+v", result)
+	}
+	if count := strings.Count(prompts.String(), "[y/N]"); count != 3 {
+		t.Errorf("Expected 3 prompts, instead found %d:\n%s", count, prompts.String())
+	}
+	if !strings.Contains(prompts.String(), "DROP TABLE `"+unsafeNames[0]+"`") {
+		t.Errorf("Expected prompt to display DROP TABLE statement, but it did not:\n%s", prompts.String())
+	}
+
+	tmp, err := ioutil.TempFile("", "skeema-confirm-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	oldStdout := os.Stdout
+	os.Stdout = tmp
+	skipCount := target.processDDL(ddls, NewPrinter(false))
+	os.Stdout = oldStdout
+	tmp.Close()
+	if skipCount != 0 {
+		t.Errorf("Expected skipCount of 0, instead found %d", skipCount)
+	}
+	contents, err := ioutil.ReadFile(ranFile)
+	if err != nil {
+		t.Fatalf("Unable to read %s: %v", ranFile, err)
+	}
+	ran := strings.Fields(string(contents))
+	var ranUnsafe []string
+	var ranComments bool
+	for _, name := range ran {
+		if name == "comments" {
+			ranComments = true
+		} else {
+			ranUnsafe = append(ranUnsafe, name)
+		}
+	}
+	if !ranComments || len(ranUnsafe) != 1 || ranUnsafe[0] != unsafeNames[0] {
+		t.Errorf("Expected DDL to run for comments and %s only, instead ran for %v", unsafeNames[0], ran)
+	}
+
+	// Without a TTY, nothing can be confirmed, so the entire target should be
+	// skipped without reading any input, rather than applying only the safe
+	// statements
+	input := strings.NewReader("y\ny\ny\n")
+	prompts.Reset()
+	target.confirm = newConfirmer(input, &prompts, false)
+	result = Result{}
+	if _, _, ok = target.buildDDL(objDiffs, tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80}, &result); ok {
+		t.Error("Expected buildDDL to fail without a TTY, but it did not")
+	}
+	if result.SkipCount != 4 || result.UnsafeSkipCount != 4 || result.UnsafeDeclinedCount != 0 || result.UnsafeApprovedCount != 0 {
+		t.Errorf("Unexpected result from buildDDL without a TTY: %+v", result)
+	}
+	if prompts.Len() > 0 || input.Len() != 6 {
+		t.Errorf("Expected no prompts or reads without a TTY; instead prompted %q with %d bytes unread", prompts.String(), input.Len())
+	}
+
+	// Without any confirmer, an unsafe statement causes the entire target to be
+	// skipped, as usual
+	target.confirm = nil
+	result = Result{}
+	if _, _, ok = target.buildDDL(objDiffs, tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80}, &result); ok {
+		t.Error("Expected buildDDL to fail without a confirmer, but it did not")
+	}
+	if result.SkipCount != 4 || result.UnsafeSkipCount != 4 {
+		t.Errorf("Unexpected result from buildDDL without a confirmer: %+v", result)
+	}
+}
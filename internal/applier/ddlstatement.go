@@ -3,8 +3,11 @@ package applier
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
@@ -17,12 +20,24 @@ import (
 // It may represent an external command to shell out to, or a DDL statement to
 // run directly against a DB.
 type DDLStatement struct {
-	stmt     string
-	shellOut *util.ShellOut
+	stmt      string
+	shellOut  *util.ShellOut
+	ghostConf string // gh-ost config file contents, containing credentials
 
-	instance      *tengo.Instance
+	instance      *tengo.Instance // nil if target uses a dump file
+	source        string          // instance address or dump file path, for output
 	schemaName    string
 	connectParams string
+
+	key      tengo.ObjectKey
+	diffType tengo.DiffType
+	unsafe   bool
+	sizeNote string // table row count and size for show-table-size, if enabled
+
+	// With dry-run-validate, descriptions of predicted failures, and of changes
+	// which could not be validated
+	predictedFailures []string
+	unvalidated       []string
 }
 
 // NewDDLStatement creates and returns a DDLStatement. If the statement ends up
@@ -33,7 +48,10 @@ type DDLStatement struct {
 func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, target *Target) (ddl *DDLStatement, err error) {
 	ddl = &DDLStatement{
 		instance:   target.Instance,
+		source:     target.source(),
 		schemaName: target.SchemaName,
+		key:        diff.ObjectKey(),
+		diffType:   diff.DiffType(),
 	}
 
 	// Don't run database-level DDL in a schema; not even possible for CREATE
@@ -46,14 +64,18 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 	// specified
 	var tableSize int64
 	if needTableSize(diff, target.Dir.Config) {
-		if tableSize, err = getTableSize(target, diff.ObjectKey().Name); err != nil {
+		var hasRows bool
+		if tableSize, hasRows, err = getTableSize(target, diff.ObjectKey().Name); err != nil {
 			return nil, err
 		}
 
 		// If --safe-below-size option in use, enable additional statement modifier
-		// if the table's size is less than the supplied option value
+		// if the table's size is less than the supplied option value. A table with
+		// rows but no reported size (e.g. due to stale stats) is treated as large.
 		if safeBelowSize, err := target.Dir.Config.GetBytes("safe-below-size"); err != nil {
 			return nil, err
+		} else if hasRows && tableSize <= 0 {
+			log.Debugf("Not allowing unsafe operations for %s via safe-below-size: table has rows but its size is unknown", diff.ObjectKey())
 		} else if tableSize < int64(safeBelowSize) {
 			mods.AllowUnsafe = true
 			log.Debugf("Allowing unsafe operations for %s: size=%d < safe-below-size=%d", diff.ObjectKey(), tableSize, safeBelowSize)
@@ -61,16 +83,16 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 	}
 
 	// Options may indicate some/all DDL gets executed by shelling out to another program.
-	wrapper, err := getWrapper(target.Dir.Config, diff, tableSize, &mods)
+	wrapper, err := getWrapper(target.Dir.Config, diff, target.Instance, tableSize, &mods)
 	if err != nil {
 		return nil, err
+	} else if wrapper != "" && target.Instance == nil {
+		return nil, ConfigError("Wrapper options cannot be used when comparing against a dump file")
 	}
 
 	// Get the raw DDL statement as a string, handling errors and noops correctly
 	if ddl.stmt, err = diff.Statement(mods); tengo.IsForbiddenDiff(err) {
-		// Intentionally avoiding fmt.Errorf here to avoid golint complaining about capitalization
-		errorText := fmt.Sprintf("Destructive statement /* %s */ is considered unsafe. Use --allow-unsafe or --safe-below-size to permit this operation; see --help for more information.", ddl.stmt)
-		return nil, errors.New(errorText)
+		return nil, UnsafeStatementError(ddl.stmt)
 	} else if err != nil {
 		// Leave the error untouched/unwrapped to allow caller to handle appropriately
 		return nil, err
@@ -79,8 +101,43 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 		return nil, nil
 	}
 
+	// Annotate ALTER TABLE and DROP TABLE with the table's approximate size, if
+	// requested
+	if target.Dir.Config.GetBool("show-table-size") && diff.ObjectKey().Type == tengo.ObjectTypeTable && diff.DiffType() != tengo.DiffTypeCreate {
+		ddl.sizeNote = tableSizeNote(target, diff.ObjectKey().Name)
+	}
+
+	// Probe the table's data for problems which would cause an ALTER TABLE to
+	// fail, if requested
+	if td, ok := diff.(*tengo.TableDiff); ok && td.DiffType() == tengo.DiffTypeAlter && target.Dir.Config.GetBool("dry-run-validate") {
+		ddl.predictedFailures, ddl.unvalidated = validateAlter(target, td)
+		for _, failure := range ddl.predictedFailures {
+			log.Warnf("%s: predicted to fail: %s", diff.ObjectKey(), failure)
+		}
+	}
+
+	// Flag storage format and collation conversions, since these rebuild the
+	// entire table
+	if td, ok := diff.(*tengo.TableDiff); ok && td.ChangesStorageFormat() {
+		log.Warnf("%s: changing ROW_FORMAT or KEY_BLOCK_SIZE rebuilds the table, which may be slow for large tables", diff.ObjectKey())
+	}
+	if td, ok := diff.(*tengo.TableDiff); ok && td.ConvertsCollation() {
+		log.Warnf("%s: CONVERT TO CHARACTER SET rebuilds the table, which may be slow for large tables", diff.ObjectKey())
+	}
+
+	// Track whether the statement would have been forbidden without unsafe
+	// operations being permitted, for use in output
+	if mods.AllowUnsafe {
+		safeMods := mods
+		safeMods.AllowUnsafe = false
+		_, err := diff.Statement(safeMods)
+		ddl.unsafe = tengo.IsForbiddenDiff(err)
+	}
+
 	if wrapper == "" {
-		ddl.connectParams = getConnectParams(diff, target.Dir.Config)
+		if ddl.connectParams, err = getConnectParams(diff, target.Dir.Config, mods.Flavor); err != nil {
+			return nil, ConfigError(err.Error())
+		}
 	} else {
 		var socket, port, connOpts string
 		if ddl.instance.SocketPath != "" {
@@ -121,11 +178,24 @@ func NewDDLStatement(diff tengo.ObjectDiff, mods tengo.StatementModifiers, targe
 			errorText := fmt.Sprintf("A fatal error occurred with pre-processing a DDL statement: %s.", err)
 			return nil, errors.New(errorText)
 		}
+		if strings.Contains(wrapper, "$"+ghostConfVar) {
+			ddl.ghostConf = fmt.Sprintf("[client]\nuser = %s\npassword = %s\n", ghostConfValue(variables["USER"]), ghostConfValue(variables["PASSWORD"]))
+		}
 	}
 
 	return ddl, nil
 }
 
+// UnsafeStatementError is returned by NewDDLStatement when a destructive
+// statement is needed, but unsafe statements are not permitted by the
+// allow-unsafe or safe-below-size options.
+type UnsafeStatementError string
+
+// Error satisfies the builtin error interface.
+func (use UnsafeStatementError) Error() string {
+	return fmt.Sprintf("Destructive statement /* %s */ is considered unsafe. Use --allow-unsafe or --safe-below-size to permit this operation; see --help for more information.", string(use))
+}
+
 // needTableSize returns true if diff represents an ALTER TABLE or DROP TABLE,
 // and at least one size-related option is in use, meaning that it will be
 // necessary to query for the table's size.
@@ -155,68 +225,206 @@ func needTableSize(diff tengo.ObjectDiff, config *mybase.Config) bool {
 }
 
 // getTableSize returns the size of the table on the instance corresponding to
-// the target. If the table has no rows, this method always returns a size of 0,
-// even though information_schema normally indicates at least 16kb in this case.
-func getTableSize(target *Target, tableName string) (int64, error) {
-	hasRows, err := target.Instance.TableHasRows(target.SchemaName, tableName)
+// the target, along with whether the table has any rows. If the table has no
+// rows, this method always returns a size of 0, even though information_schema
+// normally indicates at least 16kb in this case.
+func getTableSize(target *Target, tableName string) (size int64, hasRows bool, err error) {
+	if target.Instance == nil {
+		return 0, false, ConfigError("Table sizes are not available when comparing against a dump file, so size-related options cannot be used with from-dump")
+	}
+	hasRows, err = target.Instance.TableHasRows(target.SchemaName, tableName)
 	if !hasRows || err != nil {
-		return 0, err
+		return 0, hasRows, err
 	}
-	return target.Instance.TableSize(target.SchemaName, tableName)
+	size, err = target.Instance.TableSize(target.SchemaName, tableName)
+	return size, true, err
+}
+
+// tableSizeNote returns a description of the approximate row count and
+// data+index size of the table on the instance corresponding to the target,
+// for use with the show-table-size option. If these statistics cannot be
+// obtained, such as when comparing against a dump file, "unknown" is returned.
+func tableSizeNote(target *Target, tableName string) string {
+	if target.Instance == nil {
+		return "unknown"
+	}
+	rows, size, err := target.Instance.TableStats(target.SchemaName, tableName)
+	if err != nil {
+		log.Debugf("Unable to obtain size of table %s: %s", tengo.EscapeIdentifier(tableName), err)
+		return "unknown"
+	}
+	return fmt.Sprintf("~%d rows, %s", rows, formatBytes(size))
+}
+
+// formatBytes returns a human-readable representation of a number of bytes,
+// using binary units.
+func formatBytes(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	value := float64(size)
+	var unit string
+	for _, unit = range []string{"KiB", "MiB", "GiB", "TiB"} {
+		value /= 1024
+		if value < 1024 {
+			break
+		}
+	}
+	return fmt.Sprintf("%.1f %s", value, unit)
 }
 
 // getWrapper returns the command-line for executing diff as a shell-out, if
 // configured to do so. Any variable placeholders in the returned string have
 // NOT been interpolated yet.
-func getWrapper(config *mybase.Config, diff tengo.ObjectDiff, tableSize int64, mods *tengo.StatementModifiers) (string, error) {
+func getWrapper(config *mybase.Config, diff tengo.ObjectDiff, inst *tengo.Instance, tableSize int64, mods *tengo.StatementModifiers) (string, error) {
 	wrapper := config.Get("ddl-wrapper")
-	if diff.ObjectKey().Type == tengo.ObjectTypeTable && diff.DiffType() == tengo.DiffTypeAlter && config.Changed("alter-wrapper") {
-		minSize, err := config.GetBytes("alter-wrapper-min-size")
-		if err != nil {
-			return "", ConfigError(err.Error())
-		}
-		if tableSize >= int64(minSize) {
-			wrapper = config.Get("alter-wrapper")
-
-			// If alter-wrapper-min-size is set, and the table is big enough to use
-			// alter-wrapper, disable --alter-algorithm and --alter-lock. This allows
-			// for a configuration using built-in online DDL for small tables, and an
-			// external OSC tool for large tables, without risk of ALGORITHM or LOCK
-			// clauses breaking expectations of the OSC tool.
-			if minSize > 0 {
-				log.Debugf("Using alter-wrapper for %s: size=%d >= alter-wrapper-min-size=%d", diff.ObjectKey(), tableSize, minSize)
-				if mods.AlgorithmClause != "" || mods.LockClause != "" {
-					log.Debug("Ignoring --alter-algorithm and --alter-lock for generating DDL for alter-wrapper")
-					mods.AlgorithmClause = ""
-					mods.LockClause = ""
-				}
-			}
-		} else {
-			log.Debugf("Skipping alter-wrapper for %s: size=%d < alter-wrapper-min-size=%d", diff.ObjectKey(), tableSize, minSize)
+	if diff.ObjectKey().Type != tengo.ObjectTypeTable || diff.DiffType() != tengo.DiffTypeAlter {
+		return wrapper, nil
+	}
+	useGhost := config.GetBool("gh-ost")
+	if useGhost && config.Changed("alter-wrapper") {
+		return "", ConfigError("Options --gh-ost and --alter-wrapper cannot be used together")
+	} else if !useGhost && !config.Changed("alter-wrapper") {
+		return wrapper, nil
+	}
+	minSize, err := config.GetBytes("alter-wrapper-min-size")
+	if err != nil {
+		return "", ConfigError(err.Error())
+	}
+	if tableSize < int64(minSize) {
+		log.Debugf("Skipping alter-wrapper for %s: size=%d < alter-wrapper-min-size=%d", diff.ObjectKey(), tableSize, minSize)
+		return wrapper, nil
+	}
+	if minSize > 0 {
+		log.Debugf("Using alter-wrapper for %s: size=%d >= alter-wrapper-min-size=%d", diff.ObjectKey(), tableSize, minSize)
+	}
+
+	// If alter-wrapper-min-size is set, and the table is big enough to use
+	// alter-wrapper, disable --alter-algorithm and --alter-lock. This allows
+	// for a configuration using built-in online DDL for small tables, and an
+	// external OSC tool for large tables, without risk of ALGORITHM or LOCK
+	// clauses breaking expectations of the OSC tool. gh-ost never accepts these
+	// clauses, so they're always disabled for it.
+	if (minSize > 0 || useGhost) && (mods.AlgorithmClause != "" || mods.LockClause != "") {
+		log.Debug("Ignoring --alter-algorithm and --alter-lock for generating DDL for alter-wrapper")
+		mods.AlgorithmClause = ""
+		mods.LockClause = ""
+	}
+	if useGhost {
+		return getGhostWrapper(config, inst)
+	}
+	return config.Get("alter-wrapper"), nil
+}
+
+// ghostConfVar is the name of the environment variable supplying gh-ost with
+// the path to a temporary config file containing the database credentials.
+const ghostConfVar = "SKEEMA_GHOST_CONF"
+
+// getGhostWrapper returns a command-line template for executing an ALTER TABLE
+// using gh-ost, with connection parameters, the table name, and the ALTER
+// clauses passed as flags. Any extra flags from --gh-ost-flags are appended
+// as-is. The user and password are not passed as flags, since they would be
+// visible in the process list; see DDLStatement.runGhost.
+func getGhostWrapper(config *mybase.Config, inst *tengo.Instance) (string, error) {
+	if inst != nil && inst.SocketPath != "" {
+		return "", ConfigError("Option --gh-ost requires a TCP connection, but a UNIX domain socket is configured")
+	}
+	for _, name := range []string{"ssh", "socks5"} {
+		if config.Get(name) != "" {
+			return "", ConfigError("Options --gh-ost and --" + name + " cannot be used together, since gh-ost would connect to the database directly")
 		}
 	}
+	bin := config.Get("gh-ost-bin")
+	if bin == "" {
+		return "", ConfigError("Option --gh-ost-bin must not be blank when using --gh-ost")
+	}
+	wrapper := bin + " --conf=\"$" + ghostConfVar + "\" --host={HOST} --port={PORT} --database={SCHEMA} --table={TABLE} --alter={CLAUSES} --execute"
+	if flags := config.Get("gh-ost-flags"); flags != "" {
+		wrapper += " " + flags
+	}
 	return wrapper, nil
 }
 
+// ghostConfValue returns value quoted and escaped for use in a gh-ost config
+// file, which uses git-config syntax.
+func ghostConfValue(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
 // getConnectParams returns the necessary connection params (session variables)
-// for the supplied diff and config.
-func getConnectParams(diff tengo.ObjectDiff, config *mybase.Config) string {
+// for the supplied diff, config, and flavor. An error is returned if the config
+// has invalid timeout option values.
+func getConnectParams(diff tengo.ObjectDiff, config *mybase.Config, flavor tengo.Flavor) (string, error) {
+	var params []string
+
 	// Use unlimited query timeout for ALTER TABLE or DROP TABLE, since these
 	// operations can be slow on large tables.
 	// For ALTER TABLE, if requested, also use foreign_key_checks=1 if adding
 	// new foreign key constraints.
 	if td, ok := diff.(*tengo.TableDiff); ok && td.Type == tengo.DiffTypeAlter {
+		params = append(params, "readTimeout=0")
 		if config.GetBool("foreign-key-checks") {
 			_, addFKs := td.SplitAddForeignKeys()
 			if addFKs != nil {
-				return "readTimeout=0&foreign_key_checks=1"
+				params = append(params, "foreign_key_checks=1")
 			}
 		}
-		return "readTimeout=0"
 	} else if ok && td.Type == tengo.DiffTypeDrop {
-		return "readTimeout=0"
+		params = append(params, "readTimeout=0")
+	}
+
+	timeoutParams, err := getTimeoutParams(config, flavor)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append(params, timeoutParams...), "&"), nil
+}
+
+// getTimeoutParams returns connection params (session variables) limiting how
+// long each statement may wait for locks or run, based on the lock-wait-timeout
+// and statement-timeout options. A zero or blank value for either option
+// leaves the corresponding server default in place.
+func getTimeoutParams(config *mybase.Config, flavor tengo.Flavor) (params []string, err error) {
+	lockWait, err := getTimeoutOption(config, "lock-wait-timeout")
+	if err != nil {
+		return nil, err
+	} else if lockWait > 0 {
+		// lock_wait_timeout is in whole seconds, with a minimum of 1
+		seconds := int64((lockWait + time.Second - 1) / time.Second)
+		params = append(params, fmt.Sprintf("lock_wait_timeout=%d", seconds))
+	}
+
+	stmtTimeout, err := getTimeoutOption(config, "statement-timeout")
+	if err != nil {
+		return nil, err
+	} else if stmtTimeout > 0 {
+		// MariaDB's max_statement_time is in seconds but permits fractional
+		// values. MySQL's max_execution_time is not usable here, since MySQL only
+		// enforces it for SELECT statements, so the option would silently have no
+		// effect on DDL.
+		if !flavor.VendorMinVersion(tengo.VendorMariaDB, 10, 1) {
+			return nil, fmt.Errorf("Option statement-timeout is not supported by flavor %s: only MariaDB 10.1+ can limit the execution time of DDL statements", flavor)
+		}
+		params = append(params, fmt.Sprintf("max_statement_time=%s", strconv.FormatFloat(stmtTimeout.Seconds(), 'f', -1, 64)))
+	}
+	return params, nil
+}
+
+// getTimeoutOption parses the duration value of the named option. A blank
+// value is treated as zero.
+func getTimeoutOption(config *mybase.Config, name string) (time.Duration, error) {
+	value := config.Get(name)
+	if value == "" {
+		return 0, nil
 	}
-	return ""
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("Option %s has invalid value: %s", name, err)
+	} else if d < 0 {
+		return 0, fmt.Errorf("Option %s cannot be negative", name)
+	}
+	return d, nil
 }
 
 // IsShellOut returns true if the DDL is to be executed via shelling out to an
@@ -239,7 +447,9 @@ func (ddl *DDLStatement) String() string {
 // Execute runs the DDL statement, either by running a SQL query against a DB,
 // or shelling out to an external program, as appropriate.
 func (ddl *DDLStatement) Execute() error {
-	if ddl.IsShellOut() {
+	if ddl.ghostConf != "" {
+		return ddl.runGhost()
+	} else if ddl.IsShellOut() {
 		return ddl.shellOut.Run()
 	}
 	db, err := ddl.instance.CachedConnectionPool(ddl.schemaName, ddl.connectParams)
@@ -249,3 +459,25 @@ func (ddl *DDLStatement) Execute() error {
 	_, err = db.Exec(ddl.stmt)
 	return err
 }
+
+// runGhost runs the gh-ost shellout, first writing the database credentials to
+// a temporary config file only readable by the current user. The file's path is
+// supplied to gh-ost via an environment variable, and the file is removed once
+// gh-ost exits.
+func (ddl *DDLStatement) runGhost() error {
+	f, err := ioutil.TempFile("", "skeema-gh-ost-*.cnf") // always created with mode 0600
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(ddl.ghostConf)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	s := *ddl.shellOut
+	s.Env = append([]string{ghostConfVar + "=" + f.Name()}, ddl.shellOut.Env...)
+	return s.Run()
+}
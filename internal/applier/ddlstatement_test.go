@@ -1,12 +1,17 @@
 package applier
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/workspace"
@@ -58,6 +63,12 @@ func (s ApplierIntegrationSuite) TestNewDDLStatement(t *testing.T) {
 		"ddl-wrapper":            "/bin/echo ddl-wrapper {SCHEMA}.{NAME} {TYPE} {CLASS}",
 		"alter-wrapper":          "/bin/echo alter-wrapper {SCHEMA}.{TABLE} {TYPE} {CLAUSES}",
 		"alter-wrapper-min-size": "1",
+		"gh-ost":                 "",
+		"foreign-key-checks":     "",
+		"lock-wait-timeout":      "",
+		"statement-timeout":      "",
+		"show-table-size":        "",
+		"dry-run-validate":       "",
 		"alter-algorithm":        "inplace",
 		"alter-lock":             "none",
 		"safe-below-size":        "0",
@@ -119,6 +130,305 @@ func (s ApplierIntegrationSuite) TestNewDDLStatement(t *testing.T) {
 	}
 }
 
+// TestNewDDLStatementSafeBelowSize confirms that safe-below-size permits unsafe
+// operations on a per-table basis, depending on each table's size.
+func (s ApplierIntegrationSuite) TestNewDDLStatementSafeBelowSize(t *testing.T) {
+	if _, err := s.d[0].SourceSQL("testdata/safebelowsize.sql"); err != nil {
+		t.Fatalf("Unexpected error from SourceSQL: %s", err)
+	}
+	fsSchema, err := s.d[0].Schema("sizes")
+	if err != nil {
+		t.Fatalf("Unable to obtain schema: %s", err)
+	}
+
+	// Add a column to every table in the db, so that the diff will require an
+	// unsafe DROP COLUMN for each. Populate large_rows with a few MB of data, and
+	// small_rows with a single row.
+	db, err := s.d[0].CachedConnectionPool("sizes", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+	queries := []string{
+		"ALTER TABLE small_rows ADD COLUMN extra char(255)",
+		"ALTER TABLE large_rows ADD COLUMN extra char(255)",
+		"ALTER TABLE no_rows ADD COLUMN extra char(255)",
+		"INSERT INTO small_rows (name, extra) VALUES ('foo', 'bar')",
+		"INSERT INTO large_rows (name, extra) VALUES ('foo', REPEAT('x', 255))",
+	}
+	for n := 0; n < 14; n++ {
+		queries = append(queries, "INSERT INTO large_rows (name, extra) SELECT name, extra FROM large_rows")
+	}
+	queries = append(queries, "ANALYZE TABLE small_rows, large_rows")
+	for _, query := range queries {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Error running query %s: %s", query, err)
+		}
+	}
+	instSchema, err := s.d[0].Schema("sizes")
+	if err != nil {
+		t.Fatalf("Unable to obtain schema: %s", err)
+	}
+
+	configMap := map[string]string{
+		"user":                   "root",
+		"password":               s.d[0].Instance.Password,
+		"environment":            "production",
+		"connect-options":        "",
+		"safe-below-size":        "1m",
+		"alter-wrapper":          "",
+		"alter-wrapper-min-size": "",
+		"ddl-wrapper":            "",
+		"gh-ost":                 "",
+		"foreign-key-checks":     "",
+		"lock-wait-timeout":      "",
+		"statement-timeout":      "",
+		"show-table-size":        "",
+		"dry-run-validate":       "",
+	}
+	target := &Target{
+		Instance:      s.d[0].Instance,
+		Dir:           &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(configMap)},
+		SchemaName:    "sizes",
+		DesiredSchema: &workspace.Schema{Schema: fsSchema},
+	}
+	expectAllowed := map[string]bool{
+		"small_rows": true,
+		"large_rows": false,
+		"no_rows":    true,
+	}
+	objDiffs := tengo.NewSchemaDiff(instSchema, fsSchema).ObjectDiffs()
+	if len(objDiffs) != len(expectAllowed) {
+		t.Fatalf("Expected %d object diffs, instead found %d", len(expectAllowed), len(objDiffs))
+	}
+	for _, diff := range objDiffs {
+		name := diff.ObjectKey().Name
+		ddl, err := NewDDLStatement(diff, tengo.StatementModifiers{}, target)
+		if expectAllowed[name] {
+			if err != nil {
+				t.Errorf("Expected unsafe operation on %s to be permitted, but received error: %s", name, err)
+			} else if !ddl.unsafe {
+				t.Errorf("Expected DDLStatement for %s to be marked unsafe, but it was not", name)
+			}
+		} else if _, ok := err.(UnsafeStatementError); !ok {
+			t.Errorf("Expected unsafe operation on %s to return UnsafeStatementError, instead found %T %v", name, err, err)
+		}
+	}
+
+	// With allow-unsafe, the large table's change should also be permitted
+	for _, diff := range objDiffs {
+		if _, err := NewDDLStatement(diff, tengo.StatementModifiers{AllowUnsafe: true}, target); err != nil {
+			t.Errorf("Unexpected error from NewDDLStatement with allow-unsafe: %s", err)
+		}
+	}
+}
+
+// TestNewDDLStatementShowTableSize confirms that show-table-size annotates
+// ALTER TABLE and DROP TABLE statements with each table's approximate size.
+func (s ApplierIntegrationSuite) TestNewDDLStatementShowTableSize(t *testing.T) {
+	if _, err := s.d[0].SourceSQL("testdata/safebelowsize.sql"); err != nil {
+		t.Fatalf("Unexpected error from SourceSQL: %s", err)
+	}
+	fsSchema, err := s.d[0].Schema("sizes")
+	if err != nil {
+		t.Fatalf("Unable to obtain schema: %s", err)
+	}
+
+	// Populate large_rows with a few MB of data, and small_rows with a single
+	// row. Then add a column to large_rows and small_rows so that the diff
+	// requires an ALTER for each of them; also drop no_rows from the filesystem
+	// side so that it requires a DROP.
+	db, err := s.d[0].CachedConnectionPool("sizes", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+	queries := []string{
+		"ALTER TABLE small_rows ADD COLUMN extra char(255)",
+		"ALTER TABLE large_rows ADD COLUMN extra char(255)",
+		"INSERT INTO small_rows (name, extra) VALUES ('foo', 'bar')",
+		"INSERT INTO large_rows (name, extra) VALUES ('foo', REPEAT('x', 255))",
+	}
+	for n := 0; n < 14; n++ {
+		queries = append(queries, "INSERT INTO large_rows (name, extra) SELECT name, extra FROM large_rows")
+	}
+	queries = append(queries, "ANALYZE TABLE small_rows, large_rows, no_rows")
+	for _, query := range queries {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Error running query %s: %s", query, err)
+		}
+	}
+	instSchema, err := s.d[0].Schema("sizes")
+	if err != nil {
+		t.Fatalf("Unable to obtain schema: %s", err)
+	}
+	var keepTables []*tengo.Table
+	for _, table := range fsSchema.Tables {
+		if table.Name != "no_rows" {
+			keepTables = append(keepTables, table)
+		}
+	}
+	fsSchema.Tables = keepTables
+
+	target := newTestTarget(t, map[string]string{"password": s.d[0].Instance.Password, "show-table-size": "1"})
+	target.Instance = s.d[0].Instance
+	target.SchemaName = "sizes"
+	target.DesiredSchema = &workspace.Schema{Schema: fsSchema}
+	expectNote := map[string]*regexp.Regexp{
+		"small_rows": regexp.MustCompile(`^~\d+ rows, \d+\.\d KiB$`),
+		"large_rows": regexp.MustCompile(`^~\d+ rows, \d+\.\d MiB$`),
+		"no_rows":    regexp.MustCompile(`^~0 rows, \d+\.\d KiB$`),
+	}
+	objDiffs := tengo.NewSchemaDiff(instSchema, fsSchema).ObjectDiffs()
+	if len(objDiffs) != len(expectNote) {
+		t.Fatalf("Expected %d object diffs, instead found %d", len(expectNote), len(objDiffs))
+	}
+	for _, diff := range objDiffs {
+		name := diff.ObjectKey().Name
+		ddl, err := NewDDLStatement(diff, tengo.StatementModifiers{AllowUnsafe: true}, target)
+		if err != nil {
+			t.Errorf("Unexpected error from NewDDLStatement for %s: %s", name, err)
+		} else if !expectNote[name].MatchString(ddl.sizeNote) {
+			t.Errorf("Expected size note for %s to match %s, instead found %q", name, expectNote[name], ddl.sizeNote)
+		}
+	}
+}
+
+// TestNewDDLStatementDryRunValidate confirms that dry-run-validate predicts
+// failures of ALTER TABLEs which tighten constraints that the table's existing
+// data does not satisfy.
+func (s ApplierIntegrationSuite) TestNewDDLStatementDryRunValidate(t *testing.T) {
+	if _, err := s.d[0].SourceSQL("testdata/validate.sql"); err != nil {
+		t.Fatalf("Unexpected error from SourceSQL: %s", err)
+	}
+	fsSchema, err := s.d[0].Schema("validate")
+	if err != nil {
+		t.Fatalf("Unable to obtain schema: %s", err)
+	}
+
+	// Loosen the constraints of both tables in the database, so that the diff
+	// requires making email NOT NULL and adding a unique key on nickname. Only
+	// has_nulls gets data which violates these constraints.
+	db, err := s.d[0].CachedConnectionPool("validate", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
+	}
+	queries := []string{
+		"ALTER TABLE has_nulls MODIFY COLUMN email varchar(80), DROP KEY nickname",
+		"ALTER TABLE no_nulls MODIFY COLUMN email varchar(80), DROP KEY nickname",
+		"INSERT INTO has_nulls (email, nickname) VALUES (NULL, 'foo'), ('a@example.com', 'foo'), ('b@example.com', 'bar')",
+		"INSERT INTO no_nulls (email, nickname) VALUES ('a@example.com', 'foo'), ('b@example.com', 'bar')",
+	}
+	for _, query := range queries {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Error running query %s: %s", query, err)
+		}
+	}
+	instSchema, err := s.d[0].Schema("validate")
+	if err != nil {
+		t.Fatalf("Unable to obtain schema: %s", err)
+	}
+
+	target := newTestTarget(t, map[string]string{"password": s.d[0].Instance.Password, "dry-run-validate": "1"})
+	target.Instance = s.d[0].Instance
+	target.SchemaName = "validate"
+	target.DesiredSchema = &workspace.Schema{Schema: fsSchema}
+	objDiffs := tengo.NewSchemaDiff(instSchema, fsSchema).ObjectDiffs()
+	if len(objDiffs) != 2 {
+		t.Fatalf("Expected 2 object diffs, instead found %d", len(objDiffs))
+	}
+	for _, diff := range objDiffs {
+		name := diff.ObjectKey().Name
+		ddl, err := NewDDLStatement(diff, tengo.StatementModifiers{Flavor: s.d[0].Flavor(), AllowUnsafe: true}, target)
+		if err != nil {
+			t.Errorf("Unexpected error from NewDDLStatement for %s: %s", name, err)
+			continue
+		}
+		if len(ddl.unvalidated) > 0 {
+			t.Errorf("Expected all changes to %s to be validated, instead found %v", name, ddl.unvalidated)
+		}
+		if name == "no_nulls" && len(ddl.predictedFailures) > 0 {
+			t.Errorf("Expected no predicted failures for %s, instead found %v", name, ddl.predictedFailures)
+		} else if name == "has_nulls" {
+			if len(ddl.predictedFailures) != 2 {
+				t.Errorf("Expected 2 predicted failures for %s, instead found %v", name, ddl.predictedFailures)
+			} else if !strings.Contains(ddl.predictedFailures[0], "`email` contains NULL values") && !strings.Contains(ddl.predictedFailures[1], "`email` contains NULL values") {
+				t.Errorf("Expected predicted failure due to NULL values in email column, instead found %v", ddl.predictedFailures)
+			}
+		}
+	}
+
+	// Nothing should have been changed by validation
+	if afterSchema, err := s.d[0].Schema("validate"); err != nil {
+		t.Fatalf("Unable to obtain schema: %s", err)
+	} else if diff := tengo.NewSchemaDiff(instSchema, afterSchema); len(diff.ObjectDiffs()) > 0 {
+		t.Errorf("Expected validation to leave schema unchanged, but found differences: %s", diff)
+	}
+}
+
+// TestNewDDLStatementTimeouts confirms that lock-wait-timeout and
+// statement-timeout are applied as session variables on the connection used to
+// execute DDL.
+func (s ApplierIntegrationSuite) TestNewDDLStatementTimeouts(t *testing.T) {
+	if _, err := s.d[0].SourceSQL("testdata/setup.sql"); err != nil {
+		t.Fatalf("Unexpected error from SourceSQL: %s", err)
+	}
+	instSchema, err := s.d[0].Schema("analytics")
+	if err != nil {
+		t.Fatalf("Unable to obtain schema: %s", err)
+	}
+	fsSchema, err := s.d[0].Schema("analytics")
+	if err != nil {
+		t.Fatalf("Unable to obtain schema: %s", err)
+	}
+	fsSchema.Tables = fsSchema.Tables[1:]
+
+	overrides := map[string]string{
+		"password":          s.d[0].Instance.Password,
+		"lock-wait-timeout": "7s",
+		"statement-timeout": "90s",
+	}
+	target := newTestTarget(t, overrides)
+	target.Instance = s.d[0].Instance
+	target.DesiredSchema = &workspace.Schema{Schema: fsSchema}
+	objDiffs := tengo.NewSchemaDiff(instSchema, fsSchema).ObjectDiffs()
+	if len(objDiffs) != 1 {
+		t.Fatalf("Expected 1 object diff, instead found %d", len(objDiffs))
+	}
+	mods := tengo.StatementModifiers{AllowUnsafe: true, Flavor: s.d[0].Flavor()}
+
+	// statement-timeout is only supported by MariaDB, since MySQL does not
+	// enforce max_execution_time for DDL
+	supportsStmtTimeout := mods.Flavor.VendorMinVersion(tengo.VendorMariaDB, 10, 1)
+	if !supportsStmtTimeout {
+		if _, err := NewDDLStatement(objDiffs[0], mods, target); err == nil {
+			t.Errorf("Expected NewDDLStatement to return an error for statement-timeout with flavor %s, but it did not", mods.Flavor)
+		}
+		overrides["statement-timeout"] = ""
+		target.Dir = newTestTarget(t, overrides).Dir
+	}
+	ddl, err := NewDDLStatement(objDiffs[0], mods, target)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewDDLStatement: %s", err)
+	}
+	db, err := s.d[0].ConnectionPool("analytics", ddl.connectParams)
+	if err != nil {
+		t.Fatalf("Unable to connect using DDLStatement's connect params %q: %s", ddl.connectParams, err)
+	}
+	defer db.Close()
+	var lockWait int
+	if err := db.QueryRow("SELECT @@lock_wait_timeout").Scan(&lockWait); err != nil || lockWait != 7 {
+		t.Errorf("Expected session lock_wait_timeout to be 7, instead found %d (err=%v)", lockWait, err)
+	}
+	if supportsStmtTimeout {
+		var stmtTimeout float64
+		if err := db.QueryRow("SELECT @@max_statement_time").Scan(&stmtTimeout); err != nil || stmtTimeout != 90 {
+			t.Errorf("Expected session max_statement_time to be 90, instead found %v (err=%v)", stmtTimeout, err)
+		}
+	}
+	if err := ddl.Execute(); err != nil {
+		t.Errorf("Unexpected error from Execute: %s", err)
+	}
+}
+
 // helper for TestNewDDLStatement; return value is specific to the setup of
 // that test
 func objectDiffExpected(t *testing.T, diff tengo.ObjectDiff, ddl *DDLStatement, flavor tengo.Flavor) (expected string) {
@@ -172,3 +482,978 @@ func objectDiffExpected(t *testing.T, diff tengo.ObjectDiff, ddl *DDLStatement,
 	}
 	return
 }
+
+// newTestTarget returns a Target for use in unit tests which do not require a
+// live database. Its config sets every option used by NewDDLStatement to its
+// default value, other than any supplied in overrides. Its Instance is never
+// actually connected to.
+func newTestTarget(t *testing.T, overrides map[string]string) *Target {
+	t.Helper()
+	configMap := map[string]string{
+		"user":                   "root",
+		"password":               "",
+		"environment":            "production",
+		"connect-options":        "",
+		"ssh":                    "",
+		"socks5":                 "",
+		"safe-below-size":        "",
+		"alter-wrapper":          "",
+		"alter-wrapper-min-size": "",
+		"ddl-wrapper":            "",
+		"gh-ost":                 "",
+		"gh-ost-bin":             "gh-ost",
+		"gh-ost-flags":           "",
+		"foreign-key-checks":     "",
+		"lock-wait-timeout":      "",
+		"statement-timeout":      "",
+		"show-table-size":        "",
+		"dry-run-validate":       "",
+	}
+	for name, value := range overrides {
+		configMap[name] = value
+	}
+	inst, err := tengo.NewInstance("mysql", "root@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unable to create instance: %v", err)
+	}
+	return &Target{
+		Instance:   inst,
+		Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(configMap)},
+		SchemaName: "analytics",
+	}
+}
+
+// makeTestTable returns an InnoDB table with the supplied name and columns,
+// using the latin1 charset and its default collation. Its CreateStatement is
+// generated for the supplied flavor, so callers that modify other fields of the
+// table afterwards must regenerate it.
+func makeTestTable(flavor tengo.Flavor, name string, cols ...*tengo.Column) *tengo.Table {
+	table := &tengo.Table{
+		Name:               name,
+		Engine:             "InnoDB",
+		CharSet:            "latin1",
+		Collation:          "latin1_swedish_ci",
+		CollationIsDefault: true,
+		Columns:            cols,
+	}
+	table.CreateStatement = table.GeneratedCreateStatement(flavor)
+	return table
+}
+
+func TestNewDDLStatementGhost(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Fake gh-ost script requires /bin/sh")
+	}
+
+	// Set up fake gh-ost binaries: one which records its args, one per line,
+	// followed by the mode and contents of its config file; and another which
+	// always fails
+	tmpDir, err := ioutil.TempDir("", "skeema-ghost-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	fakeGhost := filepath.Join(tmpDir, "gh-ost")
+	failingGhost := filepath.Join(tmpDir, "gh-ost-fail")
+	outFile := filepath.Join(tmpDir, "out")
+	fakeScript := "#!/bin/sh\nfor arg in \"$@\"; do echo \"$arg\"; done > " + outFile + "\nls -l \"$SKEEMA_GHOST_CONF\" | cut -c1-10 >> " + outFile + "\ncat \"$SKEEMA_GHOST_CONF\" >> " + outFile + "\n"
+	if err := ioutil.WriteFile(fakeGhost, []byte(fakeScript), 0755); err != nil {
+		t.Fatalf("Unable to write %s: %v", fakeGhost, err)
+	}
+	if err := ioutil.WriteFile(failingGhost, []byte("#!/bin/sh\nexit 3\n"), 0755); err != nil {
+		t.Fatalf("Unable to write %s: %v", failingGhost, err)
+	}
+
+	overrides := map[string]string{
+		"password":     "s3cr3t pass",
+		"gh-ost":       "1",
+		"gh-ost-bin":   fakeGhost,
+		"gh-ost-flags": "--allow-on-master --max-load=Threads_running=25",
+	}
+	target := newTestTarget(t, overrides)
+	idCol := &tengo.Column{Name: "id", TypeInDB: "int(10) unsigned"}
+	nameCol := &tengo.Column{Name: "name", TypeInDB: "varchar(30)", Default: "'it''s'", CharSet: "latin1", Collation: "latin1_swedish_ci", CollationIsDefault: true}
+	from := &tengo.Schema{
+		Name:   "analytics",
+		Tables: []*tengo.Table{makeTestTable(tengo.FlavorUnknown, "foo", idCol)},
+	}
+	to := &tengo.Schema{
+		Name:   "analytics",
+		Tables: []*tengo.Table{makeTestTable(tengo.FlavorUnknown, "foo", idCol, nameCol), makeTestTable(tengo.FlavorUnknown, "bar", idCol)},
+	}
+	mods := tengo.StatementModifiers{AlgorithmClause: "inplace", LockClause: "none"}
+	getDDLs := func() (alter, create *DDLStatement) {
+		t.Helper()
+		for _, objDiff := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+			ddl, err := NewDDLStatement(objDiff, mods, target)
+			if err != nil {
+				t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+			}
+			if objDiff.DiffType() == tengo.DiffTypeAlter {
+				alter = ddl
+			} else {
+				create = ddl
+			}
+		}
+		if alter == nil || create == nil {
+			t.Fatal("Test fixture did not generate expected diffs")
+		}
+		return alter, create
+	}
+
+	alter, create := getDDLs()
+	if create.IsShellOut() {
+		t.Errorf("Expected CREATE TABLE to run directly, but it is a shellout: %s", create)
+	}
+	if !alter.IsShellOut() {
+		t.Fatalf("Expected ALTER TABLE to be a shellout, but it is not: %s", alter)
+	}
+	if strings.Contains(alter.shellOut.Command, "s3cr3t") || strings.Contains(alter.shellOut.Command, "--password") {
+		t.Errorf("Expected password to be omitted from gh-ost command-line, instead found %s", alter.shellOut.Command)
+	}
+	if err := alter.Execute(); err != nil {
+		t.Fatalf("Unexpected error running fake gh-ost: %v", err)
+	}
+	contents, err := ioutil.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("Unable to read %s: %v", outFile, err)
+	}
+	actualOutput := string(contents)
+	confArg := strings.SplitN(actualOutput, "\n", 2)[0]
+	if !strings.HasPrefix(confArg, "--conf=") {
+		t.Fatalf("Expected first arg to gh-ost to be --conf, instead found %q", confArg)
+	} else if _, err := os.Stat(strings.TrimPrefix(confArg, "--conf=")); !os.IsNotExist(err) {
+		t.Errorf("Expected gh-ost config file to be removed after use, but Stat returned %v", err)
+	}
+	expectedArgs := []string{
+		confArg,
+		"--host=127.0.0.1",
+		"--port=3306",
+		"--database=analytics",
+		"--table=foo",
+		"--alter=ADD COLUMN `name` varchar(30) NOT NULL DEFAULT 'it''s'",
+		"--execute",
+		"--allow-on-master",
+		"--max-load=Threads_running=25",
+		"-rw-------",
+		"[client]",
+		`user = "root"`,
+		`password = "s3cr3t pass"`,
+	}
+	if expectedOutput := strings.Join(expectedArgs, "\n") + "\n"; actualOutput != expectedOutput {
+		t.Errorf("Incorrect args passed to gh-ost.\nExpected:\n%sActual:\n%s", expectedOutput, actualOutput)
+	}
+
+	// Non-zero exit status from gh-ost should be surfaced as an error
+	overrides["gh-ost-bin"] = failingGhost
+	target = newTestTarget(t, overrides)
+	alter, _ = getDDLs()
+	if err := alter.Execute(); err == nil {
+		t.Error("Expected failing gh-ost to return an error from Execute, but it did not")
+	}
+
+	// gh-ost cannot be combined with alter-wrapper, nor with ssh or socks5 since
+	// gh-ost would bypass the tunnel or proxy
+	alterDiff := tengo.NewSchemaDiff(from, to).FilteredTableDiffs(tengo.DiffTypeAlter)[0]
+	incompatible := map[string]string{
+		"alter-wrapper": "/bin/echo {TABLE}",
+		"ssh":           "bastion.example.com",
+		"socks5":        "proxy.example.com:1080",
+	}
+	for name, value := range incompatible {
+		overrides[name] = value
+		target = newTestTarget(t, overrides)
+		if _, err := NewDDLStatement(alterDiff, mods, target); err == nil {
+			t.Errorf("Expected combining --gh-ost with --%s to return an error, but it did not", name)
+		}
+		delete(overrides, name)
+	}
+}
+
+func TestGetConnectParams(t *testing.T) {
+	configMap := map[string]string{
+		"foreign-key-checks": "",
+		"lock-wait-timeout":  "",
+		"statement-timeout":  "",
+		"show-table-size":    "",
+		"dry-run-validate":   "",
+	}
+	fromTable := &tengo.Table{Name: "foo", Engine: "InnoDB", Columns: []*tengo.Column{{Name: "id", TypeInDB: "int"}}}
+	toTable := &tengo.Table{Name: "foo", Engine: "InnoDB", Columns: []*tengo.Column{{Name: "id", TypeInDB: "bigint"}}}
+	alter := tengo.NewAlterTable(fromTable, toTable)
+	create := tengo.NewCreateTable(toTable)
+
+	cases := []struct {
+		diff        tengo.ObjectDiff
+		lockWait    string
+		stmtTimeout string
+		flavor      tengo.Flavor
+		expected    string
+	}{
+		{create, "", "", tengo.FlavorMySQL80, ""},
+		{alter, "", "", tengo.FlavorMySQL80, "readTimeout=0"},
+		{alter, "0", "0s", tengo.FlavorMySQL80, "readTimeout=0"},
+		{create, "30s", "", tengo.FlavorMySQL80, "lock_wait_timeout=30"},
+		{create, "1500ms", "", tengo.FlavorMySQL80, "lock_wait_timeout=2"},
+		{alter, "10s", "", tengo.FlavorMySQL80, "readTimeout=0&lock_wait_timeout=10"},
+		{create, "", "1500ms", tengo.FlavorMariaDB105, "max_statement_time=1.5"},
+		{alter, "10s", "2m", tengo.FlavorMariaDB105, "readTimeout=0&lock_wait_timeout=10&max_statement_time=120"},
+	}
+	for n, c := range cases {
+		configMap["lock-wait-timeout"] = c.lockWait
+		configMap["statement-timeout"] = c.stmtTimeout
+		actual, err := getConnectParams(c.diff, mybase.SimpleConfig(configMap), c.flavor)
+		if err != nil {
+			t.Errorf("Case %d: Unexpected error from getConnectParams: %v", n, err)
+		} else if actual != c.expected {
+			t.Errorf("Case %d: Expected connect params %q, instead found %q", n, c.expected, actual)
+		}
+	}
+
+	// statement-timeout is only supported by MariaDB 10.1+, since MySQL does not
+	// enforce max_execution_time for DDL
+	configMap["lock-wait-timeout"] = ""
+	configMap["statement-timeout"] = "2m"
+	for _, flavor := range []tengo.Flavor{tengo.FlavorMySQL56, tengo.FlavorMySQL80, tengo.FlavorPercona57, {Vendor: tengo.VendorMariaDB, Major: 10, Minor: 0}} {
+		if _, err := getConnectParams(create, mybase.SimpleConfig(configMap), flavor); err == nil {
+			t.Errorf("Expected error from statement-timeout with flavor %s, but received nil", flavor)
+		}
+	}
+
+	// Invalid durations should return an error
+	for _, value := range []string{"30", "bork", "-5s"} {
+		configMap["lock-wait-timeout"] = value
+		configMap["statement-timeout"] = ""
+		if _, err := getConnectParams(create, mybase.SimpleConfig(configMap), tengo.FlavorMySQL80); err == nil {
+			t.Errorf("Expected error from lock-wait-timeout=%q, but received nil", value)
+		}
+		configMap["lock-wait-timeout"] = ""
+		configMap["statement-timeout"] = value
+		if _, err := getConnectParams(create, mybase.SimpleConfig(configMap), tengo.FlavorMariaDB105); err == nil {
+			t.Errorf("Expected error from statement-timeout=%q, but received nil", value)
+		}
+	}
+}
+
+func TestNewDDLStatementAlterClauses(t *testing.T) {
+	target := newTestTarget(t, nil)
+	makeTable := func(name string, cols ...*tengo.Column) *tengo.Table {
+		return makeTestTable(tengo.FlavorMySQL80, name, append([]*tengo.Column{{Name: "id", TypeInDB: "int(10) unsigned"}}, cols...)...)
+	}
+	nameCol := &tengo.Column{Name: "name", TypeInDB: "varchar(30)", Nullable: true, Default: "NULL", CharSet: "latin1", Collation: "latin1_swedish_ci", CollationIsDefault: true}
+	from := &tengo.Schema{
+		Name:   "analytics",
+		Tables: []*tengo.Table{makeTable("foo"), makeTable("dropme")},
+	}
+	to := &tengo.Schema{
+		Name:   "analytics",
+		Tables: []*tengo.Table{makeTable("foo", nameCol), makeTable("bar")},
+	}
+	mods := tengo.StatementModifiers{
+		AlgorithmClause: "instant",
+		LockClause:      "none",
+		AllowUnsafe:     true,
+		Flavor:          tengo.FlavorMySQL80,
+	}
+	objDiffs := tengo.NewSchemaDiff(from, to).ObjectDiffs()
+	if len(objDiffs) != 3 {
+		t.Fatalf("Expected 3 object diffs, instead found %d", len(objDiffs))
+	}
+	for _, objDiff := range objDiffs {
+		ddl, err := NewDDLStatement(objDiff, mods, target)
+		if err != nil {
+			t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+		}
+		hasClauses := strings.Contains(ddl.stmt, "ALGORITHM=INSTANT, LOCK=NONE")
+		if objDiff.DiffType() == tengo.DiffTypeAlter && !hasClauses {
+			t.Errorf("Expected ALTER to include ALGORITHM and LOCK clauses, instead found %s", ddl.stmt)
+		} else if objDiff.DiffType() != tengo.DiffTypeAlter && strings.Contains(ddl.stmt, "ALGORITHM") {
+			t.Errorf("Expected %s to omit ALGORITHM and LOCK clauses, instead found %s", objDiff.DiffType(), ddl.stmt)
+		}
+	}
+}
+
+func TestNewDDLStatementCreateOptions(t *testing.T) {
+	target := newTestTarget(t, nil)
+	makeTable := func(createOptions string) *tengo.Table {
+		table := makeTestTable(tengo.FlavorMySQL80, "foo", &tengo.Column{Name: "id", TypeInDB: "int(10) unsigned"})
+		table.CreateOptions = createOptions
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL80)
+		return table
+	}
+	mods := tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80}
+
+	cases := []struct {
+		from, to      string
+		expected      string // blank means no diff expected
+		storageFormat bool
+	}{
+		{"", "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8", "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8", true},
+		{"ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8", "", "ROW_FORMAT=DEFAULT KEY_BLOCK_SIZE=0", true},
+		{"ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8", "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=4", "KEY_BLOCK_SIZE=4", true},
+		{"STATS_PERSISTENT=1 ROW_FORMAT=DYNAMIC", "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=4", "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=4 STATS_PERSISTENT=DEFAULT", true},
+		{"", "COMPRESSION='zlib'", "COMPRESSION='zlib'", false},
+		{"COMPRESSION='zlib'", "", "COMPRESSION=''", false},
+		{"KEY_BLOCK_SIZE=8 ROW_FORMAT=COMPRESSED", "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8", "", false},
+		{"COMPRESSION='None'", "", "", false},
+		{"", "COMPRESSION='none'", "", false},
+	}
+	for _, c := range cases {
+		td := tengo.NewAlterTable(makeTable(c.from), makeTable(c.to))
+		if c.expected == "" {
+			if td != nil {
+				stmt, _ := td.Statement(mods)
+				t.Errorf("Expected no difference between create options %q and %q, but found %s", c.from, c.to, stmt)
+			}
+			continue
+		} else if td == nil {
+			t.Errorf("Expected difference between create options %q and %q, but none found", c.from, c.to)
+			continue
+		}
+		ddl, err := NewDDLStatement(td, mods, target)
+		if err != nil {
+			t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+		}
+		if expected := "ALTER TABLE `foo` " + c.expected; ddl.stmt != expected {
+			t.Errorf("Expected statement %q, instead found %q", expected, ddl.stmt)
+		}
+		if td.ChangesStorageFormat() != c.storageFormat {
+			t.Errorf("Expected ChangesStorageFormat to return %t for %q to %q, but it did not", c.storageFormat, c.from, c.to)
+		}
+	}
+}
+
+func TestNewDDLStatementCharSetAliases(t *testing.T) {
+	target := newTestTarget(t, nil)
+
+	// makeTable returns a table using the supplied alias for the utf8 charset,
+	// with an extra utf8_bin column if extraCol is true
+	flavor8030 := tengo.NewFlavor("mysql:8.0.30")
+	makeTable := func(alias string, flavor tengo.Flavor, extraCol bool) *tengo.Table {
+		table := makeTestTable(flavor, "foo",
+			&tengo.Column{Name: "id", TypeInDB: "int unsigned"},
+			&tengo.Column{Name: "name", TypeInDB: "varchar(30)", Nullable: true, Default: "NULL", CharSet: alias, Collation: alias + "_general_ci", CollationIsDefault: true},
+		)
+		table.CharSet, table.Collation = alias, alias+"_general_ci"
+		if extraCol {
+			table.Columns = append(table.Columns, &tengo.Column{Name: "code", TypeInDB: "char(10)", Nullable: true, Default: "NULL", CharSet: alias, Collation: alias + "_bin"})
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(flavor)
+		return table
+	}
+
+	// A table introspected from MySQL 8.0.30, compared to the same table using
+	// utf8 naming, should have no differences
+	if td := tengo.NewAlterTable(makeTable("utf8mb3", flavor8030, false), makeTable("utf8", tengo.FlavorMySQL57, false)); td != nil {
+		stmt, _ := td.Statement(tengo.StatementModifiers{Flavor: flavor8030})
+		t.Errorf("Expected no difference between utf8mb3 and utf8 tables, but found %s", stmt)
+	}
+	if td := tengo.NewAlterTable(makeTable("utf8", tengo.FlavorMySQL57, false), makeTable("utf8mb3", flavor8030, false)); td != nil {
+		stmt, _ := td.Statement(tengo.StatementModifiers{Flavor: tengo.FlavorMySQL57})
+		t.Errorf("Expected no difference between utf8 and utf8mb3 tables, but found %s", stmt)
+	}
+
+	// Actual differences should generate statements using the charset and
+	// collation names preferred by the target flavor, regardless of the names
+	// used by either side
+	cases := []struct {
+		flavor   tengo.Flavor
+		expected string
+	}{
+		{tengo.FlavorMySQL57, "ALTER TABLE `foo` ADD COLUMN `code` char(10) CHARACTER SET utf8 COLLATE utf8_bin DEFAULT NULL"},
+		{tengo.NewFlavor("mysql:8.0.24"), "ALTER TABLE `foo` ADD COLUMN `code` char(10) CHARACTER SET utf8mb3 COLLATE utf8_bin DEFAULT NULL"},
+		{flavor8030, "ALTER TABLE `foo` ADD COLUMN `code` char(10) CHARACTER SET utf8mb3 COLLATE utf8mb3_bin DEFAULT NULL"},
+		{tengo.FlavorMariaDB105, "ALTER TABLE `foo` ADD COLUMN `code` char(10) CHARACTER SET utf8 COLLATE utf8_bin DEFAULT NULL"},
+		{tengo.NewFlavor("mariadb:10.6"), "ALTER TABLE `foo` ADD COLUMN `code` char(10) CHARACTER SET utf8mb3 COLLATE utf8mb3_bin DEFAULT NULL"},
+	}
+	for _, c := range cases {
+		for _, aliases := range [][2]string{{"utf8mb3", "utf8"}, {"utf8", "utf8mb3"}} {
+			td := tengo.NewAlterTable(makeTable(aliases[0], c.flavor, false), makeTable(aliases[1], c.flavor, true))
+			if td == nil {
+				t.Errorf("Flavor %s: expected difference from %s to %s, but none found", c.flavor, aliases[0], aliases[1])
+				continue
+			}
+			mods := tengo.StatementModifiers{Flavor: c.flavor}
+			ddl, err := NewDDLStatement(td, mods, target)
+			if err != nil {
+				t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+			}
+			if ddl.stmt != c.expected {
+				t.Errorf("Flavor %s from %s to %s: expected statement %q, instead found %q", c.flavor, aliases[0], aliases[1], c.expected, ddl.stmt)
+			}
+		}
+	}
+
+	// Table default charset changes should also use the target flavor's names
+	from := makeTable("latin1", flavor8030, false)
+	to := makeTable("utf8", tengo.FlavorMySQL57, false)
+	for _, col := range from.Columns {
+		if col.CharSet != "" {
+			col.CharSet, col.Collation = "utf8", "utf8_general_ci"
+		}
+	}
+	from.CreateStatement = from.GeneratedCreateStatement(flavor8030)
+	stmt, err := tengo.NewAlterTable(from, to).Statement(tengo.StatementModifiers{Flavor: flavor8030})
+	if expected := "ALTER TABLE `foo` DEFAULT CHARACTER SET = utf8mb3 COLLATE = utf8mb3_general_ci"; err != nil || stmt != expected {
+		t.Errorf("Expected statement %q, instead found %q (err=%v)", expected, stmt, err)
+	}
+}
+
+func TestNewDDLStatementConvertCollation(t *testing.T) {
+	target := newTestTarget(t, nil)
+	makeTable := func(tableCollation, colCollation string) *tengo.Table {
+		table := makeTestTable(tengo.FlavorMySQL80, "foo",
+			&tengo.Column{Name: "id", TypeInDB: "int unsigned"},
+			&tengo.Column{Name: "name", TypeInDB: "varchar(30)", Nullable: true, Default: "NULL", CharSet: "latin1", Collation: colCollation, CollationIsDefault: colCollation == "latin1_swedish_ci"},
+		)
+		table.Collation, table.CollationIsDefault = tableCollation, tableCollation == "latin1_swedish_ci"
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL80)
+		return table
+	}
+	mods := tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80}
+
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+
+	// Changing the table's default collation along with all of its columns
+	// should use CONVERT TO, and warn about the table rebuild
+	td := tengo.NewAlterTable(makeTable("latin1_swedish_ci", "latin1_swedish_ci"), makeTable("latin1_general_ci", "latin1_general_ci"))
+	ddl, err := NewDDLStatement(td, mods, target)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+	}
+	if !td.ConvertsCollation() || !strings.Contains(ddl.stmt, "CONVERT TO CHARACTER SET latin1 COLLATE latin1_general_ci") {
+		t.Errorf("Expected statement to use CONVERT TO, instead found %s", ddl.stmt)
+	}
+	if !strings.Contains(logBuf.String(), "CONVERT TO CHARACTER SET rebuilds the table") {
+		t.Errorf("Expected warning about table rebuild, instead log output was:\n%s", logBuf.String())
+	}
+
+	// Changing only a column's collation should not use CONVERT TO
+	logBuf.Reset()
+	td = tengo.NewAlterTable(makeTable("latin1_swedish_ci", "latin1_swedish_ci"), makeTable("latin1_swedish_ci", "latin1_general_ci"))
+	if ddl, err = NewDDLStatement(td, mods, target); err != nil {
+		t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+	}
+	if td.ConvertsCollation() || !strings.Contains(ddl.stmt, "MODIFY COLUMN `name`") {
+		t.Errorf("Expected statement to use MODIFY COLUMN, instead found %s", ddl.stmt)
+	}
+	if strings.Contains(logBuf.String(), "CONVERT TO") {
+		t.Errorf("Expected no CONVERT TO warning, instead log output was:\n%s", logBuf.String())
+	}
+}
+
+func TestNewDDLStatementComments(t *testing.T) {
+	target := newTestTarget(t, nil)
+
+	// makeTable returns a table with the supplied table and column comments
+	makeTable := func(tableComment, colComment string) *tengo.Table {
+		table := makeTestTable(tengo.FlavorMySQL80, "foo",
+			&tengo.Column{Name: "id", TypeInDB: "int unsigned"},
+			&tengo.Column{Name: "name", TypeInDB: "varchar(30)", Nullable: true, Default: "NULL", CharSet: "utf8mb4", Collation: "utf8mb4_general_ci", CollationIsDefault: true, Comment: colComment},
+		)
+		table.CharSet, table.Collation, table.Comment = "utf8mb4", "utf8mb4_general_ci", tableComment
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL80)
+		return table
+	}
+
+	// Comments with multibyte characters, quotes, and backslashes should only
+	// result in a difference if their decoded values actually differ
+	tableComment := "Gérer les 🐬 de l'équipe"
+	colComment := `C:\path\to\"file" 🎉`
+	if td := tengo.NewAlterTable(makeTable(tableComment, colComment), makeTable(tableComment, colComment)); td != nil {
+		stmt, _ := td.Statement(tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80})
+		t.Errorf("Expected no difference between tables with identical comments, but found %s", stmt)
+	}
+	cases := []struct {
+		tableComment string
+		colComment   string
+		expected     string
+	}{
+		{"Gérer les 🐬 de l'équipe!", colComment, "ALTER TABLE `foo` COMMENT 'Gérer les 🐬 de l''équipe!'"},
+		{tableComment, `C:\path\to\"file" 🎊`, "ALTER TABLE `foo` MODIFY COLUMN `name` varchar(30) DEFAULT NULL COMMENT 'C:\\\\path\\\\to\\\\\"file\" 🎊'"},
+	}
+	for _, c := range cases {
+		td := tengo.NewAlterTable(makeTable(tableComment, colComment), makeTable(c.tableComment, c.colComment))
+		if td == nil {
+			t.Errorf("Expected difference for table comment %q, column comment %q; but none found", c.tableComment, c.colComment)
+			continue
+		}
+		ddl, err := NewDDLStatement(td, tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80}, target)
+		if err != nil {
+			t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+		}
+		if ddl.stmt != c.expected {
+			t.Errorf("Expected statement %q, instead found %q", c.expected, ddl.stmt)
+		}
+	}
+
+	// A comment exceeding the server's maximum length should be considered equal
+	// to its truncated form. The server's CREATE TABLE truncates by character,
+	// not by byte.
+	longTable := makeTable(strings.Repeat("🐬", 2100), strings.Repeat("é", 1100))
+	truncated := makeTable(strings.Repeat("🐬", 2048), strings.Repeat("é", 1024))
+	longTable.CreateStatement = truncated.CreateStatement
+	if td := tengo.NewAlterTable(truncated, longTable); td != nil {
+		stmt, _ := td.Statement(tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80})
+		t.Errorf("Expected no difference between truncated and over-long comments, but found %s", stmt)
+	}
+	if !truncated.Columns[1].Equals(longTable.Columns[1]) {
+		t.Error("Expected truncated and over-long column comments to be considered equal, but they were not")
+	}
+}
+
+func TestNewDDLStatementDisplayWidth(t *testing.T) {
+	target := newTestTarget(t, nil)
+
+	// makeTable returns a table using the supplied column types, as introspected
+	// from the supplied flavor
+	flavor8019 := tengo.NewFlavor("mysql:8.0.19")
+	makeTable := func(flavor tengo.Flavor, colTypes ...string) *tengo.Table {
+		var cols []*tengo.Column
+		for n, colType := range colTypes {
+			cols = append(cols, &tengo.Column{Name: fmt.Sprintf("col%d", n), TypeInDB: colType})
+		}
+		return makeTestTable(flavor, "foo", cols...)
+	}
+
+	// An int pulled from 8.0.19+, compared to the int(11) of a file written for
+	// 5.7, should not result in any DDL
+	from := makeTable(flavor8019, "int", "int unsigned", "tinyint(1)", "year")
+	to := makeTable(tengo.FlavorMySQL57, "int(11)", "int(10) unsigned", "tinyint(1)", "year(4)")
+	mods := tengo.StatementModifiers{Flavor: flavor8019}
+	for _, pair := range [][2]*tengo.Table{{from, to}, {to, from}} {
+		if td := tengo.NewAlterTable(pair[0], pair[1]); td != nil {
+			if ddl, err := NewDDLStatement(td, mods, target); ddl != nil || err != nil {
+				t.Errorf("Expected no DDL from display width differences, instead found %+v (err=%v)", ddl, err)
+			}
+		}
+	}
+
+	// Actual changes should use the flavor-appropriate canonical form of the
+	// column type. Display widths are meaningful for tinyint(1) and zerofill, so
+	// those are preserved.
+	cases := []struct {
+		flavor   tengo.Flavor
+		colType  string
+		expected string
+	}{
+		{flavor8019, "bigint(20) unsigned", "ALTER TABLE `foo` MODIFY COLUMN `col0` bigint unsigned NOT NULL"},
+		{tengo.FlavorMySQL57, "bigint(20) unsigned", "ALTER TABLE `foo` MODIFY COLUMN `col0` bigint(20) unsigned NOT NULL"},
+		{flavor8019, "tinyint(1)", "ALTER TABLE `foo` MODIFY COLUMN `col0` tinyint(1) NOT NULL"},
+		{flavor8019, "int(5) unsigned zerofill", "ALTER TABLE `foo` MODIFY COLUMN `col0` int(5) unsigned zerofill NOT NULL"},
+		{flavor8019, "enum('int(5)','bigint(20)')", "ALTER TABLE `foo` MODIFY COLUMN `col0` enum('int(5)','bigint(20)') NOT NULL"},
+		{flavor8019, "set('a','int(5)')", "ALTER TABLE `foo` MODIFY COLUMN `col0` set('a','int(5)') NOT NULL"},
+	}
+	for _, c := range cases {
+		td := tengo.NewAlterTable(makeTable(c.flavor, "int(11)"), makeTable(c.flavor, c.colType))
+		if td == nil {
+			t.Errorf("Flavor %s: expected difference from int(11) to %s, but none found", c.flavor, c.colType)
+			continue
+		}
+		ddl, err := NewDDLStatement(td, tengo.StatementModifiers{Flavor: c.flavor, AllowUnsafe: true}, target)
+		if err != nil || ddl == nil {
+			t.Fatalf("Unexpected result from NewDDLStatement: %+v (err=%v)", ddl, err)
+		}
+		if ddl.stmt != c.expected {
+			t.Errorf("Flavor %s: expected statement %q, instead found %q", c.flavor, c.expected, ddl.stmt)
+		}
+	}
+
+	// Enum values resembling integer types must not be treated as display widths
+	// when comparing either
+	from = makeTable(flavor8019, "enum('int(5)','int(6)')")
+	to = makeTable(flavor8019, "enum('int(5)','int(7)')")
+	ddl, err := NewDDLStatement(tengo.NewAlterTable(from, to), tengo.StatementModifiers{Flavor: flavor8019, AllowUnsafe: true}, target)
+	if expected := "ALTER TABLE `foo` MODIFY COLUMN `col0` enum('int(5)','int(7)') NOT NULL"; err != nil || ddl == nil || ddl.stmt != expected {
+		t.Errorf("Expected statement %q, instead found %+v (err=%v)", expected, ddl, err)
+	}
+
+	// A column which only differs in display width, but is also being moved,
+	// must still be modified in order to move it
+	from = makeTable(flavor8019, "int", "int unsigned")
+	to = makeTable(tengo.FlavorMySQL57, "int(11)", "int(10) unsigned")
+	to.Columns[0], to.Columns[1] = to.Columns[1], to.Columns[0]
+	to.CreateStatement = to.GeneratedCreateStatement(tengo.FlavorMySQL57)
+	ddl, err = NewDDLStatement(tengo.NewAlterTable(from, to), mods, target)
+	if expected := "ALTER TABLE `foo` MODIFY COLUMN `col0` int NOT NULL AFTER `col1`"; err != nil || ddl == nil || ddl.stmt != expected {
+		t.Errorf("Expected statement %q, instead found %+v (err=%v)", expected, ddl, err)
+	}
+}
+
+func TestNewDDLStatementShowTableSize(t *testing.T) {
+	target := newTestTarget(t, map[string]string{"show-table-size": "1"})
+	target.Instance, target.Dump = nil, &fs.Dump{Path: "/tmp/dump.sql"}
+	makeTable := func(name string) *tengo.Table {
+		return makeTestTable(tengo.FlavorMySQL80, name, &tengo.Column{Name: "id", TypeInDB: "int(10) unsigned"})
+	}
+
+	// When comparing against a dump file, stats are unavailable, so the size of
+	// a dropped table should be noted as unknown. New tables have no size note.
+	from := &tengo.Schema{Name: "analytics", Tables: []*tengo.Table{makeTable("posts")}}
+	to := &tengo.Schema{Name: "analytics", Tables: []*tengo.Table{makeTable("comments")}}
+	var ddls []*DDLStatement
+	for _, diff := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+		ddl, err := NewDDLStatement(diff, tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80, AllowUnsafe: true}, target)
+		if err != nil {
+			t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+		}
+		if diff.DiffType() == tengo.DiffTypeDrop && ddl.sizeNote != "unknown" {
+			t.Errorf("Expected size note of dropped table to be unknown, instead found %q", ddl.sizeNote)
+		} else if diff.DiffType() == tengo.DiffTypeCreate && ddl.sizeNote != "" {
+			t.Errorf("Expected no size note for created table, instead found %q", ddl.sizeNote)
+		}
+		ddls = append(ddls, ddl)
+	}
+	if len(ddls) != 2 {
+		t.Fatalf("Expected 2 DDL statements, instead found %d", len(ddls))
+	}
+
+	// The note should be printed as a comment directly before the statement
+	tmp, err := ioutil.TempFile("", "skeema-showtablesize-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	oldStdout := os.Stdout
+	os.Stdout = tmp
+	NewPrinter(false).printDDL(ddls...)
+	os.Stdout = oldStdout
+	tmp.Close()
+	contents, _ := ioutil.ReadFile(tmp.Name())
+	if !strings.Contains(string(contents), "-- table size: unknown\nDROP TABLE `posts`;\n") {
+		t.Errorf("Expected size note to precede DROP TABLE, but it did not. Output:\n%s", contents)
+	}
+	if strings.Count(string(contents), "-- table size:") != 1 {
+		t.Errorf("Expected exactly one size note in output, instead found:\n%s", contents)
+	}
+
+	// Without the option, there should be no size notes
+	target.Dir = newTestTarget(t, nil).Dir
+	for _, diff := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+		if ddl, err := NewDDLStatement(diff, tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80, AllowUnsafe: true}, target); err != nil {
+			t.Errorf("Unexpected error from NewDDLStatement: %v", err)
+		} else if ddl.sizeNote != "" {
+			t.Errorf("Expected no size note without show-table-size, instead found %q", ddl.sizeNote)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	cases := map[int64]string{
+		0:                       "0 B",
+		1023:                    "1023 B",
+		1024:                    "1.0 KiB",
+		16384:                   "16.0 KiB",
+		1536 * 1024:             "1.5 MiB",
+		5 * 1024 * 1024 * 1024:  "5.0 GiB",
+		3 << 40:                 "3.0 TiB",
+		2048 * 1024 * (1 << 30): "2048.0 TiB",
+	}
+	for input, expected := range cases {
+		if actual := formatBytes(input); actual != expected {
+			t.Errorf("Expected formatBytes(%d) to return %q, instead found %q", input, expected, actual)
+		}
+	}
+}
+
+func TestNewDDLStatementIndexVisibility(t *testing.T) {
+	target := newTestTarget(t, nil)
+
+	// makeTable returns a table with columns id, name, and (optionally) extra,
+	// and a secondary index on name followed by the supplied indexes
+	makeTable := func(withExtra bool, indexes ...*tengo.Index) *tengo.Table {
+		cols := []*tengo.Column{
+			{Name: "id", TypeInDB: "int unsigned"},
+			{Name: "name", TypeInDB: "varchar(40)", Nullable: true},
+		}
+		if withExtra {
+			cols = append(cols, &tengo.Column{Name: "extra", TypeInDB: "int"})
+		}
+		table := makeTestTable(tengo.FlavorMySQL80, "foo", cols...)
+		table.SecondaryIndexes = indexes
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL80)
+		return table
+	}
+	makeIndex := func(name string, invisible bool, cols ...string) *tengo.Index {
+		idx := &tengo.Index{Name: name, Type: "BTREE", Invisible: invisible}
+		for _, col := range cols {
+			idx.Parts = append(idx.Parts, tengo.IndexPart{ColumnName: col})
+		}
+		return idx
+	}
+
+	cases := []struct {
+		from, to *tengo.Table
+		flavor   tengo.Flavor
+		expected string
+	}{
+		// Toggling visibility alone should use ALTER INDEX, in both directions
+		{
+			from:     makeTable(false, makeIndex("idx_name", false, "name")),
+			to:       makeTable(false, makeIndex("idx_name", true, "name")),
+			expected: "ALTER TABLE `foo` ALTER INDEX `idx_name` INVISIBLE",
+		},
+		{
+			from:     makeTable(false, makeIndex("idx_name", true, "name")),
+			to:       makeTable(false, makeIndex("idx_name", false, "name")),
+			expected: "ALTER TABLE `foo` ALTER INDEX `idx_name` VISIBLE",
+		},
+		// Adding a new invisible index should include the keyword
+		{
+			from:     makeTable(false),
+			to:       makeTable(false, makeIndex("idx_name", true, "name")),
+			expected: "ALTER TABLE `foo` ADD KEY `idx_name` (`name`) /*!80000 INVISIBLE */",
+		},
+		// Unrelated changes should not affect an existing invisible index
+		{
+			from:     makeTable(false, makeIndex("idx_name", true, "name")),
+			to:       makeTable(true, makeIndex("idx_name", true, "name")),
+			expected: "ALTER TABLE `foo` ADD COLUMN `extra` int NOT NULL",
+		},
+		{
+			from:     makeTable(false, makeIndex("idx_name", true, "name"), makeIndex("idx_id", false, "id")),
+			to:       makeTable(false, makeIndex("idx_name", true, "name")),
+			expected: "ALTER TABLE `foo` DROP KEY `idx_id`",
+		},
+		// Changing an invisible index's definition requires dropping and re-adding
+		// it, which must retain its visibility
+		{
+			from:     makeTable(false, makeIndex("idx_name", true, "name")),
+			to:       makeTable(false, makeIndex("idx_name", true, "name", "id")),
+			expected: "ALTER TABLE `foo` DROP KEY `idx_name`, ADD KEY `idx_name` (`name`,`id`) /*!80000 INVISIBLE */",
+		},
+		// Flavors without invisible index support cannot toggle visibility
+		{
+			from:     makeTable(false, makeIndex("idx_name", false, "name")),
+			to:       makeTable(false, makeIndex("idx_name", true, "name")),
+			flavor:   tengo.FlavorMySQL57,
+			expected: "",
+		},
+	}
+	for n, c := range cases {
+		flavor := c.flavor
+		if flavor == tengo.FlavorUnknown {
+			flavor = tengo.FlavorMySQL80
+		}
+		td := tengo.NewAlterTable(c.from, c.to)
+		if td == nil {
+			t.Errorf("Case %d: expected NewAlterTable to return a diff, but it returned nil", n)
+			continue
+		}
+		ddl, err := NewDDLStatement(td, tengo.StatementModifiers{Flavor: flavor}, target)
+		if err != nil {
+			t.Errorf("Case %d: unexpected error from NewDDLStatement: %v", n, err)
+		} else if c.expected == "" && ddl != nil {
+			t.Errorf("Case %d: expected no DDL, instead found %s", n, ddl.stmt)
+		} else if c.expected != "" && (ddl == nil || ddl.stmt != c.expected) {
+			t.Errorf("Case %d: expected DDL %q, instead found %+v", n, c.expected, ddl)
+		}
+	}
+}
+
+func TestNewDDLStatementColumnSRID(t *testing.T) {
+	target := newTestTarget(t, nil)
+
+	// makeTable returns a table with a point column using the supplied SRID, and
+	// optionally an extra column
+	makeTable := func(srid string, withExtra bool) *tengo.Table {
+		cols := []*tengo.Column{
+			{Name: "id", TypeInDB: "int unsigned"},
+			{Name: "loc", TypeInDB: "point", SRID: srid},
+		}
+		if withExtra {
+			cols = append(cols, &tengo.Column{Name: "extra", TypeInDB: "int"})
+		}
+		return makeTestTable(tengo.FlavorMySQL80, "places", cols...)
+	}
+
+	if tengo.NewAlterTable(makeTable("4326", false), makeTable("4326", false)) != nil {
+		t.Error("Expected no diff between tables with identical column SRIDs")
+	}
+	if tengo.NewAlterTable(makeTable("", false), makeTable("", false)) != nil {
+		t.Error("Expected no diff between tables without column SRIDs")
+	}
+	cases := []struct {
+		from, to *tengo.Table
+		expected string
+	}{
+		// Adding, changing, or removing an SRID should modify the column
+		{
+			from:     makeTable("", false),
+			to:       makeTable("4326", false),
+			expected: "ALTER TABLE `places` MODIFY COLUMN `loc` point NOT NULL /*!80003 SRID 4326 */",
+		},
+		{
+			from:     makeTable("4326", false),
+			to:       makeTable("3857", false),
+			expected: "ALTER TABLE `places` MODIFY COLUMN `loc` point NOT NULL /*!80003 SRID 3857 */",
+		},
+		{
+			from:     makeTable("4326", false),
+			to:       makeTable("", false),
+			expected: "ALTER TABLE `places` MODIFY COLUMN `loc` point NOT NULL",
+		},
+		// SRID of 0 is distinct from having no SRID attribute at all
+		{
+			from:     makeTable("", false),
+			to:       makeTable("0", false),
+			expected: "ALTER TABLE `places` MODIFY COLUMN `loc` point NOT NULL /*!80003 SRID 0 */",
+		},
+		// Unrelated changes should not affect a column's SRID
+		{
+			from:     makeTable("4326", false),
+			to:       makeTable("4326", true),
+			expected: "ALTER TABLE `places` ADD COLUMN `extra` int NOT NULL",
+		},
+	}
+	for n, c := range cases {
+		td := tengo.NewAlterTable(c.from, c.to)
+		if td == nil {
+			t.Errorf("Case %d: expected NewAlterTable to return a diff, but it returned nil", n)
+			continue
+		}
+		ddl, err := NewDDLStatement(td, tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80}, target)
+		if err != nil {
+			t.Errorf("Case %d: unexpected error from NewDDLStatement: %v", n, err)
+		} else if ddl == nil || ddl.stmt != c.expected {
+			t.Errorf("Case %d: expected DDL %q, instead found %+v", n, c.expected, ddl)
+		} else if ddl.unsafe {
+			t.Errorf("Case %d: expected DDL to be considered safe, but it was not", n)
+		}
+	}
+}
+
+func TestNewDDLStatementDryRunValidate(t *testing.T) {
+	target := newTestTarget(t, map[string]string{"dry-run-validate": "1"})
+	target.Instance, target.Dump = nil, &fs.Dump{Path: "/tmp/dump.sql"}
+	makeTable := func(withUnique bool) *tengo.Table {
+		table := makeTestTable(tengo.FlavorMySQL80, "users",
+			&tengo.Column{Name: "id", TypeInDB: "int unsigned"},
+			&tengo.Column{Name: "name", TypeInDB: "varchar(40)"},
+		)
+		if withUnique {
+			table.SecondaryIndexes = []*tengo.Index{{Name: "name", Type: "BTREE", Unique: true, Parts: []tengo.IndexPart{{ColumnName: "name"}}}}
+			table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL80)
+		}
+		return table
+	}
+
+	// When comparing against a dump file, nothing can be validated, which should
+	// be noted in the output
+	td := tengo.NewAlterTable(makeTable(false), makeTable(true))
+	ddl, err := NewDDLStatement(td, tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80}, target)
+	if err != nil {
+		t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+	}
+	if len(ddl.predictedFailures) != 0 || len(ddl.unvalidated) != 1 {
+		t.Fatalf("Unexpected validation result: predicted failures %v, unvalidated %v", ddl.predictedFailures, ddl.unvalidated)
+	}
+
+	// Predicted failures and unvalidated changes should be printed as comments
+	// directly before the statement
+	ddl.predictedFailures = []string{"existing rows have duplicate values for new unique key `name`"}
+	tmp, err := ioutil.TempFile("", "skeema-dryrunvalidate-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	oldStdout := os.Stdout
+	os.Stdout = tmp
+	NewPrinter(false).printDDL(ddl)
+	os.Stdout = oldStdout
+	tmp.Close()
+	contents, _ := ioutil.ReadFile(tmp.Name())
+	expected := "-- predicted to fail: existing rows have duplicate values for new unique key `name`\n-- not validated: validation requires a database instance\nALTER TABLE `users` ADD UNIQUE KEY `name` (`name`);\n"
+	if !strings.Contains(string(contents), expected) {
+		t.Errorf("Expected output to contain:\n%s\nInstead found:\n%s", expected, contents)
+	}
+
+	// Without the option, there should be no validation
+	target.Dir = newTestTarget(t, nil).Dir
+	if ddl, err := NewDDLStatement(td, tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80}, target); err != nil {
+		t.Errorf("Unexpected error from NewDDLStatement: %v", err)
+	} else if len(ddl.predictedFailures)+len(ddl.unvalidated) > 0 {
+		t.Errorf("Expected no validation without dry-run-validate, instead found %v, %v", ddl.predictedFailures, ddl.unvalidated)
+	}
+}
+
+func TestTableDiffGeneratedColumnReAdd(t *testing.T) {
+	makeTable := func(virtual bool, indexed bool) *tengo.Table {
+		table := makeTestTable(tengo.FlavorMySQL80, "orders",
+			&tengo.Column{Name: "id", TypeInDB: "int unsigned"},
+			&tengo.Column{Name: "total", TypeInDB: "int", Nullable: true, GenerationExpr: "(`id` * 2)", Virtual: virtual},
+		)
+		if indexed {
+			table.SecondaryIndexes = []*tengo.Index{{Name: "total", Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "total"}}}}
+			table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL80)
+		}
+		return table
+	}
+
+	// Switching from VIRTUAL to STORED requires dropping and re-adding the column,
+	// which is considered unsafe
+	td := tengo.NewAlterTable(makeTable(true, false), makeTable(false, false))
+	if _, err := td.Statement(tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80}); !tengo.IsForbiddenDiff(err) {
+		t.Errorf("Expected re-adding a column to be forbidden without AllowUnsafe, instead err=%v", err)
+	}
+	stmt, err := td.Statement(tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80, AllowUnsafe: true})
+	if err != nil {
+		t.Fatalf("Unexpected error from Statement: %v", err)
+	} else if !strings.Contains(stmt, "DROP COLUMN `total`, ADD COLUMN `total`") {
+		t.Errorf("Expected column to be dropped and re-added, instead found %s", stmt)
+	}
+
+	// If the column is indexed, dropping it would also remove it from the index,
+	// so the change is not supported
+	td = tengo.NewAlterTable(makeTable(true, true), makeTable(false, true))
+	if _, err := td.Statement(tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80, AllowUnsafe: true}); !tengo.IsUnsupportedDiff(err) {
+		t.Errorf("Expected re-adding an indexed column to be unsupported, instead err=%v", err)
+	}
+}
+
+func TestTableDiffForeignKeyActionEquivalence(t *testing.T) {
+	makeTable := func(flavor tengo.Flavor, deleteRule, updateRule string) *tengo.Table {
+		table := makeTestTable(flavor, "posts",
+			&tengo.Column{Name: "id", TypeInDB: "int unsigned"},
+			&tengo.Column{Name: "user_id", TypeInDB: "int unsigned"},
+		)
+		table.SecondaryIndexes = []*tengo.Index{{Name: "user_fk", Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "user_id"}}}}
+		table.ForeignKeys = []*tengo.ForeignKey{{
+			Name:                  "user_fk",
+			ColumnNames:           []string{"user_id"},
+			ReferencedTableName:   "users",
+			ReferencedColumnNames: []string{"id"},
+			DeleteRule:            deleteRule,
+			UpdateRule:            updateRule,
+		}}
+		table.CreateStatement = table.GeneratedCreateStatement(flavor)
+		return table
+	}
+
+	// Flavors without a data dictionary display NO ACTION in SHOW CREATE TABLE,
+	// but not RESTRICT. Regardless, these should be treated as equivalent.
+	for _, flavor := range []tengo.Flavor{tengo.FlavorMySQL57, tengo.FlavorMariaDB103, tengo.FlavorMySQL80} {
+		noAction := makeTable(flavor, "NO ACTION", "NO ACTION")
+		for _, other := range []*tengo.Table{makeTable(flavor, "RESTRICT", "RESTRICT"), makeTable(flavor, "NO ACTION", "RESTRICT"), makeTable(flavor, "RESTRICT", "NO ACTION")} {
+			if td := tengo.NewAlterTable(noAction, other); td != nil {
+				stmt, err := td.Statement(tengo.StatementModifiers{Flavor: flavor})
+				t.Errorf("Flavor %s: expected no diff between NO ACTION and RESTRICT, instead found %q, err=%v", flavor, stmt, err)
+			}
+			if td := tengo.NewAlterTable(other, noAction); td != nil {
+				stmt, err := td.Statement(tengo.StatementModifiers{Flavor: flavor})
+				t.Errorf("Flavor %s: expected no diff between RESTRICT and NO ACTION, instead found %q, err=%v", flavor, stmt, err)
+			}
+		}
+
+		// Other actions should still be diffed, and supported
+		cascade := makeTable(flavor, "CASCADE", "NO ACTION")
+		td := tengo.NewAlterTable(noAction, cascade)
+		if td == nil {
+			t.Errorf("Flavor %s: expected diff between NO ACTION and CASCADE, but found none", flavor)
+		} else if stmt, err := td.Statement(tengo.StatementModifiers{Flavor: flavor}); err != nil || !strings.Contains(stmt, "ON DELETE CASCADE") {
+			t.Errorf("Flavor %s: unexpected result from Statement: %q, err=%v", flavor, stmt, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package applier
+
+import (
+	"fmt"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/util"
+)
+
+// runPushHook shells out to the command configured by the supplied option
+// (pre-push-command or post-push-command), if any. Information about the
+// target is supplied to the command via environment variables. statementCount
+// should be the number of DDL statements about to be run (pre-push) or
+// successfully run (post-push). status should be "success" or "failure" for
+// post-push, or a blank string for pre-push. A non-nil error is returned if
+// the command could not be run or returned a non-zero exit code.
+func (t *Target) runPushHook(optionName string, statementCount int, status string) error {
+	command := t.Dir.Config.Get(optionName)
+	if command == "" {
+		return nil
+	}
+	s := &util.ShellOut{
+		Command: command,
+		Env:     t.pushHookEnv(optionName, statementCount, status),
+	}
+	log.Debugf("Running %s for %s %s: %s", optionName, t.source(), t.SchemaName, s)
+	if err := s.Run(); err != nil {
+		return fmt.Errorf("%s failed for %s %s: %s", optionName, t.source(), t.SchemaName, err)
+	}
+	return nil
+}
+
+// pushHookEnv returns the environment variables supplied to a push hook
+// command, in "KEY=value" form. All names use a SKEEMA_HOOK_ prefix, rather
+// than just SKEEMA_, so that any skeema command run by the hook does not pick
+// them up as option values (e.g. SKEEMA_HOST would otherwise override --host).
+func (t *Target) pushHookEnv(optionName string, statementCount int, status string) []string {
+	var host, port, socket string
+	if t.Instance != nil {
+		host = t.Instance.Host
+		if t.Instance.SocketPath != "" {
+			socket = t.Instance.SocketPath
+		} else {
+			port = strconv.Itoa(t.Instance.Port)
+		}
+	}
+	return []string{
+		"SKEEMA_HOOK_NAME=" + optionName,
+		"SKEEMA_HOOK_HOST=" + host,
+		"SKEEMA_HOOK_PORT=" + port,
+		"SKEEMA_HOOK_SOCKET=" + socket,
+		"SKEEMA_HOOK_SCHEMA=" + t.SchemaName,
+		"SKEEMA_HOOK_ENVIRONMENT=" + t.Dir.Config.Get("environment"),
+		"SKEEMA_HOOK_DIRNAME=" + t.Dir.BaseName(),
+		"SKEEMA_HOOK_DIRPATH=" + t.Dir.Path,
+		"SKEEMA_HOOK_STATEMENT_COUNT=" + strconv.Itoa(statementCount),
+		"SKEEMA_HOOK_STATUS=" + status,
+	}
+}
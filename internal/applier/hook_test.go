@@ -0,0 +1,118 @@
+package applier
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/skeema/internal/util"
+	"github.com/skeema/tengo"
+)
+
+func TestRunPushHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Fake hook script requires /bin/sh")
+	}
+
+	// Set up a fake hook script which records the SKEEMA_* environment variables
+	// it received, and another which always fails
+	tmpDir, err := ioutil.TempDir("", "skeema-hook-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	fakeHook := filepath.Join(tmpDir, "hook")
+	failingHook := filepath.Join(tmpDir, "hook-fail")
+	envFile := filepath.Join(tmpDir, "env")
+	if err := ioutil.WriteFile(fakeHook, []byte("#!/bin/sh\nenv | grep '^SKEEMA_' | sort > \"$1\"\n"), 0755); err != nil {
+		t.Fatalf("Unable to write %s: %v", fakeHook, err)
+	}
+	if err := ioutil.WriteFile(failingHook, []byte("#!/bin/sh\nexit 3\n"), 0755); err != nil {
+		t.Fatalf("Unable to write %s: %v", failingHook, err)
+	}
+
+	inst, err := tengo.NewInstance("mysql", "root@tcp(127.0.0.1:3307)/")
+	if err != nil {
+		t.Fatalf("Unable to create instance: %v", err)
+	}
+	configMap := map[string]string{
+		"environment":       "staging",
+		"pre-push-command":  failingHook,
+		"post-push-command": fakeHook + " " + envFile,
+	}
+	target := &Target{
+		Instance:   inst,
+		Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(configMap)},
+		SchemaName: "analytics",
+	}
+
+	if err := target.runPushHook("pre-push-command", 3, ""); err == nil {
+		t.Error("Expected failing pre-push-command to return an error, but it did not")
+	}
+	if err := target.runPushHook("post-push-command", 2, "failure"); err != nil {
+		t.Fatalf("Unexpected error from post-push-command: %v", err)
+	}
+	contents, err := ioutil.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("Unable to read %s: %v", envFile, err)
+	}
+	expected := []string{
+		"SKEEMA_HOOK_DIRNAME=fakedir",
+		"SKEEMA_HOOK_DIRPATH=/var/tmp/fakedir",
+		"SKEEMA_HOOK_ENVIRONMENT=staging",
+		"SKEEMA_HOOK_HOST=127.0.0.1",
+		"SKEEMA_HOOK_NAME=post-push-command",
+		"SKEEMA_HOOK_PORT=3307",
+		"SKEEMA_HOOK_SCHEMA=analytics",
+		"SKEEMA_HOOK_SOCKET=",
+		"SKEEMA_HOOK_STATEMENT_COUNT=2",
+		"SKEEMA_HOOK_STATUS=failure",
+	}
+	if actual := strings.TrimSpace(string(contents)); actual != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected environment received by hook; expected:\n%s\nfound:\n%s", strings.Join(expected, "\n"), actual)
+	}
+
+	// An unset hook option should be a no-op
+	configMap["pre-push-command"] = ""
+	target.Dir.Config = mybase.SimpleConfig(configMap)
+	if err := target.runPushHook("pre-push-command", 3, ""); err != nil {
+		t.Errorf("Expected blank pre-push-command to be a no-op, but it returned %v", err)
+	}
+}
+
+// TestPushHookEnvNotOptions confirms that the environment variables supplied to
+// a push hook are not interpreted as option values by a skeema command run from
+// within the hook.
+func TestPushHookEnvNotOptions(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root@tcp(10.0.0.5:3307)/")
+	if err != nil {
+		t.Fatalf("Unable to create instance: %v", err)
+	}
+	configMap := map[string]string{"environment": "staging"}
+	target := &Target{
+		Instance:   inst,
+		Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: mybase.SimpleConfig(configMap)},
+		SchemaName: "analytics",
+	}
+	for _, kv := range target.pushHookEnv("post-push-command", 2, "success") {
+		tokens := strings.SplitN(kv, "=", 2)
+		os.Setenv(tokens[0], tokens[1])
+		defer os.Unsetenv(tokens[0])
+	}
+
+	cfg := getBaseConfig(t, "")
+	util.AddGlobalConfigFiles(cfg)
+	for name := range cfg.CLI.Command.Options() {
+		if src, ok := cfg.Source(name).(*mybase.EnvProvider); ok {
+			t.Errorf("Expected option %s to be unaffected by push hook env, but its value %q came from %s", name, cfg.Get(name), src)
+		}
+	}
+	if actual := cfg.Get("host"); actual == inst.Host {
+		t.Errorf("Expected host to be unaffected by push hook env, but found %q", actual)
+	}
+}
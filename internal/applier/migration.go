@@ -0,0 +1,129 @@
+package applier
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/tengo"
+)
+
+// migration accumulates forward ("up") and inverse ("down") statements for
+// each target, for writing to a pair of files usable by a traditional migration
+// runner.
+type migration struct {
+	dirPath string
+	prefix  string // timestamp prefix for file names
+	blocks  []migrationBlock
+}
+
+// migrationBlock stores the statements for a single target.
+type migrationBlock struct {
+	source     string
+	schemaName string
+	up         []string
+	down       []string
+}
+
+// UpPath returns the path to the file containing forward statements.
+func (m *migration) UpPath() string {
+	return filepath.Join(m.dirPath, m.prefix+"_up.sql")
+}
+
+// DownPath returns the path to the file containing inverse statements.
+func (m *migration) DownPath() string {
+	return filepath.Join(m.dirPath, m.prefix+"_down.sql")
+}
+
+// write creates the up and down files. Targets appear in the down file in the
+// opposite order from the up file, so that changes are undone in reverse. If
+// no target had any differences, no files are created.
+func (m *migration) write() error {
+	if len(m.blocks) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(m.dirPath, 0777); err != nil {
+		return err
+	}
+	var up, down strings.Builder
+	for n := range m.blocks {
+		m.blocks[n].writeTo(&up, m.blocks[n].up)
+		reverse := m.blocks[len(m.blocks)-1-n]
+		reverse.writeTo(&down, reverse.down)
+	}
+	if err := ioutil.WriteFile(m.UpPath(), []byte(up.String()), 0666); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(m.DownPath(), []byte(down.String()), 0666)
+}
+
+func (block migrationBlock) writeTo(b *strings.Builder, stmts []string) {
+	if len(stmts) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "-- instance: %s\nUSE %s;\n", block.source, tengo.EscapeIdentifier(block.schemaName))
+	for _, stmt := range stmts {
+		b.WriteString(stmt)
+	}
+}
+
+// newMigration returns a migration which will write files to dirPath, using
+// file names based on the supplied time.
+func newMigration(dirPath string, now time.Time) *migration {
+	return &migration{
+		dirPath: dirPath,
+		prefix:  now.UTC().Format("20060102150405"),
+	}
+}
+
+// inverseStatements returns statements which undo the supplied ddls, which
+// must have been generated from a diff of from to to. The returned statements
+// are in execution order, and each already has a delimiter. If a change cannot
+// be precisely undone, for example because it destroyed data, the returned
+// value for that change is a commented-out placeholder instead of a statement.
+func inverseStatements(ddls []*DDLStatement, from, to *tengo.Schema, mods tengo.StatementModifiers) []string {
+	changed := make(map[tengo.ObjectKey]bool, len(ddls))
+	for _, ddl := range ddls {
+		changed[ddl.key] = changed[ddl.key] || ddl.unsafe
+	}
+
+	// Inverse of a non-destructive change may itself be destructive, e.g. the
+	// inverse of adding a column is dropping it
+	mods.AllowUnsafe = true
+
+	var stmts []string
+	for _, objDiff := range tengo.NewSchemaDiff(to, from).ObjectDiffs() {
+		key := objDiff.ObjectKey()
+		destructive, ok := changed[key]
+		if !ok {
+			continue
+		}
+		stmt, err := objDiff.Statement(mods)
+		if err != nil {
+			stmts = append(stmts, fmt.Sprintf("-- TODO: unable to generate inverse of change to %s: %s\n", key, err))
+		} else if stmt == "" {
+			continue
+		} else if destructive {
+			placeholder := fmt.Sprintf("-- TODO: the change to %s is destructive, so its data cannot be restored.\n", key) +
+				"-- The following statement only restores its definition:\n" +
+				commentOut(fs.AddDelimiter(stmt))
+			stmts = append(stmts, placeholder)
+		} else {
+			stmts = append(stmts, fs.AddDelimiter(stmt))
+		}
+	}
+	return stmts
+}
+
+// commentOut prefixes each line of s with a SQL comment marker.
+func commentOut(s string) string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	for n := range lines {
+		lines[n] = "-- " + lines[n]
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,159 @@
+package applier
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/tengo"
+)
+
+func TestInverseStatements(t *testing.T) {
+	inst, err := tengo.NewInstance("mysql", "root@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unable to create instance: %v", err)
+	}
+	cfg := mybase.SimpleConfig(map[string]string{
+		"safe-below-size":        "",
+		"alter-wrapper":          "",
+		"alter-wrapper-min-size": "",
+		"ddl-wrapper":            "",
+		"gh-ost":                 "",
+		"lock-wait-timeout":      "",
+		"statement-timeout":      "",
+		"show-table-size":        "",
+		"dry-run-validate":       "",
+		"foreign-key-checks":     "",
+	})
+	target := &Target{
+		Instance:   inst,
+		Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: cfg},
+		SchemaName: "analytics",
+	}
+	idCol := &tengo.Column{Name: "id", TypeInDB: "int(10) unsigned"}
+	nameCol := &tengo.Column{Name: "name", TypeInDB: "varchar(30)", Nullable: true, Default: "NULL", CharSet: "latin1", Collation: "latin1_swedish_ci", CollationIsDefault: true}
+	makeTable := func(name string, cols []*tengo.Column, indexes ...*tengo.Index) *tengo.Table {
+		table := &tengo.Table{
+			Name:               name,
+			Engine:             "InnoDB",
+			CharSet:            "latin1",
+			Collation:          "latin1_swedish_ci",
+			CollationIsDefault: true,
+			Columns:            cols,
+			SecondaryIndexes:   indexes,
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorUnknown)
+		return table
+	}
+	nameIdx := &tengo.Index{Name: "name", Parts: []tengo.IndexPart{{ColumnName: "name"}}, Type: "BTREE"}
+	getInverse := func(from, to *tengo.Schema, mods tengo.StatementModifiers) (up, down []string) {
+		t.Helper()
+		var ddls []*DDLStatement
+		for _, objDiff := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+			ddl, err := NewDDLStatement(objDiff, mods, target)
+			if err != nil {
+				t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+			}
+			ddls = append(ddls, ddl)
+			up = append(up, ddl.stmt)
+		}
+		return up, inverseStatements(ddls, from, to, mods)
+	}
+
+	// Added column: inverse is dropping the column
+	from := &tengo.Schema{Name: "analytics", Tables: []*tengo.Table{makeTable("foo", []*tengo.Column{idCol})}}
+	to := &tengo.Schema{Name: "analytics", Tables: []*tengo.Table{makeTable("foo", []*tengo.Column{idCol, nameCol})}}
+	up, down := getInverse(from, to, tengo.StatementModifiers{})
+	if len(up) != 1 || len(down) != 1 {
+		t.Fatalf("Expected 1 up and 1 down statement; instead found %v, %v", up, down)
+	}
+	if expected := "ALTER TABLE `foo` DROP COLUMN `name`;\n"; down[0] != expected {
+		t.Errorf("Expected inverse of %q to be %q, instead found %q", up[0], expected, down[0])
+	}
+
+	// Added index: inverse is dropping the index
+	from = to
+	to = &tengo.Schema{Name: "analytics", Tables: []*tengo.Table{makeTable("foo", []*tengo.Column{idCol, nameCol}, nameIdx)}}
+	up, down = getInverse(from, to, tengo.StatementModifiers{})
+	if len(up) != 1 || len(down) != 1 {
+		t.Fatalf("Expected 1 up and 1 down statement; instead found %v, %v", up, down)
+	}
+	if expected := "ALTER TABLE `foo` DROP KEY `name`;\n"; down[0] != expected {
+		t.Errorf("Expected inverse of %q to be %q, instead found %q", up[0], expected, down[0])
+	}
+
+	// Dropped column: inverse cannot restore the data, so a commented-out
+	// placeholder is returned instead
+	from, to = to, from
+	from.Tables[0].SecondaryIndexes = nil
+	from.Tables[0].CreateStatement = from.Tables[0].GeneratedCreateStatement(tengo.FlavorUnknown)
+	to = &tengo.Schema{Name: "analytics", Tables: []*tengo.Table{makeTable("foo", []*tengo.Column{idCol})}}
+	up, down = getInverse(from, to, tengo.StatementModifiers{AllowUnsafe: true})
+	if len(up) != 1 || len(down) != 1 {
+		t.Fatalf("Expected 1 up and 1 down statement; instead found %v, %v", up, down)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(down[0], "\n"), "\n") {
+		if !strings.HasPrefix(line, "-- ") {
+			t.Errorf("Expected inverse of destructive statement to be entirely commented out, instead found line %q", line)
+		}
+	}
+	if !strings.Contains(down[0], "ADD COLUMN `name`") || !strings.Contains(down[0], "cannot be restored") {
+		t.Errorf("Inverse of destructive statement did not contain expected placeholder: %s", down[0])
+	}
+}
+
+func TestPrinterOutputMigration(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "skeema-migration-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	migrationDir := filepath.Join(tmpDir, "migrations")
+
+	p := NewPrinter(true)
+	p.OutputMigration(migrationDir, time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC))
+	for _, schemaName := range []string{"one", "two"} {
+		target := &Target{
+			Instance:   nil,
+			Dump:       &fs.Dump{Path: "/tmp/dump.sql"},
+			SchemaName: schemaName,
+		}
+		ddl := &DDLStatement{stmt: "ALTER TABLE foo ADD COLUMN " + schemaName + " int"}
+		p.addMigration(target, []*DDLStatement{ddl}, []string{"ALTER TABLE foo DROP COLUMN " + schemaName + ";\n"})
+	}
+	p.addMigration(&Target{SchemaName: "three"}, nil, nil) // no-op since no DDL
+	if err := p.Flush(); err != nil {
+		t.Fatalf("Unexpected error from Flush: %v", err)
+	}
+
+	expectUp := "-- instance: /tmp/dump.sql\nUSE `one`;\nALTER TABLE foo ADD COLUMN one int;\n" +
+		"-- instance: /tmp/dump.sql\nUSE `two`;\nALTER TABLE foo ADD COLUMN two int;\n"
+	expectDown := "-- instance: /tmp/dump.sql\nUSE `two`;\nALTER TABLE foo DROP COLUMN two;\n" +
+		"-- instance: /tmp/dump.sql\nUSE `one`;\nALTER TABLE foo DROP COLUMN one;\n"
+	for filename, expected := range map[string]string{"20210304050607_up.sql": expectUp, "20210304050607_down.sql": expectDown} {
+		contents, err := ioutil.ReadFile(filepath.Join(migrationDir, filename))
+		if err != nil {
+			t.Errorf("Unable to read %s: %v", filename, err)
+		} else if string(contents) != expected {
+			t.Errorf("Unexpected contents of %s:\n%s", filename, contents)
+		}
+	}
+
+	// Without any differences, no files should be written, and the dir should not
+	// be created
+	emptyDir := filepath.Join(tmpDir, "empty")
+	p = NewPrinter(true)
+	p.OutputMigration(emptyDir, time.Date(2021, 3, 4, 5, 6, 8, 0, time.UTC))
+	p.addMigration(&Target{SchemaName: "one"}, nil, nil)
+	if err := p.Flush(); err != nil {
+		t.Fatalf("Unexpected error from Flush: %v", err)
+	}
+	if _, err := os.Stat(emptyDir); !os.IsNotExist(err) {
+		t.Errorf("Expected %s to not exist, instead Stat returned err=%v", emptyDir, err)
+	}
+}
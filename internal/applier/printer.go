@@ -1,9 +1,13 @@
 package applier
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"sync"
+	"time"
 
+	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/tengo"
 )
 
@@ -11,50 +15,189 @@ import (
 // being called from multiple pushworker goroutines.
 type Printer struct {
 	briefOutput        bool
+	jsonOutput         bool
+	jsonEntries        []JSONEntry
 	lastStdoutInstance string
 	lastStdoutSchema   string
-	seenInstance       map[string]bool
+	seenObject         map[string]bool
+	buffered           bool
+	migration          *migration
 	*sync.Mutex
 }
 
 // NewPrinter returns a pointer to a new Printer. If briefMode is true, this
-// printer is used to print instance names ("host:port\n") of instances that
-// have one or more differences found. If briefMode is false, this printer is
-// used to print any arbitrary output specific to an instance and schema.
+// printer is used to print the names of objects that have one or more
+// differences ("schema.object type\n"), grouped by instance. If briefMode is
+// false, this printer is used to print any arbitrary output specific to an
+// instance and schema.
 func NewPrinter(briefMode bool) *Printer {
 	return &Printer{
-		briefOutput:  briefMode,
-		seenInstance: make(map[string]bool),
-		Mutex:        new(sync.Mutex),
+		briefOutput: briefMode,
+		seenObject:  make(map[string]bool),
+		Mutex:       new(sync.Mutex),
 	}
 }
 
+// NewJSONPrinter returns a pointer to a new Printer which buffers DDL, and then
+// outputs it as a single JSON array upon calling Flush.
+func NewJSONPrinter() *Printer {
+	p := NewPrinter(false)
+	p.jsonOutput = true
+	p.jsonEntries = []JSONEntry{}
+	return p
+}
+
+// BufferPerTarget causes each target's DDL to be printed all at once, after
+// the target has been completely processed. This prevents output from
+// interleaving when multiple targets are processed concurrently, at the cost of
+// no longer printing each DDL statement prior to executing it.
+func (p *Printer) BufferPerTarget() {
+	p.buffered = true
+}
+
+// OutputMigration causes each target's DDL, along with statements for undoing
+// it, to be written to a pair of timestamp-prefixed files in dirPath upon
+// calling Flush. This is in addition to the printer's normal output.
+func (p *Printer) OutputMigration(dirPath string, now time.Time) {
+	p.migration = newMigration(dirPath, now)
+}
+
+// addMigration tracks the statements for a target, for subsequent writing to
+// migration files by Flush. It has no effect unless OutputMigration was called.
+func (p *Printer) addMigration(t *Target, ddls []*DDLStatement, down []string) {
+	if p.migration == nil || len(ddls) == 0 {
+		return
+	}
+	block := migrationBlock{
+		source:     t.source(),
+		schemaName: t.SchemaName,
+		down:       down,
+	}
+	for _, ddl := range ddls {
+		block.up = append(block.up, fs.AddDelimiter(ddl.stmt))
+	}
+	p.Lock()
+	defer p.Unlock()
+	p.migration.blocks = append(p.migration.blocks, block)
+}
+
+// JSONEntry represents a single DDL statement in JSON output.
+type JSONEntry struct {
+	Instance   string   `json:"instance"`
+	Schema     string   `json:"schema"`
+	ObjectType string   `json:"objectType"`
+	ObjectName string   `json:"objectName"`
+	DiffType   string   `json:"diffType"`
+	Statements []string `json:"statements"`
+	ShellOut   string   `json:"shellOut,omitempty"`
+	Unsafe     bool     `json:"unsafe"`
+}
+
+// Flush outputs any buffered output. This must be called after all workers
+// have completed, but has no effect unless the Printer was created using
+// NewJSONPrinter, or OutputMigration was called.
+func (p *Printer) Flush() error {
+	p.Lock()
+	defer p.Unlock()
+	if p.migration != nil {
+		if err := p.migration.write(); err != nil {
+			return err
+		}
+	}
+	if !p.jsonOutput {
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p.jsonEntries)
+}
+
+func (p *Printer) addJSONEntry(ddl *DDLStatement) {
+	entry := JSONEntry{
+		Instance:   ddl.source,
+		Schema:     ddl.schemaName,
+		ObjectType: string(ddl.key.Type),
+		ObjectName: ddl.key.Name,
+		DiffType:   ddl.diffType.String(),
+		Statements: []string{ddl.stmt},
+		Unsafe:     ddl.unsafe,
+	}
+	if ddl.IsShellOut() {
+		entry.ShellOut = ddl.shellOut.String()
+	}
+	p.jsonEntries = append(p.jsonEntries, entry)
+}
+
 // printDDL outputs DDLStatement values to STDOUT in a way that prevents
 // interleaving of output from multiple workers.
 // TODO: buffer output from external commands and also prevent interleaving there
-func (p *Printer) printDDL(ddl *DDLStatement) {
+func (p *Printer) printDDL(ddls ...*DDLStatement) {
 	p.Lock()
 	defer p.Unlock()
-	instString := ddl.instance.String()
+	for _, ddl := range ddls {
+		p.printOne(ddl)
+	}
+}
+
+// printOne outputs a single DDLStatement. The caller must hold the lock.
+func (p *Printer) printOne(ddl *DDLStatement) {
+	instString := ddl.source
+
+	// Support diff --output-format=json, which buffers output until Flush
+	if p.jsonOutput {
+		p.addJSONEntry(ddl)
+		return
+	}
 
-	// Support diff --brief, which only outputs instances that have differences,
-	// rather than outputting the actual differences
+	// Support diff --brief, which only outputs the names of objects that have
+	// differences, rather than outputting the actual differences. An object may
+	// have multiple DDL statements (e.g. DROP and re-CREATE of a routine) but is
+	// only listed once.
 	if p.briefOutput {
-		if _, already := p.seenInstance[instString]; !already {
-			fmt.Printf("%s\n", instString)
-			p.seenInstance[instString] = true
+		line := briefLine(ddl)
+		if p.seenObject[instString+" "+line] {
+			return
 		}
+		p.seenObject[instString+" "+line] = true
+		p.printInstanceHeader(instString)
+		fmt.Print(line)
 		return
 	}
 
+	p.printInstanceHeader(instString)
+	if ddl.schemaName != p.lastStdoutSchema && ddl.schemaName != "" {
+		fmt.Printf("USE %s;\n", tengo.EscapeIdentifier(ddl.schemaName))
+		p.lastStdoutSchema = ddl.schemaName
+	}
+	if ddl.sizeNote != "" {
+		fmt.Printf("-- table size: %s\n", ddl.sizeNote)
+	}
+	for _, failure := range ddl.predictedFailures {
+		fmt.Printf("-- predicted to fail: %s\n", failure)
+	}
+	for _, note := range ddl.unvalidated {
+		fmt.Printf("-- not validated: %s\n", note)
+	}
+	fmt.Print(ddl.String())
+}
+
+// printInstanceHeader outputs a header line for instString, if the previous
+// output was for a different instance. The caller must hold the lock.
+func (p *Printer) printInstanceHeader(instString string) {
 	if instString != p.lastStdoutInstance {
 		fmt.Printf("-- instance: %s\n", instString)
 		p.lastStdoutInstance = instString
 		p.lastStdoutSchema = ""
 	}
-	if ddl.schemaName != p.lastStdoutSchema && ddl.schemaName != "" {
-		fmt.Printf("USE %s;\n", tengo.EscapeIdentifier(ddl.schemaName))
-		p.lastStdoutSchema = ddl.schemaName
+}
+
+// briefLine returns the line used to represent ddl's object in brief output,
+// in the form "schema.object type\n". Schema-level differences omit the prefix,
+// since the object name is already the schema name.
+func briefLine(ddl *DDLStatement) string {
+	name := ddl.key.Name
+	if ddl.schemaName != "" {
+		name = ddl.schemaName + "." + name
 	}
-	fmt.Print(ddl.String())
+	return fmt.Sprintf("%s %s\n", name, ddl.key.Type)
 }
@@ -0,0 +1,315 @@
+package applier
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/tengo"
+)
+
+func TestPrinterJSON(t *testing.T) {
+	// captureFlush returns the output of p.Flush(), parsed into JSONEntry values
+	captureFlush := func(p *Printer) (entries []JSONEntry) {
+		t.Helper()
+		tmp, err := ioutil.TempFile("", "skeema-printer-test")
+		if err != nil {
+			t.Fatalf("Unable to create temp file: %v", err)
+		}
+		defer os.Remove(tmp.Name())
+		oldStdout := os.Stdout
+		os.Stdout = tmp
+		err = p.Flush()
+		os.Stdout = oldStdout
+		if err != nil {
+			t.Fatalf("Unexpected error from Flush: %v", err)
+		}
+		contents, _ := ioutil.ReadFile(tmp.Name())
+		if err := json.Unmarshal(contents, &entries); err != nil {
+			t.Fatalf("Output is not valid JSON: %v\n%s", err, contents)
+		} else if entries == nil {
+			t.Fatalf("Expected output to be a JSON array, instead found %s", contents)
+		}
+		return entries
+	}
+
+	inst, err := tengo.NewInstance("mysql", "root@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unable to create instance: %v", err)
+	}
+	cfg := mybase.SimpleConfig(map[string]string{
+		"safe-below-size":        "",
+		"alter-wrapper":          "",
+		"alter-wrapper-min-size": "",
+		"ddl-wrapper":            "",
+		"gh-ost":                 "",
+		"lock-wait-timeout":      "",
+		"statement-timeout":      "",
+		"show-table-size":        "",
+		"dry-run-validate":       "",
+		"foreign-key-checks":     "",
+	})
+	target := &Target{
+		Instance:   inst,
+		Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: cfg},
+		SchemaName: "analytics",
+	}
+	from := &tengo.Schema{
+		Name: "analytics",
+		Tables: []*tengo.Table{
+			{Name: "foo", CreateStatement: "CREATE TABLE `foo` (\n  `id` int(10) unsigned NOT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=latin1"},
+		},
+	}
+	to := &tengo.Schema{
+		Name: "analytics",
+		Tables: []*tengo.Table{
+			{Name: "bar", CreateStatement: "CREATE TABLE `bar` (\n  `id` int(10) unsigned NOT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=latin1"},
+		},
+	}
+
+	// Zero differences should still result in a JSON array
+	p := NewJSONPrinter()
+	if entries := captureFlush(p); len(entries) != 0 {
+		t.Errorf("Expected no entries, instead found %+v", entries)
+	}
+
+	p = NewJSONPrinter()
+	mods := tengo.StatementModifiers{AllowUnsafe: true}
+	for _, objDiff := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+		ddl, err := NewDDLStatement(objDiff, mods, target)
+		if err != nil {
+			t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+		}
+		p.printDDL(ddl)
+	}
+	entries := captureFlush(p)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, instead found %d: %+v", len(entries), entries)
+	}
+	expected := map[string]JSONEntry{
+		"bar": {
+			Instance:   "127.0.0.1:3306",
+			Schema:     "analytics",
+			ObjectType: "table",
+			ObjectName: "bar",
+			DiffType:   "CREATE",
+			Statements: []string{to.Tables[0].CreateStatement},
+			Unsafe:     false,
+		},
+		"foo": {
+			Instance:   "127.0.0.1:3306",
+			Schema:     "analytics",
+			ObjectType: "table",
+			ObjectName: "foo",
+			DiffType:   "DROP",
+			Statements: []string{"DROP TABLE `foo`"},
+			Unsafe:     true,
+		},
+	}
+	for _, entry := range entries {
+		exp, ok := expected[entry.ObjectName]
+		if !ok {
+			t.Errorf("Unexpected entry %+v", entry)
+			continue
+		}
+		if entry.Instance != exp.Instance || entry.Schema != exp.Schema || entry.ObjectType != exp.ObjectType || entry.DiffType != exp.DiffType || entry.Unsafe != exp.Unsafe || len(entry.Statements) != 1 || entry.Statements[0] != exp.Statements[0] {
+			t.Errorf("Entry does not match expectation.\nExpected: %+v\nActual:   %+v", exp, entry)
+		}
+	}
+}
+
+func TestPrinterBufferPerTarget(t *testing.T) {
+	cfg := mybase.SimpleConfig(map[string]string{
+		"dry-run":                "1",
+		"safe-below-size":        "",
+		"alter-wrapper":          "",
+		"alter-wrapper-min-size": "",
+		"ddl-wrapper":            "",
+		"gh-ost":                 "",
+		"lock-wait-timeout":      "",
+		"statement-timeout":      "",
+		"show-table-size":        "",
+		"dry-run-validate":       "",
+		"foreign-key-checks":     "",
+	})
+	dir := &fs.Dir{Path: "/var/tmp/fakedir", Config: cfg}
+	from := &tengo.Schema{Name: "analytics"}
+	to := &tengo.Schema{Name: "analytics"}
+	for _, name := range []string{"one", "two", "three"} {
+		to.Tables = append(to.Tables, &tengo.Table{
+			Name:            name,
+			CreateStatement: "CREATE TABLE `" + name + "` (\n  `id` int(10) unsigned NOT NULL\n) ENGINE=InnoDB DEFAULT CHARSET=latin1",
+		})
+	}
+
+	p := NewPrinter(false)
+	p.BufferPerTarget()
+	var targets []*Target
+	var ddls [][]*DDLStatement
+	for n := 0; n < 4; n++ {
+		inst, err := tengo.NewInstance("mysql", fmt.Sprintf("root@tcp(127.0.0.1:%d)/", 3306+n))
+		if err != nil {
+			t.Fatalf("Unable to create instance: %v", err)
+		}
+		target := &Target{Instance: inst, Dir: dir, SchemaName: "analytics"}
+		var targetDDLs []*DDLStatement
+		for _, objDiff := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+			ddl, err := NewDDLStatement(objDiff, tengo.StatementModifiers{}, target)
+			if err != nil {
+				t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+			}
+			targetDDLs = append(targetDDLs, ddl)
+		}
+		targets = append(targets, target)
+		ddls = append(ddls, targetDDLs)
+	}
+
+	tmp, err := ioutil.TempFile("", "skeema-printer-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	oldStdout := os.Stdout
+	os.Stdout = tmp
+	var wg sync.WaitGroup
+	for n := range targets {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			targets[n].processDDL(ddls[n], p)
+		}(n)
+	}
+	wg.Wait()
+	os.Stdout = oldStdout
+	contents, _ := ioutil.ReadFile(tmp.Name())
+
+	// Each instance header should be followed by that instance's complete output,
+	// and appear exactly once
+	blocks := strings.Split(string(contents), "-- instance: ")[1:]
+	if len(blocks) != len(targets) {
+		t.Fatalf("Expected %d instance headers, instead found %d:\n%s", len(targets), len(blocks), contents)
+	}
+	for _, block := range blocks {
+		if strings.Count(block, "USE `analytics`;\n") != 1 || strings.Count(block, "CREATE TABLE") != len(to.Tables) {
+			t.Errorf("Output for instance was not contiguous:\n%s", block)
+		}
+	}
+}
+
+func TestPrinterBrief(t *testing.T) {
+	cfg := mybase.SimpleConfig(map[string]string{
+		"dry-run":                "1",
+		"brief":                  "1",
+		"safe-below-size":        "",
+		"alter-wrapper":          "",
+		"alter-wrapper-min-size": "",
+		"ddl-wrapper":            "",
+		"gh-ost":                 "",
+		"lock-wait-timeout":      "",
+		"statement-timeout":      "",
+		"show-table-size":        "",
+		"dry-run-validate":       "",
+		"foreign-key-checks":     "",
+	})
+	dir := &fs.Dir{Path: "/var/tmp/fakedir", Config: cfg}
+	makeTable := func(name string, colNames ...string) *tengo.Table {
+		table := &tengo.Table{
+			Name:               name,
+			Engine:             "InnoDB",
+			CharSet:            "latin1",
+			Collation:          "latin1_swedish_ci",
+			CollationIsDefault: true,
+		}
+		for _, colName := range colNames {
+			table.Columns = append(table.Columns, &tengo.Column{Name: colName, TypeInDB: "int(10) unsigned"})
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL80)
+		return table
+	}
+	viewCreate := func(body string) *tengo.View {
+		return &tengo.View{
+			Name:            "recent",
+			Algorithm:       "UNDEFINED",
+			Definer:         "root@localhost",
+			SecurityType:    "DEFINER",
+			CheckOption:     "NONE",
+			Body:            body,
+			CreateStatement: "CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`localhost` SQL SECURITY DEFINER VIEW `recent` AS " + body,
+		}
+	}
+	from := &tengo.Schema{
+		Name: "appdb",
+		Tables: []*tengo.Table{
+			makeTable("posts", "id"),
+			makeTable("users", "id"),
+			makeTable("widgets", "id"),
+		},
+		Views: []*tengo.View{viewCreate("select `id` from `posts`")},
+	}
+	to := &tengo.Schema{
+		Name: "appdb",
+		Tables: []*tengo.Table{
+			makeTable("posts", "id", "title_id"),
+			makeTable("users", "id", "name_id"),
+			makeTable("widgets", "id"),
+		},
+		Views: []*tengo.View{viewCreate("select `id`,`title_id` from `posts`")},
+	}
+
+	p := NewPrinter(true)
+	var targets []*Target
+	for n := 0; n < 2; n++ {
+		inst, err := tengo.NewInstance("mysql", fmt.Sprintf("root@tcp(127.0.0.1:%d)/", 3306+n))
+		if err != nil {
+			t.Fatalf("Unable to create instance: %v", err)
+		}
+		targets = append(targets, &Target{Instance: inst, Dir: dir, SchemaName: "appdb"})
+	}
+
+	tmp, err := ioutil.TempFile("", "skeema-printer-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	oldStdout := os.Stdout
+	os.Stdout = tmp
+	for _, target := range targets {
+		var ddls []*DDLStatement
+		for _, objDiff := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+			ddl, err := NewDDLStatement(objDiff, tengo.StatementModifiers{AllowUnsafe: true, Flavor: tengo.FlavorMySQL80}, target)
+			if err != nil {
+				os.Stdout = oldStdout
+				t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+			}
+			ddls = append(ddls, ddl)
+		}
+		target.processDDL(ddls, p)
+	}
+	os.Stdout = oldStdout
+	contents, _ := ioutil.ReadFile(tmp.Name())
+
+	blocks := strings.Split(string(contents), "-- instance: ")[1:]
+	if len(blocks) != len(targets) {
+		t.Fatalf("Expected %d instance headers, instead found %d:\n%s", len(targets), len(blocks), contents)
+	}
+	for n, block := range blocks {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		if lines[0] != targets[n].source() {
+			t.Errorf("Expected instance header for %s, instead found %q", targets[n].source(), lines[0])
+		}
+		actual := lines[1:]
+		sort.Strings(actual)
+		expected := []string{"appdb.posts table", "appdb.recent view", "appdb.users table"}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("Unexpected brief output for %s:\nExpected: %v\nActual:   %v", targets[n].source(), expected, actual)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package applier
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// progressHeartbeatInterval is how often a message is logged while a single
+// DDL statement is still running, when the progress option is enabled.
+var progressHeartbeatInterval = 30 * time.Second
+
+// progress returns true if the start, completion, and elapsed time of each DDL
+// statement should be logged.
+func (t *Target) progress() bool {
+	return !t.dryRun() && t.Dir.Config.GetBool("progress")
+}
+
+// executeWithProgress runs ddl, logging a message before and after, as well as
+// periodically while it is still running. n is the 1-based position of ddl
+// among the total number of statements for the target.
+func (t *Target) executeWithProgress(ddl *DDLStatement, n, total int) error {
+	prefix := fmt.Sprintf("%s %s: [%d/%d]", t.source(), t.SchemaName, n, total)
+	log.Infof("%s Running %s %s", prefix, ddl.diffType, ddl.key)
+	start := time.Now()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(progressHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				log.Infof("%s Still running %s %s after %s", prefix, ddl.diffType, ddl.key, elapsedSince(start))
+			}
+		}
+	}()
+
+	err := ddl.Execute()
+	close(done)
+	wg.Wait()
+	if err != nil {
+		log.Infof("%s Failed after %s", prefix, elapsedSince(start))
+	} else {
+		log.Infof("%s Completed in %s", prefix, elapsedSince(start))
+	}
+	return err
+}
+
+// elapsedSince returns the time elapsed since start, rounded for display
+// purposes.
+func elapsedSince(start time.Time) time.Duration {
+	elapsed := time.Since(start)
+	if elapsed < time.Second {
+		return elapsed.Round(time.Millisecond)
+	}
+	return elapsed.Round(100 * time.Millisecond)
+}
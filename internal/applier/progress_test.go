@@ -0,0 +1,139 @@
+package applier
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/tengo"
+)
+
+func TestProcessDDLProgress(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Fake ddl-wrapper requires sleep binary")
+	}
+
+	cfg := mybase.SimpleConfig(map[string]string{
+		"dry-run":                "",
+		"brief":                  "",
+		"progress":               "1",
+		"safe-below-size":        "",
+		"alter-wrapper":          "",
+		"alter-wrapper-min-size": "",
+		"ddl-wrapper":            "sleep 0.05",
+		"gh-ost":                 "",
+		"lock-wait-timeout":      "",
+		"statement-timeout":      "",
+		"show-table-size":        "",
+		"dry-run-validate":       "",
+		"foreign-key-checks":     "",
+		"connect-options":        "",
+		"user":                   "root",
+		"password":               "",
+		"environment":            "production",
+	})
+	inst, err := tengo.NewInstance("mysql", "root@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unable to create instance: %v", err)
+	}
+	target := &Target{
+		Instance:   inst,
+		Dir:        &fs.Dir{Path: "/var/tmp/fakedir", Config: cfg},
+		SchemaName: "appdb",
+	}
+	makeTable := func(name string) *tengo.Table {
+		table := &tengo.Table{
+			Name:               name,
+			Engine:             "InnoDB",
+			CharSet:            "latin1",
+			Collation:          "latin1_swedish_ci",
+			CollationIsDefault: true,
+			Columns:            []*tengo.Column{{Name: "id", TypeInDB: "int(10) unsigned"}},
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL80)
+		return table
+	}
+	from := &tengo.Schema{Name: "appdb"}
+	to := &tengo.Schema{
+		Name:   "appdb",
+		Tables: []*tengo.Table{makeTable("posts"), makeTable("users")},
+	}
+	var ddls []*DDLStatement
+	for _, objDiff := range tengo.NewSchemaDiff(from, to).ObjectDiffs() {
+		ddl, err := NewDDLStatement(objDiff, tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80}, target)
+		if err != nil {
+			t.Fatalf("Unexpected error from NewDDLStatement: %v", err)
+		}
+		ddls = append(ddls, ddl)
+	}
+	if len(ddls) != 2 {
+		t.Fatalf("Expected 2 DDL statements, instead found %d", len(ddls))
+	}
+	// Object diff order is not deterministic, so sort for predictable output
+	sort.Slice(ddls, func(i, j int) bool { return ddls[i].key.Name < ddls[j].key.Name })
+
+	// Capture log output, as well as printed DDL on stdout, and use a short
+	// heartbeat interval so that at least one heartbeat occurs per statement
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+	oldInterval := progressHeartbeatInterval
+	progressHeartbeatInterval = 10 * time.Millisecond
+	defer func() { progressHeartbeatInterval = oldInterval }()
+	tmp, err := ioutil.TempFile("", "skeema-progress-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	oldStdout := os.Stdout
+	os.Stdout = tmp
+	skipCount := target.processDDL(ddls, NewPrinter(false))
+	os.Stdout = oldStdout
+	tmp.Close()
+	if skipCount != 0 {
+		t.Errorf("Expected skipCount of 0, instead found %d", skipCount)
+	}
+
+	output := logBuf.String()
+	patterns := []string{
+		`127\.0\.0\.1:3306 appdb: \[1/2\] Running CREATE table ` + "`posts`",
+		`127\.0\.0\.1:3306 appdb: \[1/2\] Still running CREATE table ` + "`posts`" + ` after \d+ms`,
+		`127\.0\.0\.1:3306 appdb: \[1/2\] Completed in \d+ms`,
+		`127\.0\.0\.1:3306 appdb: \[2/2\] Running CREATE table ` + "`users`",
+		`127\.0\.0\.1:3306 appdb: \[2/2\] Completed in \d+ms`,
+		`127\.0\.0\.1:3306 appdb: Ran 2 of 2 statements in \d+ms`,
+	}
+	for _, pattern := range patterns {
+		if !regexp.MustCompile(pattern).MatchString(output) {
+			t.Errorf("Expected log output to match %s, but it did not. Output:\n%s", pattern, output)
+		}
+	}
+
+	// Without the progress option, none of these messages should be logged
+	logBuf.Reset()
+	target.Dir.Config = mybase.SimpleConfig(map[string]string{
+		"dry-run":          "",
+		"dry-run-validate": "",
+		"progress":         "",
+	})
+	tmp, err = ioutil.TempFile("", "skeema-progress-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	os.Stdout = tmp
+	target.processDDL(ddls, NewPrinter(false))
+	os.Stdout = oldStdout
+	tmp.Close()
+	if logBuf.Len() > 0 {
+		t.Errorf("Expected no log output without progress option, instead found:\n%s", logBuf.String())
+	}
+}
@@ -2,7 +2,9 @@ package applier
 
 import (
 	"database/sql"
+	"time"
 
+	"github.com/VividCortex/mysqlerr"
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/workspace"
@@ -13,16 +15,34 @@ import (
 // one instance and schema, targets are generated as the cartesian product of
 // (instances this dir maps to) x (schemas that this dir maps to on each
 // instance).
+//
+// With the from-dump option, Instance is nil, and the current version of the
+// schema is instead obtained from DumpSchema, which is nil if the dump does
+// not contain the schema.
 type Target struct {
 	Instance      *tengo.Instance
 	Dir           *fs.Dir
 	SchemaName    string
 	DesiredSchema *workspace.Schema
+	Dump          *fs.Dump
+	DumpSchema    *workspace.Schema
+	dumpFlavor    tengo.Flavor
+	throttle      *throttler
+	confirm       *confirmer
 }
 
 // SchemaFromInstance introspects and returns the instance's version of the
-// schema, if it exists.
+// schema, if it exists. If the target uses a dump file instead of an instance,
+// the dump's version of the schema is returned.
 func (t *Target) SchemaFromInstance() (*tengo.Schema, error) {
+	if t.Dump != nil {
+		if t.DumpSchema == nil {
+			return nil, nil
+		}
+		schemaCopy := *t.DumpSchema.Schema
+		schemaCopy.Name = t.SchemaName
+		return &schemaCopy, nil
+	}
 	schema, err := t.Instance.Schema(t.SchemaName)
 	if err == sql.ErrNoRows {
 		err = nil
@@ -37,10 +57,30 @@ func (t *Target) SchemaFromDir() *tengo.Schema {
 	return &schemaCopy
 }
 
+// source returns a string describing where the target's current version of
+// the schema comes from: the instance's address, or the path of a dump file.
+func (t *Target) source() string {
+	if t.Dump != nil {
+		return t.Dump.Path
+	}
+	return t.Instance.String()
+}
+
+// flavor returns the flavor of the target's instance, or with a dump file, the
+// flavor of the workspace that the dump was executed in.
+func (t *Target) flavor() tengo.Flavor {
+	if t.Dump != nil {
+		return t.dumpFlavor
+	}
+	return t.Instance.Flavor()
+}
+
 // dryRun returns true if this target is only being used for dry-run purposes,
-// rather than actually wanting to apply changes to this target.
+// rather than actually wanting to apply changes to this target. The
+// dry-run-validate option implies dry-run, regardless of which option file
+// enabled it.
 func (t *Target) dryRun() bool {
-	return t.Dir.Config.GetBool("dry-run")
+	return t.Dir.Config.GetBool("dry-run") || t.Dir.Config.GetBool("dry-run-validate")
 }
 
 // briefOutput returns true if this target is only being evaluated for having
@@ -51,9 +91,9 @@ func (t *Target) briefOutput() bool {
 
 func (t *Target) logApplyStart() {
 	if t.dryRun() {
-		log.Infof("Generating diff of %s %s vs %s/*.sql", t.Instance, t.SchemaName, t.Dir)
+		log.Infof("Generating diff of %s %s vs %s/*.sql", t.source(), t.SchemaName, t.Dir)
 	} else {
-		log.Infof("Pushing changes from %s/*.sql to %s %s", t.Dir, t.Instance, t.SchemaName)
+		log.Infof("Pushing changes from %s/*.sql to %s %s", t.Dir, t.source(), t.SchemaName)
 	}
 	if len(t.Dir.IgnoredStatements) > 0 {
 		log.Warnf("Ignoring %d unsupported or unparseable statements found in this directory's *.sql files; run `skeema lint` for more info", len(t.Dir.IgnoredStatements))
@@ -66,30 +106,85 @@ func (t *Target) logApplyEnd(result Result) {
 		if t.dryRun() {
 			verb = "diff"
 		}
-		log.Infof("%s %s: %s complete\n", t.Instance, t.SchemaName, verb)
+		log.Infof("%s %s: %s complete\n", t.source(), t.SchemaName, verb)
 	} else {
-		log.Infof("%s %s: No differences found\n", t.Instance, t.SchemaName)
+		log.Infof("%s %s: No differences found\n", t.source(), t.SchemaName)
 	}
 }
 
 func (t *Target) processDDL(ddls []*DDLStatement, printer *Printer) (skipCount int) {
+	// If output is buffered per target, print everything attempted once done
+	attempted := len(ddls)
+	if printer.buffered {
+		defer func() {
+			printer.printDDL(ddls[:attempted]...)
+		}()
+	}
+	// With the progress option, log a summary once done
+	var executed int
+	if t.progress() {
+		start := time.Now()
+		defer func() {
+			log.Infof("%s %s: Ran %d of %s in %s", t.source(), t.SchemaName, executed, countAndNoun(len(ddls), "statement", "statements"), elapsedSince(start))
+		}()
+	}
 	for i, ddl := range ddls {
-		printer.printDDL(ddl)
+		// With max-replica-lag, wait for replicas to catch up before each statement.
+		// External tools already handle their own throttling, so shell-outs are
+		// not delayed.
+		if t.throttle != nil && !ddl.IsShellOut() {
+			if _, err := t.throttle.wait(); err != nil {
+				attempted = i
+				skipCount += len(ddls) - i
+				log.Errorf("%s; skipping %d remaining operations for %s %s", err, len(ddls)-i, t.source(), t.SchemaName)
+				return
+			}
+		}
+		if !printer.buffered {
+			printer.printDDL(ddl)
+		}
 		if !t.dryRun() {
-			if err := ddl.Execute(); err != nil {
-				log.Errorf("Error running DDL on %s %s: %s", t.Instance, t.SchemaName, err)
+			var err error
+			if t.progress() {
+				err = t.executeWithProgress(ddl, i+1, len(ddls))
+			} else {
+				err = ddl.Execute()
+			}
+			if err != nil {
+				attempted = i + 1
+				log.Errorf("Error running DDL on %s %s: %s", t.source(), t.SchemaName, err)
+				if hint := ddlErrorHint(err); hint != "" {
+					log.Error(hint)
+				}
 				skipped := len(ddls) - i
 				skipCount += skipped
 				if skipped > 1 {
-					log.Warnf("Skipping %d remaining operations for %s %s due to previous error", skipped-1, t.Instance, t.SchemaName)
+					log.Warnf("Skipping %d remaining operations for %s %s due to previous error", skipped-1, t.source(), t.SchemaName)
 				}
 				return
 			}
+			executed++
 		}
 	}
 	return
 }
 
+// mariaStatementTimeout is MariaDB's error code when a statement is
+// interrupted for exceeding max_statement_time, which is not present in
+// mysqlerr.
+const mariaStatementTimeout = 1969
+
+// ddlErrorHint returns an explanation of how options may relate to the supplied
+// DDL execution error, or a blank string if there's no relevant explanation.
+func ddlErrorHint(err error) string {
+	if tengo.IsDatabaseError(err, mysqlerr.ER_LOCK_WAIT_TIMEOUT, mysqlerr.ER_QUERY_TIMEOUT, mariaStatementTimeout) {
+		return "This statement exceeded a limit imposed by option lock-wait-timeout or statement-timeout. Consider raising the option's value, or running the statement at a less busy time."
+	} else if tengo.IsDatabaseError(err, mysqlerr.ER_ALTER_OPERATION_NOT_SUPPORTED, mysqlerr.ER_ALTER_OPERATION_NOT_SUPPORTED_REASON) {
+		return "The server cannot run this statement with the ALGORITHM or LOCK clause requested by option alter-algorithm or alter-lock. The statement was not retried without the clause; adjust these options if a more expensive operation is acceptable."
+	}
+	return ""
+}
+
 // TargetGroup represents a group of Targets that all have the same Instance.
 type TargetGroup []*Target
 
@@ -107,15 +202,26 @@ func TargetsForDir(dir *fs.Dir, maxDepth int) (targets []*Target, skipCount int)
 		log.Errorf("Skipping %s: %s\n", dir.Path, dir.ParseError)
 		return nil, 1
 	}
-	if dir.Config.Changed("host") && dir.HasSchema() {
+	if dumpPath := dir.Config.Get("from-dump"); (dir.Config.Changed("host") || dumpPath != "") && dir.HasSchema() {
+		// With from-dump, there is no instance; targets use the dump file instead
 		var instances []*tengo.Instance
-		instances, skipCount = instancesForDir(dir)
+		var dump *fs.Dump
+		if dumpPath != "" {
+			var err error
+			if dump, err = fs.ParseDump(dumpPath); err != nil {
+				log.Errorf("Skipping %s: Unable to read dump file: %s\n", dir, err)
+				return nil, 1
+			}
+			instances = []*tengo.Instance{nil}
+		} else {
+			instances, skipCount = instancesForDir(dir)
+		}
 
 		// For each LogicalSchema, obtain a *tengo.Schema representation and then
 		// create a Target for each instance x schema combination
 		if len(instances) > 0 {
 			for n, logicalSchema := range dir.LogicalSchemas {
-				thisTargets, thisSkipCount := targetsForLogicalSchema(logicalSchema, dir, instances)
+				thisTargets, thisSkipCount := targetsForLogicalSchema(logicalSchema, dir, instances, dump)
 				targets = append(targets, thisTargets...)
 				skipCount += thisSkipCount
 				if thisSkipCount > 0 {
@@ -190,7 +296,10 @@ func instancesForDir(dir *fs.Dir) (instances []*tengo.Instance, skipCount int) {
 	return
 }
 
-func targetsForLogicalSchema(logicalSchema *fs.LogicalSchema, dir *fs.Dir, instances []*tengo.Instance) (targets []*Target, skipCount int) {
+// targetsForLogicalSchema returns Targets for each instance x schema name
+// combination that logicalSchema maps to. If dump is non-nil, instances should
+// consist of a single nil element, and the dump is used in place of an instance.
+func targetsForLogicalSchema(logicalSchema *fs.LogicalSchema, dir *fs.Dir, instances []*tengo.Instance, dump *fs.Dump) (targets []*Target, skipCount int) {
 	// If there are multiple logical schemas defined in this directory, prohibit
 	// mixing configuration styles. Either all CREATEs should be in a single
 	// unnamed logical schema (with schema name controlled via .skeema file), OR
@@ -204,7 +313,13 @@ func targetsForLogicalSchema(logicalSchema *fs.LogicalSchema, dir *fs.Dir, insta
 
 	// Obtain a *tengo.Schema representation of the dir's *.sql files from a
 	// workspace
-	opts, err := workspace.OptionsForDir(dir, instances[0])
+	var opts workspace.Options
+	var err error
+	if dump != nil {
+		opts, err = workspace.OptionsForDump(dir, dump.Flavor)
+	} else {
+		opts, err = workspace.OptionsForDir(dir, instances[0])
+	}
 	if err != nil {
 		log.Errorf("Skipping %s: %s\n", dir, err)
 		return nil, len(instances)
@@ -223,7 +338,11 @@ func targetsForLogicalSchema(logicalSchema *fs.LogicalSchema, dir *fs.Dir, insta
 	for _, inst := range instances {
 		// Obtain the list of schema names configured in .skeema
 		schemaNames, err := dir.SchemaNames(inst)
-		if err != nil {
+		if err != nil && dump != nil {
+			log.Errorf("Skipping %s: %s\n", dir, err)
+			skipCount++
+			continue
+		} else if err != nil {
 			log.Errorf("Skipping %s for %s: %s\n", inst, dir, err)
 			skipCount++
 			continue
@@ -259,12 +378,42 @@ func targetsForLogicalSchema(logicalSchema *fs.LogicalSchema, dir *fs.Dir, insta
 				SchemaName:    schemaName,
 				DesiredSchema: wsSchema,
 			}
+			if dump != nil {
+				if t.DumpSchema, err = dumpSchema(dump, schemaName, opts); err != nil {
+					log.Errorf("Skipping %s schema %s for %s: %s\n", dump.Path, schemaName, dir, err)
+					skipCount++
+					continue
+				}
+				t.Dump, t.dumpFlavor = dump, opts.Flavor
+			}
 			targets = append(targets, t)
 		}
 	}
 	return
 }
 
+// dumpSchema executes the dump's statements for the supplied schema name in a
+// workspace, returning the resulting schema, or nil if the dump does not
+// contain the schema. Any SQL error is returned as an error, since the dump
+// would not be an accurate representation of the schema in that case.
+func dumpSchema(dump *fs.Dump, schemaName string, opts workspace.Options) (*workspace.Schema, error) {
+	logicalSchema := dump.LogicalSchema(schemaName)
+	if logicalSchema == nil {
+		return nil, nil
+	}
+	wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(wsSchema.Failures) > 0 {
+		for _, stmtErr := range wsSchema.Failures[1:] {
+			log.Error(stmtErr.Error())
+		}
+		return nil, wsSchema.Failures[0]
+	}
+	return wsSchema, nil
+}
+
 // TargetGroupChanForDir returns a channel for obtaining TargetGroups for this
 // dir and its subdirs, and count of directories that were skipped due to non-
 // fatal errors.
@@ -274,17 +423,53 @@ func TargetGroupChanForDir(dir *fs.Dir) (<-chan TargetGroup, int) {
 	go func() {
 		byInst := make(map[string]TargetGroup)
 		for _, t := range targets {
-			key := t.Instance.String()
+			key := t.source()
 			byInst[key] = append(byInst[key], t)
 		}
 		for _, tg := range byInst {
-			groups <- tg
+			groups <- tg.orderByForeignKeys()
 		}
 		close(groups)
 	}()
 	return groups, skipCount
 }
 
+// orderByForeignKeys returns a copy of tg, reordered such that any target
+// whose desired schema has foreign keys referencing another target's schema
+// comes after that target. Otherwise, the original order is preserved. Since
+// the targets in a group are processed sequentially, this ensures tables are
+// created in referenced schemas before tables referencing them. In the case of
+// circular references, the original order is used to break the cycle.
+func (tg TargetGroup) orderByForeignKeys() TargetGroup {
+	bySchema := make(map[string]*Target, len(tg))
+	for _, t := range tg {
+		bySchema[t.SchemaName] = t
+	}
+	result := make(TargetGroup, 0, len(tg))
+	visited := make(map[*Target]bool, len(tg))
+	var visit func(t *Target)
+	visit = func(t *Target) {
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+		if t.DesiredSchema != nil && t.DesiredSchema.Schema != nil {
+			for _, table := range t.DesiredSchema.Tables {
+				for _, fk := range table.ForeignKeys {
+					if parent := bySchema[fk.ReferencedSchemaName]; parent != nil && parent != t {
+						visit(parent)
+					}
+				}
+			}
+		}
+		result = append(result, t)
+	}
+	for _, t := range tg {
+		visit(t)
+	}
+	return result
+}
+
 func logFailedStatements(dir *fs.Dir, failures []*workspace.StatementError) {
 	for _, stmtErr := range failures {
 		log.Error(stmtErr.Error())
@@ -265,10 +265,78 @@ func (s ApplierIntegrationSuite) TestTargetGroupChanForDir(t *testing.T) {
 	}
 }
 
+func (s ApplierIntegrationSuite) TestTargetGroupChanForDirCrossSchemaFK(t *testing.T) {
+	setupHostList(t, s.d[0].Instance)
+
+	// invoices has a foreign key referencing lookup, so lookup must be processed
+	// first, even though its dir sorts after invoices
+	dir := getDir(t, "testdata/crossschema", "")
+	tgchan, skipCount := TargetGroupChanForDir(dir)
+	if skipCount != 0 {
+		t.Fatalf("Expected skip count of 0, instead found %d", skipCount)
+	}
+	tg := <-tgchan
+	if len(tg) != 2 || tg[0].SchemaName != "lookup" || tg[1].SchemaName != "invoices" {
+		t.Fatalf("Unexpected contents in targetgroup: %+v", tg)
+	}
+	if fk := tg[1].DesiredSchema.Tables[0].ForeignKeys[0]; fk.ReferencedSchemaName != "lookup" {
+		t.Errorf("Expected foreign key to reference schema lookup, instead found %q", fk.ReferencedSchemaName)
+	}
+}
+
+func TestTargetGroupOrderByForeignKeys(t *testing.T) {
+	makeTarget := func(schemaName string, referencedSchemas ...string) *Target {
+		table := &tengo.Table{Name: "tbl"}
+		for _, ref := range referencedSchemas {
+			table.ForeignKeys = append(table.ForeignKeys, &tengo.ForeignKey{
+				Name:                 "fk_" + ref,
+				ReferencedSchemaName: ref,
+				ReferencedTableName:  "tbl",
+			})
+		}
+		return &Target{
+			SchemaName: schemaName,
+			DesiredSchema: &workspace.Schema{
+				Schema: &tengo.Schema{Name: schemaName, Tables: []*tengo.Table{table}},
+			},
+		}
+	}
+	schemaNames := func(tg TargetGroup) string {
+		names := make([]string, len(tg))
+		for n, target := range tg {
+			names[n] = target.SchemaName
+		}
+		return strings.Join(names, ",")
+	}
+	cases := []struct {
+		input    TargetGroup
+		expected string
+	}{
+		// Referenced schemas move before referencing ones
+		{TargetGroup{makeTarget("orders", "lookup"), makeTarget("lookup")}, "lookup,orders"},
+		// Transitive references; also, references to unmanaged schemas or the same
+		// schema are ignored
+		{TargetGroup{makeTarget("a", "b", "external"), makeTarget("b", "c"), makeTarget("c", "c")}, "c,b,a"},
+		// Without references, the original order is preserved
+		{TargetGroup{makeTarget("z"), makeTarget("y"), makeTarget("x")}, "z,y,x"},
+		// Circular references still yield each target exactly once
+		{TargetGroup{makeTarget("a", "b"), makeTarget("b", "a"), makeTarget("c")}, "b,a,c"},
+		// Targets without a desired schema are left as-is
+		{TargetGroup{makeTarget("a", "b"), {SchemaName: "b"}}, "b,a"},
+	}
+	for _, c := range cases {
+		if actual := schemaNames(c.input.orderByForeignKeys()); actual != c.expected {
+			t.Errorf("Unexpected order from orderByForeignKeys: expected %s, found %s", c.expected, actual)
+		}
+	}
+}
+
 func getBaseConfig(t *testing.T, cliFlags string) *mybase.Config {
 	cmd := mybase.NewCommand("appliertest", "", "", nil)
 	cmd.AddOption(mybase.BoolOption("verify", 0, true, "Test all generated ALTER statements on temp schema to verify correctness"))
 	cmd.AddOption(mybase.BoolOption("allow-unsafe", 0, false, "Permit running ALTER or DROP operations that are potentially destructive"))
+	cmd.AddOption(mybase.BoolOption("interactive", 0, false, "Prompt for confirmation of each unsafe statement, instead of skipping the target"))
+	cmd.AddOption(mybase.BoolOption("atomic", 0, false, "Skip targets whose changes cannot be applied in a single atomic DDL statement"))
 	cmd.AddOption(mybase.BoolOption("dry-run", 0, false, "Output DDL but don't run it; equivalent to `skeema diff`"))
 	cmd.AddOption(mybase.BoolOption("first-only", '1', false, "For dirs mapping to multiple instances or schemas, just run against the first per dir"))
 	cmd.AddOption(mybase.BoolOption("exact-match", 0, false, "Follow *.sql table definitions exactly, even for differences with no functional impact"))
@@ -279,7 +347,20 @@ func getBaseConfig(t *testing.T, cliFlags string) *mybase.Config {
 	cmd.AddOption(mybase.StringOption("alter-lock", 0, "", `Apply a LOCK clause to all ALTER TABLEs (valid values: "none", "shared", "exclusive")`))
 	cmd.AddOption(mybase.StringOption("alter-algorithm", 0, "", `Apply an ALGORITHM clause to all ALTER TABLEs (valid values: "inplace", "copy", "instant")`))
 	cmd.AddOption(mybase.StringOption("ddl-wrapper", 'X', "", "Like --alter-wrapper, but applies to all DDL types (CREATE, DROP, ALTER)"))
+	cmd.AddOption(mybase.BoolOption("gh-ost", 0, false, "Run ALTER TABLEs using gh-ost, subject to --alter-wrapper-min-size"))
+	cmd.AddOption(mybase.StringOption("gh-ost-bin", 0, "gh-ost", "Path to gh-ost binary for use with --gh-ost"))
+	cmd.AddOption(mybase.StringOption("gh-ost-flags", 0, "", "Additional flags to pass through to gh-ost for use with --gh-ost"))
+	cmd.AddOption(mybase.StringOption("pre-push-command", 0, "", "Shell command to run before pushing changes to each target; target is skipped if it fails"))
+	cmd.AddOption(mybase.StringOption("post-push-command", 0, "", "Shell command to run after pushing changes to each target"))
 	cmd.AddOption(mybase.StringOption("safe-below-size", 0, "0", "Always permit destructive operations for tables below this size in bytes"))
+	cmd.AddOption(mybase.StringOption("lock-wait-timeout", 0, "", "Limit how long each DDL statement may wait for metadata locks, e.g. \"30s\""))
+	cmd.AddOption(mybase.StringOption("statement-timeout", 0, "", "Limit how long each DDL statement may run, e.g. \"10m\"; flavor-dependent"))
+	cmd.AddOption(mybase.BoolOption("show-table-size", 0, false, "Annotate each ALTER TABLE or DROP TABLE with the table's approximate row count and size"))
+	cmd.AddOption(mybase.BoolOption("dry-run-validate", 0, false, "Like --dry-run, but also check live data for problems that would cause ALTER TABLE to fail"))
+	cmd.AddOption(mybase.StringOption("max-replica-lag", 0, "", "Before each DDL statement, wait while lag of any --replica exceeds this duration"))
+	cmd.AddOption(mybase.StringOption("replica", 0, "", "Replica host to check for --max-replica-lag").Repeatable(","))
+	cmd.AddOption(mybase.BoolOption("progress", 0, false, "Log each DDL statement as it runs, along with its elapsed time"))
+	cmd.AddOption(mybase.StringOption("from-dump", 0, "", "Compare the filesystem to the schema in this mysqldump or SHOW CREATE file"))
 	cmd.AddOption(mybase.StringOption("concurrent-instances", 'c', "1", "Perform operations on this number of instances concurrently"))
 	cmd.AddArg("environment", "production", false)
 	util.AddGlobalOptions(cmd)
@@ -0,0 +1,7 @@
+CREATE TABLE invoices (
+	id int unsigned NOT NULL AUTO_INCREMENT,
+	country_code char(2) NOT NULL,
+	PRIMARY KEY (id),
+	KEY country_code (country_code),
+	CONSTRAINT invoices_country FOREIGN KEY (country_code) REFERENCES lookup.countries (code)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
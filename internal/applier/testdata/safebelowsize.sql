@@ -0,0 +1,20 @@
+CREATE DATABASE sizes;
+USE sizes
+
+CREATE TABLE small_rows (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(40) NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
+
+CREATE TABLE large_rows (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(40) NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
+
+CREATE TABLE no_rows (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(40) NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
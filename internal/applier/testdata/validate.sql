@@ -0,0 +1,18 @@
+CREATE DATABASE validate;
+USE validate
+
+CREATE TABLE has_nulls (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  email varchar(80) NOT NULL,
+  nickname varchar(40) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY nickname (nickname)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
+
+CREATE TABLE no_nulls (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  email varchar(80) NOT NULL,
+  nickname varchar(40) NOT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY nickname (nickname)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
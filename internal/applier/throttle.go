@@ -0,0 +1,147 @@
+package applier
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/tengo"
+)
+
+// lagCheckInterval is how long a throttler sleeps between checks of replica
+// lag, while lag exceeds the configured maximum.
+const lagCheckInterval = time.Second
+
+// lagSource is anything capable of reporting its replication lag. Typically
+// this is a replica instance.
+type lagSource interface {
+	ReplicaLag() (time.Duration, error)
+	String() string
+}
+
+// throttler delays execution of DDL while replication lag of any replica
+// exceeds a maximum, as configured by the max-replica-lag and replica options.
+type throttler struct {
+	maxLag   time.Duration
+	replicas []lagSource
+	interval time.Duration
+	sleep    func(time.Duration)
+}
+
+// newThrottler returns a throttler based on the max-replica-lag and replica
+// options of the target's dir. If max-replica-lag is not set, a nil throttler
+// is returned.
+func newThrottler(t *Target) (*throttler, error) {
+	maxLag, err := getTimeoutOption(t.Dir.Config, "max-replica-lag")
+	if err != nil || maxLag == 0 {
+		return nil, err
+	}
+	hosts := t.Dir.Config.GetSlice("replica", ',', true)
+	if len(hosts) == 0 {
+		return nil, errors.New("Option max-replica-lag requires option replica to list at least one replica host")
+	}
+	instances, err := t.Dir.InstancesForHosts(hosts)
+	if err != nil {
+		return nil, err
+	}
+	th := &throttler{
+		maxLag:   maxLag,
+		interval: lagCheckInterval,
+		sleep:    time.Sleep,
+	}
+	for _, inst := range instances {
+		th.replicas = append(th.replicas, replicaInstance{inst})
+	}
+	return th, nil
+}
+
+// wait blocks until the lag of every replica is at or below the maximum,
+// checking lag repeatedly with a sleep in between each check. The number of
+// sleep cycles is returned. If the lag of any replica cannot be determined, an
+// error is returned immediately.
+func (th *throttler) wait() (cycles int, err error) {
+	for {
+		var worstLag time.Duration
+		var worstReplica lagSource
+		for _, replica := range th.replicas {
+			lag, err := replica.ReplicaLag()
+			if err != nil {
+				return cycles, fmt.Errorf("Unable to determine replica lag of %s: %s", replica, err)
+			}
+			if worstReplica == nil || lag > worstLag {
+				worstLag, worstReplica = lag, replica
+			}
+		}
+		if worstLag <= th.maxLag {
+			if cycles > 0 {
+				log.Infof("Replica lag has subsided to %s; resuming", worstLag)
+			}
+			return cycles, nil
+		}
+		if cycles == 0 {
+			log.Infof("Replica %s is lagging by %s, exceeding max-replica-lag of %s; pausing", worstReplica, worstLag, th.maxLag)
+		} else {
+			log.Debugf("Replica %s is still lagging by %s", worstReplica, worstLag)
+		}
+		th.sleep(th.interval)
+		cycles++
+	}
+}
+
+// replicaInstance wraps a tengo.Instance to satisfy the lagSource interface.
+type replicaInstance struct {
+	*tengo.Instance
+}
+
+// ReplicaLag returns the instance's replication lag, as reported by its
+// replication status. An error is returned if the instance cannot be reached,
+// is not a replica, or does not currently have replication running.
+func (ri replicaInstance) ReplicaLag() (time.Duration, error) {
+	query, column := "SHOW SLAVE STATUS", "Seconds_Behind_Master"
+	if ri.Flavor().MySQLishMinVersion(8, 0, 22) {
+		query, column = "SHOW REPLICA STATUS", "Seconds_Behind_Source"
+	}
+	db, err := ri.CachedConnectionPool("", "")
+	if err != nil {
+		return 0, err
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, errors.New("instance is not configured as a replica")
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for n := range values {
+		dest[n] = &values[n]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+	for n, name := range columns {
+		if name != column {
+			continue
+		} else if !values[n].Valid {
+			return 0, errors.New("replication is not running")
+		}
+		seconds, err := strconv.ParseInt(values[n].String, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, fmt.Errorf("%s did not return column %s", query, column)
+}
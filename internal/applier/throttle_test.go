@@ -0,0 +1,77 @@
+package applier
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeLagSource returns successive values from lags on each call to
+// ReplicaLag, repeating the final value once exhausted.
+type fakeLagSource struct {
+	name  string
+	lags  []time.Duration
+	err   error
+	calls int
+}
+
+func (fls *fakeLagSource) ReplicaLag() (time.Duration, error) {
+	if fls.err != nil {
+		return 0, fls.err
+	}
+	n := fls.calls
+	if n >= len(fls.lags) {
+		n = len(fls.lags) - 1
+	}
+	fls.calls++
+	return fls.lags[n], nil
+}
+
+func (fls *fakeLagSource) String() string {
+	return fls.name
+}
+
+func TestThrottlerWait(t *testing.T) {
+	var sleeps []time.Duration
+	newTestThrottler := func(replicas ...lagSource) *throttler {
+		sleeps = nil
+		return &throttler{
+			maxLag:   30 * time.Second,
+			replicas: replicas,
+			interval: 5 * time.Second,
+			sleep:    func(d time.Duration) { sleeps = append(sleeps, d) },
+		}
+	}
+
+	// Lag below the limit should not cause any waiting
+	th := newTestThrottler(&fakeLagSource{name: "replica1", lags: []time.Duration{0}})
+	if cycles, err := th.wait(); cycles != 0 || err != nil || len(sleeps) != 0 {
+		t.Errorf("Expected no waiting, instead found cycles=%d err=%v sleeps=%v", cycles, err, sleeps)
+	}
+
+	// High-then-low lag: should wait once per high value, based on the worst
+	// lag among all replicas
+	replica1 := &fakeLagSource{name: "replica1", lags: []time.Duration{45 * time.Second, 31 * time.Second, 10 * time.Second}}
+	replica2 := &fakeLagSource{name: "replica2", lags: []time.Duration{time.Minute, 20 * time.Second, 40 * time.Second, 30 * time.Second}}
+	th = newTestThrottler(replica1, replica2)
+	if cycles, err := th.wait(); cycles != 3 || err != nil {
+		t.Errorf("Expected 3 cycles and nil error, instead found cycles=%d err=%v", cycles, err)
+	}
+	if len(sleeps) != 3 || sleeps[0] != 5*time.Second {
+		t.Errorf("Unexpected sleeps: %v", sleeps)
+	}
+	if replica1.calls != 4 || replica2.calls != 4 {
+		t.Errorf("Expected each replica to be checked 4 times, instead found %d and %d", replica1.calls, replica2.calls)
+	}
+
+	// Once lag has subsided, a subsequent call should not wait
+	if cycles, err := th.wait(); cycles != 0 || err != nil {
+		t.Errorf("Expected no waiting, instead found cycles=%d err=%v", cycles, err)
+	}
+
+	// Inability to determine lag should return an error, without waiting
+	th = newTestThrottler(&fakeLagSource{name: "replica1", lags: []time.Duration{0}}, &fakeLagSource{name: "replica2", err: errors.New("connection refused")})
+	if cycles, err := th.wait(); err == nil || cycles != 0 || len(sleeps) != 0 {
+		t.Errorf("Expected error without waiting, instead found cycles=%d err=%v sleeps=%v", cycles, err, sleeps)
+	}
+}
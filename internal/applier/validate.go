@@ -0,0 +1,113 @@
+package applier
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/skeema/tengo"
+)
+
+// validateAlter probes the live table on the target's instance for data which
+// would cause the ALTER TABLE in td to fail, for use with the dry-run-validate
+// option. No supported flavor can roll back DDL in a transaction, so instead
+// inexpensive queries check for NULL values prior to a column becoming NOT
+// NULL, and for duplicate values prior to adding a unique or primary key.
+// Descriptions of predicted failures are returned, along with descriptions of
+// any data-dependent changes which cannot be checked this way. Changes which
+// cannot fail due to the table's existing data are not mentioned in either.
+func validateAlter(target *Target, td *tengo.TableDiff) (failures, unchecked []string) {
+	clauses := td.AlterClauses()
+	if len(clauses) == 0 {
+		return nil, nil
+	} else if target.Instance == nil {
+		return nil, []string{"validation requires a database instance"}
+	}
+	db, err := target.Instance.CachedConnectionPool(target.SchemaName, "")
+	if err != nil {
+		return nil, []string{fmt.Sprintf("unable to connect to check table contents: %s", err)}
+	}
+
+	// probe returns true if query returns at least one row. Errors are tracked
+	// as unchecked, since the outcome cannot be predicted.
+	probe := func(query, what string) bool {
+		var found int
+		err := db.Get(&found, query)
+		if err != nil && err != sql.ErrNoRows {
+			unchecked = append(unchecked, fmt.Sprintf("unable to check %s: %s", what, err))
+		}
+		return err == nil
+	}
+	table := tengo.EscapeIdentifier(td.From.Name)
+	existing := td.From.ColumnsByName()
+
+	for _, clause := range clauses {
+		switch clause := clause.(type) {
+		case tengo.ModifyColumn:
+			col := tengo.EscapeIdentifier(clause.OldColumn.Name)
+			if clause.OldColumn.Nullable && !clause.NewColumn.Nullable {
+				what := fmt.Sprintf("column %s for NULL values", col)
+				if probe(fmt.Sprintf("SELECT 1 FROM %s WHERE %s IS NULL LIMIT 1", table, col), what) {
+					failures = append(failures, fmt.Sprintf("column %s contains NULL values, but its new definition is NOT NULL", col))
+				}
+			}
+			if !strings.EqualFold(clause.OldColumn.TypeInDB, clause.NewColumn.TypeInDB) {
+				unchecked = append(unchecked, fmt.Sprintf("changing type of column %s from %s to %s may fail if existing values do not fit the new type", col, clause.OldColumn.TypeInDB, clause.NewColumn.TypeInDB))
+			}
+		case tengo.AddIndex:
+			if !clause.Index.Unique && !clause.Index.PrimaryKey {
+				continue
+			}
+			kind := "unique key"
+			if clause.Index.PrimaryKey {
+				kind = "primary key"
+			}
+			var exprs, conditions []string
+			for _, part := range clause.Index.Parts {
+				var expr string
+				if part.Expression != "" {
+					expr = "(" + part.Expression + ")"
+				} else if existing[part.ColumnName] == nil {
+					// Column is being added by the same ALTER, so it cannot be queried yet
+					exprs = nil
+					break
+				} else if part.PrefixLength > 0 {
+					expr = fmt.Sprintf("LEFT(%s, %d)", tengo.EscapeIdentifier(part.ColumnName), part.PrefixLength)
+				} else {
+					expr = tengo.EscapeIdentifier(part.ColumnName)
+				}
+				exprs = append(exprs, expr)
+				conditions = append(conditions, expr+" IS NOT NULL")
+			}
+			name := tengo.EscapeIdentifier(clause.Index.Name)
+			if clause.Index.PrimaryKey {
+				name = "PRIMARY"
+			}
+			if exprs == nil {
+				unchecked = append(unchecked, fmt.Sprintf("adding %s %s may fail if existing rows have duplicate values, which cannot be checked since it includes a new column", kind, name))
+				continue
+			}
+			var where string
+			if !clause.Index.PrimaryKey {
+				where = " WHERE " + strings.Join(conditions, " AND ")
+			}
+			query := fmt.Sprintf("SELECT 1 FROM %s%s GROUP BY %s HAVING COUNT(*) > 1 LIMIT 1", table, where, strings.Join(exprs, ", "))
+			if probe(query, fmt.Sprintf("%s %s for duplicate values", kind, name)) {
+				failures = append(failures, fmt.Sprintf("existing rows have duplicate values for new %s %s", kind, name))
+			}
+		case tengo.AddForeignKey:
+			if target.Dir.Config.GetBool("foreign-key-checks") {
+				unchecked = append(unchecked, fmt.Sprintf("adding foreign key %s may fail if existing rows lack a matching parent row", tengo.EscapeIdentifier(clause.ForeignKey.Name)))
+			}
+		case tengo.AddCheck:
+			unchecked = append(unchecked, fmt.Sprintf("adding check constraint %s may fail if existing rows violate it", tengo.EscapeIdentifier(clause.Check.Name)))
+		case tengo.AlterCheck:
+			if clause.NewEnforcement {
+				unchecked = append(unchecked, fmt.Sprintf("enforcing check constraint %s may fail if existing rows violate it", tengo.EscapeIdentifier(clause.Check.Name)))
+			}
+		case tengo.PartitionBy:
+			unchecked = append(unchecked, "partitioning may fail if existing rows do not fit the partition definitions")
+		}
+	}
+	return failures, unchecked
+}
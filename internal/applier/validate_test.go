@@ -0,0 +1,72 @@
+package applier
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/tengo"
+)
+
+// TestDryRunValidateFromOptionFile confirms that enabling dry-run-validate in
+// a dir's option file, rather than on the command-line, still prevents any DDL
+// from being executed.
+func TestDryRunValidateFromOptionFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Fake ddl-wrapper requires /bin/sh")
+	}
+
+	// Use a ddl-wrapper which records the name of each object it runs DDL for
+	tmpDir, err := ioutil.TempDir("", "skeema-validate-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	ranFile := filepath.Join(tmpDir, "ran")
+	fs.WriteTestFile(t, filepath.Join(tmpDir, ".skeema"), "schema=appdb\ndry-run-validate\nddl-wrapper=echo {NAME} >> "+ranFile+"\n")
+	dir := getDir(t, tmpDir, "")
+	if dir.Config.GetBool("dry-run") || !dir.Config.GetBool("dry-run-validate") {
+		t.Fatal("Test setup assumption failed: expected dry-run-validate to only be enabled by option file")
+	}
+
+	inst, err := tengo.NewInstance("mysql", "root@tcp(127.0.0.1:3306)/")
+	if err != nil {
+		t.Fatalf("Unable to create instance: %v", err)
+	}
+	target := &Target{
+		Instance:   inst,
+		Dir:        dir,
+		SchemaName: "appdb",
+	}
+	if !target.dryRun() {
+		t.Fatal("Expected dry-run-validate to imply dry-run for target, but it did not")
+	}
+	table := makeTestTable(tengo.FlavorMySQL80, "posts", &tengo.Column{Name: "id", TypeInDB: "int unsigned"})
+	objDiffs := tengo.NewSchemaDiff(&tengo.Schema{Name: "appdb"}, &tengo.Schema{Name: "appdb", Tables: []*tengo.Table{table}}).ObjectDiffs()
+
+	var result Result
+	ddls, _, ok := target.buildDDL(objDiffs, tengo.StatementModifiers{Flavor: tengo.FlavorMySQL80}, &result)
+	if !ok || len(ddls) != 1 {
+		t.Fatalf("Unexpected result from buildDDL: ok=%t, %d statements", ok, len(ddls))
+	}
+	tmp, err := ioutil.TempFile("", "skeema-validate-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	oldStdout := os.Stdout
+	os.Stdout = tmp
+	skipCount := target.processDDL(ddls, NewPrinter(false))
+	os.Stdout = oldStdout
+	tmp.Close()
+	if skipCount != 0 {
+		t.Errorf("Expected skipCount of 0, instead found %d", skipCount)
+	}
+	if _, err := os.Stat(ranFile); !os.IsNotExist(err) {
+		contents, _ := ioutil.ReadFile(ranFile)
+		t.Errorf("Expected no DDL to be executed, but ddl-wrapper ran for: %s", contents)
+	}
+}
@@ -30,11 +30,12 @@ func VerifyDiff(diff *tengo.SchemaDiff, t *Target) error {
 		StrictIndexOrder:       true, // needed since we must get the SHOW CREATE TABLEs to match
 		StrictCheckOrder:       true, // ditto
 		StrictForeignKeyNaming: true, // ditto
+		StrictCheckNaming:      true, // ditto
 		AllowUnsafe:            true, // needed since we're just running against the temp schema
 		SkipPreDropAlters:      true, // needed to ignore DROP PARTITION generated just to speed up DROP TABLE
-		Flavor:                 t.Instance.Flavor(),
+		Flavor:                 t.flavor(),
 	}
-	if major, minor, _ := t.Instance.Version(); major > 5 || minor > 5 {
+	if flavor := t.flavor(); flavor.Major > 5 || flavor.Minor > 5 {
 		// avoid having MySQL ignore index changes that are simply reordered, but only
 		// legal syntax in 5.6+
 		mods.AlgorithmClause = "copy"
@@ -68,10 +69,22 @@ func VerifyDiff(diff *tengo.SchemaDiff, t *Target) error {
 		}
 	}
 
-	opts, err := workspace.OptionsForDir(t.Dir, t.Instance)
+	var opts workspace.Options
+	var err error
+	if t.Dump != nil {
+		opts, err = workspace.OptionsForDump(t.Dir, t.dumpFlavor)
+	} else {
+		opts, err = workspace.OptionsForDir(t.Dir, t.Instance)
+	}
 	if err != nil {
 		return err
 	}
+	// Verification runs a different set of statements than the dir itself, so
+	// it must not disturb a persisted temp schema
+	if opts.CleanupAction == workspace.CleanupActionPersist {
+		opts.CleanupAction = workspace.CleanupActionDrop
+		opts.SchemaName += "_verify"
+	}
 	wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, opts)
 	if err == nil && len(wsSchema.Failures) > 0 {
 		err = wsSchema.Failures[0]
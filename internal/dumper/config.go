@@ -8,12 +8,13 @@ import (
 
 // Options controls dumper behavior.
 type Options struct {
-	IncludeAutoInc bool                     // if false, strip AUTO_INCREMENT clauses from CREATE TABLE
-	Partitioning   tengo.PartitioningMode   // PartitioningKeep: retain previous FS partitioning clause; PartitioningRemove: strip partitioning clause
-	CountOnly      bool                     // if true, skip writing files, just report count of rewrites
-	IgnoreTable    *regexp.Regexp           // skip tables with names matching this regex
-	skipKeys       map[tengo.ObjectKey]bool // skip objects with true values
-	onlyKeys       map[tengo.ObjectKey]bool // if map is non-nil, only format objects with true values
+	IncludeAutoInc bool                      // if false, strip AUTO_INCREMENT clauses from CREATE TABLE
+	Partitioning   tengo.PartitioningMode    // PartitioningKeep: retain previous FS partitioning clause; PartitioningRemove: strip partitioning clause
+	CountOnly      bool                      // if true, skip writing files, just report count of rewrites
+	IgnoreTable    *regexp.Regexp            // skip tables with names matching this regex
+	ObjectTypes    map[tengo.ObjectType]bool // if map is non-nil, skip objects with types not in this set
+	skipKeys       map[tengo.ObjectKey]bool  // skip objects with true values
+	onlyKeys       map[tengo.ObjectKey]bool  // if map is non-nil, only format objects with true values
 }
 
 // OnlyKeys specifies a list of tengo.ObjectKeys that the dump should
@@ -50,6 +51,9 @@ func (opts *Options) shouldIgnore(key tengo.ObjectKey) bool {
 	if key.Type == tengo.ObjectTypeTable && opts.IgnoreTable != nil && opts.IgnoreTable.MatchString(key.Name) {
 		return true
 	}
+	if opts.ObjectTypes != nil && !opts.ObjectTypes[key.Type] {
+		return true
+	}
 	if opts.onlyKeys != nil && !opts.onlyKeys[key] {
 		return true
 	}
@@ -25,6 +25,15 @@ func TestOptionsIgnore(t *testing.T) {
 	assertIgnore(tengo.ObjectTypeTable, "ultimulti", false)
 	assertIgnore(tengo.ObjectTypeFunc, "multi1", false)
 
+	// Confirm behavior of ObjectTypes
+	opts = Options{
+		ObjectTypes: map[tengo.ObjectType]bool{tengo.ObjectTypeTable: true, tengo.ObjectTypeView: true},
+	}
+	assertIgnore(tengo.ObjectTypeTable, "multi1", false)
+	assertIgnore(tengo.ObjectTypeView, "multi1", false)
+	assertIgnore(tengo.ObjectTypeProc, "pounce", true)
+	assertIgnore(tengo.ObjectTypeTrigger, "multi1", true)
+
 	// Confirm behavior of OnlyKeys
 	keys := []tengo.ObjectKey{
 		{Type: tengo.ObjectTypeTable, Name: "cats"},
@@ -7,6 +7,7 @@ package dumper
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/skeema/internal/fs"
@@ -23,7 +24,9 @@ type statement struct {
 // DumpSchema updates the *.sql files in dir to match the creation statements
 // in schema. Any preexisting creation statements in the dir will be updated to
 // match the canonical format from the live schema. Objects that no longer exist
-// in the live schema will have their statements removed. A count of modified
+// in the live schema will have their statements removed. Statements which only
+// differ from the canonical format by the presence of comments are left as-is,
+// so that any user-supplied comments are preserved. A count of modified
 // statements is returned, along with any fatal write error. If opts.CountOnly
 // is true, no actual filesystem writes occur, but a count is still returned.
 func DumpSchema(schema *tengo.Schema, dir *fs.Dir, opts Options) (count int, err error) {
@@ -42,7 +45,7 @@ func DumpSchema(schema *tengo.Schema, dir *fs.Dir, opts Options) (count int, err
 
 	filesToRewrite := make(map[*fs.TokenizedSQLFile]bool)
 	for key, s := range getStatementMap(schema, dir, opts) {
-		if opts.shouldIgnore(key) || s.canonicalCreate == s.filesystemCreate {
+		if opts.shouldIgnore(key) || s.canonicalCreate == s.filesystemCreate || s.onlyCommentsDiffer() {
 			continue
 		}
 
@@ -56,7 +59,10 @@ func DumpSchema(schema *tengo.Schema, dir *fs.Dir, opts Options) (count int, err
 
 		if s.fsStatement == nil { // exists in live db schema but not yet in filesystem
 			contents := fs.AddDelimiter(s.canonicalCreate)
-			filePath := fs.PathForObject(dir.Path, key.Name)
+			filePath := dir.PathForObject(key)
+			if err := dir.EnsureDirForFile(filePath); err != nil {
+				return count, err
+			}
 			if err := appendToFile(filePath, contents); err != nil {
 				return count, err
 			}
@@ -139,6 +145,80 @@ func getStatementMap(schema *tengo.Schema, dir *fs.Dir, opts Options) map[tengo.
 	return statementMap
 }
 
+// onlyCommentsDiffer returns true if the filesystem create contains comments,
+// and would be equivalent to the canonical create if those comments were
+// removed, ignoring any resulting differences in whitespace.
+func (s statement) onlyCommentsDiffer() bool {
+	if s.canonicalCreate == "" || s.fsStatement == nil {
+		return false
+	}
+	fsStripped, hasComments := stripComments(s.filesystemCreate)
+	if !hasComments {
+		return false
+	}
+	canonicalStripped, _ := stripComments(s.canonicalCreate)
+	return fsStripped == canonicalStripped
+}
+
+// stripComments returns body with any comments removed, and with each run of
+// whitespace outside of quoted strings and identifiers collapsed to a single
+// space. Version-gated /*! ... */ comments are retained, since the server
+// executes their contents. The second return value is true if any comments
+// were removed.
+func stripComments(body string) (string, bool) {
+	var b strings.Builder
+	var found, pendingSpace bool
+	for i := 0; i < len(body); {
+		c := body[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := i + 1
+			for end < len(body) && body[end] != c {
+				if body[end] == '\\' && c != '`' {
+					end++
+				}
+				end++
+			}
+			end++ // include closing quote; a doubled quote is handled as two adjacent quoted strings
+			if end > len(body) {
+				end = len(body)
+			}
+			if pendingSpace && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(body[i:end])
+			i, pendingSpace = end, false
+		case c == '#' || (c == '-' && strings.HasPrefix(body[i:], "--") && (i+2 == len(body) || isSpace(body[i+2]))):
+			if end := strings.IndexByte(body[i:], '\n'); end == -1 {
+				i = len(body)
+			} else {
+				i += end
+			}
+			found, pendingSpace = true, true
+		case strings.HasPrefix(body[i:], "/*") && !strings.HasPrefix(body[i:], "/*!"):
+			if end := strings.Index(body[i+2:], "*/"); end == -1 {
+				i = len(body)
+			} else {
+				i += end + 4
+			}
+			found, pendingSpace = true, true
+		case isSpace(c):
+			i, pendingSpace = i+1, true
+		default:
+			if pendingSpace && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteByte(c)
+			i, pendingSpace = i+1, false
+		}
+	}
+	return b.String(), found
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
 // appendToFile appends contents to filePath.
 func appendToFile(filePath, contents string) error {
 	if bytesWritten, wasNew, err := fs.AppendToFile(filePath, contents); err != nil {
@@ -25,6 +25,27 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
+func TestStripComments(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+		found    bool
+	}{
+		{"CREATE TABLE foo (\n  id int\n)", "CREATE TABLE foo ( id int )", false},
+		{"CREATE TABLE foo (\n  -- comment\n  id int # another\n)", "CREATE TABLE foo ( id int )", true},
+		{"CREATE TABLE foo (id int /* inline */ NOT NULL)", "CREATE TABLE foo (id int NOT NULL)", true},
+		{"CREATE TABLE foo (id int) /*!50100 PARTITION BY HASH (id) */", "CREATE TABLE foo (id int) /*!50100 PARTITION BY HASH (id) */", false},
+		{"CREATE TABLE foo (a varchar(10) DEFAULT '-- not  a # comment')", "CREATE TABLE foo (a varchar(10) DEFAULT '-- not  a # comment')", false},
+		{"CREATE TABLE `foo--#` (a varchar(10) DEFAULT 'it\\'s /* x */')", "CREATE TABLE `foo--#` (a varchar(10) DEFAULT 'it\\'s /* x */')", false},
+		{"CREATE TABLE foo (a int DEFAULT 2--1)", "CREATE TABLE foo (a int DEFAULT 2--1)", false},
+	}
+	for _, c := range cases {
+		if actual, found := stripComments(c.input); actual != c.expected || found != c.found {
+			t.Errorf("Expected stripComments(%q) to return %q, %t; instead found %q, %t", c.input, c.expected, c.found, actual, found)
+		}
+	}
+}
+
 func TestIntegration(t *testing.T) {
 	images := tengo.SplitEnv("SKEEMA_TEST_IMAGES")
 	if len(images) == 0 {
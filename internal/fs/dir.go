@@ -1,6 +1,7 @@
 package fs
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/url"
@@ -70,6 +71,94 @@ func (logicalSchema *LogicalSchema) AddStatement(stmt *Statement) error {
 	}
 }
 
+// IgnoredKeys returns the ObjectKeys of all CREATE statements in the logical
+// schema which were annotated with a skeema:ignore comment. These objects
+// should still be executed in workspaces, so that other objects may reference
+// them, but they should be excluded from diff and push.
+func (logicalSchema *LogicalSchema) IgnoredKeys() (keys []tengo.ObjectKey) {
+	for key, stmt := range logicalSchema.Creates {
+		if stmt.Ignored {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// OrderedCreates returns the logical schema's CREATE statements in an order
+// which may be executed sequentially in an empty schema. This mirrors the
+// order used by tengo.SchemaDiff.ObjectDiffs: sequences first, followed by
+// tables, routines, triggers, views, and finally events. Tables are ordered so
+// that a foreign key's parent table precedes the child table, and views are
+// ordered so that any other views they reference precede them. Beyond these
+// constraints, statements of the same type are ordered by name. Circular
+// foreign key references cannot be satisfied by any order, so these still
+// require foreign_key_checks to be disabled when executing the statements.
+func (logicalSchema *LogicalSchema) OrderedCreates() []*Statement {
+	typeOrder := []tengo.ObjectType{
+		tengo.ObjectTypeSequence,
+		tengo.ObjectTypeTable,
+		tengo.ObjectTypeProc,
+		tengo.ObjectTypeFunc,
+		tengo.ObjectTypeTrigger,
+		tengo.ObjectTypeView,
+		tengo.ObjectTypeEvent,
+	}
+	byType := make(map[tengo.ObjectType][]*Statement)
+	for _, stmt := range logicalSchema.Creates {
+		byType[stmt.ObjectType] = append(byType[stmt.ObjectType], stmt)
+	}
+	result := make([]*Statement, 0, len(logicalSchema.Creates))
+	for _, objType := range typeOrder {
+		stmts := byType[objType]
+		sort.Slice(stmts, func(i, j int) bool {
+			return stmts[i].ObjectName < stmts[j].ObjectName
+		})
+		if objType != tengo.ObjectTypeTable && objType != tengo.ObjectTypeView {
+			result = append(result, stmts...)
+			continue
+		}
+
+		// Depth-first traversal, emitting each statement after its dependencies.
+		// Dependencies which are already being visited indicate a cycle, and are
+		// skipped.
+		byName := make(map[string]*Statement, len(stmts))
+		defined := make(map[string]bool, len(stmts))
+		for _, stmt := range stmts {
+			byName[stmt.ObjectName] = stmt
+			defined[stmt.ObjectName] = true
+		}
+		dependencies := func(stmt *Statement) (names []string) {
+			if objType == tengo.ObjectTypeView {
+				return stmt.viewReferences(defined)
+			}
+			for _, ref := range stmt.References() {
+				if ref.Schema == "" || ref.Schema == stmt.Schema() {
+					names = append(names, ref.Name)
+				}
+			}
+			return names
+		}
+		visited := make(map[string]bool, len(stmts))
+		var visit func(stmt *Statement)
+		visit = func(stmt *Statement) {
+			if visited[stmt.ObjectName] {
+				return
+			}
+			visited[stmt.ObjectName] = true
+			for _, name := range dependencies(stmt) {
+				if dep := byName[name]; dep != nil {
+					visit(dep)
+				}
+			}
+			result = append(result, stmt)
+		}
+		for _, stmt := range stmts {
+			visit(stmt)
+		}
+	}
+	return result
+}
+
 // ParseDir parses the specified directory, including all *.sql files in it,
 // its .skeema config file, and all .skeema config files of its parent
 // directory hierarchy. Evaluation of parent dirs stops once we hit either a
@@ -123,11 +212,61 @@ func (dir *Dir) RelPath() string {
 	return rel
 }
 
+// RepoBase returns the absolute path of the dir's containing repo, or of the
+// topmost-found .skeema file if no repo was found. If neither could be
+// determined, the dir's own Path is returned.
+func (dir *Dir) RepoBase() string {
+	if dir.repoBase == "" {
+		return dir.Path
+	}
+	return dir.repoBase
+}
+
 // Delete unlinks the directory and all files within.
 func (dir *Dir) Delete() error {
 	return os.RemoveAll(dir.Path)
 }
 
+// ValidateEnvironment returns an error if the environment name supplied on the
+// command-line does not correspond to a section of any option file applying to
+// dir or its subdirectories. Option files considered include global option
+// files (excluding .my.cnf, which never uses environment sections), the .skeema
+// files of dir and its parent directories, and the .skeema files of all
+// subdirectories of dir. When an environment is selected, options in its
+// section take precedence over sectionless options of the same file. No error
+// is returned for the default environment, which is permitted even if no option
+// file has a section for it.
+func (dir *Dir) ValidateEnvironment() error {
+	if !dir.Config.CLI.Command.HasArg("environment") || !dir.Config.Changed("environment") {
+		return nil
+	}
+	environment := dir.Config.Get("environment")
+	for _, source := range dir.Config.Sources() {
+		if f, ok := source.(*mybase.File); ok && !strings.HasSuffix(f.Path(), ".my.cnf") && f.HasSection(environment) {
+			return nil
+		}
+	}
+	// Check subdirectories, stopping the walk as soon as a match is found. Any
+	// problems reading or parsing subdirs are ignored here, since they will be
+	// reported when the subdirs are processed.
+	errFound := errors.New("environment found")
+	err := filepath.Walk(dir.Path, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || !fi.IsDir() {
+			return nil
+		} else if path != dir.Path && fi.Name()[0] == '.' {
+			return filepath.SkipDir
+		}
+		if f, err := parseOptionFile(path, dir.repoBase, dir.Config); err == nil && f.HasSection(environment) {
+			return errFound
+		}
+		return nil
+	})
+	if err != errFound {
+		return fmt.Errorf("Environment %q is not defined: no [%s] section found in any option file for %s or its subdirectories", environment, environment, dir)
+	}
+	return nil
+}
+
 // HasFile returns true if the specified filename exists in dir.
 func (dir *Dir) HasFile(name string) (bool, error) {
 	_, err := os.Lstat(filepath.Join(dir.Path, name))
@@ -149,9 +288,15 @@ func (dir *Dir) Subdirs() ([]*Dir, error) {
 	if err != nil {
 		return nil, err
 	}
+	layout := dir.Layout()
 	result := make([]*Dir, 0, len(fileInfos))
 	for _, fi := range fileInfos {
 		if fi.IsDir() && fi.Name()[0] != '.' {
+			// The views-dir is part of this dir, as are subdirs lacking a .skeema
+			// file when using split-by
+			if fi.Name() == layout.ViewsDir || (layout.SplitBy != "" && !hasOptionFile(filepath.Join(dir.Path, fi.Name()))) {
+				continue
+			}
 			sub := &Dir{
 				Path:     filepath.Join(dir.Path, fi.Name()),
 				Config:   dir.Config.Clone(),
@@ -263,7 +408,16 @@ func (dir *Dir) Instances() ([]*tengo.Instance, error) {
 	hosts, err := dir.Hostnames()
 	if err != nil {
 		return nil, err
-	} else if len(hosts) == 0 {
+	}
+	return dir.InstancesForHosts(hosts)
+}
+
+// InstancesForHosts returns a slice of tengo.Instance, one per supplied
+// hostname, using the dir's configuration for user, password, port, socket,
+// and other connection options. Hostnames may include a port, in host:port
+// format. An empty slice of hostnames results in a nil return value.
+func (dir *Dir) InstancesForHosts(hosts []string) ([]*tengo.Instance, error) {
+	if len(hosts) == 0 {
 		// If no host defined in this dir (meaning this dir's .skeema, as well as
 		// parent dirs' .skeema, global option files, or command-line) then nothing
 		// to do
@@ -287,13 +441,25 @@ func (dir *Dir) Instances() ([]*tengo.Instance, error) {
 	portIsntDefault := dir.Config.Changed("port")
 	socketValue := dir.Config.Get("socket")
 	socketWasSupplied := dir.Config.Supplied("socket")
+	sshBastion := dir.Config.Get("ssh")
+	proxy, err := util.SOCKS5ProxyForConfig(dir.Config)
+	if err != nil {
+		return nil, err
+	} else if proxy != nil && sshBastion != "" {
+		return nil, errors.New("Options ssh and socks5 cannot be used together")
+	}
+	network := "tcp"
+	if proxy != nil {
+		network = proxy.DSNNetwork()
+	}
 
 	// For each hostname, construct a DSN and use it to create an Instance
 	var instances []*tengo.Instance
 	for _, host := range hosts {
 		var dsn string
+		var tunnel *util.SSHTunnel
 		thisPortValue := portValue
-		if host == "localhost" && (socketWasSupplied || !portWasSupplied) {
+		if host == "localhost" && sshBastion == "" && proxy == nil && (socketWasSupplied || !portWasSupplied) {
 			dsn = fmt.Sprintf("%s@unix(%s)/?%s", userAndPass, socketValue, params)
 		} else {
 			splitHost, splitPort, err := tengo.SplitHostOptionalPort(host)
@@ -307,15 +473,25 @@ func (dir *Dir) Instances() ([]*tengo.Instance, error) {
 				host = splitHost
 				thisPortValue = splitPort
 			}
-			dsn = fmt.Sprintf("%s@tcp(%s:%d)/?%s", userAndPass, host, thisPortValue, params)
+			addr := fmt.Sprintf("%s:%d", host, thisPortValue)
+			if sshBastion != "" {
+				if tunnel, err = util.OpenSSHTunnel(sshBastion, dir.Config.Get("ssh-key"), addr); err != nil {
+					return nil, err
+				}
+				addr = tunnel.LocalAddr
+			}
+			dsn = fmt.Sprintf("%s@%s(%s)/?%s", userAndPass, network, addr, params)
 		}
 		instance, err := util.NewInstance("mysql", dsn)
 		if err != nil {
-			if dir.Config.Changed("password") {
-				safeUserPass := fmt.Sprintf("%s:*****", dir.Config.Get("user"))
-				dsn = strings.Replace(dsn, userAndPass, safeUserPass, 1)
-			}
-			return nil, fmt.Errorf("Invalid connection information for %s (DSN=%s): %s", dir, dsn, err)
+			err = util.RedactError(dir.Config, err)
+			return nil, fmt.Errorf("Invalid connection information for %s (DSN=%s): %s", dir, util.RedactDSN(dsn), err)
+		}
+
+		// When tunneling, the instance should still be identified by its real
+		// address in output and option files; only the DSN uses the tunnel
+		if tunnel != nil {
+			instance.Host, instance.Port = host, thisPortValue
 		}
 		instances = append(instances, instance)
 	}
@@ -348,12 +524,33 @@ func (dir *Dir) FirstInstance() (*tengo.Instance, error) {
 
 // ValidateInstance confirms the supplied instance is (or has been) reachable,
 // and applies any dir-configured Flavor override if the instance's flavor
-// cannot be auto-detected.
+// cannot be auto-detected. Transient connection failures are retried as
+// configured by the connect-retries and connect-retry-delay options.
 // An error will be returned if the instance is not reachable. Otherwise, the
 // return value will be nil, but any flavor mismatches/problems will be logged.
 func (dir *Dir) ValidateInstance(instance *tengo.Instance) error {
-	ok, err := instance.Valid()
+	policy, err := util.ConnectRetryPolicyForConfig(dir.Config)
+	if err != nil {
+		return err
+	}
+	var ok bool
+	err = policy.Do(func() (err error) {
+		ok, err = instance.Valid()
+		return err
+	})
+	err = util.RedactError(dir.Config, err)
 	if !ok {
+		if bastion := dir.Config.Get("ssh"); bastion != "" && err != nil {
+			return fmt.Errorf("Unable to connect to database %s through SSH tunnel via %s: %s", instance, bastion, err)
+		}
+		if proxyAddr := dir.Config.Get("socks5"); proxyAddr != "" && err != nil {
+			// Failures of the proxy itself already identify the proxy in their message
+			var proxyErr *util.SOCKS5ProxyError
+			if errors.As(err, &proxyErr) {
+				return fmt.Errorf("Unable to connect to database %s: %s", instance, err)
+			}
+			return fmt.Errorf("Unable to connect to database %s through SOCKS5 proxy %s: %s", instance, proxyAddr, err)
+		}
 		return err
 	}
 
@@ -377,7 +574,8 @@ func (dir *Dir) ValidateInstance(instance *tengo.Instance) error {
 // to, in cases where no schema name is explicitly specified in SQL statements.
 // If the ignore-schema option is set, it will filter out matching results from
 // the returned slice.
-// An instance must be supplied since the value may be instance-specific.
+// An instance must be supplied since the value may be instance-specific. If
+// instance is nil, an error is returned for values requiring an instance.
 func (dir *Dir) SchemaNames(instance *tengo.Instance) (names []string, err error) {
 	// If no schema defined in this dir (meaning this dir's .skeema, as well as
 	// parent dirs' .skeema, global option files, or command-line) for the current
@@ -386,8 +584,11 @@ func (dir *Dir) SchemaNames(instance *tengo.Instance) (names []string, err error
 		return nil, nil
 	}
 
-	schemaValue := dir.Config.Get("schema")                        // Get strips quotes (including backticks) from fully quoted-wrapped values
-	rawSchemaValue := dir.Config.GetRaw("schema")                  // GetRaw does not strip quotes
+	schemaValue := dir.Config.Get("schema")       // Get strips quotes (including backticks) from fully quoted-wrapped values
+	rawSchemaValue := dir.Config.GetRaw("schema") // GetRaw does not strip quotes
+	if instance == nil && ((rawSchemaValue != schemaValue && rawSchemaValue[0] == '`') || schemaValue == "*" || looksLikeRegex(schemaValue)) {
+		return nil, fmt.Errorf("Option schema=%s requires a database instance", rawSchemaValue)
+	}
 	if rawSchemaValue != schemaValue && rawSchemaValue[0] == '`' { // no need to check len, the Changed check above already tells us schema != ""
 		variables := map[string]string{
 			"HOST":        instance.Host,
@@ -403,7 +604,7 @@ func (dir *Dir) SchemaNames(instance *tengo.Instance) (names []string, err error
 			names, err = shellOut.RunCaptureSplit()
 		}
 		if err != nil {
-			return nil, err
+			return nil, util.RedactError(dir.Config, err)
 		}
 	} else if schemaValue == "*" || looksLikeRegex(schemaValue) {
 		// This automatically already filters out information_schema, performance_schema, sys, test, mysql
@@ -487,6 +688,308 @@ func (dir *Dir) HasSchema() bool {
 	return false
 }
 
+// ProjectSchemaNames returns the names of all schemas mapped to by any dir in
+// the same project as dir. The project's root is the topmost dir containing a
+// .skeema file, without climbing above dir's RepoBase; the project consists of
+// that root and its subdirs, up to 5 levels deep. Only the current
+// environment's configuration is considered. Some schema option values can only
+// be resolved by querying a database instance, such as a wildcard, regular
+// expression, or shell-out; if any dir uses such a value, the returned bool is
+// false, indicating that the list is incomplete. Dirs which cannot be read or
+// parsed are silently skipped.
+func (dir *Dir) ProjectSchemaNames() (names []string, complete bool) {
+	rootPath := dir.Path
+	if dir.Path != dir.RepoBase() {
+		for _, ancestor := range ancestorPaths(dir.Path)[1:] {
+			if hasOptionFile(ancestor) {
+				rootPath = ancestor
+			}
+			if ancestor == dir.RepoBase() {
+				break
+			}
+		}
+	}
+	root := dir
+	if rootPath != dir.Path {
+		root = &Dir{
+			Path:     rootPath,
+			Config:   dir.Config.Clone(),
+			repoBase: dir.repoBase,
+		}
+		root.parseContents()
+	}
+	complete = true
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	var walk func(d *Dir, depth int)
+	walk = func(d *Dir, depth int) {
+		if d.ParseError != nil {
+			return
+		}
+		if d.OptionFile != nil {
+			if val, _ := d.OptionFile.OptionValue("schema"); val != "" {
+				schemaNames, err := d.SchemaNames(nil)
+				if err != nil {
+					complete = false
+				}
+				for _, name := range schemaNames {
+					add(name)
+				}
+			}
+		}
+		for _, logicalSchema := range d.LogicalSchemas {
+			if logicalSchema.Name != "" {
+				add(logicalSchema.Name)
+			}
+		}
+		if depth >= 5 {
+			return
+		}
+		subdirs, _ := d.Subdirs()
+		for _, sub := range subdirs {
+			walk(sub, depth+1)
+		}
+	}
+	walk(root, 0)
+	return names, complete
+}
+
+// Layout describes how a schema dir's *.sql files are distributed across its
+// subdirs, as controlled by the split-by and views-dir options.
+type Layout struct {
+	SplitBy  string // "firstletter" to use single-character subdirs, or blank
+	ViewsDir string // name of subdir containing views, or blank
+}
+
+// Layout returns the dir's current Layout. If the dir does not map to a
+// schema, a zero value is returned.
+func (dir *Dir) Layout() Layout {
+	return Layout{
+		SplitBy:  dir.SplitBy(),
+		ViewsDir: dir.ViewsDir(),
+	}
+}
+
+// OptionFileLayout returns the Layout expressed by the dir's own option file,
+// disregarding any overrides on the command-line or in parent dirs.
+func (dir *Dir) OptionFileLayout() (layout Layout) {
+	if dir.OptionFile != nil {
+		layout.SplitBy, _ = dir.OptionFile.OptionValue("split-by")
+		layout.SplitBy = strings.ToLower(layout.SplitBy)
+		layout.ViewsDir, _ = dir.OptionFile.OptionValue("views-dir")
+	}
+	return layout
+}
+
+// SplitBy returns the normalized value of the split-by option, if this dir
+// maps to a schema and distributes its *.sql files into subdirs. Otherwise, a
+// blank string is returned.
+func (dir *Dir) SplitBy() string {
+	if !dir.HasSchema() {
+		return ""
+	}
+	return strings.ToLower(dir.Config.Get("split-by"))
+}
+
+// ViewsDir returns the value of the views-dir option, if this dir maps to a
+// schema and stores its views in a dedicated subdir. Otherwise, a blank string
+// is returned.
+func (dir *Dir) ViewsDir() string {
+	if !dir.HasSchema() {
+		return ""
+	}
+	return dir.Config.Get("views-dir")
+}
+
+// PathForObject returns a string containing a path to use for a new SQLFile
+// representing the supplied object. If the views-dir or split-by options are
+// in use, this path may be in a subdir of dir.
+func (dir *Dir) PathForObject(key tengo.ObjectKey) string {
+	fileName := filepath.Base(PathForObject(dir.Path, key.Name))
+	return filepath.Join(dir.pathForFile(fileName, key.Type == tengo.ObjectTypeView), fileName)
+}
+
+// pathForFile returns the path of the directory that should contain a *.sql
+// file of the supplied name, based on the dir's Layout.
+func (dir *Dir) pathForFile(fileName string, isView bool) string {
+	layout := dir.Layout()
+	if isView && layout.ViewsDir != "" {
+		return filepath.Join(dir.Path, layout.ViewsDir)
+	} else if layout.SplitBy != "" {
+		return filepath.Join(dir.Path, splitSubdirName(fileName))
+	}
+	return dir.Path
+}
+
+// EnsureDirForFile creates the directory that will contain filePath, if it
+// does not already exist. If this directory is the dir's views-dir, a .skeema
+// marker file is also created in it.
+func (dir *Dir) EnsureDirForFile(filePath string) error {
+	fileDir := filepath.Dir(filePath)
+	if err := os.MkdirAll(fileDir, 0777); err != nil {
+		return err
+	}
+	if viewsDir := dir.ViewsDir(); viewsDir != "" && fileDir == filepath.Join(dir.Path, viewsDir) && !hasOptionFile(fileDir) {
+		markerPath := filepath.Join(fileDir, ".skeema")
+		if err := ioutil.WriteFile(markerPath, []byte(viewsDirMarker), 0666); err != nil {
+			return err
+		}
+		log.Infof("Created %s", markerPath)
+	}
+	return nil
+}
+
+// viewsDirMarker is the contents of the .skeema file created in a views-dir.
+// This file does not set any options, so the views-dir is never interpreted
+// as a separate schema dir.
+const viewsDirMarker = "# This directory contains views for the schema of its parent directory.\n"
+
+// Relayout moves the dir's *.sql files as needed to match its current Layout,
+// and then re-parses them if any were moved. The previous Layout must be
+// supplied, since it determines which subdirs previously contained *.sql files
+// belonging to dir. Symlinks are left in place.
+func (dir *Dir) Relayout(from Layout) error {
+	files, err := sqlFiles(dir.Path, dir.repoBase)
+	if err != nil {
+		return err
+	}
+	if from.SplitBy != "" {
+		subdirFiles, err := dir.splitSubdirFiles()
+		if err != nil {
+			return err
+		}
+		files = append(files, subdirFiles...)
+	}
+	fromViewsPath := filepath.Join(dir.Path, from.ViewsDir)
+	if from.ViewsDir != "" {
+		if viewFiles, err := sqlFiles(fromViewsPath, dir.repoBase); err == nil {
+			files = append(files, viewFiles...)
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	var moved int
+	for _, sf := range files {
+		destDir := dir.pathForFile(sf.FileName, isViewFile(sf))
+		if destDir == sf.Dir {
+			continue
+		}
+		if fi, err := os.Lstat(sf.Path()); err != nil {
+			return err
+		} else if fi.Mode()&os.ModeSymlink == os.ModeSymlink {
+			continue
+		}
+		dest := filepath.Join(destDir, sf.FileName)
+		if _, err := os.Lstat(dest); err == nil {
+			return fmt.Errorf("Unable to move %s: destination %s already exists", sf.Path(), dest)
+		}
+		if err := dir.EnsureDirForFile(dest); err != nil {
+			return err
+		}
+		if err := os.Rename(sf.Path(), dest); err != nil {
+			return err
+		}
+		log.Infof("Moved %s to %s", sf.Path(), dest)
+		moved++
+		if sf.Dir != dir.Path && sf.Dir != fromViewsPath {
+			os.Remove(sf.Dir) // only succeeds if the former split subdir is now empty
+		}
+	}
+
+	// If the dir previously used a different views-dir, remove it if nothing but
+	// its marker file remains
+	if from.ViewsDir != "" && from.ViewsDir != dir.ViewsDir() {
+		markerPath := filepath.Join(fromViewsPath, ".skeema")
+		if contents, err := ioutil.ReadFile(markerPath); err == nil && string(contents) == viewsDirMarker {
+			if remaining, err := ioutil.ReadDir(fromViewsPath); err == nil && len(remaining) == 1 {
+				os.Remove(markerPath)
+				os.Remove(fromViewsPath)
+			}
+		}
+	}
+
+	if moved > 0 {
+		dir.parseSQLFiles()
+	}
+	return dir.ParseError
+}
+
+// isViewFile returns true if the supplied file exclusively contains CREATE
+// VIEW statements, and at least one such statement.
+func isViewFile(sf SQLFile) bool {
+	tokenizedFile, err := sf.Tokenize()
+	if err != nil {
+		return false
+	}
+	var foundView bool
+	for _, stmt := range tokenizedFile.Statements {
+		if stmt.Type == StatementTypeCreate && stmt.ObjectType == tengo.ObjectTypeView {
+			foundView = true
+		} else if stmt.Type != StatementTypeNoop && stmt.Type != StatementTypeCommand {
+			return false
+		}
+	}
+	return foundView
+}
+
+// splitSubdirFiles returns a slice of SQLFile for all *.sql files found in
+// subdirs of dir lacking a .skeema file. It is an error to use the split-by
+// option if any subdir with a single-character name has its own .skeema file,
+// since this would prevent that subdir from being used to store *.sql files
+// for this dir.
+func (dir *Dir) splitSubdirFiles() ([]SQLFile, error) {
+	fileInfos, err := ioutil.ReadDir(dir.Path)
+	if err != nil {
+		return nil, err
+	}
+	var result []SQLFile
+	viewsDir := dir.ViewsDir()
+	for _, fi := range fileInfos {
+		if !fi.IsDir() || fi.Name()[0] == '.' || fi.Name() == viewsDir {
+			continue
+		}
+		subPath := filepath.Join(dir.Path, fi.Name())
+		if hasOptionFile(subPath) {
+			if len(fi.Name()) == 1 {
+				return nil, fmt.Errorf("Option split-by cannot be used in %s: subdir %s has its own .skeema file", dir.Path, fi.Name())
+			}
+			continue
+		}
+		files, err := sqlFiles(subPath, dir.repoBase)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, files...)
+	}
+	return result, nil
+}
+
+// splitSubdirName returns the name of the subdir used for the supplied *.sql
+// file name when using split-by=firstletter: the lowercased first letter or
+// digit of the name, or an underscore for any other character.
+func splitSubdirName(fileName string) string {
+	first := fileName[0]
+	if first >= 'A' && first <= 'Z' {
+		return string(first + ('a' - 'A'))
+	} else if (first >= 'a' && first <= 'z') || (first >= '0' && first <= '9') {
+		return string(first)
+	}
+	return "_"
+}
+
+// hasOptionFile returns true if the directory at dirPath contains a .skeema
+// file.
+func hasOptionFile(dirPath string) bool {
+	_, err := os.Lstat(filepath.Join(dirPath, ".skeema"))
+	return err == nil
+}
+
 var reUseStatement = regexp.MustCompile(`(?i)\bUSE\b`)
 
 // NamedSchemaStatements returns a slice of Statements in the dir that are
@@ -555,11 +1058,20 @@ func (dir *Dir) InstanceDefaultParams() (string, error) {
 		return "", err
 	}
 
-	// Prefer TLS, but not during integration testing
+	// Prefer TLS, but not during integration testing, unless TLS options were
+	// explicitly configured
 	defaultTLS := "preferred"
 	if dir.Config.IsTest {
 		defaultTLS = "false"
 	}
+	tlsOpts, err := util.TLSOptionsForConfig(dir.Config)
+	if err != nil {
+		return "", err
+	} else if tlsOpts.Configured() {
+		if defaultTLS, err = tlsOpts.DSNParam(); err != nil {
+			return "", err
+		}
+	}
 
 	v := url.Values{}
 
@@ -573,6 +1085,8 @@ func (dir *Dir) InstanceDefaultParams() (string, error) {
 	for name, value := range options {
 		if banned[strings.ToLower(name)] {
 			return "", fmt.Errorf("connect-options is not allowed to contain %s", name)
+		} else if tlsOpts.Configured() && strings.EqualFold(name, "tls") {
+			return "", fmt.Errorf("connect-options cannot contain %s when using ssl-mode, ssl-ca, ssl-cert, or ssl-key", name)
 		}
 		v.Set(name, value)
 	}
@@ -598,11 +1112,42 @@ func (dir *Dir) parseContents() {
 		}
 		dir.Config.AddSource(dir.OptionFile)
 	}
+	dir.parseSQLFiles()
+}
 
-	// Tokenize and parse any *.sql files
+// parseSQLFiles tokenizes and parses the dir's *.sql files, including those in
+// split subdirs or the views-dir if these options are in use, populating
+// fields of dir accordingly. This method modifies dir in-place. Any fatal
+// error will populate dir.ParseError.
+func (dir *Dir) parseSQLFiles() {
+	dir.SQLFiles, dir.LogicalSchemas, dir.IgnoredStatements = nil, nil, nil
 	if dir.SQLFiles, dir.ParseError = sqlFiles(dir.Path, dir.repoBase); dir.ParseError != nil {
 		return
 	}
+	if dir.HasSchema() {
+		if _, dir.ParseError = dir.Config.GetEnum("split-by", "", "firstletter"); dir.ParseError != nil {
+			return
+		}
+		if viewsDir := dir.ViewsDir(); strings.ContainsAny(viewsDir, "/\\") || viewsDir == "." || viewsDir == ".." {
+			dir.ParseError = fmt.Errorf("Option views-dir must be the name of a subdirectory, not a path: %s", viewsDir)
+			return
+		}
+	}
+	if dir.SplitBy() != "" {
+		var subdirFiles []SQLFile
+		if subdirFiles, dir.ParseError = dir.splitSubdirFiles(); dir.ParseError != nil {
+			return
+		}
+		dir.SQLFiles = append(dir.SQLFiles, subdirFiles...)
+	}
+	if viewsDir := dir.ViewsDir(); viewsDir != "" {
+		viewFiles, err := sqlFiles(filepath.Join(dir.Path, viewsDir), dir.repoBase)
+		if err != nil && !os.IsNotExist(err) {
+			dir.ParseError = err
+			return
+		}
+		dir.SQLFiles = append(dir.SQLFiles, viewFiles...)
+	}
 	logicalSchemasByName := make(map[string]*LogicalSchema)
 	for _, sf := range dir.SQLFiles {
 		tokenizedFile, err := sf.Tokenize()
@@ -1,6 +1,7 @@
 package fs
 
 import (
+	"io/ioutil"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -57,6 +58,33 @@ func TestParseDir(t *testing.T) {
 	}
 }
 
+func TestLogicalSchemaOrderedCreates(t *testing.T) {
+	logicalSchema := &LogicalSchema{Creates: make(map[tengo.ObjectKey]*Statement)}
+	add := func(objectType tengo.ObjectType, name, text string) {
+		stmt := &Statement{Text: text + ";\n", delimiter: ";", Type: StatementTypeCreate, ObjectType: objectType, ObjectName: name}
+		logicalSchema.Creates[stmt.ObjectKey()] = stmt
+	}
+	// a and b reference each other, and c references itself as well as a table
+	// in another schema
+	add(tengo.ObjectTypeTable, "a", "CREATE TABLE a (id int, b_id int, FOREIGN KEY (b_id) REFERENCES b (id))")
+	add(tengo.ObjectTypeTable, "b", "CREATE TABLE b (id int, a_id int, FOREIGN KEY (a_id) REFERENCES a (id))")
+	add(tengo.ObjectTypeTable, "c", "CREATE TABLE c (id int, parent_id int, d_id int, FOREIGN KEY (parent_id) REFERENCES c (id), FOREIGN KEY (d_id) REFERENCES other.d (id))")
+	add(tengo.ObjectTypeTable, "d", "CREATE TABLE d (id int, c_id int, FOREIGN KEY (c_id) REFERENCES c (id))")
+	add(tengo.ObjectTypeEvent, "e", "CREATE EVENT e ON SCHEDULE EVERY 1 DAY DO DELETE FROM v1")
+	add(tengo.ObjectTypeView, "v1", "CREATE VIEW v1 AS SELECT * FROM v2 JOIN a")
+	add(tengo.ObjectTypeView, "v2", "CREATE VIEW v2 AS SELECT v2.id FROM d AS v2")
+	add(tengo.ObjectTypeSequence, "s", "CREATE SEQUENCE s")
+
+	var actual []string
+	for _, stmt := range logicalSchema.OrderedCreates() {
+		actual = append(actual, stmt.ObjectName)
+	}
+	expected := []string{"s", "b", "a", "c", "d", "v2", "v1", "e"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected result from OrderedCreates: expected %v, found %v", expected, actual)
+	}
+}
+
 func TestParseDirErrors(t *testing.T) {
 	// Confirm error cases: nonexistent dir; non-dir file; dir with *.sql files
 	// creating same table multiple times
@@ -130,6 +158,71 @@ func TestParseDirSymlinks(t *testing.T) {
 	}
 }
 
+// TestParseDirIncludes tests parsing of option files using include directives.
+func TestParseDirIncludes(t *testing.T) {
+	// Simple include: values from the included file apply, including in named
+	// sections, but a value defined after the include directive overrides it
+	dir := getDir(t, "testdata/include/simple")
+	if port := dir.Config.Get("port"); port != "3308" {
+		t.Errorf("Expected port to be overridden to 3308 after include, instead found %s", port)
+	}
+	if flavor := dir.Config.Get("flavor"); flavor != "mysql:8.0" {
+		t.Errorf("Expected flavor mysql:8.0 from included file, instead found %s", flavor)
+	}
+	if host := dir.Config.Get("host"); host != "10.0.0.1" {
+		t.Errorf("Expected host 10.0.0.1 from included file's environment section, instead found %s", host)
+	}
+
+	// Command-line options still take precedence over included values
+	dir, err := ParseDir("testdata/include/simple", getValidConfig(t, "--flavor=mariadb:10.5", "--host=db.example.com"))
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseDir: %v", err)
+	}
+	if flavor, host := dir.Config.Get("flavor"), dir.Config.Get("host"); flavor != "mariadb:10.5" || host != "db.example.com" {
+		t.Errorf("Expected command-line options to override included values, instead found flavor=%s host=%s", flavor, host)
+	}
+
+	// Nested include, with relative paths resolved against each including
+	// file's directory
+	dir = getDir(t, "testdata/include/nested")
+	expected := map[string]string{
+		"schema":                "foo",
+		"default-character-set": "utf8mb4",
+		"port":                  "3307",
+		"host":                  "10.0.0.1",
+	}
+	for name, value := range expected {
+		if actual := dir.Config.Get(name); actual != value {
+			t.Errorf("Expected option %s to have value %q from nested include, instead found %q", name, value, actual)
+		}
+	}
+
+	// Include cycles result in an error
+	if _, err := ParseDir("testdata/include/cycle", getValidConfig(t)); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("Expected include cycle error from ParseDir, instead found %v", err)
+	}
+}
+
+func TestParseDirIgnoreAnnotations(t *testing.T) {
+	dir := getDir(t, "testdata/ignoreannotations")
+	if len(dir.LogicalSchemas) != 1 || len(dir.LogicalSchemas[0].Creates) != 6 {
+		t.Fatalf("Unexpected logical schemas in testdata/ignoreannotations: %+v", dir.LogicalSchemas)
+	}
+	expected := map[tengo.ObjectKey]bool{
+		{Type: tengo.ObjectTypeTable, Name: "legacy_users"}:   true,
+		{Type: tengo.ObjectTypeTable, Name: "external_audit"}: true,
+		{Type: tengo.ObjectTypeView, Name: "legacy_user_ids"}: true,
+	}
+	for key, stmt := range dir.LogicalSchemas[0].Creates {
+		if stmt.Ignored != expected[key] {
+			t.Errorf("Expected Ignored=%t for %s, instead found %t", expected[key], key, stmt.Ignored)
+		}
+	}
+	if keys := dir.LogicalSchemas[0].IgnoredKeys(); len(keys) != len(expected) {
+		t.Errorf("Expected IgnoredKeys to return %d keys, instead found %v", len(expected), keys)
+	}
+}
+
 // TestParseDirNamedSchemas tests parsing of dirs that have explicit schema
 // names in the *.sql files, in various combinations.
 func TestParseDirNamedSchemas(t *testing.T) {
@@ -299,6 +392,224 @@ func TestDirSubdirs(t *testing.T) {
 	}
 }
 
+func TestDirSplitBy(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "skeema-splitby-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	MakeTestDirectory(t, filepath.Join(tmpDir, "a"))
+	MakeTestDirectory(t, filepath.Join(tmpDir, "b"))
+	MakeTestDirectory(t, filepath.Join(tmpDir, "zz"))
+	WriteTestFile(t, filepath.Join(tmpDir, ".skeema"), "schema=foo\nsplit-by=firstletter\n")
+	WriteTestFile(t, filepath.Join(tmpDir, "a", "apples.sql"), "CREATE TABLE apples (id int);\n")
+	WriteTestFile(t, filepath.Join(tmpDir, "b", "Bananas.sql"), "CREATE TABLE Bananas (id int);\n")
+	WriteTestFile(t, filepath.Join(tmpDir, "cherries.sql"), "CREATE TABLE cherries (id int);\n")
+	WriteTestFile(t, filepath.Join(tmpDir, "zz", ".skeema"), "schema=bar\n")
+	WriteTestFile(t, filepath.Join(tmpDir, "zz", "dates.sql"), "CREATE TABLE dates (id int);\n")
+
+	dir := getDir(t, tmpDir)
+	if dir.SplitBy() != "firstletter" {
+		t.Errorf("Unexpected return from SplitBy(): %q", dir.SplitBy())
+	}
+	if len(dir.LogicalSchemas) != 1 || len(dir.LogicalSchemas[0].Creates) != 3 {
+		t.Fatalf("Unexpected logical schema contents: %+v", dir.LogicalSchemas)
+	}
+	if subs, err := dir.Subdirs(); err != nil || len(subs) != 1 || subs[0].BaseName() != "zz" {
+		t.Errorf("Unexpected return from Subdirs(): %v, %v", subs, err)
+	}
+	expectPaths := map[string]string{
+		"apples":  filepath.Join(tmpDir, "a", "apples.sql"),
+		"Bananas": filepath.Join(tmpDir, "b", "Bananas.sql"),
+		"_misc":   filepath.Join(tmpDir, "_", "_misc.sql"),
+		"9lives":  filepath.Join(tmpDir, "9", "9lives.sql"),
+	}
+	for name, expected := range expectPaths {
+		key := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: name}
+		if actual := dir.PathForObject(key); actual != expected {
+			t.Errorf("Expected PathForObject(%s) to return %s, instead found %s", key, expected, actual)
+		}
+	}
+
+	// Relayout should move the top-level file into a subdir, and be a no-op on
+	// subsequent calls
+	if err := dir.Relayout(Layout{SplitBy: "firstletter"}); err != nil {
+		t.Fatalf("Unexpected error from Relayout: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "c", "cherries.sql")); err != nil {
+		t.Errorf("Expected cherries.sql to be moved to subdir c, but stat returned %v", err)
+	}
+	if len(dir.LogicalSchemas[0].Creates) != 3 {
+		t.Errorf("Expected 3 CREATEs after Relayout, instead found %d", len(dir.LogicalSchemas[0].Creates))
+	}
+	if err := dir.Relayout(Layout{SplitBy: "firstletter"}); err != nil {
+		t.Fatalf("Unexpected error from Relayout: %v", err)
+	}
+
+	// Overriding split-by to be blank should move all files back to the top
+	// level, removing the emptied subdirs, but leaving dirs with a .skeema file
+	dir, err = ParseDir(tmpDir, getValidConfig(t, "--split-by=''"))
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseDir: %v", err)
+	}
+	if err := dir.Relayout(Layout{SplitBy: "firstletter"}); err != nil {
+		t.Fatalf("Unexpected error from Relayout: %v", err)
+	}
+	for _, name := range []string{"apples.sql", "Bananas.sql", "cherries.sql", "zz"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); err != nil {
+			t.Errorf("Expected %s to exist at top level, but stat returned %v", name, err)
+		}
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); err == nil {
+			t.Errorf("Expected subdir %s to be removed, but it still exists", name)
+		}
+	}
+	if len(dir.LogicalSchemas) != 1 || len(dir.LogicalSchemas[0].Creates) != 3 {
+		t.Errorf("Unexpected logical schema contents after unsplitting: %+v", dir.LogicalSchemas)
+	}
+
+	// Single-character subdir with its own .skeema file is an error, as is an
+	// invalid value for split-by
+	MakeTestDirectory(t, filepath.Join(tmpDir, "x"))
+	WriteTestFile(t, filepath.Join(tmpDir, "x", ".skeema"), "schema=baz\n")
+	if _, err := ParseDir(tmpDir, getValidConfig(t)); err == nil {
+		t.Error("Expected error from ParseDir with single-char subdir containing .skeema, but err was nil")
+	}
+	RemoveTestDirectory(t, filepath.Join(tmpDir, "x"))
+	if _, err := ParseDir(tmpDir, getValidConfig(t, "--split-by=lastletter")); err == nil {
+		t.Error("Expected error from ParseDir with invalid split-by value, but err was nil")
+	}
+}
+
+func TestDirViewsDir(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "skeema-viewsdir-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	WriteTestFile(t, filepath.Join(tmpDir, ".skeema"), "schema=foo\nviews-dir=_views\n")
+	WriteTestFile(t, filepath.Join(tmpDir, "apples.sql"), "CREATE TABLE apples (id int);\n")
+	WriteTestFile(t, filepath.Join(tmpDir, "red_apples.sql"), "CREATE VIEW red_apples AS SELECT * FROM apples;\n")
+	MakeTestDirectory(t, filepath.Join(tmpDir, "other"))
+	WriteTestFile(t, filepath.Join(tmpDir, "other", ".skeema"), "schema=bar\n")
+
+	dir := getDir(t, tmpDir)
+	if len(dir.LogicalSchemas) != 1 || len(dir.LogicalSchemas[0].Creates) != 2 {
+		t.Fatalf("Unexpected logical schema contents: %+v", dir.LogicalSchemas)
+	}
+	viewKey := tengo.ObjectKey{Type: tengo.ObjectTypeView, Name: "green_apples"}
+	if expected, actual := filepath.Join(tmpDir, "_views", "green_apples.sql"), dir.PathForObject(viewKey); actual != expected {
+		t.Errorf("Expected PathForObject(%s) to return %s, instead found %s", viewKey, expected, actual)
+	}
+	tableKey := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "bananas"}
+	if expected, actual := filepath.Join(tmpDir, "bananas.sql"), dir.PathForObject(tableKey); actual != expected {
+		t.Errorf("Expected PathForObject(%s) to return %s, instead found %s", tableKey, expected, actual)
+	}
+
+	// Relayout should move the view's file into the views-dir, creating the dir
+	// and its marker file
+	if err := dir.Relayout(Layout{}); err != nil {
+		t.Fatalf("Unexpected error from Relayout: %v", err)
+	}
+	if contents := ReadTestFile(t, filepath.Join(tmpDir, "_views", ".skeema")); contents != viewsDirMarker {
+		t.Errorf("Unexpected contents of views-dir marker file: %q", contents)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "_views", "red_apples.sql")); err != nil {
+		t.Errorf("Expected red_apples.sql to be moved to views-dir, but stat returned %v", err)
+	}
+	if len(dir.LogicalSchemas[0].Creates) != 2 {
+		t.Errorf("Expected 2 CREATEs after Relayout, instead found %d", len(dir.LogicalSchemas[0].Creates))
+	}
+	if subs, err := dir.Subdirs(); err != nil || len(subs) != 1 || subs[0].BaseName() != "other" {
+		t.Errorf("Unexpected return from Subdirs(): %v, %v", subs, err)
+	}
+	viewsDir := getDir(t, filepath.Join(tmpDir, "_views"))
+	if viewsDir.HasSchema() {
+		t.Error("Expected views-dir to not be treated as a separate schema dir, but HasSchema returned true")
+	}
+
+	// Overriding views-dir to be blank should move the view back, and remove
+	// the views-dir
+	dir, err = ParseDir(tmpDir, getValidConfig(t, "--views-dir=''"))
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseDir: %v", err)
+	}
+	if err := dir.Relayout(Layout{ViewsDir: "_views"}); err != nil {
+		t.Fatalf("Unexpected error from Relayout: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "red_apples.sql")); err != nil {
+		t.Errorf("Expected red_apples.sql to be moved to top level, but stat returned %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "_views")); err == nil {
+		t.Error("Expected views-dir to be removed, but it still exists")
+	}
+
+	// views-dir must not be a path
+	if _, err := ParseDir(tmpDir, getValidConfig(t, "--views-dir=../foo")); err == nil {
+		t.Error("Expected error from ParseDir with views-dir set to a path, but err was nil")
+	}
+}
+
+func TestDirValidateEnvironment(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "skeema-environment-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	WriteTestFile(t, filepath.Join(tmpDir, ".skeema"), "host=db.example.com\nport=3306\n\n[staging]\nport=3307\n")
+	MakeTestDirectory(t, filepath.Join(tmpDir, "sub"))
+	WriteTestFile(t, filepath.Join(tmpDir, "sub", ".skeema"), "schema=foo\n\n[development]\nhost=localhost\n")
+
+	// Options in the selected environment's section override sectionless ones;
+	// the default environment is permitted even without a section of its own
+	cases := map[string]string{
+		"":           "3306",
+		"staging":    "3307",
+		"production": "3306",
+	}
+	for environment, expectPort := range cases {
+		dir, err := ParseDir(tmpDir, getValidConfig(t, environment))
+		if err != nil {
+			t.Fatalf("Unexpected error from ParseDir: %v", err)
+		}
+		if err := dir.ValidateEnvironment(); err != nil {
+			t.Errorf("Unexpected error from ValidateEnvironment with environment %q: %v", environment, err)
+		}
+		if port := dir.Config.Get("port"); port != expectPort {
+			t.Errorf("With environment %q, expected port %s, instead found %s", environment, expectPort, port)
+		}
+	}
+
+	// An environment defined only in a subdir's .skeema file is valid from the
+	// parent dir, as well as from the subdir itself
+	for _, dirPath := range []string{tmpDir, filepath.Join(tmpDir, "sub")} {
+		dir, err := ParseDir(dirPath, getValidConfig(t, "development"))
+		if err != nil {
+			t.Fatalf("Unexpected error from ParseDir: %v", err)
+		}
+		if err := dir.ValidateEnvironment(); err != nil {
+			t.Errorf("Unexpected error from ValidateEnvironment for %s: %v", dirPath, err)
+		}
+	}
+	dir, err := ParseDir(filepath.Join(tmpDir, "sub"), getValidConfig(t, "development"))
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseDir: %v", err)
+	}
+	if host := dir.Config.Get("host"); host != "localhost" {
+		t.Errorf("Expected host to be overridden by subdir's [development] section, instead found %s", host)
+	}
+
+	// An environment not defined anywhere is an error
+	dir, err = ParseDir(tmpDir, getValidConfig(t, "bogus"))
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseDir: %v", err)
+	}
+	if err := dir.ValidateEnvironment(); err == nil || !strings.Contains(err.Error(), "bogus") {
+		t.Errorf("Expected error mentioning undefined environment, instead found %v", err)
+	}
+}
+
 func TestDirInstances(t *testing.T) {
 	assertInstances := func(optionValues map[string]string, expectError bool, expectedInstances ...string) []*tengo.Instance {
 		cmd := mybase.NewCommand("test", "1.0", "this is for testing", nil)
@@ -353,6 +664,33 @@ func TestDirInstances(t *testing.T) {
 	assertInstances(map[string]string{"host": "some.db.host:3306", "port": "3307"}, true)
 	assertInstances(map[string]string{"host": "@@@@@"}, true)
 	assertInstances(map[string]string{"host-wrapper": "`echo {INVALID_VAR}`", "host": "irrelevant"}, true)
+	assertInstances(map[string]string{"host": "some.db.host", "socks5": "proxy.host"}, true)
+	assertInstances(map[string]string{"host": "some.db.host", "socks5-user": "proxyuser"}, true)
+	assertInstances(map[string]string{"host": "some.db.host", "socks5": "proxy.host:1080", "ssh": "bastion"}, true)
+
+	// with socks5, instances are identified by their real address, and even
+	// localhost uses TCP, but the DSN routes through the proxy's dialer
+	for _, inst := range assertInstances(map[string]string{"host": "some.db.host,localhost", "socks5": "proxy.host:1080"}, false, "some.db.host:3306", "localhost:3306") {
+		if !strings.HasPrefix(inst.BaseDSN, "root@skeema-socks5-") {
+			t.Errorf("Expected DSN to use SOCKS5 dialer, instead found %s", inst.BaseDSN)
+		}
+	}
+
+	// Errors involving the DSN must not expose the password
+	cmd := mybase.NewCommand("test", "1.0", "this is for testing", nil)
+	cmd.AddArg("environment", "production", false)
+	util.AddGlobalOptions(cmd)
+	cfg := mybase.NewConfig(&mybase.CommandLine{Command: cmd}, mybase.SimpleSource(map[string]string{
+		"host":            "some.db.host",
+		"password":        "s3cr3t-pw",
+		"connect-options": "readTimeout=bogus",
+	}))
+	dir := &Dir{Path: "/tmp/dummydir", Config: cfg}
+	if _, err := dir.Instances(); err == nil {
+		t.Error("Expected error from invalid connect-options, but err is nil")
+	} else if strings.Contains(err.Error(), "s3cr3t-pw") || !strings.Contains(err.Error(), util.RedactedValue) {
+		t.Errorf("Expected password to be redacted from error, instead found: %s", err)
+	}
 
 	// dynamic hosts via host-wrapper command execution
 	if runtime.GOOS == "windows" {
@@ -373,10 +711,21 @@ func TestDirInstances(t *testing.T) {
 }
 
 func TestDirInstanceDefaultParams(t *testing.T) {
-	getFakeDir := func(connectOptions string) *Dir {
+	getFakeDir := func(connectOptions string, tlsValues ...string) *Dir {
+		values := map[string]string{
+			"connect-options": connectOptions,
+			"ssl-mode":        "",
+			"ssl-ca":          "",
+			"ssl-cert":        "",
+			"ssl-key":         "",
+		}
+		for _, kv := range tlsValues {
+			tokens := strings.SplitN(kv, "=", 2)
+			values[tokens[0]] = tokens[1]
+		}
 		return &Dir{
 			Path:   "/tmp/dummydir",
-			Config: mybase.SimpleConfig(map[string]string{"connect-options": connectOptions}),
+			Config: mybase.SimpleConfig(values),
 		}
 	}
 
@@ -419,6 +768,30 @@ func TestDirInstanceDefaultParams(t *testing.T) {
 			t.Errorf("Did not get expected error from connect-options=\"%s\"", connOpts)
 		}
 	}
+
+	// TLS options should override the default tls param, and cannot be combined
+	// with an explicit tls param in connect-options
+	ca, cert, key := "../util/testdata/tls/ca.pem", "../util/testdata/tls/client-cert.pem", "../util/testdata/tls/client-key.pem"
+	expectTLS := map[string][]string{
+		"false":       {"ssl-mode=disabled"},
+		"skip-verify": {"ssl-mode=required"},
+		"true":        {"ssl-mode=verify-identity"},
+		"skeema-":     {"ssl-ca=" + ca, "ssl-cert=" + cert, "ssl-key=" + key},
+	}
+	for expected, tlsValues := range expectTLS {
+		params, err := getFakeDir("", tlsValues...).InstanceDefaultParams()
+		if err != nil {
+			t.Errorf("Unexpected error from %v: %v", tlsValues, err)
+		} else if v, _ := url.ParseQuery(params); !strings.HasPrefix(v.Get("tls"), expected) {
+			t.Errorf("Expected %v to yield tls param starting with %q, instead found %q", tlsValues, expected, v.Get("tls"))
+		}
+	}
+	if _, err := getFakeDir("tls=false", "ssl-mode=required").InstanceDefaultParams(); err == nil {
+		t.Error("Expected error from combining connect-options tls with ssl-mode, but err was nil")
+	}
+	if _, err := getFakeDir("", "ssl-cert="+cert, "ssl-key="+ca).InstanceDefaultParams(); err == nil {
+		t.Error("Expected error from invalid ssl-key, but err was nil")
+	}
 }
 
 func TestHostDefaultDirName(t *testing.T) {
@@ -475,7 +848,7 @@ func TestAncestorPaths(t *testing.T) {
 	}
 }
 
-func getValidConfig(t *testing.T) *mybase.Config {
+func getValidConfig(t *testing.T, cliArgs ...string) *mybase.Config {
 	cmd := mybase.NewCommand("fstest", "", "", nil)
 	cmd.AddOption(mybase.StringOption("schema", 0, "", "Database schema name").Hidden())
 	cmd.AddOption(mybase.StringOption("default-character-set", 0, "", "Schema-level default character set").Hidden())
@@ -483,8 +856,10 @@ func getValidConfig(t *testing.T) *mybase.Config {
 	cmd.AddOption(mybase.StringOption("host", 0, "", "Database hostname or IP address").Hidden())
 	cmd.AddOption(mybase.StringOption("port", 0, "3306", "Port to use for database host").Hidden())
 	cmd.AddOption(mybase.StringOption("flavor", 0, "", "Database server expressed in format vendor:major.minor, for use in vendor/version specific syntax").Hidden())
+	cmd.AddOption(mybase.StringOption("split-by", 0, "", "Distribute each schema's *.sql files into subdirs"))
+	cmd.AddOption(mybase.StringOption("views-dir", 0, "", "Name of subdir for storing each schema's views"))
 	cmd.AddArg("environment", "production", false)
-	return mybase.ParseFakeCLI(t, cmd, "fstest")
+	return mybase.ParseFakeCLI(t, cmd, strings.Join(append([]string{"fstest"}, cliArgs...), " "))
 }
 
 func getDir(t *testing.T, dirPath string) *Dir {
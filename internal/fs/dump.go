@@ -0,0 +1,91 @@
+package fs
+
+import (
+	"bufio"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/skeema/tengo"
+)
+
+// Dump represents a file of CREATE statements previously captured from a
+// database, for example by mysqldump or a series of SHOW CREATE statements.
+// It is used for comparing a directory against a schema without access to
+// the database that the schema came from.
+type Dump struct {
+	Path              string
+	Flavor            tengo.Flavor              // from the dump's header, or FlavorUnknown if not present
+	LogicalSchemas    map[string]*LogicalSchema // keyed by name; "" for statements not preceded by a USE command
+	IgnoredStatements []*Statement              // non-CREATE statements, e.g. INSERTs or SET commands
+}
+
+// mysqldump wraps some statements, or clauses of statements, in version-gated
+// comments like /*!50001 ... */. The server executes these, so the tokenizer
+// must see their contents instead of treating them as comments.
+var reVersionComment = regexp.MustCompile(`(?s)/\*!\d{5}\s?(.*?)\s*\*/`)
+
+// mysqldump headers include a line such as "-- Server version	8.0.23"
+var reDumpServerVersion = regexp.MustCompile(`(?m)^-- Server version\s+(\S+)`)
+
+// ParseDump reads the file at the supplied path, and returns a Dump
+// containing its CREATE statements. If the same object is created more than
+// once, the last definition is used; this handles the placeholder tables and
+// views that mysqldump emits prior to the final definition of each view.
+func ParseDump(path string) (*Dump, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dump := &Dump{
+		Path:           path,
+		LogicalSchemas: make(map[string]*LogicalSchema),
+	}
+	if matches := reDumpServerVersion.FindSubmatch(contents); matches != nil {
+		dump.Flavor = tengo.ParseFlavor(string(matches[1]), "")
+	}
+
+	text := reVersionComment.ReplaceAllString(string(contents), "$1")
+	tokenizer := newStatementTokenizer(path, ";")
+	statements, err := tokenizer.readStatements(bufio.NewReader(strings.NewReader(text)))
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range statements {
+		if stmt.Type == StatementTypeUnknown {
+			dump.IgnoredStatements = append(dump.IgnoredStatements, stmt)
+		}
+		if stmt.Type != StatementTypeCreate {
+			continue
+		}
+		logicalSchema := dump.LogicalSchemas[stmt.Schema()]
+		if logicalSchema == nil {
+			logicalSchema = &LogicalSchema{
+				Name:    stmt.Schema(),
+				Creates: make(map[tengo.ObjectKey]*Statement),
+			}
+			dump.LogicalSchemas[stmt.Schema()] = logicalSchema
+		}
+		if stmt.ObjectType == tengo.ObjectTypeView {
+			delete(logicalSchema.Creates, tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: stmt.ObjectName})
+		}
+		logicalSchema.Creates[stmt.ObjectKey()] = stmt
+	}
+	return dump, nil
+}
+
+// LogicalSchema returns the dump's LogicalSchema for the supplied schema name.
+// If the dump has no statements for that name, but has statements that were
+// not preceded by any USE command, those are returned instead, since a dump of
+// a single schema typically lacks a USE command. Otherwise, nil is returned.
+func (dump *Dump) LogicalSchema(name string) *LogicalSchema {
+	if logicalSchema, ok := dump.LogicalSchemas[name]; ok {
+		return logicalSchema
+	}
+	return dump.LogicalSchemas[""]
+}
@@ -0,0 +1,95 @@
+package fs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skeema/tengo"
+)
+
+func TestParseDump(t *testing.T) {
+	dump, err := ParseDump("testdata/dump.sql")
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseDump: %v", err)
+	}
+	if expected := tengo.NewFlavor("mysql:5.7.33"); dump.Flavor != expected {
+		t.Errorf("Expected dump flavor %s, instead found %s", expected, dump.Flavor)
+	}
+	if len(dump.LogicalSchemas) != 1 {
+		t.Fatalf("Expected 1 logical schema, instead found %d", len(dump.LogicalSchemas))
+	}
+	logicalSchema := dump.LogicalSchema("dumpdb")
+	if logicalSchema == nil || logicalSchema.Name != "dumpdb" {
+		t.Fatalf("Unexpected result from LogicalSchema: %+v", logicalSchema)
+	}
+	if dump.LogicalSchema("otherdb") != nil {
+		t.Error("Expected LogicalSchema to return nil for a schema not in the dump")
+	}
+
+	// The view's placeholder table must be replaced by the final view definition,
+	// which must be unwrapped from its version-gated comments
+	expectKeys := []tengo.ObjectKey{
+		{Type: tengo.ObjectTypeTable, Name: "customers"},
+		{Type: tengo.ObjectTypeTable, Name: "orders"},
+		{Type: tengo.ObjectTypeView, Name: "big_orders"},
+	}
+	if len(logicalSchema.Creates) != len(expectKeys) {
+		t.Errorf("Expected %d CREATEs, instead found %d", len(expectKeys), len(logicalSchema.Creates))
+	}
+	for _, key := range expectKeys {
+		if _, ok := logicalSchema.Creates[key]; !ok {
+			t.Errorf("Expected dump to contain %s, but it does not", key)
+		}
+	}
+	view := logicalSchema.Creates[expectKeys[2]]
+	if view != nil && (strings.Contains(view.Body(), "/*!") || !strings.HasPrefix(view.Body(), "CREATE ALGORITHM=UNDEFINED\nDEFINER=`root`@`%` SQL SECURITY DEFINER\nVIEW `big_orders` AS select")) {
+		t.Errorf("Unexpected view body: %s", view.Body())
+	}
+	if len(logicalSchema.Alters) > 0 {
+		t.Errorf("Expected no ALTERs, instead found %d", len(logicalSchema.Alters))
+	}
+
+	// INSERTs and other non-CREATE statements are ignored, including an INSERT
+	// containing a semicolon in a string
+	var inserts int
+	for _, stmt := range dump.IgnoredStatements {
+		if strings.HasPrefix(stmt.Text, "INSERT") {
+			inserts++
+			if !strings.Contains(stmt.Text, "'Alice; Inc.'") {
+				t.Errorf("INSERT statement was split incorrectly: %s", stmt.Text)
+			}
+		}
+	}
+	if inserts != 1 {
+		t.Errorf("Expected 1 ignored INSERT, instead found %d", inserts)
+	}
+}
+
+func TestParseDumpShowCreate(t *testing.T) {
+	dump, err := ParseDump("testdata/dump-showcreate.sql")
+	if err != nil {
+		t.Fatalf("Unexpected error from ParseDump: %v", err)
+	}
+	if dump.Flavor.Known() {
+		t.Errorf("Expected dump without header to have unknown flavor, instead found %s", dump.Flavor)
+	}
+
+	// Without any USE command, the unnamed logical schema is used for all names
+	logicalSchema := dump.LogicalSchema("anything")
+	if logicalSchema == nil || logicalSchema.Name != "" {
+		t.Fatalf("Unexpected result from LogicalSchema: %+v", logicalSchema)
+	}
+	for _, key := range []tengo.ObjectKey{
+		{Type: tengo.ObjectTypeTable, Name: "posts"},
+		{Type: tengo.ObjectTypeProc, Name: "count_posts"},
+		{Type: tengo.ObjectTypeView, Name: "recent_posts"},
+	} {
+		if _, ok := logicalSchema.Creates[key]; !ok {
+			t.Errorf("Expected dump to contain %s, but it does not", key)
+		}
+	}
+
+	if _, err := ParseDump("testdata/does-not-exist.sql"); err == nil {
+		t.Error("Expected error from ParseDump on nonexistent file, but err was nil")
+	}
+}
@@ -45,6 +45,7 @@ type Statement struct {
 	ObjectName      string
 	ObjectQualifier string
 	FromFile        *TokenizedSQLFile
+	Ignored         bool // true if a CREATE preceded by a skeema:ignore annotation comment
 	delimiter       string
 }
 
@@ -117,10 +118,151 @@ func (stmt *Statement) Remove() {
 // have been mis-parsed (for example, due to lack of DELIMITER commands)
 func (stmt *Statement) isCreateWithBegin() bool {
 	return stmt.Type == StatementTypeCreate &&
-		(stmt.ObjectType == tengo.ObjectTypeProc || stmt.ObjectType == tengo.ObjectTypeFunc) &&
+		(stmt.ObjectType == tengo.ObjectTypeProc || stmt.ObjectType == tengo.ObjectTypeFunc || stmt.ObjectType == tengo.ObjectTypeEvent || stmt.ObjectType == tengo.ObjectTypeTrigger) &&
 		strings.Contains(strings.ToLower(stmt.Text), "begin")
 }
 
+// DisabledEventBody returns the Body of a CREATE EVENT statement, modified so
+// that the event is created with status DISABLE. This permits the event to be
+// created without the event scheduler ever running it. The status which the
+// original statement specified (or implied) is also returned, using the same
+// format as SHOW CREATE EVENT. If the statement is not a CREATE EVENT, its
+// Body is returned unchanged, along with a blank status.
+func (stmt *Statement) DisabledEventBody() (body string, status string) {
+	body = stmt.Body()
+	if stmt.Type != StatementTypeCreate || stmt.ObjectType != tengo.ObjectTypeEvent {
+		return body, ""
+	}
+	lex, err := sqlLexer.Lex(strings.NewReader(body))
+	if err != nil {
+		return body, ""
+	}
+	wordType := sqlLexer.Symbols()["Word"]
+	var words []lexer.Token
+	var afterSchedule bool
+	for {
+		token, err := lex.Next()
+		if err != nil || token.EOF() {
+			return body, ""
+		} else if token.Type != wordType {
+			continue
+		}
+		word := strings.ToUpper(token.Value)
+		if word == "DO" {
+			words = append(words, token)
+			break
+		} else if word == "SCHEDULE" {
+			afterSchedule = true
+		} else if afterSchedule {
+			words = append(words, token)
+		}
+	}
+
+	// Find the status clause, if present; otherwise, insert one prior to the
+	// COMMENT clause or DO keyword
+	for n, token := range words {
+		word := strings.ToUpper(token.Value)
+		if word == "ENABLE" {
+			return body[:token.Pos.Offset] + "DISABLE" + body[token.Pos.Offset+len(token.Value):], "ENABLE"
+		} else if word == "DISABLE" {
+			if n+2 < len(words) && strings.ToUpper(words[n+1].Value) == "ON" {
+				end := words[n+2].Pos.Offset + len(words[n+2].Value)
+				return body[:token.Pos.Offset] + "DISABLE" + body[end:], "DISABLE ON " + strings.ToUpper(words[n+2].Value)
+			}
+			return body, "DISABLE"
+		} else if word == "COMMENT" || word == "DO" {
+			return body[:token.Pos.Offset] + "DISABLE " + body[token.Pos.Offset:], "ENABLE"
+		}
+	}
+	return body, ""
+}
+
+// Reference describes an object which a CREATE statement depends upon.
+type Reference struct {
+	tengo.ObjectKey
+	Schema     string // schema name qualifier used in the reference, or blank if none
+	LineOffset int    // offset of the line containing the reference, relative to the first line of the statement
+}
+
+// References returns the objects which a CREATE statement depends upon, to the
+// extent these can be determined without fully parsing the statement: tables
+// referenced by foreign keys in a CREATE TABLE, and the table of a CREATE
+// TRIGGER. Other types of statements are not examined, and nil is returned for
+// them.
+func (stmt *Statement) References() (refs []Reference) {
+	if stmt.Type != StatementTypeCreate || (stmt.ObjectType != tengo.ObjectTypeTable && stmt.ObjectType != tengo.ObjectTypeTrigger) {
+		return nil
+	}
+	symbols := sqlLexer.Symbols()
+	wordType, operatorType := symbols["Word"], symbols["Operator"]
+	tokens := stmt.tokens()
+
+	// A trigger's table follows the first ON keyword; foreign key parent tables
+	// follow each REFERENCES keyword
+	keyword := "REFERENCES"
+	if stmt.ObjectType == tengo.ObjectTypeTrigger {
+		keyword = "ON"
+	}
+	for n := 0; n+1 < len(tokens); n++ {
+		if tokens[n].Type != wordType || strings.ToUpper(tokens[n].Value) != keyword || tokens[n+1].Type != wordType {
+			continue
+		}
+		ref := Reference{
+			ObjectKey:  tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: stripBackticks(tokens[n+1].Value)},
+			LineOffset: tokens[n+1].Pos.Line - 1,
+		}
+		if n+3 < len(tokens) && tokens[n+2].Type == operatorType && tokens[n+2].Value == "." && tokens[n+3].Type == wordType {
+			ref.Schema, ref.Name = ref.Name, stripBackticks(tokens[n+3].Value)
+		}
+		refs = append(refs, ref)
+		if stmt.ObjectType == tengo.ObjectTypeTrigger {
+			break
+		}
+	}
+	return refs
+}
+
+// viewReferences returns the names of any views in viewNames which a CREATE
+// VIEW statement may depend upon. Since the statement is not fully parsed, any
+// identifier matching one of the names is treated as a reference, even if it
+// actually refers to a column or an object in another schema. The statement's
+// own name is never included.
+func (stmt *Statement) viewReferences(viewNames map[string]bool) (names []string) {
+	if stmt.Type != StatementTypeCreate || stmt.ObjectType != tengo.ObjectTypeView {
+		return nil
+	}
+	wordType := sqlLexer.Symbols()["Word"]
+	seen := map[string]bool{stmt.ObjectName: true}
+	for _, token := range stmt.tokens() {
+		name := stripBackticks(token.Value)
+		if token.Type == wordType && viewNames[name] && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// tokens returns the meaningful tokens of the statement's body, discarding
+// whitespace and comments. If the body cannot be lexed, nil is returned.
+func (stmt *Statement) tokens() (tokens []lexer.Token) {
+	body, _ := stmt.SplitTextBody()
+	lex, err := sqlLexer.Lex(strings.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	symbols := sqlLexer.Symbols()
+	for {
+		token, err := lex.Next()
+		if err != nil || token.EOF() {
+			break
+		} else if token.Type == symbols["Word"] || token.Type == symbols["Operator"] || token.Type == symbols["String"] || token.Type == symbols["Number"] {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
 // CanParse returns true if the supplied string can be parsed as a type of
 // SQL statement understood by this package. The supplied string should NOT
 // have a delimiter. Note that this method returns false for strings that are
@@ -152,6 +294,7 @@ type statementTokenizer struct {
 	inCComment      bool   // true if in a C-style comment
 	inQuote         rune   // nonzero if inside of a quoted string; value indicates which quote rune
 	defaultDatabase string // tracks most recent USE command
+	ignoreNext      bool   // true if a skeema:ignore annotation applies to the next statement
 }
 
 type lineState struct {
@@ -176,8 +319,12 @@ func (st *statementTokenizer) statements() ([]*Statement, error) {
 		return nil, err
 	}
 	defer file.Close()
-	reader := bufio.NewReader(file)
+	return st.readStatements(bufio.NewReader(file))
+}
 
+// readStatements splits the contents of reader into statements. The
+// tokenizer's filePath is only used for statement locations and errors.
+func (st *statementTokenizer) readStatements(reader *bufio.Reader) (_ []*Statement, err error) {
 	for err != io.EOF {
 		var line string
 		line, err = reader.ReadString('\n')
@@ -361,11 +508,22 @@ func (ls *lineState) doneStatement(omitEndBytes int) {
 	}
 }
 
+// reIgnoreAnnotation matches a comment annotation indicating that the
+// following CREATE statement should be excluded from diff and push.
+var reIgnoreAnnotation = regexp.MustCompile(`(?m)(?:^\s*(?:--\s|#)\s*skeema:ignore\b)|(?:/\*\s*skeema:ignore\s*\*/)`)
+
 func (ls *lineState) parseStatement() {
 	txt, _ := ls.stmt.SplitTextBody()
 	if !ls.inRelevant || txt == "" {
 		ls.stmt.Type = StatementTypeNoop
+		if reIgnoreAnnotation.MatchString(ls.stmt.Text) {
+			ls.ignoreNext = true
+		}
 	} else {
+		// Any skeema:ignore annotation only applies to the statement immediately
+		// following it, and only if that statement is a CREATE
+		ignore := ls.ignoreNext
+		ls.ignoreNext = false
 		sqlStmt := &sqlStatement{}
 		if err := nameParser.ParseString(txt, sqlStmt); err != nil || sqlStmt.forbidden() {
 			return
@@ -387,7 +545,24 @@ func (ls *lineState) parseStatement() {
 			ls.stmt.Type = StatementTypeCreate
 			ls.stmt.ObjectType = tengo.ObjectTypeFunc
 			ls.stmt.ObjectQualifier, ls.stmt.ObjectName = sqlStmt.CreateFunc.Name.schemaAndTable()
+		} else if sqlStmt.CreateView != nil {
+			ls.stmt.Type = StatementTypeCreate
+			ls.stmt.ObjectType = tengo.ObjectTypeView
+			ls.stmt.ObjectQualifier, ls.stmt.ObjectName = sqlStmt.CreateView.Name.schemaAndTable()
+		} else if sqlStmt.CreateEvent != nil {
+			ls.stmt.Type = StatementTypeCreate
+			ls.stmt.ObjectType = tengo.ObjectTypeEvent
+			ls.stmt.ObjectQualifier, ls.stmt.ObjectName = sqlStmt.CreateEvent.Name.schemaAndTable()
+		} else if sqlStmt.CreateTrigger != nil {
+			ls.stmt.Type = StatementTypeCreate
+			ls.stmt.ObjectType = tengo.ObjectTypeTrigger
+			ls.stmt.ObjectQualifier, ls.stmt.ObjectName = sqlStmt.CreateTrigger.Name.schemaAndTable()
+		} else if sqlStmt.CreateSequence != nil {
+			ls.stmt.Type = StatementTypeCreate
+			ls.stmt.ObjectType = tengo.ObjectTypeSequence
+			ls.stmt.ObjectQualifier, ls.stmt.ObjectName = sqlStmt.CreateSequence.Name.schemaAndTable()
 		}
+		ls.stmt.Ignored = ignore && ls.stmt.Type == StatementTypeCreate
 	}
 }
 
@@ -442,6 +617,10 @@ type sqlStatement struct {
 	CreateTable      *createTable      `parser:"@@"`
 	CreateProc       *createProc       `parser:"| @@"`
 	CreateFunc       *createFunc       `parser:"| @@"`
+	CreateView       *createView       `parser:"| @@"`
+	CreateEvent      *createEvent      `parser:"| @@"`
+	CreateTrigger    *createTrigger    `parser:"| @@"`
+	CreateSequence   *createSequence   `parser:"| @@"`
 	UseCommand       *useCommand       `parser:"| @@"`
 	DelimiterCommand *delimiterCommand `parser:"| @@"`
 }
@@ -488,7 +667,8 @@ type body struct {
 	Contents []string `parser:"(@Word | @String | @Number | @Operator)*"`
 }
 
-// definer represents a user who is the definer of a routine or view.
+// definer represents a user who is the definer of a routine, view, event, or
+// trigger.
 type definer struct {
 	User string `parser:"((@String | @Word) '@'"`
 	Host string `parser:"(@String | @Word))"`
@@ -515,6 +695,35 @@ type createFunc struct {
 	Body    body       `parser:"@@"`
 }
 
+// createView represents a CREATE VIEW statement.
+type createView struct {
+	Algorithm    string     `parser:"'CREATE' ('OR' 'REPLACE')? ('ALGORITHM' '=' @Word)?"`
+	Definer      *definer   `parser:"('DEFINER' '=' @@)?"`
+	SecurityType string     `parser:"('SQL' 'SECURITY' @Word)?"`
+	Name         objectName `parser:"'VIEW' @@"`
+	Body         body       `parser:"@@"`
+}
+
+// createEvent represents a CREATE EVENT statement.
+type createEvent struct {
+	Definer *definer   `parser:"'CREATE' ('DEFINER' '=' @@)?"`
+	Name    objectName `parser:"'EVENT' ('IF' 'NOT' 'EXISTS')? @@"`
+	Body    body       `parser:"@@"`
+}
+
+// createTrigger represents a CREATE TRIGGER statement.
+type createTrigger struct {
+	Definer *definer   `parser:"'CREATE' ('DEFINER' '=' @@)?"`
+	Name    objectName `parser:"'TRIGGER' ('IF' 'NOT' 'EXISTS')? @@"`
+	Body    body       `parser:"@@"`
+}
+
+// createSequence represents a CREATE SEQUENCE statement.
+type createSequence struct {
+	Name objectName `parser:"'CREATE' 'SEQUENCE' ('IF' 'NOT' 'EXISTS')? @@"`
+	Body body       `parser:"@@"`
+}
+
 // useCommand represents a USE command.
 type useCommand struct {
 	DefaultDatabase string `parser:"'USE' @Word"`
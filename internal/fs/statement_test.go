@@ -1,6 +1,7 @@
 package fs
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/skeema/tengo"
@@ -98,6 +99,57 @@ func TestStatementBody(t *testing.T) {
 	}
 }
 
+func TestStatementDisabledEventBody(t *testing.T) {
+	cases := []struct {
+		input        string
+		expectBody   string
+		expectStatus string
+	}{
+		{"CREATE EVENT e ON SCHEDULE EVERY 1 DAY DO DELETE FROM foo", "CREATE EVENT e ON SCHEDULE EVERY 1 DAY DISABLE DO DELETE FROM foo", "ENABLE"},
+		{"CREATE EVENT e ON SCHEDULE EVERY 1 DAY enable DO DELETE FROM foo", "CREATE EVENT e ON SCHEDULE EVERY 1 DAY DISABLE DO DELETE FROM foo", "ENABLE"},
+		{"CREATE EVENT e ON SCHEDULE EVERY 1 DAY DISABLE DO DELETE FROM foo", "CREATE EVENT e ON SCHEDULE EVERY 1 DAY DISABLE DO DELETE FROM foo", "DISABLE"},
+		{"CREATE EVENT e ON SCHEDULE EVERY 1 DAY Disable On Slave DO DELETE FROM foo", "CREATE EVENT e ON SCHEDULE EVERY 1 DAY DISABLE DO DELETE FROM foo", "DISABLE ON SLAVE"},
+		{"CREATE EVENT `enable` ON SCHEDULE EVERY 1 DAY COMMENT 'do not enable' DO UPDATE foo SET status='enable'", "CREATE EVENT `enable` ON SCHEDULE EVERY 1 DAY DISABLE COMMENT 'do not enable' DO UPDATE foo SET status='enable'", "ENABLE"},
+	}
+	for _, c := range cases {
+		stmt := &Statement{Text: c.input + ";\n", delimiter: ";", Type: StatementTypeCreate, ObjectType: tengo.ObjectTypeEvent, ObjectName: "e"}
+		if body, status := stmt.DisabledEventBody(); body != c.expectBody || status != c.expectStatus {
+			t.Errorf("Unexpected result from DisabledEventBody on %q: found %q, %q", c.input, body, status)
+		}
+	}
+
+	// Other statement types are returned unchanged
+	stmt := &Statement{Text: "CREATE TABLE foo (id int);\n", delimiter: ";", Type: StatementTypeCreate, ObjectType: tengo.ObjectTypeTable, ObjectName: "foo"}
+	if body, status := stmt.DisabledEventBody(); body != "CREATE TABLE foo (id int)" || status != "" {
+		t.Errorf("Unexpected result from DisabledEventBody on non-event: found %q, %q", body, status)
+	}
+}
+
+func TestStatementReferences(t *testing.T) {
+	cases := []struct {
+		objectType tengo.ObjectType
+		input      string
+		expected   []Reference
+	}{
+		{tengo.ObjectTypeTable, "CREATE TABLE foo (id int)", nil},
+		{tengo.ObjectTypeTable, "CREATE TABLE foo (\n  id int,\n  bar_id int,\n  FOREIGN KEY (bar_id) REFERENCES bar (id),\n  FOREIGN KEY (id) references `other`.`baz` (id)\n)", []Reference{
+			{ObjectKey: tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "bar"}, LineOffset: 3},
+			{ObjectKey: tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "baz"}, Schema: "other", LineOffset: 4},
+		}},
+		{tengo.ObjectTypeTable, "CREATE TABLE foo (\n  `references` varchar(20) DEFAULT 'REFERENCES bar' /* REFERENCES bar */\n)", nil},
+		{tengo.ObjectTypeTrigger, "CREATE DEFINER=`root`@`%` TRIGGER trig1 BEFORE INSERT\n  ON `foo` FOR EACH ROW SET NEW.x = (SELECT 1 FROM bar ON DUPLICATE)", []Reference{
+			{ObjectKey: tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "foo"}, LineOffset: 1},
+		}},
+		{tengo.ObjectTypeProc, "CREATE PROCEDURE p() SELECT * FROM foo JOIN bar ON foo.id = bar.id", nil},
+	}
+	for _, c := range cases {
+		stmt := &Statement{Text: c.input + ";\n", delimiter: ";", Type: StatementTypeCreate, ObjectType: c.objectType}
+		if actual := stmt.References(); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("Unexpected result from References on %q: expected %+v, found %+v", c.input, c.expected, actual)
+		}
+	}
+}
+
 func TestStripAnyQuote(t *testing.T) {
 	cases := map[string]string{
 		"":                "",
@@ -131,6 +183,13 @@ func TestCanParse(t *testing.T) {
 		"CREATE TABLE foo (like bar)":                     false,
 		"CREATE TABLE foo2 select * from foo":             false,
 		"CREATE TABLE foo2 (id int) AS select * from foo": false,
+		"CREATE VIEW foo2 AS select * from foo":           true,
+		"CREATE OR REPLACE ALGORITHM=MERGE DEFINER=`root`@`%` SQL SECURITY INVOKER VIEW `foo2` AS select `foo`.`id` AS `id` from `foo`":    true,
+		"CREATE DEFINER=CURRENT_USER VIEW foo2 AS select 1":                                                                                true,
+		"CREATE EVENT purge ON SCHEDULE EVERY 1 DAY DO DELETE FROM foo":                                                                    true,
+		"CREATE DEFINER=`root`@`%` EVENT IF NOT EXISTS `purge` ON SCHEDULE AT CURRENT_TIMESTAMP + INTERVAL 1 HOUR DISABLE DO select 1":     true,
+		"CREATE TRIGGER set_ts BEFORE INSERT ON foo FOR EACH ROW FOLLOWS other SET NEW.ts = NOW()":                                         true,
+		"CREATE SEQUENCE `order_ids` start with 1 minvalue 1 maxvalue 9223372036854775806 increment by 1 cache 1000 nocycle ENGINE=InnoDB": true,
 	}
 	for input, expected := range cases {
 		if actual, _ := CanParse(input); actual != expected {
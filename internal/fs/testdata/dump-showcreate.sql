@@ -0,0 +1,14 @@
+CREATE TABLE `posts` (
+  `id` int(10) unsigned NOT NULL,
+  `body` text,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+DELIMITER //
+CREATE DEFINER=`root`@`localhost` PROCEDURE `count_posts`()
+BEGIN
+  SELECT COUNT(*) FROM posts;
+END//
+DELIMITER ;
+
+CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`localhost` SQL SECURITY DEFINER VIEW `recent_posts` AS select `posts`.`id` AS `id` from `posts` where (`posts`.`id` > 100);
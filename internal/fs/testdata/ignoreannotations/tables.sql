@@ -0,0 +1,22 @@
+-- Legacy table, still managed by the old deployment system
+-- skeema:ignore
+CREATE TABLE legacy_users (
+  id int unsigned NOT NULL,
+  PRIMARY KEY (id)
+) ENGINE=InnoDB;
+
+CREATE TABLE accounts (
+  id int unsigned NOT NULL,
+  legacy_user_id int unsigned DEFAULT NULL,
+  PRIMARY KEY (id),
+  CONSTRAINT legacy_user FOREIGN KEY (legacy_user_id) REFERENCES legacy_users (id)
+) ENGINE=InnoDB;
+
+# skeema:ignore
+CREATE TABLE external_audit (
+  id int unsigned NOT NULL
+) ENGINE=InnoDB;
+
+CREATE TABLE widgets (
+  id int unsigned NOT NULL
+) ENGINE=InnoDB;
@@ -0,0 +1,6 @@
+/* skeema:ignore */
+CREATE VIEW legacy_user_ids AS SELECT id FROM legacy_users;
+
+-- skeema:ignore
+DELIMITER ;
+CREATE VIEW account_ids AS SELECT id FROM accounts;
@@ -0,0 +1,42 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/skeema/tengo"
+)
+
+func init() {
+	RegisterRule(Rule{
+		CheckerFunc:     TableChecker(fkUnmanagedChecker),
+		Name:            "fk-unmanaged",
+		Description:     "Flag foreign keys referencing a schema which is not managed by any dir in this Skeema project",
+		DefaultSeverity: SeverityWarning,
+	})
+}
+
+func fkUnmanagedChecker(table *tengo.Table, createStatement string, _ *tengo.Schema, opts Options) []Note {
+	results := make([]Note, 0)
+	for _, fk := range table.ForeignKeys {
+		if fk.ReferencedSchemaName == "" {
+			continue
+		}
+		// If it isn't possible to determine what schemas are managed, don't flag
+		// anything, to avoid false positives
+		if managed, known := opts.ManagedSchema(fk.ReferencedSchemaName); managed || !known {
+			continue
+		}
+		re := regexp.MustCompile(`(?i)references\s+` + "`?" + regexp.QuoteMeta(fk.ReferencedSchemaName) + "`?" + `\s*\.`)
+		message := fmt.Sprintf(
+			"Foreign key %s of table %s references table %s.%s, but schema %s is not managed by any dir in this Skeema project. Creating this table in a fresh environment may fail, unless schema %s is created by some other means beforehand.",
+			fk.Name, table.Name, fk.ReferencedSchemaName, fk.ReferencedTableName, fk.ReferencedSchemaName, fk.ReferencedSchemaName,
+		)
+		results = append(results, Note{
+			LineOffset: FindFirstLineOffset(re, createStatement),
+			Summary:    "Foreign key references unmanaged schema",
+			Message:    message,
+		})
+	}
+	return results
+}
@@ -0,0 +1,149 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/skeema/mybase"
+	"github.com/skeema/tengo"
+)
+
+func init() {
+	RegisterRule(Rule{
+		CheckerFunc:     TableChecker(nameCaseChecker),
+		Name:            "name-case",
+		Description:     "Flag table, column, and index names not following the convention in --name-case-style",
+		DefaultSeverity: SeverityIgnore,
+		RelatedOption:   mybase.StringOption("name-case-style", 0, "snake", "Naming convention for --lint-name-case: snake, lowercase, or a regular expression"),
+		ExtraOptions: []*mybase.Option{
+			mybase.StringOption("name-case-objects", 0, "table,column,index", "List of object kinds checked by --lint-name-case"),
+		},
+		ConfigFunc: RuleConfigFunc(nameCaseConfiger),
+	})
+}
+
+// nameCaseConfig is a custom configuration struct used by nameCaseChecker.
+type nameCaseConfig struct {
+	style   string         // "snake", "lowercase", or "regexp"
+	re      *regexp.Regexp // only used for style "regexp"
+	objects map[string]bool
+}
+
+var reSnakeCase = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// matches returns true if name satisfies the configured convention.
+func (ncc nameCaseConfig) matches(name string) bool {
+	switch ncc.style {
+	case "snake":
+		return reSnakeCase.MatchString(name)
+	case "lowercase":
+		return name == strings.ToLower(name)
+	default:
+		return ncc.re.MatchString(name)
+	}
+}
+
+// expected returns a description of the expected form of name, for use in
+// an annotation message.
+func (ncc nameCaseConfig) expected(name string) string {
+	switch ncc.style {
+	case "snake":
+		return fmt.Sprintf("expected snake_case, such as %s", toSnakeCase(name))
+	case "lowercase":
+		return fmt.Sprintf("expected lowercase, such as %s", strings.ToLower(name))
+	default:
+		return fmt.Sprintf("expected a name matching regular expression %s", ncc.re)
+	}
+}
+
+func nameCaseChecker(table *tengo.Table, createStatement string, _ *tengo.Schema, opts Options) []Note {
+	ncc := opts.RuleConfig["name-case"].(nameCaseConfig)
+	results := make([]Note, 0)
+	makeNote := func(what, name string, lineOffset int) Note {
+		return Note{
+			LineOffset: lineOffset,
+			Summary:    "Name does not follow naming convention",
+			Message:    fmt.Sprintf("%s does not follow the configured naming convention: %s.", what, ncc.expected(name)),
+		}
+	}
+
+	if ncc.objects["table"] && !ncc.matches(table.Name) {
+		results = append(results, makeNote("Table "+table.Name, table.Name, 0))
+	}
+	if ncc.objects["column"] {
+		for _, col := range table.Columns {
+			if !ncc.matches(col.Name) {
+				what := fmt.Sprintf("Column %s of table %s", col.Name, table.Name)
+				results = append(results, makeNote(what, col.Name, FindColumnLineOffset(col, createStatement)))
+			}
+		}
+	}
+	if ncc.objects["index"] {
+		for _, idx := range table.SecondaryIndexes {
+			if !ncc.matches(idx.Name) {
+				what := fmt.Sprintf("Index %s of table %s", idx.Name, table.Name)
+				re := regexp.MustCompile(fmt.Sprintf("(?i)(?:key|index)\\s+`?%s\\b", regexp.QuoteMeta(idx.Name)))
+				results = append(results, makeNote(what, idx.Name, FindFirstLineOffset(re, createStatement)))
+			}
+		}
+	}
+	return results
+}
+
+// nameCaseConfiger establishes the naming convention and the set of object
+// kinds it applies to. Any value of name-case-style other than "snake" or
+// "lowercase" is treated as a regular expression.
+func nameCaseConfiger(config *mybase.Config) interface{} {
+	ncc := nameCaseConfig{
+		style:   strings.ToLower(config.Get("name-case-style")),
+		objects: make(map[string]bool),
+	}
+	if ncc.style != "snake" && ncc.style != "lowercase" {
+		re, err := config.GetRegexp("name-case-style")
+		if err != nil {
+			return err
+		} else if re == nil {
+			return fmt.Errorf("Option name-case-style must be non-empty when using --lint-name-case")
+		}
+		ncc.style, ncc.re = "regexp", re
+	}
+	for _, kind := range config.GetSlice("name-case-objects", ',', true) {
+		kind = strings.ToLower(kind)
+		if kind != "table" && kind != "column" && kind != "index" {
+			return fmt.Errorf("Option name-case-objects has invalid value %s: only table, column, and index are supported", kind)
+		}
+		ncc.objects[kind] = true
+	}
+	return ncc
+}
+
+// toSnakeCase converts name to snake_case, splitting words at transitions
+// from lowercase letters or digits to uppercase letters, as well as at the
+// end of an uppercase acronym. Any characters other than letters and digits
+// are treated as word separators.
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	pendingSeparator := false
+	for n, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingSeparator = true
+			continue
+		}
+		if unicode.IsUpper(r) && n > 0 {
+			prev := runes[n-1]
+			nextIsLower := n+1 < len(runes) && unicode.IsLower(runes[n+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				pendingSeparator = true
+			}
+		}
+		if pendingSeparator && b.Len() > 0 {
+			b.WriteRune('_')
+		}
+		pendingSeparator = false
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/fs"
@@ -27,7 +28,7 @@ func AddCommandOptions(cmd *mybase.Command) {
 	cmd.AddOptions("linter rule", mybase.StringOption("warnings", 0, "", "Deprecated method of setting multiple linter options to warning level").Hidden())
 	cmd.AddOptions("linter rule", mybase.StringOption("errors", 0, "", "Deprecated method of setting multiple linter options to error level").Hidden())
 	for _, r := range rulesByName {
-		opt := mybase.StringOption(r.optionName(), 0, string(r.DefaultSeverity), r.optionDescription())
+		opt := mybase.StringOption(r.optionName(), 0, string(r.DefaultSeverity), r.optionDescription()).Negatable()
 		if r.hidden() {
 			opt.Hidden()
 		}
@@ -35,6 +36,7 @@ func AddCommandOptions(cmd *mybase.Command) {
 		if r.RelatedOption != nil {
 			cmd.AddOptions("linter rule", r.RelatedOption)
 		}
+		cmd.AddOptions("linter rule", r.ExtraOptions...)
 	}
 }
 
@@ -44,7 +46,42 @@ type Options struct {
 	RuleConfig   map[string]interface{}
 	IgnoreTable  *regexp.Regexp
 	Flavor       tengo.Flavor
+	Concurrency  int                      // max number of objects to check at once; values below 2 check serially
 	onlyKeys     map[tengo.ObjectKey]bool // if map is non-nil, only format objects with true values
+	project      *projectSchemas          // schemas managed by the dir's project; only needed by some rules
+}
+
+// projectSchemas lazily determines which schema names are managed by the
+// project containing a dir. Since this requires parsing the project's entire
+// directory tree, it is only done once a rule actually needs the information.
+type projectSchemas struct {
+	once     sync.Once
+	dir      *fs.Dir
+	names    map[string]bool
+	complete bool
+}
+
+// ManagedSchema returns true if the supplied schema name is mapped to by any dir
+// in the project being linted. The second return value is false if this cannot
+// be determined, for example if some dir's schema names can only be resolved
+// by querying a database instance.
+func (opts *Options) ManagedSchema(name string) (managed bool, known bool) {
+	if opts.project == nil || opts.project.dir == nil {
+		return false, false
+	}
+	ps := opts.project
+	ps.once.Do(func() {
+		var names []string
+		names, ps.complete = ps.dir.ProjectSchemaNames()
+		ps.names = make(map[string]bool, len(names))
+		for _, name := range names {
+			ps.names[strings.ToLower(name)] = true
+		}
+	})
+	if ps.names[strings.ToLower(name)] {
+		return true, true
+	}
+	return false, ps.complete
 }
 
 // AllowList returns a slice of configured allowed values for the given rule.
@@ -118,6 +155,7 @@ func OptionsForDir(dir *fs.Dir) (Options, error) {
 		RuleSeverity: make(map[string]Severity),
 		RuleConfig:   make(map[string]interface{}),
 		Flavor:       tengo.NewFlavor(dir.Config.Get("flavor")),
+		project:      &projectSchemas{dir: dir},
 	}
 
 	var err error
@@ -69,6 +69,9 @@ func TestOptionsForDir(t *testing.T) {
 		"--allow-engine=''",
 		"--lint-engine=gentle-nudge",
 		"--allow-definer=''",
+		"--lint-name-case=warning --name-case-style='+'",
+		"--lint-name-case=warning --name-case-style=''",
+		"--lint-name-case=warning --name-case-objects=table,view",
 	}
 	confirmError := func(cliArgs string) {
 		t.Helper()
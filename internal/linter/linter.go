@@ -3,8 +3,10 @@ package linter
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/skeema/mybase"
+	"github.com/skeema/skeema/internal/fs"
 	"github.com/skeema/skeema/internal/workspace"
 	"github.com/skeema/tengo"
 )
@@ -13,12 +15,16 @@ import (
 // (This function does not operate directly on a tengo.Schema alone, because the
 // original fs.LogicalSchema is also needed, in order to generate annotations
 // corresponding to SQL statements / files / line numbers.)
+// If opts.Concurrency is greater than 1, objects are checked by that many
+// concurrent workers. The workspace has already been fully executed and
+// introspected at this point, so workers only read from wsSchema. In all cases
+// the returned annotations are sorted, so output does not depend on scheduling.
 func CheckSchema(wsSchema *workspace.Schema, opts Options) *Result {
-	result := &Result{}
 	tables := wsSchema.TablesByName()
 	procs := wsSchema.ProceduresByName()
 	funcs := wsSchema.FunctionsByName()
 
+	var jobs []checkJob
 	for key, stmt := range wsSchema.LogicalSchema.Creates {
 		if opts.shouldIgnore(key) {
 			continue
@@ -33,23 +39,72 @@ func CheckSchema(wsSchema *workspace.Schema, opts Options) *Result {
 		case tengo.ObjectTypeFunc:
 			object, ok = funcs[key.Name]
 		}
-		if !ok { // happens normally if the create SQL errored
-			continue
+		if ok { // !ok happens normally if the create SQL errored
+			jobs = append(jobs, checkJob{object: object, stmt: stmt})
 		}
-		for ruleName, severity := range opts.RuleSeverity {
-			if severity == SeverityIgnore {
-				continue
-			}
-			r := rulesByName[ruleName]
-			output := r.CheckerFunc.CheckObject(object, stmt.Text, wsSchema.Schema, opts)
-			for _, lo := range output {
-				result.Annotate(stmt, severity, ruleName, lo)
-			}
+	}
+
+	workers := opts.Concurrency
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+	if workers <= 1 {
+		result := &Result{}
+		for _, job := range jobs {
+			job.check(wsSchema.Schema, opts, result)
 		}
+		result.SortByFile()
+		return result
+	}
+
+	jobChan := make(chan checkJob)
+	results := make([]*Result, workers)
+	var wg sync.WaitGroup
+	for n := range results {
+		results[n] = &Result{}
+		wg.Add(1)
+		go func(result *Result) {
+			defer wg.Done()
+			for job := range jobChan {
+				job.check(wsSchema.Schema, opts, result)
+			}
+		}(results[n])
+	}
+	for _, job := range jobs {
+		jobChan <- job
 	}
+	close(jobChan)
+	wg.Wait()
+
+	result := &Result{}
+	for _, subresult := range results {
+		result.Merge(subresult)
+	}
+	result.SortByFile()
 	return result
 }
 
+// checkJob is a single object to be checked by CheckSchema, along with the
+// CREATE statement that defines it.
+type checkJob struct {
+	object interface{}
+	stmt   *fs.Statement
+}
+
+// check runs all enabled rules against the job's object, storing any
+// annotations in result.
+func (job checkJob) check(schema *tengo.Schema, opts Options, result *Result) {
+	for ruleName, severity := range opts.RuleSeverity {
+		if severity == SeverityIgnore {
+			continue
+		}
+		r := rulesByName[ruleName]
+		for _, lo := range r.CheckerFunc.CheckObject(job.object, job.stmt.Text, schema, opts) {
+			result.Annotate(job.stmt, severity, ruleName, lo)
+		}
+	}
+}
+
 // ObjectChecker values may be used to check for problems in database objects.
 type ObjectChecker interface {
 	CheckObject(object interface{}, createStatement string, schema *tengo.Schema, opts Options) []Note
@@ -112,7 +167,8 @@ type Rule struct {
 	Name            string
 	Description     string
 	DefaultSeverity Severity
-	RelatedOption   *mybase.Option // for rules that have supplemental options, e.g. list of allowed values
+	RelatedOption   *mybase.Option   // for rules that have supplemental options, e.g. list of allowed values
+	ExtraOptions    []*mybase.Option // for rules that need more than one supplemental option
 	ConfigFunc      RuleConfigFunc
 }
 
@@ -162,7 +218,14 @@ var rulesByName = map[string]*Rule{}
 // RegisterRule indexes a single Rule by name in a package-level registry.
 // Registered rules are automatically converted to Options in config.go's
 // AddCommandOptions, and are automatically tested by integration tests.
+// Rules must be registered prior to calling AddCommandOptions, typically from
+// an init function; see any of the check_*.go files for examples.
+// This function panics if a rule with the same name has already been
+// registered, since this is indicative of programmer error.
 func RegisterRule(rule Rule) {
+	if _, already := rulesByName[rule.Name]; already {
+		panic(fmt.Errorf("Linter rule %s has already been registered", rule.Name))
+	}
 	if rule.Description == "" || rule.DefaultSeverity == Severity("") {
 		rule.DefaultSeverity = SeverityIgnore
 	}
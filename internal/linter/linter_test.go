@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -82,6 +83,10 @@ func (s IntegrationSuite) TestCheckSchema(t *testing.T) {
 	result := CheckSchema(wsSchema, opts)
 	expected := expectedAnnotations(logicalSchema, s.d.Flavor())
 	compareAnnotations(t, expected, result)
+
+	// Checking concurrently should yield identical output
+	opts.Concurrency = 4
+	compareResults(t, result, CheckSchema(wsSchema, opts))
 }
 
 // TestCheckSchemaHidden runs a few hidden checkers against the dir
@@ -233,6 +238,381 @@ func (s IntegrationSuite) TestCheckSchemaCompression(t *testing.T) {
 	}
 }
 
+// TestCheckSchemaCustomRules confirms that rules registered outside of the
+// built-in check_*.go files are exposed as options and executed with their
+// configured severity. See testdata/customrule/.skeema for the configuration.
+func (s IntegrationSuite) TestCheckSchemaCustomRules(t *testing.T) {
+	createdAtChecker := func(table *tengo.Table, _ string, _ *tengo.Schema, _ Options) *Note {
+		for _, col := range table.Columns {
+			if col.Name == "created_at" {
+				return nil
+			}
+		}
+		return &Note{
+			Summary: "Missing created_at column",
+			Message: fmt.Sprintf("Table %s does not have a created_at column.", table.Name),
+		}
+	}
+	enumChecker := func(table *tengo.Table, createStatement string, _ *tengo.Schema, _ Options) (notes []Note) {
+		for _, col := range table.Columns {
+			if strings.HasPrefix(col.TypeInDB, "enum(") {
+				notes = append(notes, Note{
+					LineOffset: FindColumnLineOffset(col, createStatement),
+					Summary:    "ENUM column found",
+					Message:    fmt.Sprintf("Column %s uses an ENUM type, which is not permitted.", col.Name),
+				})
+			}
+		}
+		return notes
+	}
+	RegisterRule(Rule{
+		CheckerFunc:     TableBinaryChecker(createdAtChecker),
+		Name:            "created-at",
+		Description:     "Flag tables that lack a created_at column",
+		DefaultSeverity: SeverityWarning,
+	})
+	RegisterRule(Rule{
+		CheckerFunc:     TableChecker(enumChecker),
+		Name:            "enum-cols",
+		Description:     "Flag columns using ENUM types",
+		DefaultSeverity: SeverityIgnore,
+	})
+	defer func() {
+		delete(rulesByName, "created-at")
+		delete(rulesByName, "enum-cols")
+	}()
+
+	dir := getDir(t, "testdata/customrule")
+	opts, err := OptionsForDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+	if opts.RuleSeverity["created-at"] != SeverityWarning || opts.RuleSeverity["enum-cols"] != SeverityError {
+		t.Fatalf("Custom rule severity does not match expectation: %v", opts.RuleSeverity)
+	}
+	for name := range opts.RuleSeverity {
+		if name != "created-at" && name != "enum-cols" {
+			opts.RuleSeverity[name] = SeverityIgnore
+		}
+	}
+	opts.Flavor = s.d.Flavor()
+
+	logicalSchema := dir.LogicalSchemas[0]
+	wsOpts, err := workspace.OptionsForDir(dir, s.d.Instance)
+	if err != nil {
+		t.Fatalf("Unexpected error from workspace.OptionsForDir: %v", err)
+	}
+	wsSchema, err := workspace.ExecLogicalSchema(logicalSchema, wsOpts)
+	if err != nil {
+		t.Fatalf("Unexpected error from workspace.ExecLogicalSchema: %v", err)
+	} else if len(wsSchema.Failures) > 0 {
+		t.Fatalf("Unexpectedly found %d failing CREATE statements in %s/*.sql", len(wsSchema.Failures), dir)
+	}
+
+	result := CheckSchema(wsSchema, opts)
+	compareAnnotations(t, expectedAnnotations(logicalSchema, s.d.Flavor()), result)
+	if result.ErrorCount != 2 || result.WarningCount != 1 {
+		t.Errorf("Expected 2 errors and 1 warning, instead found %d errors and %d warnings", result.ErrorCount, result.WarningCount)
+	}
+
+	// Severity should also be configurable on the command-line, including
+	// disabling the rule entirely
+	dir = getDir(t, "testdata/customrule", "--lint-enum-cols=warning", "--skip-lint-created-at")
+	if opts, err = OptionsForDir(dir); err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+	if opts.RuleSeverity["created-at"] != SeverityIgnore || opts.RuleSeverity["enum-cols"] != SeverityWarning {
+		t.Errorf("Custom rule severity does not match expectation: %v", opts.RuleSeverity)
+	}
+
+	// Registering a duplicate rule name should panic
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected RegisterRule to panic on duplicate name, but it did not")
+		}
+	}()
+	RegisterRule(Rule{CheckerFunc: TableBinaryChecker(createdAtChecker), Name: "pk"})
+}
+
+// TestCheckSchemaNameCase provides additional coverage for the name-case rule,
+// confirming the exact messages and the effect of its supplemental options.
+func (s IntegrationSuite) TestCheckSchemaNameCase(t *testing.T) {
+	getResult := func(cliArgs ...string) *Result {
+		t.Helper()
+		dir := getDir(t, "testdata/namecase", cliArgs...)
+		opts, err := OptionsForDir(dir)
+		if err != nil {
+			t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+		}
+		forceOnlyRulesWarning(opts, "name-case")
+		opts.Flavor = s.d.Flavor()
+		wsOpts, err := workspace.OptionsForDir(dir, s.d.Instance)
+		if err != nil {
+			t.Fatalf("Unexpected error from workspace.OptionsForDir: %v", err)
+		}
+		wsSchema, err := workspace.ExecLogicalSchema(dir.LogicalSchemas[0], wsOpts)
+		if err != nil {
+			t.Fatalf("Unexpected error from workspace.ExecLogicalSchema: %v", err)
+		} else if len(wsSchema.Failures) > 0 {
+			t.Fatalf("Unexpectedly found %d failing CREATE statements in %s/*.sql", len(wsSchema.Failures), dir)
+		}
+		return CheckSchema(wsSchema, opts)
+	}
+
+	dir := getDir(t, "testdata/namecase")
+	result := getResult()
+	compareAnnotations(t, expectedAnnotations(dir.LogicalSchemas[0], s.d.Flavor()), result)
+	expectedMessages := map[string]bool{
+		"Table UserAccounts does not follow the configured naming convention: expected snake_case, such as user_accounts.":                        true,
+		"Column firstName of table UserAccounts does not follow the configured naming convention: expected snake_case, such as first_name.":       true,
+		"Column Group of table UserAccounts does not follow the configured naming convention: expected snake_case, such as group.":                true,
+		"Column legacy__code of table UserAccounts does not follow the configured naming convention: expected snake_case, such as legacy_code.":   true,
+		"Index idxFirstName of table UserAccounts does not follow the configured naming convention: expected snake_case, such as idx_first_name.": true,
+	}
+	for _, a := range result.Annotations {
+		if !expectedMessages[a.Note.Message] {
+			t.Errorf("Unexpected annotation message: %s", a.Note.Message)
+		}
+	}
+
+	// With lowercase style, only names containing uppercase letters are flagged;
+	// scoping to just columns should further reduce this to 2 annotations
+	if result := getResult("--name-case-style=lowercase"); len(result.Annotations) != 4 {
+		t.Errorf("Expected 4 annotations with lowercase style, instead found %d", len(result.Annotations))
+	}
+	if result := getResult("--name-case-style=lowercase", "--name-case-objects=column"); len(result.Annotations) != 2 {
+		t.Errorf("Expected 2 annotations with lowercase style for columns only, instead found %d", len(result.Annotations))
+	}
+
+	// Custom regexp style: require names to start with a letter, which flags the
+	// index 2fa_order but otherwise permits any case
+	result = getResult("--name-case-style='^[a-zA-Z]'")
+	if len(result.Annotations) != 1 {
+		t.Errorf("Expected 1 annotation with custom regexp style, instead found %d", len(result.Annotations))
+	} else if msg := result.Annotations[0].Note.Message; msg != "Index 2fa_order of table UserAccounts does not follow the configured naming convention: expected a name matching regular expression ^[a-zA-Z]." {
+		t.Errorf("Unexpected annotation message: %s", msg)
+	}
+}
+
+// TestCheckSchemaCrossSchemaFK confirms that the fk-unmanaged rule flags a
+// foreign key to a schema outside of the project, but not one to a schema
+// managed by a sibling dir.
+func (s IntegrationSuite) TestCheckSchemaCrossSchemaFK(t *testing.T) {
+	dir := getDir(t, "testdata/crossschema/orders")
+	opts, err := OptionsForDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+	forceOnlyRulesWarning(opts, "fk-unmanaged")
+	opts.Flavor = s.d.Flavor()
+	wsOpts, err := workspace.OptionsForDir(dir, s.d.Instance)
+	if err != nil {
+		t.Fatalf("Unexpected error from workspace.OptionsForDir: %v", err)
+	}
+	wsSchema, err := workspace.ExecLogicalSchema(dir.LogicalSchemas[0], wsOpts)
+	if err != nil {
+		t.Fatalf("Unexpected error from workspace.ExecLogicalSchema: %v", err)
+	} else if len(wsSchema.Failures) > 0 {
+		t.Fatalf("Unexpectedly found %d failing CREATE statements in %s/*.sql", len(wsSchema.Failures), dir)
+	}
+
+	// The referenced schema qualifier must be preserved in the introspected
+	// definition, since the workspace uses a different schema name
+	table := wsSchema.Tables[0]
+	if !strings.Contains(table.CreateStatement, "REFERENCES `lookup`.`countries`") || !strings.Contains(table.CreateStatement, "REFERENCES `billing`.`accounts`") {
+		t.Errorf("Expected CREATE TABLE to retain referenced schema names, instead found:\n%s", table.CreateStatement)
+	}
+
+	result := CheckSchema(wsSchema, opts)
+	compareAnnotations(t, expectedAnnotations(dir.LogicalSchemas[0], s.d.Flavor()), result)
+}
+
+func TestFKUnmanagedChecker(t *testing.T) {
+	dir := getDir(t, "testdata/crossschema/orders")
+	opts, err := OptionsForDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+	if opts.RuleSeverity["fk-unmanaged"] != SeverityWarning {
+		t.Errorf("Expected fk-unmanaged to default to warning, instead found %q", opts.RuleSeverity["fk-unmanaged"])
+	}
+	cases := map[string]bool{
+		"lookup":  true,
+		"LOOKUP":  true,
+		"orders":  true,
+		"billing": false,
+	}
+	for name, expected := range cases {
+		if managed, known := opts.ManagedSchema(name); managed != expected || !known {
+			t.Errorf("Unexpected result from ManagedSchema(%q): %t, %t", name, managed, known)
+		}
+	}
+
+	// Use the fixture's CREATE TABLE text for line offsets, along with a hand-
+	// built table equivalent to its introspected form
+	stmt := dir.LogicalSchemas[0].Creates[tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "orders"}]
+	table := &tengo.Table{
+		Name: "orders",
+		ForeignKeys: []*tengo.ForeignKey{
+			{Name: "orders_account", ReferencedSchemaName: "billing", ReferencedTableName: "accounts"},
+			{Name: "orders_country", ReferencedSchemaName: "lookup", ReferencedTableName: "countries"},
+			{Name: "orders_self", ReferencedTableName: "orders"},
+		},
+	}
+	notes := fkUnmanagedChecker(table, stmt.Text, nil, opts)
+	if len(notes) != 1 {
+		t.Fatalf("Expected 1 note, instead found %d: %+v", len(notes), notes)
+	}
+	if notes[0].LineOffset != 8 || !strings.Contains(notes[0].Message, "references table billing.accounts") {
+		t.Errorf("Unexpected note: %+v", notes[0])
+	}
+
+	// If managed schemas cannot be determined, nothing should be flagged
+	if notes := fkUnmanagedChecker(table, stmt.Text, nil, Options{}); len(notes) != 0 {
+		t.Errorf("Expected no notes without project information, instead found %+v", notes)
+	}
+}
+
+// TestCheckSchemaConcurrency confirms that checking objects concurrently
+// yields exactly the same output as checking them serially. This uses a
+// synthetic schema, so it does not require a database.
+func TestCheckSchemaConcurrency(t *testing.T) {
+	opts, err := OptionsForDir(getDir(t, "testdata/validcfg", syntheticSchemaArgs...))
+	if err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+	opts.Flavor = tengo.FlavorMySQL57
+
+	wsSchema := syntheticSchema(300)
+	opts.Concurrency = 1
+	serial := CheckSchema(wsSchema, opts)
+	if len(serial.Annotations) == 0 {
+		t.Fatal("Incorrect test setup: synthetic schema did not yield any annotations")
+	}
+	for _, concurrency := range []int{2, 8, 1000} {
+		opts.Concurrency = concurrency
+		for n := 0; n < 3; n++ {
+			compareResults(t, serial, CheckSchema(wsSchema, opts))
+		}
+	}
+
+	// Empty schema should not block or panic
+	opts.Concurrency = 8
+	if result := CheckSchema(syntheticSchema(0), opts); len(result.Annotations) > 0 {
+		t.Errorf("Expected no annotations from empty schema, instead found %d", len(result.Annotations))
+	}
+}
+
+func BenchmarkCheckSchema(b *testing.B) {
+	cmd := mybase.NewCommand("lintertest", "", "", nil)
+	util.AddGlobalOptions(cmd)
+	workspace.AddCommandOptions(cmd)
+	AddCommandOptions(cmd)
+	cmd.AddArg("environment", "production", false)
+	cfg, err := mybase.ParseCLI(cmd, append([]string{"lintertest"}, syntheticSchemaArgs...))
+	if err != nil {
+		b.Fatalf("Unexpected error from ParseCLI: %v", err)
+	}
+	dir, err := fs.ParseDir("testdata/validcfg", cfg)
+	if err != nil {
+		b.Fatalf("Unexpected error parsing dir: %v", err)
+	}
+	opts, err := OptionsForDir(dir)
+	if err != nil {
+		b.Fatalf("Unexpected error from OptionsForDir: %v", err)
+	}
+	opts.Flavor = tengo.FlavorMySQL57
+	wsSchema := syntheticSchema(5000)
+
+	concurrencies := []int{1}
+	if maxProcs := runtime.GOMAXPROCS(0); maxProcs > 1 {
+		concurrencies = append(concurrencies, maxProcs)
+	}
+	for _, concurrency := range concurrencies {
+		opts.Concurrency = concurrency
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				CheckSchema(wsSchema, opts)
+			}
+		})
+	}
+}
+
+// syntheticSchemaArgs are command-line args enabling the linter rules which
+// are triggered by objects in syntheticSchema.
+var syntheticSchemaArgs = []string{
+	"--lint-engine=warning",
+	"--lint-has-float=warning",
+	"--lint-has-time=warning",
+	"--lint-auto-inc=warning",
+	"--lint-name-case=warning",
+}
+
+// syntheticSchema returns a workspace.Schema containing the requested number
+// of tables, without needing a database. The tables vary in ways that trigger
+// several different linter rules, and each one is defined in its own file.
+func syntheticSchema(tableCount int) *workspace.Schema {
+	schema := &tengo.Schema{Name: "synthetic", CharSet: "utf8mb4", Collation: "utf8mb4_general_ci"}
+	logicalSchema := &fs.LogicalSchema{Creates: make(map[tengo.ObjectKey]*fs.Statement, tableCount)}
+	for n := 0; n < tableCount; n++ {
+		table := &tengo.Table{
+			Name:      fmt.Sprintf("tbl%05d", n),
+			Engine:    "InnoDB",
+			CharSet:   "utf8mb4",
+			Collation: "utf8mb4_general_ci",
+			Columns: []*tengo.Column{
+				{Name: "id", TypeInDB: "int(10) unsigned", AutoIncrement: true},
+				{Name: "name", TypeInDB: "varchar(40)", Nullable: true, Default: "NULL", CharSet: "utf8mb4", Collation: "utf8mb4_general_ci", CollationIsDefault: true},
+			},
+		}
+		if n%3 > 0 {
+			table.PrimaryKey = &tengo.Index{Name: "PRIMARY", PrimaryKey: true, Unique: true, Type: "BTREE", Parts: []tengo.IndexPart{{ColumnName: "id"}}}
+		}
+		switch n % 4 {
+		case 1:
+			table.Engine = "MyISAM"
+		case 2:
+			table.CharSet, table.Collation = "latin1", "latin1_swedish_ci"
+			table.Columns[1].CharSet, table.Columns[1].Collation = "latin1", "latin1_swedish_ci"
+		case 3:
+			table.Columns = append(table.Columns,
+				&tengo.Column{Name: "price", TypeInDB: "float"},
+				&tengo.Column{Name: "updated_at", TypeInDB: "timestamp", Default: "CURRENT_TIMESTAMP"},
+			)
+		}
+		table.CreateStatement = table.GeneratedCreateStatement(tengo.FlavorMySQL57)
+		schema.Tables = append(schema.Tables, table)
+
+		stmt := &fs.Statement{
+			File:       fmt.Sprintf("%s.sql", table.Name),
+			LineNo:     1,
+			Text:       table.CreateStatement + ";\n",
+			Type:       fs.StatementTypeCreate,
+			ObjectType: tengo.ObjectTypeTable,
+			ObjectName: table.Name,
+		}
+		logicalSchema.Creates[stmt.ObjectKey()] = stmt
+	}
+	return &workspace.Schema{Schema: schema, LogicalSchema: logicalSchema}
+}
+
+// compareResults confirms that actual contains exactly the same annotations as
+// expected, in the same order.
+func compareResults(t *testing.T, expected, actual *Result) {
+	t.Helper()
+	if expected.ErrorCount != actual.ErrorCount || expected.WarningCount != actual.WarningCount {
+		t.Errorf("Expected %d errors and %d warnings, instead found %d errors and %d warnings", expected.ErrorCount, expected.WarningCount, actual.ErrorCount, actual.WarningCount)
+	}
+	if len(expected.Annotations) != len(actual.Annotations) {
+		t.Fatalf("Expected %d annotations, instead found %d", len(expected.Annotations), len(actual.Annotations))
+	}
+	for n := range expected.Annotations {
+		if *expected.Annotations[n] != *actual.Annotations[n] {
+			t.Errorf("Annotation[%d]: expected %+v, instead found %+v", n, *expected.Annotations[n], *actual.Annotations[n])
+		}
+	}
+}
+
 func (s *IntegrationSuite) Setup(backend string) (err error) {
 	s.d, err = s.manager.GetOrCreateInstance(tengo.DockerizedInstanceOptions{
 		Name:              fmt.Sprintf("skeema-test-%s", strings.Replace(backend, ":", "-", -1)),
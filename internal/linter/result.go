@@ -218,7 +218,7 @@ func (r *Result) Fatal(err error) {
 
 // sortByFile implements the sort.Interface for []*Annotation to get a deterministic
 // sort order for Annotation lists.
-// Sorting is ordered by file name, line number, and problem name.
+// Sorting is ordered by file name, line number, problem name, and message.
 type sortByFile []*Annotation
 
 func (a sortByFile) Len() int      { return len(a) }
@@ -228,8 +228,10 @@ func (a sortByFile) Less(i, j int) bool {
 		return a[i].Statement.File < a[j].Statement.File
 	} else if a[i].LineNo() != a[j].LineNo() {
 		return a[i].LineNo() < a[j].LineNo()
+	} else if a[i].RuleName != a[j].RuleName {
+		return a[i].RuleName < a[j].RuleName
 	}
-	return a[i].RuleName < a[j].RuleName
+	return a[i].Message < a[j].Message
 }
 
 // Merge combines other into r's value in-place.
@@ -68,6 +68,7 @@ func TestResultSortByFile(t *testing.T) {
 			{RuleName: "pk", Note: Note{LineOffset: 0}, Statement: &fs.Statement{File: "aaa.sql", LineNo: 1, Text: "0"}},
 			{RuleName: "engine", Note: Note{LineOffset: 0}, Statement: &fs.Statement{File: "ccc.sql", LineNo: 10, Text: "5"}},
 			{RuleName: "engine", Note: Note{LineOffset: 3}, Statement: &fs.Statement{File: "aaa.sql", LineNo: 4, Text: "2"}},
+			{RuleName: "engine", Note: Note{LineOffset: 0, Message: "zzz"}, Statement: &fs.Statement{File: "ccc.sql", LineNo: 10, Text: "6"}},
 			{RuleName: "engine", Note: Note{LineOffset: 8}, Statement: &fs.Statement{File: "ccc.sql", LineNo: 1, Text: "4"}},
 		},
 	}
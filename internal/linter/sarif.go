@@ -0,0 +1,177 @@
+package linter
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Constants identifying the SARIF format version emitted by WriteSARIF
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// The types below represent the subset of the SARIF 2.1.0 object model used
+// in linter output.
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifLevel converts a Severity to the corresponding SARIF level string.
+func sarifLevel(sev Severity) string {
+	switch sev {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityIgnore:
+		return "none"
+	default:
+		return "note"
+	}
+}
+
+// WriteSARIF writes r's annotations to w as a SARIF 2.1.0 log. The rule
+// metadata lists every registered linter rule. File paths in result locations
+// are expressed relative to baseDir when possible. toolVersion is used as the
+// version of the reporting tool.
+func (r *Result) WriteSARIF(w io.Writer, toolVersion, baseDir string) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "skeema",
+				Version:        toolVersion,
+				InformationURI: "https://www.skeema.io",
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	names := make([]string, 0, len(rulesByName))
+	for name := range rulesByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rule := rulesByName[name]
+		desc := rule.Description
+		if rule.hidden() {
+			desc = "Hidden/internal linter rule"
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   name,
+			ShortDescription:     sarifMessage{Text: desc},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(rule.DefaultSeverity)},
+		})
+	}
+
+	if r != nil {
+		for _, a := range r.Annotations {
+			ruleID := a.RuleName
+			if ruleID == "" {
+				ruleID = "unparseable"
+			}
+			result := sarifResult{
+				RuleID:  ruleID,
+				Level:   sarifLevel(a.Severity),
+				Message: sarifMessage{Text: a.Message},
+			}
+			if a.Statement != nil && a.Statement.File != "" {
+				loc := sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: sarifURI(a.Statement.File, baseDir)},
+				}
+				if lineNo := a.LineNo(); lineNo > 0 {
+					loc.Region = &sarifRegion{StartLine: lineNo}
+				}
+				result.Locations = []sarifLocation{{PhysicalLocation: loc}}
+			}
+			run.Results = append(run.Results, result)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifURI returns a URI reference for filePath. If filePath is within
+// baseDir, a relative reference is returned; otherwise, an absolute file URI is
+// returned.
+func sarifURI(filePath, baseDir string) string {
+	if rel, err := filepath.Rel(baseDir, filePath); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	if abs, err := filepath.Abs(filePath); err == nil {
+		filePath = abs
+	}
+	filePath = filepath.ToSlash(filePath)
+	if !strings.HasPrefix(filePath, "/") { // Windows drive letter paths
+		filePath = "/" + filePath
+	}
+	return "file://" + filePath
+}
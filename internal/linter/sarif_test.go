@@ -0,0 +1,148 @@
+package linter
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/tengo"
+)
+
+func TestResultWriteSARIF(t *testing.T) {
+	dir := getDir(t, "testdata/validcfg")
+	annotations := expectedAnnotations(dir.LogicalSchemas[0], tengo.FlavorUnknown)
+	if len(annotations) == 0 {
+		t.Fatal("Incorrect test setup: no annotations found in testdata/validcfg")
+	}
+	result := &Result{}
+	for n, a := range annotations {
+		sev := SeverityWarning
+		if n%2 == 0 {
+			sev = SeverityError
+		}
+		a.Message = "problem found by " + a.RuleName
+		result.Annotate(a.Statement, sev, a.RuleName, a.Note)
+	}
+	result.SortByFile()
+
+	var buf bytes.Buffer
+	if err := result.WriteSARIF(&buf, "1.2.3", dir.Path); err != nil {
+		t.Fatalf("Unexpected error from WriteSARIF: %v", err)
+	}
+	log := validateSARIF(t, buf.Bytes(), len(result.Annotations))
+	for n, sr := range log.Runs[0].Results {
+		a := result.Annotations[n]
+		expectLevel := map[Severity]string{SeverityError: "error", SeverityWarning: "warning"}[a.Severity]
+		if sr.RuleID != a.RuleName || sr.Level != expectLevel {
+			t.Errorf("Result[%d]: expected ruleId %q and level %q; instead found %q and %q", n, a.RuleName, expectLevel, sr.RuleID, sr.Level)
+		}
+		loc := sr.Locations[0].PhysicalLocation
+		if expectURI := filepath.Base(a.Statement.File); loc.ArtifactLocation.URI != expectURI {
+			t.Errorf("Result[%d]: expected uri %q, instead found %q", n, expectURI, loc.ArtifactLocation.URI)
+		}
+		if loc.Region.StartLine != a.LineNo() {
+			t.Errorf("Result[%d]: expected startLine %d, instead found %d", n, a.LineNo(), loc.Region.StartLine)
+		}
+	}
+
+	// A result without a file location should omit locations entirely, and
+	// an empty result should still produce a valid document
+	result = &Result{}
+	result.Annotate(&fs.Statement{Text: "bork bork bork"}, SeverityWarning, "", Note{Message: "Ignoring unsupported or unparseable SQL statement"})
+	buf.Reset()
+	if err := result.WriteSARIF(&buf, "1.2.3", dir.Path); err != nil {
+		t.Fatalf("Unexpected error from WriteSARIF: %v", err)
+	}
+	if log = validateSARIF(t, buf.Bytes(), 1); log.Runs[0].Results[0].Locations != nil {
+		t.Errorf("Expected no locations for statement without a file, instead found %+v", log.Runs[0].Results[0].Locations)
+	}
+	buf.Reset()
+	if err := (&Result{}).WriteSARIF(&buf, "1.2.3", dir.Path); err != nil {
+		t.Fatalf("Unexpected error from WriteSARIF: %v", err)
+	}
+	validateSARIF(t, buf.Bytes(), 0)
+}
+
+func TestSARIFURI(t *testing.T) {
+	base := filepath.FromSlash("/var/repo")
+	cases := map[string]string{
+		"/var/repo/foo.sql":        "foo.sql",
+		"/var/repo/sub/dir/x.sql":  "sub/dir/x.sql",
+		"/var/elsewhere/other.sql": "file:///var/elsewhere/other.sql",
+	}
+	for input, expected := range cases {
+		if actual := sarifURI(filepath.FromSlash(input), base); !strings.HasSuffix(actual, expected) {
+			t.Errorf("sarifURI(%q): expected %q, found %q", input, expected, actual)
+		}
+	}
+}
+
+// validateSARIF unmarshals a SARIF document and confirms it contains the
+// fields required by the SARIF 2.1.0 schema, as well as one result per
+// expected annotation and rule metadata for every registered rule.
+func validateSARIF(t *testing.T, doc []byte, expectResults int) (log sarifLog) {
+	t.Helper()
+
+	// Check required properties generically first, since the typed struct would
+	// silently hydrate zero values for missing fields
+	var raw map[string]interface{}
+	if err := json.Unmarshal(doc, &raw); err != nil {
+		t.Fatalf("SARIF output is not valid JSON: %v", err)
+	}
+	for _, field := range []string{"version", "runs"} {
+		if _, ok := raw[field]; !ok {
+			t.Fatalf("SARIF output is missing required top-level property %q", field)
+		}
+	}
+	if err := json.Unmarshal(doc, &log); err != nil {
+		t.Fatalf("SARIF output could not be unmarshaled: %v", err)
+	}
+	if log.Version != "2.1.0" {
+		t.Errorf("Expected SARIF version 2.1.0, instead found %q", log.Version)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("Expected exactly 1 run, instead found %d", len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name == "" {
+		t.Error("SARIF run is missing required tool.driver.name")
+	}
+
+	seenRules := make(map[string]bool)
+	for _, rule := range run.Tool.Driver.Rules {
+		if rule.ID == "" || rule.ShortDescription.Text == "" {
+			t.Errorf("SARIF rule missing id or shortDescription.text: %+v", rule)
+		}
+		seenRules[rule.ID] = true
+	}
+	for name := range rulesByName {
+		if !seenRules[name] {
+			t.Errorf("Expected rule %s to be present in SARIF rule metadata, but it was not", name)
+		}
+	}
+
+	if len(run.Results) != expectResults {
+		t.Fatalf("Expected %d results, instead found %d", expectResults, len(run.Results))
+	}
+	validLevels := map[string]bool{"none": true, "note": true, "warning": true, "error": true}
+	for n, sr := range run.Results {
+		if sr.Message.Text == "" || sr.RuleID == "" {
+			t.Errorf("Result[%d] is missing message.text or ruleId: %+v", n, sr)
+		}
+		if !validLevels[sr.Level] {
+			t.Errorf("Result[%d] has invalid level %q", n, sr.Level)
+		}
+		for _, loc := range sr.Locations {
+			if loc.PhysicalLocation.ArtifactLocation.URI == "" {
+				t.Errorf("Result[%d] has a location without artifactLocation.uri", n)
+			}
+			if loc.PhysicalLocation.Region != nil && loc.PhysicalLocation.Region.StartLine < 1 {
+				t.Errorf("Result[%d] has invalid region.startLine %d", n, loc.PhysicalLocation.Region.StartLine)
+			}
+		}
+	}
+	return log
+}
@@ -0,0 +1,5 @@
+CREATE TABLE countries (
+  code char(2) NOT NULL,
+  name varchar(60) NOT NULL,
+  PRIMARY KEY (code)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
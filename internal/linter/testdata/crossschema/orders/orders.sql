@@ -0,0 +1,10 @@
+CREATE TABLE orders (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  country_code char(2) NOT NULL,
+  account_id int unsigned NOT NULL,
+  PRIMARY KEY (id),
+  KEY country_code (country_code),
+  KEY account_id (account_id),
+  CONSTRAINT orders_country FOREIGN KEY (country_code) REFERENCES lookup.countries (code),
+  CONSTRAINT orders_account FOREIGN KEY (account_id) REFERENCES `billing`.`accounts` (id) /* annotations: fk-unmanaged */
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
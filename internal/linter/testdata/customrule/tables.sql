@@ -0,0 +1,17 @@
+# Tables testing behavior of custom linter rules registered by tests in
+# linter_test.go, rather than any of the built-in rules.
+
+CREATE TABLE posts (
+	id int unsigned NOT NULL AUTO_INCREMENT,
+	status enum('draft','live') NOT NULL, /* annotations: enum-cols */
+	visibility enum('public','private') NOT NULL, /* annotations: enum-cols */
+	created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id)
+) ENGINE=InnoDB;
+
+CREATE TABLE comments ( /* annotations: created-at */
+	id int unsigned NOT NULL AUTO_INCREMENT,
+	post_id int unsigned NOT NULL,
+	body text,
+	PRIMARY KEY (id)
+) ENGINE=InnoDB;
@@ -0,0 +1,22 @@
+# Tables testing behavior of the name-case linter rule, using its default
+# configuration of snake_case for tables, columns, and indexes.
+
+CREATE TABLE UserAccounts ( /* annotations: name-case */
+	id int unsigned NOT NULL AUTO_INCREMENT,
+	firstName varchar(30) NOT NULL, /* annotations: name-case */
+	address_line2 varchar(100), # digits are fine
+	`order` int unsigned NOT NULL, # quoted reserved word is fine
+	`Group` varchar(20), /* annotations: name-case */
+	legacy__code char(4), /* annotations: name-case */
+	PRIMARY KEY (id),
+	KEY idxFirstName (firstName), /* annotations: name-case */
+	KEY `2fa_order` (`order`)
+) ENGINE=InnoDB;
+
+CREATE TABLE order_items_2 (
+	id int unsigned NOT NULL AUTO_INCREMENT,
+	order_id int unsigned NOT NULL,
+	sku_v2 varchar(20) NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY order_sku (order_id, sku_v2)
+) ENGINE=InnoDB;
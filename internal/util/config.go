@@ -7,10 +7,12 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/skeema/mybase"
+	"github.com/skeema/tengo"
 	terminal "golang.org/x/term"
 )
 
@@ -29,19 +31,36 @@ func AddGlobalOptions(cmd *mybase.Command) {
 	// Visible global options
 	cmd.AddOptions("global",
 		mybase.StringOption("user", 'u', "root", "Username to connect to database host"),
-		mybase.StringOption("password", 'p', "", "Password for database user; omit value to prompt from TTY (default no password)").ValueOptional(),
+		mybase.StringOption("password", 'p', "", "Password for database user; omit value to prompt from TTY (default no password)").ValueOptional().ValueFromFile(),
 		mybase.StringOption("host-wrapper", 'H', "", "External bin to shell out to for host lookup; see manual for template vars"),
+		mybase.StringOption("ssh", 0, "", "Connect to database hosts through an SSH tunnel via this bastion, in format [user@]host[:port]"),
+		mybase.StringOption("ssh-key", 0, "", "Path to private key for --ssh; if omitted, ssh-agent and default identities are used").ValueIsPath(),
+		mybase.StringOption("socks5", 0, "", "Connect to database hosts through a SOCKS5 proxy at this host:port"),
+		mybase.StringOption("socks5-user", 0, "", "Username for authenticating to --socks5 proxy"),
+		mybase.StringOption("socks5-pass", 0, "", "Password for authenticating to --socks5 proxy").ValueFromFile(),
 		mybase.StringOption("connect-options", 'o', "", "Comma-separated session options to set upon connecting to each database instance"),
-		mybase.StringOption("ignore-schema", 0, "", "Ignore schemas that match regex"),
-		mybase.StringOption("ignore-table", 0, "", "Ignore tables that match regex"),
+		mybase.EnumOption("ssl-mode", 0, "", `TLS mode for database connections: "disabled", "preferred", "required", "verify-ca", or "verify-identity"`, "disabled", "preferred", "required", "verify-ca", "verify-identity"),
+		mybase.StringOption("ssl-ca", 0, "", "Path to PEM file of CA certs for verifying database server certs").ValueIsPath(),
+		mybase.StringOption("ssl-cert", 0, "", "Path to PEM file of client cert for TLS authentication").ValueIsPath(),
+		mybase.StringOption("ssl-key", 0, "", "Path to PEM file of private key for --ssl-cert").ValueIsPath(),
+		mybase.StringOption("connect-retries", 0, "0", "Number of times to retry connecting to each database instance after a transient failure"),
+		mybase.StringOption("connect-retry-delay", 0, "500ms", "Initial delay between connection retries, doubling after each retry"),
+		mybase.StringOption("ignore-schema", 0, "", "Ignore schemas that match regex; may be repeated").Repeatable("|"),
+		mybase.StringOption("ignore-table", 0, "", "Ignore tables that match regex; may be repeated").Repeatable("|"),
+		mybase.StringOption("object-types", 0, "", "Only manage these comma-separated object types, e.g. \"table,view\" (default all types)"),
+		mybase.EnumOption("split-by", 0, "", "Distribute each schema's *.sql files into subdirs; only \"firstletter\" is supported", "firstletter"),
+		mybase.StringOption("views-dir", 0, "", "Name of subdir for storing each schema's views, separately from other objects"),
 		mybase.BoolOption("debug", 0, false, "Enable debug logging"),
 		mybase.BoolOption("my-cnf", 0, true, "Parse ~/.my.cnf for configuration"),
 	)
 }
 
 // AddGlobalConfigFiles takes the mybase.Config generated from the CLI and adds
-// global option files as sources.
+// global option files as sources. It also adds SKEEMA_-prefixed environment
+// variables as a source, which override all option files but not the CLI.
 func AddGlobalConfigFiles(cfg *mybase.Config) {
+	cfg.AddOverrideSource(mybase.NewEnvProvider("SKEEMA_"))
+
 	globalFilePaths := make([]string, 0, 4)
 
 	// Avoid using "real" global paths in test logic. Otherwise, if the user
@@ -92,7 +111,8 @@ func AddGlobalConfigFiles(cfg *mybase.Config) {
 
 // ProcessSpecialGlobalOptions performs special handling of global options with
 // unusual semantics -- handling restricted placement of host and schema;
-// obtaining a password from MYSQL_PWD or STDIN; enable debug logging.
+// obtaining a password from MYSQL_PWD or STDIN; enable debug logging;
+// validating TLS options.
 func ProcessSpecialGlobalOptions(cfg *mybase.Config) error {
 	// The host and schema options are special -- most commands only expect
 	// to find them when recursively crawling directory configs. So if these
@@ -128,7 +148,14 @@ func ProcessSpecialGlobalOptions(cfg *mybase.Config) error {
 		log.SetLevel(log.DebugLevel)
 	}
 
-	return nil
+	// Validate any TLS options set globally, including loading the client cert
+	// and key, so that problems are reported before connecting to anything
+	tlsOpts, err := TLSOptionsForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	_, err = tlsOpts.TLSConfig()
+	return err
 }
 
 // PromptPassword reads a password from STDIN without echoing the typed
@@ -146,6 +173,182 @@ func PromptPassword() (string, error) {
 	return string(bytePassword), nil
 }
 
+// RedactedValue is substituted for passwords and other sensitive option values
+// in logging and error output.
+const RedactedValue = "xxxxx"
+
+// RedactDSN returns a copy of dsn, which should be formatted according to
+// go-sql-driver/mysql, with any password replaced by RedactedValue. This makes
+// the DSN safe for inclusion in logging or error output.
+func RedactDSN(dsn string) string {
+	// Mirror the driver's parsing logic: the user/pass portion ends at the last
+	// @ prior to the last slash, and the password begins after the first colon
+	// in that portion
+	slash := strings.LastIndex(dsn, "/")
+	if slash < 0 {
+		return dsn
+	}
+	at := strings.LastIndex(dsn[:slash], "@")
+	if at < 0 {
+		return dsn
+	}
+	colon := strings.Index(dsn[:at], ":")
+	if colon < 0 {
+		return dsn
+	}
+	return dsn[:colon+1] + RedactedValue + dsn[at:]
+}
+
+// reDSNAddress matches the portion of a DSN, formatted according to
+// go-sql-driver/mysql, which begins at the @ following the user:pass portion
+// and ends at the slash preceding the database name. The network type and
+// address are optional, as in "user:pass@/dbname".
+var reDSNAddress = regexp.MustCompile(`@(?:\w+\([^()]*\))?/`)
+
+// RedactSecrets returns a copy of input with sensitive option values redacted
+// to RedactedValue. Sensitive options consist of the password option, as well
+// as any other option which permits its value to be read from a file.
+// The password portion of any DSN is redacted, as are option assignments of
+// the form name=value or --name=value for sensitive options. Additionally, the
+// configured values of sensitive options in cfg are redacted wherever else
+// they appear. Empty values, including quoted-empty values, are never redacted.
+func RedactSecrets(cfg *mybase.Config, input string) string {
+	var names, values []string
+	for name, opt := range cfg.CLI.Command.Options() {
+		if name == "password" || opt.FileOptionName != "" {
+			names = append(names, regexp.QuoteMeta(name))
+			if value := cfg.Get(name); !isEmptySecret(value) {
+				values = append(values, value)
+			}
+		}
+	}
+	input = redactDSNPasswords(input)
+	if len(names) > 0 {
+		sort.Strings(names)
+		reAssignment := regexp.MustCompile(`(?:^|[^\w-])(?:--)?(?:` + strings.Join(names, "|") + `)=('[^']*'|"[^"]*"|[^\s&]+)`)
+		input = redactMatches(reAssignment, input)
+	}
+
+	// Replace longer values first, in case one value contains another
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, value := range values {
+		input = strings.Replace(input, value, RedactedValue, -1)
+	}
+	return input
+}
+
+// redactDSNPasswords returns a copy of input with the password portion of any
+// DSNs replaced by RedactedValue. As in RedactDSN, a password extends from the
+// first colon of the user:pass portion to the last @ before the DSN's address,
+// so passwords may contain @ or whitespace.
+func redactDSNPasswords(input string) string {
+	var b strings.Builder
+	var last int
+	for _, loc := range reDSNAddress.FindAllStringIndex(input, -1) {
+		at := loc[0]
+		// Walk backwards one word at a time, until finding a word containing a
+		// colon, which marks the user:pass boundary. A colon ending a word before
+		// whitespace (as in "Error 1045: ...") is prose, not a DSN.
+		for wordEnd := at; wordEnd > last; {
+			wordStart := last + strings.LastIndexAny(input[last:wordEnd], " \t\r\n=('\"") + 1
+			if colon := strings.IndexByte(input[wordStart:wordEnd], ':'); colon >= 0 {
+				start := wordStart + colon + 1
+				if start == wordEnd && wordEnd != at {
+					break
+				}
+				if !isEmptySecret(input[start:at]) {
+					b.WriteString(input[last:start])
+					b.WriteString(RedactedValue)
+					last = at
+				}
+				break
+			}
+			wordEnd = wordStart - 1
+		}
+		b.WriteString(input[last:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(input[last:])
+	return b.String()
+}
+
+// redactMatches returns a copy of input with the first capture group of each
+// match of re replaced by RedactedValue, unless the captured value is empty or
+// quoted-empty.
+func redactMatches(re *regexp.Regexp, input string) string {
+	var b strings.Builder
+	var last int
+	for _, loc := range re.FindAllStringSubmatchIndex(input, -1) {
+		start, end := loc[2], loc[3]
+		if isEmptySecret(input[start:end]) {
+			continue
+		}
+		b.WriteString(input[last:start])
+		b.WriteString(RedactedValue)
+		last = end
+	}
+	b.WriteString(input[last:])
+	return b.String()
+}
+
+// isEmptySecret returns true if value is blank or quoted-empty, in which case
+// there is nothing to redact.
+func isEmptySecret(value string) bool {
+	return value == "" || value == "''" || value == `""`
+}
+
+// RedactError returns an error equivalent to err, but with any sensitive
+// option values from cfg redacted from its message; see RedactSecrets. If the
+// message contains no sensitive values, err is returned as-is, preserving its
+// type.
+func RedactError(cfg *mybase.Config, err error) error {
+	if err == nil {
+		return nil
+	}
+	if msg := RedactSecrets(cfg, err.Error()); msg != err.Error() {
+		return errors.New(msg)
+	}
+	return err
+}
+
+// objectTypeNames maps each value permitted in the object-types option to its
+// corresponding object type.
+var objectTypeNames = map[string]tengo.ObjectType{
+	"table":     tengo.ObjectTypeTable,
+	"view":      tengo.ObjectTypeView,
+	"procedure": tengo.ObjectTypeProc,
+	"proc":      tengo.ObjectTypeProc,
+	"function":  tengo.ObjectTypeFunc,
+	"func":      tengo.ObjectTypeFunc,
+	"trigger":   tengo.ObjectTypeTrigger,
+	"event":     tengo.ObjectTypeEvent,
+	"sequence":  tengo.ObjectTypeSequence,
+}
+
+// ObjectTypes returns the set of object types managed by Skeema, as specified
+// by the object-types option in cfg. If the option is not set, all object
+// types are managed, and a nil map is returned. Object type names may be
+// singular or plural. Schemas themselves are always managed.
+func ObjectTypes(cfg *mybase.Config) (map[tengo.ObjectType]bool, error) {
+	names := cfg.GetSlice("object-types", ',', false)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	types := map[tengo.ObjectType]bool{tengo.ObjectTypeDatabase: true}
+	for _, name := range names {
+		name = strings.ToLower(name)
+		ot, ok := objectTypeNames[name]
+		if !ok {
+			ot, ok = objectTypeNames[strings.TrimSuffix(name, "s")]
+		}
+		if !ok {
+			return nil, fmt.Errorf("Option object-types: invalid object type %q", name)
+		}
+		types[ot] = true
+	}
+	return types, nil
+}
+
 // SplitConnectOptions takes a string containing a comma-separated list of
 // connection options (typically obtained from the "connect-options" option)
 // and splits them into a map of individual key: value strings. This function
@@ -1,13 +1,18 @@
 package util
 
 import (
+	"bytes"
+	"errors"
 	"io/ioutil"
+	"log"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/skeema/mybase"
+	"github.com/skeema/tengo"
 )
 
 func TestAddGlobalConfigFiles(t *testing.T) {
@@ -70,6 +75,506 @@ func TestAddGlobalConfigFiles(t *testing.T) {
 	}
 }
 
+func TestEnvOptions(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmdSuite.AddSubCommand(mybase.NewCommand("diff", "", "", nil))
+
+	os.Setenv("SKEEMA_USER", "envuser")
+	os.Setenv("SKEEMA_CONNECT_OPTIONS", "wait_timeout=10")
+	defer func() {
+		os.Unsetenv("SKEEMA_USER")
+		os.Unsetenv("SKEEMA_CONNECT_OPTIONS")
+	}()
+
+	// Option present in env but not on CLI: env value should be used, even over
+	// values from option files (including ones added after the env source)
+	fakeFileSource := mybase.SimpleSource(map[string]string{
+		"user": "fileuser",
+	})
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff")
+	AddGlobalConfigFiles(cfg)
+	cfg.AddSource(fakeFileSource)
+	if actual := cfg.Get("user"); actual != "envuser" {
+		t.Errorf("Expected user to come from env; instead found %s", actual)
+	}
+	if actual := cfg.Get("connect-options"); actual != "wait_timeout=10" {
+		t.Errorf("Expected connect-options to come from env; instead found %s", actual)
+	}
+	if src, ok := cfg.Source("user").(*mybase.EnvProvider); !ok {
+		t.Errorf("Expected source of user to be *mybase.EnvProvider; instead found %T", src)
+	}
+
+	// Option present in both env and CLI: CLI should win out
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --user=cliuser")
+	AddGlobalConfigFiles(cfg)
+	cfg.AddSource(fakeFileSource)
+	if actual := cfg.Get("user"); actual != "cliuser" {
+		t.Errorf("Expected user to come from CLI; instead found %s", actual)
+	}
+
+	// Blank env var should be treated as unset
+	os.Setenv("SKEEMA_USER", "")
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff")
+	AddGlobalConfigFiles(cfg)
+	cfg.AddSource(fakeFileSource)
+	if actual := cfg.Get("user"); actual != "fileuser" {
+		t.Errorf("Expected user to come from file; instead found %s", actual)
+	}
+}
+
+func TestRepeatableOptions(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmdSuite.AddSubCommand(mybase.NewCommand("diff", "", "", nil))
+	fakeFileSource := mybase.SimpleSource(map[string]string{
+		"ignore-table": "^_",
+		"user":         "fileuser",
+	})
+
+	// Single value from file only
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff", fakeFileSource)
+	if actual := cfg.Get("ignore-table"); actual != "^_" {
+		t.Errorf("Expected ignore-table to come from file; instead found %q", actual)
+	}
+
+	// Repeated on CLI: values accumulate, and override the file's value entirely
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --ignore-table=^foo --ignore-table ^bar$ --ignore-schema=x", fakeFileSource)
+	if actual := cfg.Get("ignore-table"); actual != "^foo|^bar$" {
+		t.Errorf("Expected ignore-table values to accumulate; instead found %q", actual)
+	}
+	if re, err := cfg.GetRegexp("ignore-table"); err != nil {
+		t.Errorf("Unexpected error from GetRegexp: %v", err)
+	} else if !re.MatchString("bar") || !re.MatchString("foobar") || re.MatchString("_hello") {
+		t.Errorf("Accumulated ignore-table regexp %s does not match as expected", re)
+	}
+	if actual := cfg.Get("ignore-schema"); actual != "x" {
+		t.Errorf("Expected ignore-schema to be unaffected by ignore-table; instead found %q", actual)
+	}
+
+	// Empty value clears previous values
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --ignore-table=^foo --ignore-table= --ignore-table=^bar", fakeFileSource)
+	if actual := cfg.Get("ignore-table"); actual != "^bar" {
+		t.Errorf("Expected empty value to reset ignore-table; instead found %q", actual)
+	}
+
+	// Non-repeatable options still just use the last value
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --user=one -u two", fakeFileSource)
+	if actual := cfg.Get("user"); actual != "two" {
+		t.Errorf("Expected last value of non-repeatable option to be used; instead found %q", actual)
+	}
+}
+
+func TestOptionAbbreviations(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmd := mybase.NewCommand("push", "", "", nil)
+	cmd.AddOption(mybase.BoolOption("allow-unsafe", 0, false, "Permit unsafe changes"))
+	cmd.AddOption(mybase.StringOption("alter-wrapper", 'x', "", "External bin to shell out to for ALTER TABLE"))
+	cmd.AddOption(mybase.StringOption("alter-wrapper-min-size", 0, "0", "Ignore alter-wrapper for tables smaller than this size"))
+	cmdSuite.AddSubCommand(cmd)
+
+	// Unique prefix resolves to the corresponding option, including with
+	// negation prefixes
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema push --allow-un --connect-opt=wait_timeout=5 --skip-my")
+	if !cfg.GetBool("allow-unsafe") {
+		t.Error("Expected --allow-un to enable allow-unsafe, but it did not")
+	}
+	if actual := cfg.Get("connect-options"); actual != "wait_timeout=5" {
+		t.Errorf("Expected --connect-opt to set connect-options; instead found %q", actual)
+	}
+	if cfg.GetBool("my-cnf") {
+		t.Error("Expected --skip-my to disable my-cnf, but it did not")
+	}
+
+	// Exact match takes priority, even if also a prefix of other options
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema push --alter-wrapper=foo --host=bar")
+	if actual := cfg.Get("alter-wrapper"); actual != "foo" {
+		t.Errorf("Expected --alter-wrapper to set alter-wrapper exactly; instead found %q", actual)
+	} else if cfg.Changed("alter-wrapper-min-size") {
+		t.Error("Expected alter-wrapper-min-size to be unchanged, but it was changed")
+	}
+	if actual := cfg.Get("host"); actual != "bar" || cfg.Changed("host-wrapper") {
+		t.Errorf("Expected --host to set host exactly; instead found host=%q host-wrapper=%q", actual, cfg.Get("host-wrapper"))
+	}
+
+	// Ambiguous prefix returns an error listing candidates
+	_, err := mybase.ParseCLI(cmdSuite, []string{"skeema", "push", "--alter-w=foo"})
+	if aerr, ok := err.(mybase.OptionAmbiguousError); !ok {
+		t.Errorf("Expected ambiguous prefix to return OptionAmbiguousError; instead found %T %v", err, err)
+	} else if !reflect.DeepEqual(aerr.Candidates, []string{"alter-wrapper", "alter-wrapper-min-size"}) {
+		t.Errorf("Unexpected candidate list in error: %v", aerr.Candidates)
+	}
+
+	// No match still returns an OptionNotDefinedError
+	_, err = mybase.ParseCLI(cmdSuite, []string{"skeema", "push", "--nope"})
+	if _, ok := err.(mybase.OptionNotDefinedError); !ok {
+		t.Errorf("Expected unknown option to return OptionNotDefinedError; instead found %T %v", err, err)
+	}
+}
+
+func TestDeprecatedOptions(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmd := mybase.NewCommand("diff", "", "", nil)
+	cmd.AddOption(mybase.StringOption("frob-level", 0, "1", "Replacement option"))
+	cmd.AddOption(mybase.StringOption("frob-amount", 0, "1", "Deprecated option").Deprecated("frob-level"))
+	cmdSuite.AddSubCommand(cmd)
+	if !cmd.Options()["frob-amount"].HiddenOnCLI {
+		t.Error("Expected deprecated option to be hidden, but it was not")
+	}
+
+	var logBuf bytes.Buffer
+	log.SetOutput(&logBuf)
+	defer log.SetOutput(os.Stderr)
+	expectWarning := func(contains ...string) {
+		t.Helper()
+		output := logBuf.String()
+		logBuf.Reset()
+		if strings.Count(output, "\n") != 1 {
+			t.Errorf("Expected exactly one line of warning output, instead found %q", output)
+		}
+		for _, substring := range contains {
+			if !strings.Contains(output, substring) {
+				t.Errorf("Expected warning output to contain %q, instead found %q", substring, output)
+			}
+		}
+	}
+
+	// Deprecated option on CLI only: value used for replacement option
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --frob-amount=5")
+	if actual := cfg.Get("frob-level"); actual != "5" || !cfg.OnCLI("frob-level") || !cfg.Changed("frob-level") {
+		t.Errorf("Expected frob-level to be mapped from deprecated option on CLI; instead found %q", actual)
+	}
+	expectWarning("frob-amount", "command line", "please use frob-level instead")
+
+	// Subsequent lookups should not repeat the warning
+	cfg.MarkDirty()
+	cfg.Get("frob-level")
+	if logBuf.Len() > 0 {
+		t.Errorf("Expected warning to only be logged once, but found additional output %q", logBuf.String())
+	}
+
+	// Deprecated option in option file only
+	ioutil.WriteFile("fake-deprecated.cnf", []byte("frob-amount=7\n"), 0600)
+	ioutil.WriteFile("fake-deprecated-both.cnf", []byte("frob-amount=7\nfrob-level=8\n"), 0600)
+	defer func() {
+		os.Remove("fake-deprecated.cnf")
+		os.Remove("fake-deprecated-both.cnf")
+	}()
+	getFileConfig := func(fileName, commandLine string) *mybase.Config {
+		t.Helper()
+		cfg := mybase.ParseFakeCLI(t, cmdSuite, commandLine)
+		f := mybase.NewFile(fileName)
+		if err := f.Parse(cfg); err != nil {
+			t.Fatalf("Unexpected error parsing %s: %v", fileName, err)
+		}
+		cfg.AddSource(f)
+		return cfg
+	}
+	cfg = getFileConfig("fake-deprecated.cnf", "skeema diff")
+	if actual := cfg.Get("frob-level"); actual != "7" {
+		t.Errorf("Expected frob-level to be mapped from deprecated option in file; instead found %q", actual)
+	}
+	expectWarning("frob-amount", "fake-deprecated.cnf", "please use frob-level instead")
+
+	// Both supplied: replacement option wins, even if the deprecated option came
+	// from a higher-priority source
+	cfg = getFileConfig("fake-deprecated-both.cnf", "skeema diff")
+	if actual := cfg.Get("frob-level"); actual != "8" {
+		t.Errorf("Expected frob-level to take precedence over deprecated option; instead found %q", actual)
+	}
+	expectWarning("frob-amount", "fake-deprecated-both.cnf", "being ignored", "frob-level")
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --frob-amount=5", mybase.SimpleSource(map[string]string{"frob-level": "9"}))
+	if actual := cfg.Get("frob-level"); actual != "9" {
+		t.Errorf("Expected frob-level to take precedence over deprecated option; instead found %q", actual)
+	}
+	expectWarning("frob-amount", "being ignored")
+
+	// Deprecated options are not considered when resolving abbreviations
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --frob=3")
+	if actual := cfg.Get("frob-level"); actual != "3" {
+		t.Errorf("Expected abbreviation to resolve to frob-level; instead found %q", actual)
+	}
+	if logBuf.Len() > 0 {
+		t.Errorf("Expected no warnings when only using replacement option, but found %q", logBuf.String())
+	}
+}
+
+func TestEnumOptions(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmd := mybase.NewCommand("diff", "", "", nil)
+	cmd.AddOption(mybase.EnumOption("frob-mode", 0, "auto", "Enum option", "On", "off", "Auto"))
+	cmd.AddOption(mybase.EnumOption("blank-mode", 0, "", "Enum option with blank default", "fast", "slow").ValueOptional())
+	cmdSuite.AddSubCommand(cmd)
+
+	// Mixed-case input should be accepted, and GetEnum returns the declared
+	// casing of the value
+	cases := map[string]string{
+		"skeema diff":                  "Auto",
+		"skeema diff --frob-mode=ON":   "On",
+		"skeema diff --frob-mode on":   "On",
+		"skeema diff --frob-mode=OfF":  "off",
+		"skeema diff --frob-mode=AUTO": "Auto",
+	}
+	for cmdLine, expected := range cases {
+		cfg := mybase.ParseFakeCLI(t, cmdSuite, cmdLine)
+		if actual, err := cfg.GetEnum("frob-mode"); actual != expected || err != nil {
+			t.Errorf("Expected GetEnum on %q to return %q, nil; instead found %q, %v", cmdLine, expected, actual, err)
+		}
+	}
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --blank-mode --frob-mode=off")
+	if actual, err := cfg.GetEnum("blank-mode"); actual != "" || err != nil {
+		t.Errorf("Expected blank value to be permitted for option with blank default; instead found %q, %v", actual, err)
+	}
+
+	// Values outside of the allowed set should be rejected at parse time, with an
+	// error naming the option and listing the allowed values
+	for _, cmdLine := range []string{"skeema diff --frob-mode=maybe", "skeema diff --frob-mode=", "skeema diff --blank-mode=medium"} {
+		_, err := mybase.ParseCLI(cmdSuite, strings.Split(cmdLine, " "))
+		if _, ok := err.(mybase.OptionInvalidValueError); !ok {
+			t.Errorf("Expected %q to return OptionInvalidValueError; instead found %T %v", cmdLine, err, err)
+		}
+	}
+	_, err := mybase.ParseCLI(cmdSuite, []string{"skeema", "diff", "--frob-mode=maybe"})
+	for _, substring := range []string{"frob-mode", `"maybe"`, `"On", "off", "Auto"`} {
+		if err == nil || !strings.Contains(err.Error(), substring) {
+			t.Errorf("Expected error message to contain %s, instead found %v", substring, err)
+		}
+	}
+
+	// Same validation applies to option files, unless the file is shared with
+	// other programs (as with .my.cnf), in which case validation is deferred to
+	// GetEnum
+	ioutil.WriteFile("fake-enum.cnf", []byte("frob-mode=Maybe\n"), 0600)
+	defer os.Remove("fake-enum.cnf")
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff")
+	f := mybase.NewFile("fake-enum.cnf")
+	if err := f.Parse(cfg); err == nil || !strings.Contains(err.Error(), "fake-enum.cnf line 1") {
+		t.Errorf("Expected error from parsing file with invalid enum value, instead found %v", err)
+	}
+	f = mybase.NewFile("fake-enum.cnf")
+	f.IgnoreUnknownOptions = true
+	if err := f.Parse(cfg); err != nil {
+		t.Fatalf("Unexpected error parsing file with IgnoreUnknownOptions: %v", err)
+	}
+	cfg.AddSource(f)
+	if _, err := cfg.GetEnum("frob-mode"); err == nil || !strings.Contains(err.Error(), "frob-mode") {
+		t.Errorf("Expected GetEnum to return error for invalid value, instead found %v", err)
+	}
+}
+
+func TestNegatedOptions(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmd := mybase.NewCommand("diff", "", "", nil)
+	cmd.AddOption(mybase.BoolOption("frob", 0, false, "Bool option with false default"))
+	cmd.AddOption(mybase.BoolOption("wobble", 0, true, "Bool option with true default"))
+	cmd.AddOption(mybase.StringOption("flavor-text", 0, "plain", "String option requiring a value"))
+	cmd.AddOption(mybase.StringOption("garnish", 0, "", "String option with optional value").ValueOptional())
+	cmd.AddOption(mybase.StringOption("severity", 0, "warning", "Negatable string option").Negatable())
+	cmdSuite.AddSubCommand(cmd)
+
+	// Every form of enabling or negating should work for any bool option
+	cases := map[string]bool{
+		"skeema diff":                         false,
+		"skeema diff --frob":                  true,
+		"skeema diff --frob=1":                true,
+		"skeema diff --frob=true":             true,
+		"skeema diff --enable-frob":           true,
+		"skeema diff --frob=0":                false,
+		"skeema diff --skip-frob":             false,
+		"skeema diff --disable-frob":          false,
+		"skeema diff --frob --skip-frob":      false,
+		"skeema diff --skip-frob --frob":      true,
+		"skeema diff --skip-frob=0":           true,
+		"skeema diff --skip-frob=1":           false,
+		"skeema diff --loose-skip-frob":       false,
+		"skeema diff --enable-frob=off":       false,
+		"skeema diff --skip-fr":               false,
+		"skeema diff --skip-frob --enable-fr": true,
+	}
+	for cmdLine, expected := range cases {
+		cfg := mybase.ParseFakeCLI(t, cmdSuite, cmdLine)
+		if actual := cfg.GetBool("frob"); actual != expected {
+			t.Errorf("Expected %q to set frob=%t, instead found %t", cmdLine, expected, actual)
+		}
+	}
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --skip-wobble")
+	if cfg.GetBool("wobble") {
+		t.Error("Expected --skip-wobble to disable an option with a true default, but it did not")
+	}
+
+	// Negating a non-bool option is an error, unless it is marked as negatable
+	for _, cmdLine := range []string{"skeema diff --skip-flavor-text", "skeema diff --disable-flavor-text=spicy", "skeema diff --skip-garnish"} {
+		_, err := mybase.ParseCLI(cmdSuite, strings.Split(cmdLine, " "))
+		if _, ok := err.(mybase.OptionNegationError); !ok {
+			t.Errorf("Expected %q to return OptionNegationError; instead found %T %v", cmdLine, err, err)
+		}
+	}
+	_, err := mybase.ParseCLI(cmdSuite, []string{"skeema", "diff", "--skip-flavor-text"})
+	if err == nil || !strings.Contains(err.Error(), "flavor-text requires a value") {
+		t.Errorf("Expected error message to explain that option requires a value; instead found %v", err)
+	}
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --skip-severity")
+	if !cfg.Changed("severity") || cfg.Get("severity") != "" || cfg.GetBool("severity") {
+		t.Errorf("Expected --skip-severity to set a negatable option to an empty value; instead found %q", cfg.GetRaw("severity"))
+	}
+
+	// Same rules apply to option files
+	ioutil.WriteFile("fake-negation.cnf", []byte("skip-frob\nskip-severity\n"), 0600)
+	defer os.Remove("fake-negation.cnf")
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --frob")
+	f := mybase.NewFile("fake-negation.cnf")
+	if err := f.Parse(cfg); err != nil {
+		t.Fatalf("Unexpected error parsing file: %v", err)
+	}
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff", f)
+	if cfg.GetBool("frob") || cfg.Get("severity") != "" {
+		t.Errorf("Expected option file to negate frob and severity; instead found frob=%t severity=%q", cfg.GetBool("frob"), cfg.Get("severity"))
+	}
+	ioutil.WriteFile("fake-negation.cnf", []byte("frob\nskip-flavor-text\n"), 0600)
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff")
+	f = mybase.NewFile("fake-negation.cnf")
+	if err := f.Parse(cfg); err == nil || !strings.Contains(err.Error(), "fake-negation.cnf line 2") {
+		t.Errorf("Expected error from negating option requiring a value in file, instead found %v", err)
+	}
+}
+
+func TestInfoRequested(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "1.2.3", "")
+	AddGlobalOptions(cmdSuite)
+	var handlerCalled bool
+	cmd := mybase.NewCommand("diff", "summary", "description", func(*mybase.Config) error {
+		handlerCalled = true
+		return nil
+	})
+	cmd.AddArg("environment", "production", false)
+	cmdSuite.AddSubCommand(cmd)
+
+	cases := map[string]error{
+		"skeema diff":                  nil,
+		"skeema diff staging":          nil,
+		"skeema":                       mybase.ErrHelpRequested,
+		"skeema help":                  mybase.ErrHelpRequested,
+		"skeema help diff":             mybase.ErrHelpRequested,
+		"skeema diff --help":           mybase.ErrHelpRequested,
+		"skeema --help diff":           mybase.ErrHelpRequested,
+		"skeema diff -?":               mybase.ErrHelpRequested,
+		"skeema version":               mybase.ErrVersionRequested,
+		"skeema --version":             mybase.ErrVersionRequested,
+		"skeema diff --version":        mybase.ErrVersionRequested,
+		"skeema diff --help --version": mybase.ErrHelpRequested,
+	}
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+	for cmdLine, expected := range cases {
+		cfg, err := mybase.ParseCLI(cmdSuite, strings.Split(cmdLine, " "))
+		if err != nil {
+			t.Fatalf("Unexpected error from ParseCLI on %q: %v", cmdLine, err)
+		}
+		if actual := cfg.CLI.InfoRequested(); actual != expected {
+			t.Errorf("Expected InfoRequested on %q to return %v, instead found %v", cmdLine, expected, actual)
+		}
+
+		// Handling help or version must print to STDOUT and return nil, without
+		// exiting the process or calling the command's handler
+		if expected == nil {
+			continue
+		}
+		tmp, err := ioutil.TempFile("", "skeema-info-test")
+		if err != nil {
+			t.Fatalf("Unable to create temp file: %v", err)
+		}
+		os.Stdout = tmp
+		handlerCalled = false
+		err = cfg.HandleCommand()
+		os.Stdout = stdout
+		tmp.Close()
+		output, _ := ioutil.ReadFile(tmp.Name())
+		os.Remove(tmp.Name())
+		if err != nil || handlerCalled {
+			t.Errorf("Expected HandleCommand on %q to return nil without calling handler; instead found err=%v, handlerCalled=%t", cmdLine, err, handlerCalled)
+		}
+		if expected == mybase.ErrVersionRequested && !strings.Contains(string(output), "version 1.2.3") {
+			t.Errorf("Expected HandleCommand on %q to print version, instead found output %q", cmdLine, output)
+		} else if expected == mybase.ErrHelpRequested && !strings.Contains(string(output), "Usage:") {
+			t.Errorf("Expected HandleCommand on %q to print usage, instead found output %q", cmdLine, output)
+		}
+	}
+}
+
+func TestDashArgs(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmd := mybase.NewCommand("diff", "", "", nil)
+	cmd.AddOption(mybase.BoolOption("first-only", '1', false, "Only use first instance"))
+	cmd.AddArg("environment", "production", false)
+	cmdSuite.AddSubCommand(cmd)
+
+	// Bare "-" is a positional arg
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff -")
+	if actual := cfg.Get("environment"); actual != "-" {
+		t.Errorf("Expected environment arg to be \"-\"; instead found %q", actual)
+	}
+
+	// Negative number is a positional arg, unless it corresponds to a shorthand
+	// option
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff -5")
+	if actual := cfg.Get("environment"); actual != "-5" {
+		t.Errorf("Expected environment arg to be \"-5\"; instead found %q", actual)
+	}
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff -1")
+	if actual := cfg.Get("environment"); actual != "production" || !cfg.GetBool("first-only") {
+		t.Errorf("Expected -1 to be parsed as shorthand option; instead environment=%q first-only=%t", actual, cfg.GetBool("first-only"))
+	}
+
+	// A bare dash or negative number may follow an option after a space
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --connect-options - -5")
+	if actual := cfg.Get("connect-options"); actual != "-" {
+		t.Errorf("Expected connect-options to be \"-\"; instead found %q", actual)
+	}
+	if actual := cfg.Get("environment"); actual != "-5" {
+		t.Errorf("Expected environment arg to be \"-5\"; instead found %q", actual)
+	}
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff -u -5")
+	if actual := cfg.Get("user"); actual != "-5" {
+		t.Errorf("Expected user to be \"-5\"; instead found %q", actual)
+	}
+
+	// Other values beginning with a dash require an equals sign for long
+	// options, or no space for short options
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --user=-value --host=-x")
+	if actual := cfg.Get("user"); actual != "-value" {
+		t.Errorf("Expected user to be \"-value\"; instead found %q", actual)
+	}
+	if actual := cfg.Get("host"); actual != "-x" {
+		t.Errorf("Expected host to be \"-x\"; instead found %q", actual)
+	}
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff -u-value")
+	if actual := cfg.Get("user"); actual != "-value" {
+		t.Errorf("Expected user to be \"-value\"; instead found %q", actual)
+	}
+	for _, cmdLine := range []string{"skeema diff --host -x", "skeema diff --user -value", "skeema diff -u -value"} {
+		if _, err := mybase.ParseCLI(cmdSuite, strings.Split(cmdLine, " ")); err == nil {
+			t.Errorf("Expected command-line %q to return an error, but it did not", cmdLine)
+		}
+	}
+
+	// Value-slurping still stops at real options
+	for _, cmdLine := range []string{"skeema diff --user --debug", "skeema diff --user -p", "skeema diff -u -1", "skeema diff --user --"} {
+		if _, err := mybase.ParseCLI(cmdSuite, strings.Split(cmdLine, " ")); err == nil {
+			t.Errorf("Expected command-line %q to return an error, but it did not", cmdLine)
+		}
+	}
+
+	// Unknown shorthand options still return an error
+	if _, err := mybase.ParseCLI(cmdSuite, []string{"skeema", "diff", "-z"}); err == nil {
+		t.Error("Expected unknown shorthand option to return an error, but it did not")
+	}
+}
+
 func TestPasswordOption(t *testing.T) {
 	assertPassword := func(cfg *mybase.Config, expected string) {
 		t.Helper()
@@ -164,6 +669,277 @@ func TestPasswordOption(t *testing.T) {
 	}
 }
 
+func TestPasswordFileOption(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmdSuite.AddSubCommand(mybase.NewCommand("diff", "", "", nil))
+	os.Unsetenv("MYSQL_PWD")
+
+	ioutil.WriteFile("fake-secret", []byte("s3cret\n"), 0600)
+	ioutil.WriteFile("fake-empty-secret", []byte{}, 0600)
+	defer func() {
+		os.Remove("fake-secret")
+		os.Remove("fake-empty-secret")
+	}()
+
+	// File value used when password option is absent, with trailing newline
+	// stripped
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --password-file=fake-secret")
+	if err := ProcessSpecialGlobalOptions(cfg); err != nil {
+		t.Errorf("Unexpected error from ProcessSpecialGlobalOptions: %s", err)
+	}
+	if actual := cfg.Get("password"); actual != "s3cret" {
+		t.Errorf("Expected password to come from file; instead found %q", actual)
+	}
+
+	// File supplied via option file rather than CLI
+	fakeFileSource := mybase.SimpleSource(map[string]string{
+		"password-file": "fake-secret",
+	})
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff", fakeFileSource)
+	if actual := cfg.Get("password"); actual != "s3cret" {
+		t.Errorf("Expected password to come from file; instead found %q", actual)
+	}
+
+	// Direct option, even from a lower-priority source, takes precedence over
+	// file option
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --password-file=fake-secret --password=direct")
+	if actual := cfg.Get("password"); actual != "direct" {
+		t.Errorf("Expected password to come from direct option; instead found %q", actual)
+	}
+	fakeFileSource["password"] = "fromfile"
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --password-file=fake-secret", fakeFileSource)
+	if actual := cfg.Get("password"); actual != "fromfile" {
+		t.Errorf("Expected password to come from direct option; instead found %q", actual)
+	}
+
+	// Empty file is treated as an explicitly empty value, which should not
+	// trigger a TTY prompt
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --password-file=fake-empty-secret")
+	if err := ProcessSpecialGlobalOptions(cfg); err != nil {
+		t.Errorf("Unexpected error from ProcessSpecialGlobalOptions: %s", err)
+	}
+	if actual := cfg.Get("password"); actual != "" || !cfg.Supplied("password") {
+		t.Errorf("Expected password to be supplied as empty string; instead found %q", actual)
+	}
+
+	// Missing file is an error
+	_, err := mybase.ParseCLI(cmdSuite, []string{"skeema", "diff", "--password-file=does-not-exist"})
+	if _, ok := err.(mybase.OptionValueFileError); !ok {
+		t.Errorf("Expected missing file to return OptionValueFileError; instead found %T %v", err, err)
+	}
+	ioutil.WriteFile("fake-secret-option-file", []byte("password-file=does-not-exist\n"), 0600)
+	defer os.Remove("fake-secret-option-file")
+	f := mybase.NewFile("fake-secret-option-file")
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff")
+	if err := f.Parse(cfg); err == nil {
+		t.Error("Expected option file referencing missing password-file to fail parsing, but it did not")
+	}
+}
+
+func TestPathOptions(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmdSuite.AddSubCommand(mybase.NewCommand("diff", "", "", nil))
+	os.Unsetenv("MYSQL_PWD")
+
+	os.MkdirAll("fake-home", 0777)
+	defer os.RemoveAll("fake-home")
+	fakeHome, _ := filepath.Abs("fake-home")
+	for name, value := range map[string]string{"HOME": fakeHome, "USERPROFILE": fakeHome, "SKEEMA_TEST_CERT_DIR": "/opt/certs"} {
+		if prev, ok := os.LookupEnv(name); ok {
+			defer os.Setenv(name, prev)
+		} else {
+			defer os.Unsetenv(name)
+		}
+		os.Setenv(name, value)
+	}
+	os.Unsetenv("SKEEMA_TEST_UNSET_VAR")
+
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --ssl-ca=~/certs/ca.pem --ssl-cert=$HOME/certs/client.pem --ssl-key=${SKEEMA_TEST_CERT_DIR}/client-key.pem --ssh-key=${SKEEMA_TEST_UNSET_VAR}/id_rsa")
+	expected := map[string]string{
+		"ssl-ca":   fakeHome + "/certs/ca.pem",
+		"ssl-cert": fakeHome + "/certs/client.pem",
+		"ssl-key":  "/opt/certs/client-key.pem",
+		"ssh-key":  "/id_rsa",
+	}
+	for name, expectedValue := range expected {
+		if actual := cfg.Get(name); actual != expectedValue {
+			t.Errorf("Expected option %s to have value %q, instead found %q", name, expectedValue, actual)
+		}
+	}
+	if actual := cfg.GetRaw("ssl-ca"); actual != "~/certs/ca.pem" {
+		t.Errorf("Expected raw value of ssl-ca to be unexpanded, instead found %q", actual)
+	}
+
+	// Values without any expansion tokens are unchanged, as are values of
+	// options which are not paths
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --ssl-ca=/etc/ssl/ca.pem --ssl-cert=certs/client~1.pem --ssh=$HOME")
+	if actual := cfg.Get("ssl-ca"); actual != "/etc/ssl/ca.pem" {
+		t.Errorf("Expected ssl-ca to be unchanged, instead found %q", actual)
+	}
+	if actual := cfg.Get("ssl-cert"); actual != "certs/client~1.pem" {
+		t.Errorf("Expected ssl-cert to be unchanged, instead found %q", actual)
+	}
+	if actual := cfg.Get("ssh"); actual != "$HOME" {
+		t.Errorf("Expected non-path option ssh to be unchanged, instead found %q", actual)
+	}
+
+	// File options for values and include directives are expanded as well
+	ioutil.WriteFile("fake-home/secret", []byte("s3cret\n"), 0600)
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --password-file=~/secret")
+	if err := ProcessSpecialGlobalOptions(cfg); err != nil {
+		t.Errorf("Unexpected error from ProcessSpecialGlobalOptions: %s", err)
+	}
+	if actual := cfg.Get("password"); actual != "s3cret" {
+		t.Errorf("Expected password to come from file; instead found %q", actual)
+	}
+	ioutil.WriteFile("fake-home/included.cnf", []byte("user=fromhome\n"), 0600)
+	ioutil.WriteFile("fake-home/including.cnf", []byte("include ${HOME}/included.cnf\n"), 0600)
+	f := mybase.NewFile("fake-home/including.cnf")
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff")
+	if err := f.Parse(cfg); err != nil {
+		t.Fatalf("Unexpected error parsing file with include: %v", err)
+	}
+	cfg.AddSource(f)
+	if actual := cfg.Get("user"); actual != "fromhome" {
+		t.Errorf("Expected user to come from included file; instead found %q", actual)
+	}
+}
+
+func TestRedactDSN(t *testing.T) {
+	cases := map[string]string{
+		"root:s3cret@tcp(127.0.0.1:3306)/?timeout=5s":   "root:xxxxx@tcp(127.0.0.1:3306)/?timeout=5s",
+		"root:p@ss:w/rd@unix(/tmp/mysql.sock)/":         "root:xxxxx@unix(/tmp/mysql.sock)/",
+		"root@tcp(127.0.0.1:3306)/?timeout=5s":          "root@tcp(127.0.0.1:3306)/?timeout=5s",
+		"root:@tcp(127.0.0.1:3306)/":                    "root:xxxxx@tcp(127.0.0.1:3306)/",
+		"tcp(127.0.0.1:3306)/":                          "tcp(127.0.0.1:3306)/",
+		"not a dsn":                                     "not a dsn",
+		"user:pw@tcp(host:3306)/db?sql_mode=%27ANSI%27": "user:xxxxx@tcp(host:3306)/db?sql_mode=%27ANSI%27",
+	}
+	for input, expected := range cases {
+		if actual := RedactDSN(input); actual != expected {
+			t.Errorf("RedactDSN(%q): expected %q, found %q", input, expected, actual)
+		}
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmdSuite.AddOption(mybase.StringOption("api-token", 0, "", "Fake secret option for testing").ValueFromFile())
+	cmdSuite.AddSubCommand(mybase.NewCommand("diff", "", "", nil))
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --password=hunter2 --api-token=tok123 --user=hunter")
+
+	input := "Unable to connect via hunter:hunter2@tcp(127.0.0.1:3306)/?timeout=5s with --api-token=tok123 password='hunter2'"
+	actual := RedactSecrets(cfg, input)
+	if strings.Contains(actual, "hunter2") || strings.Contains(actual, "tok123") {
+		t.Errorf("Expected sensitive values to be redacted, instead found %q", actual)
+	}
+	if expected := "Unable to connect via hunter:xxxxx@tcp(127.0.0.1:3306)/?timeout=5s with --api-token=xxxxx password=xxxxx"; actual != expected {
+		t.Errorf("Expected RedactSecrets to return %q, instead found %q", expected, actual)
+	}
+
+	// RedactError should only return a new error if redaction was needed
+	origErr := errors.New(input)
+	if err := RedactError(cfg, origErr); err == origErr || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Expected RedactError to return new redacted error, instead found %v", err)
+	}
+	origErr = errors.New("Unable to connect as hunter")
+	if err := RedactError(cfg, origErr); err != origErr {
+		t.Errorf("Expected RedactError to return original error when no redaction needed, instead found %v", err)
+	}
+	if err := RedactError(cfg, nil); err != nil {
+		t.Errorf("Expected RedactError of nil to return nil, instead found %v", err)
+	}
+
+	// DSN passwords should be redacted even if they contain whitespace or @, or
+	// if the DSN lacks a network type and address, or if the password is not
+	// the configured one
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff")
+	cases := map[string]string{
+		"DSN=root:s3cr3t pass@tcp(127.0.0.1:3306)/":          "DSN=root:xxxxx@tcp(127.0.0.1:3306)/",
+		"Unable to connect via root:secret@/db":              "Unable to connect via root:xxxxx@/db",
+		"Unable to connect via root:p@ss@unix(/tmp/s.sock)/": "Unable to connect via root:xxxxx@unix(/tmp/s.sock)/",
+		"Error 1045: connecting to root@/db failed":          "Error 1045: connecting to root@/db failed",
+		"a:b@/x and c:d@tcp(h:1)/y":                          "a:xxxxx@/x and c:xxxxx@tcp(h:1)/y",
+	}
+	for input, expected := range cases {
+		if actual := RedactSecrets(cfg, input); actual != expected {
+			t.Errorf("Expected RedactSecrets(%q) to return %q, instead found %q", input, expected, actual)
+		}
+	}
+
+	// Configured values of sensitive options should be redacted wherever they
+	// appear, including values containing whitespace and values read from files
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --password='s3cr3t pass' --socks5-pass=proxypw")
+	input = "Server rejected password s3cr3t pass; proxy said: bad credentials proxypw"
+	if actual, expected := RedactSecrets(cfg, input), "Server rejected password xxxxx; proxy said: bad credentials xxxxx"; actual != expected {
+		t.Errorf("Expected RedactSecrets to return %q, instead found %q", expected, actual)
+	}
+	tmpDir, err := ioutil.TempDir("", "skeema-redact-test")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	passwordFile := filepath.Join(tmpDir, "pw")
+	if err := ioutil.WriteFile(passwordFile, []byte("fromfile\n"), 0600); err != nil {
+		t.Fatalf("Unable to write %s: %v", passwordFile, err)
+	}
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --password-file="+passwordFile)
+	input = "Unexpected response to fromfile"
+	if actual, expected := RedactSecrets(cfg, input), "Unexpected response to xxxxx"; actual != expected {
+		t.Errorf("Expected RedactSecrets to return %q, instead found %q", expected, actual)
+	}
+
+	// Empty and quoted-empty passwords should not cause any replacement
+	for _, cmdLine := range []string{"skeema diff", "skeema diff --password=''", "skeema diff --password="} {
+		cfg = mybase.ParseFakeCLI(t, cmdSuite, cmdLine)
+		input = "Unable to connect via root:@tcp(127.0.0.1:3306)/ with password='' in ''quoted'' text"
+		if actual := RedactSecrets(cfg, input); actual != input {
+			t.Errorf("Expected no redaction of empty password with %q, instead found %q", cmdLine, actual)
+		}
+	}
+}
+
+func TestObjectTypes(t *testing.T) {
+	cmdSuite := mybase.NewCommandSuite("skeematest", "", "")
+	AddGlobalOptions(cmdSuite)
+	cmdSuite.AddSubCommand(mybase.NewCommand("diff", "", "", nil))
+
+	// Without the option, nil should be returned, indicating all types are managed
+	cfg := mybase.ParseFakeCLI(t, cmdSuite, "skeema diff")
+	if types, err := ObjectTypes(cfg); types != nil || err != nil {
+		t.Errorf("Expected ObjectTypes to return nil, nil; instead found %v, %v", types, err)
+	}
+
+	cases := map[string][]tengo.ObjectType{
+		"table":                {tengo.ObjectTypeDatabase, tengo.ObjectTypeTable},
+		"table,views":          {tengo.ObjectTypeDatabase, tengo.ObjectTypeTable, tengo.ObjectTypeView},
+		"PROCS, func,triggers": {tengo.ObjectTypeDatabase, tengo.ObjectTypeProc, tengo.ObjectTypeFunc, tengo.ObjectTypeTrigger},
+	}
+	for value, expectTypes := range cases {
+		cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --object-types='"+value+"'")
+		types, err := ObjectTypes(cfg)
+		if err != nil {
+			t.Errorf("Unexpected error from ObjectTypes with object-types=%q: %v", value, err)
+			continue
+		}
+		expected := make(map[tengo.ObjectType]bool, len(expectTypes))
+		for _, ot := range expectTypes {
+			expected[ot] = true
+		}
+		if !reflect.DeepEqual(types, expected) {
+			t.Errorf("Expected ObjectTypes with object-types=%q to return %v, instead found %v", value, expected, types)
+		}
+	}
+
+	cfg = mybase.ParseFakeCLI(t, cmdSuite, "skeema diff --object-types=table,widget")
+	if _, err := ObjectTypes(cfg); err == nil {
+		t.Error("Expected error from invalid object type, but err was nil")
+	}
+}
+
 func TestSplitConnectOptions(t *testing.T) {
 	assertConnectOpts := func(connectOptions string, expectedPair ...string) {
 		result, err := SplitConnectOptions(connectOptions)
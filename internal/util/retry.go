@@ -0,0 +1,56 @@
+package util
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/mybase"
+	"github.com/skeema/tengo"
+)
+
+// maxConnectRetryDelay caps the exponential backoff between connection
+// attempts.
+const maxConnectRetryDelay = 30 * time.Second
+
+// ConnectRetryPolicy controls retrying of database connection attempts which
+// fail due to transient problems, such as a freshly-started server not
+// accepting connections yet.
+type ConnectRetryPolicy struct {
+	Retries int           // max number of attempts after the initial one
+	Delay   time.Duration // delay before the first retry; doubles after each retry
+}
+
+// ConnectRetryPolicyForConfig returns a ConnectRetryPolicy based on the
+// connect-retries and connect-retry-delay options.
+func ConnectRetryPolicyForConfig(cfg *mybase.Config) (policy ConnectRetryPolicy, err error) {
+	if policy.Retries, err = cfg.GetInt("connect-retries"); err != nil {
+		return policy, err
+	} else if policy.Retries < 0 {
+		return policy, fmt.Errorf("Option connect-retries cannot be negative")
+	}
+	if policy.Delay, err = time.ParseDuration(cfg.Get("connect-retry-delay")); err != nil {
+		return policy, fmt.Errorf("Option connect-retry-delay has invalid value: %s", err)
+	} else if policy.Delay <= 0 {
+		return policy, fmt.Errorf("Option connect-retry-delay must be positive")
+	}
+	return policy, nil
+}
+
+// Do calls connect until it succeeds, returns an error which is not transient
+// according to tengo.IsTransientConnectError, or fails more than p.Retries
+// additional times. The last error from connect is returned.
+func (p ConnectRetryPolicy) Do(connect func() error) error {
+	delay := p.Delay
+	for attempt := 1; ; attempt++ {
+		err := connect()
+		if err == nil || attempt > p.Retries || !tengo.IsTransientConnectError(err) {
+			return err
+		}
+		log.Debugf("Connection attempt %d of %d failed: %s. Retrying in %s.", attempt, p.Retries+1, err, delay)
+		time.Sleep(delay)
+		if delay *= 2; delay > maxConnectRetryDelay {
+			delay = maxConnectRetryDelay
+		}
+	}
+}
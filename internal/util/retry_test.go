@@ -0,0 +1,84 @@
+package util
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/skeema/mybase"
+)
+
+func TestConnectRetryPolicyDo(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	policy := ConnectRetryPolicy{Retries: 4, Delay: time.Millisecond}
+
+	// connector that fails with a transient error N-1 times, then succeeds
+	var attempts int
+	failUntil := func(n int, transientErr error) func() error {
+		attempts = 0
+		return func() error {
+			attempts++
+			if attempts < n {
+				return transientErr
+			}
+			return nil
+		}
+	}
+	for _, transientErr := range []error{refused, mysql.ErrInvalidConn, &mysql.MySQLError{Number: 1040, Message: "Too many connections"}} {
+		if err := policy.Do(failUntil(5, transientErr)); err != nil {
+			t.Errorf("Expected success after retries of %v, instead found error %v", transientErr, err)
+		} else if attempts != 5 {
+			t.Errorf("Expected 5 attempts, instead found %d", attempts)
+		}
+	}
+
+	// Exceeding the retry budget returns the last error
+	if err := policy.Do(failUntil(6, refused)); err != refused {
+		t.Errorf("Expected error %v after exhausting retries, instead found %v", refused, err)
+	} else if attempts != 5 {
+		t.Errorf("Expected 5 attempts, instead found %d", attempts)
+	}
+
+	// Access errors and other non-transient errors abort immediately
+	for _, fatalErr := range []error{
+		&mysql.MySQLError{Number: 1045, Message: "Access denied for user 'root'@'localhost'"},
+		&mysql.MySQLError{Number: 1049, Message: "Unknown database 'foo'"},
+		errors.New("some other problem"),
+	} {
+		if err := policy.Do(failUntil(3, fatalErr)); err != fatalErr {
+			t.Errorf("Expected error %v, instead found %v", fatalErr, err)
+		} else if attempts != 1 {
+			t.Errorf("Expected error %v to abort after 1 attempt, instead found %d attempts", fatalErr, attempts)
+		}
+	}
+
+	// With no retries configured, transient errors are returned immediately
+	policy.Retries = 0
+	if err := policy.Do(failUntil(2, refused)); err != refused || attempts != 1 {
+		t.Errorf("Expected error %v after 1 attempt, instead found err=%v, attempts=%d", refused, err, attempts)
+	}
+}
+
+func TestConnectRetryPolicyForConfig(t *testing.T) {
+	cmd := mybase.NewCommand("retrytest", "", "", nil)
+	AddGlobalOptions(cmd)
+	getPolicy := func(cliFlags string) (ConnectRetryPolicy, error) {
+		cfg := mybase.ParseFakeCLI(t, cmd, "retrytest "+cliFlags)
+		return ConnectRetryPolicyForConfig(cfg)
+	}
+	if policy, err := getPolicy(""); err != nil || policy.Retries != 0 || policy.Delay != 500*time.Millisecond {
+		t.Errorf("Unexpected result with default options: %+v, %v", policy, err)
+	}
+	if policy, err := getPolicy("--connect-retries=3 --connect-retry-delay=2s"); err != nil || policy.Retries != 3 || policy.Delay != 2*time.Second {
+		t.Errorf("Unexpected result: %+v, %v", policy, err)
+	}
+	for _, cliFlags := range []string{"--connect-retries=-1", "--connect-retries=many", "--connect-retry-delay=soon", "--connect-retry-delay=0s"} {
+		if _, err := getPolicy(cliFlags); err == nil {
+			t.Errorf("Expected error from options %s, but err was nil", cliFlags)
+		}
+	}
+}
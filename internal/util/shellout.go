@@ -17,6 +17,7 @@ type ShellOut struct {
 	Dir              string        // Initial working dir for the command if non-empty
 	Timeout          time.Duration // If > 0, kill process after this amount of time
 	CombineOutput    bool          // If true, combine stdout and stderr into a single stream
+	Env              []string      // Additional environment variables for the command, in "KEY=value" form
 	cancelFunc       context.CancelFunc
 }
 
@@ -42,6 +43,9 @@ func (s *ShellOut) Run() error {
 		defer s.cancelFunc()
 	}
 	cmd.Dir = s.Dir
+	if len(s.Env) > 0 {
+		cmd.Env = append(os.Environ(), s.Env...)
+	}
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	if s.CombineOutput {
@@ -68,6 +72,9 @@ func (s *ShellOut) RunCapture() (string, error) {
 		defer s.cancelFunc()
 	}
 	cmd.Dir = s.Dir
+	if len(s.Env) > 0 {
+		cmd.Env = append(os.Environ(), s.Env...)
+	}
 	cmd.Stdin = os.Stdin
 
 	var out []byte
@@ -28,6 +28,14 @@ func TestShellOutRun(t *testing.T) {
 	assertResult("true", "/invalid/dir", false)
 }
 
+func TestShellOutEnv(t *testing.T) {
+	s := &ShellOut{Command: `echo "$SKEEMA_TEST_VAR,$HOME"`, Env: []string{"SKEEMA_TEST_VAR=hello world"}}
+	out, err := s.RunCapture()
+	if expected := "hello world," + os.Getenv("HOME") + "\n"; err != nil || out != expected {
+		t.Errorf("Expected output %q with no error; instead found output %q, err=%v", expected, out, err)
+	}
+}
+
 func TestRunCaptureSplit(t *testing.T) {
 	assertResult := func(command string, expectedTokens ...string) {
 		s := &ShellOut{Command: command}
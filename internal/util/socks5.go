@@ -0,0 +1,225 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/skeema/mybase"
+)
+
+// SOCKS5Proxy represents a SOCKS5 proxy server which database connections are
+// routed through, as specified by the socks5, socks5-user, and socks5-pass
+// options.
+type SOCKS5Proxy struct {
+	Addr     string // host:port of the proxy server
+	User     string // username for proxy authentication, or blank for none
+	Password string // password for proxy authentication
+}
+
+// SOCKS5ProxyError represents a failure to connect to or through a SOCKS5
+// proxy, as opposed to a failure of the database connection going through it.
+type SOCKS5ProxyError struct {
+	Proxy string
+	Err   error
+}
+
+// Error satisfies the builtin error interface.
+func (spe *SOCKS5ProxyError) Error() string {
+	return fmt.Sprintf("SOCKS5 proxy %s failed: %s", spe.Proxy, spe.Err)
+}
+
+// SOCKS5ProxyForConfig returns a SOCKS5Proxy based on the configuration in
+// cfg, or nil if the socks5 option is not set. An error is returned if the
+// options are invalid.
+func SOCKS5ProxyForConfig(cfg *mybase.Config) (*SOCKS5Proxy, error) {
+	proxy := &SOCKS5Proxy{
+		Addr:     cfg.Get("socks5"),
+		User:     cfg.Get("socks5-user"),
+		Password: cfg.Get("socks5-pass"),
+	}
+	if proxy.Addr == "" {
+		if proxy.User != "" || proxy.Password != "" {
+			return nil, errors.New("Options socks5-user and socks5-pass require option socks5")
+		}
+		return nil, nil
+	}
+	host, port, err := net.SplitHostPort(proxy.Addr)
+	if err != nil || host == "" {
+		return nil, fmt.Errorf("Invalid SOCKS5 proxy %q: must be in format host:port", proxy.Addr)
+	}
+	if portNum, err := strconv.Atoi(port); err != nil || portNum < 1 || portNum > 65535 {
+		return nil, fmt.Errorf("Invalid SOCKS5 proxy port %q", port)
+	}
+	if proxy.User == "" && proxy.Password != "" {
+		return nil, errors.New("Option socks5-pass requires option socks5-user")
+	} else if len(proxy.User) > 255 || len(proxy.Password) > 255 {
+		return nil, errors.New("Options socks5-user and socks5-pass are limited to 255 bytes each")
+	}
+	return proxy, nil
+}
+
+// DialContext connects to addr (a host:port address, resolved by the proxy)
+// through the proxy. Any error returned is a *SOCKS5ProxyError. If ctx has a
+// deadline, it applies to the proxy handshake as well as the initial dial.
+func (proxy SOCKS5Proxy) DialContext(ctx context.Context, addr string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", proxy.Addr)
+	if err != nil {
+		return nil, &SOCKS5ProxyError{Proxy: proxy.Addr, Err: err}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if err := proxy.handshake(conn, addr); err != nil {
+		conn.Close()
+		return nil, &SOCKS5ProxyError{Proxy: proxy.Addr, Err: err}
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// socks5ReplyMessages maps SOCKS5 reply codes to messages, per RFC 1928.
+var socks5ReplyMessages = map[byte]string{
+	1: "general SOCKS server failure",
+	2: "connection not allowed by ruleset",
+	3: "network unreachable",
+	4: "host unreachable",
+	5: "connection refused",
+	6: "TTL expired",
+	7: "command not supported",
+	8: "address type not supported",
+}
+
+// handshake performs method negotiation, optional username/password
+// authentication (RFC 1929), and a CONNECT request for addr on conn.
+func (proxy SOCKS5Proxy) handshake(conn net.Conn, addr string) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid destination port %q", portStr)
+	}
+
+	// Method negotiation: always offer no-auth, and also offer
+	// username/password if a user was configured
+	methods := []byte{5, 1, 0}
+	if proxy.User != "" {
+		methods = []byte{5, 2, 0, 2}
+	}
+	if _, err := conn.Write(methods); err != nil {
+		return err
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return err
+	} else if buf[0] != 5 {
+		return fmt.Errorf("unexpected protocol version %d", buf[0])
+	}
+	switch buf[1] {
+	case 0:
+	case 2:
+		if proxy.User == "" {
+			return errors.New("proxy requires authentication, but socks5-user is not set")
+		}
+		auth := []byte{1, byte(len(proxy.User))}
+		auth = append(auth, proxy.User...)
+		auth = append(auth, byte(len(proxy.Password)))
+		auth = append(auth, proxy.Password...)
+		if _, err := conn.Write(auth); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+			return err
+		} else if buf[1] != 0 {
+			return errors.New("proxy rejected socks5-user and socks5-pass")
+		}
+	default:
+		return errors.New("proxy does not support any offered authentication method")
+	}
+
+	// CONNECT request
+	req := []byte{5, 1, 0}
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return fmt.Errorf("destination host name %q is too long", host)
+		}
+		req = append(req, 3, byte(len(host)))
+		req = append(req, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		req = append(req, 1)
+		req = append(req, ip4...)
+	} else {
+		req = append(req, 4)
+		req = append(req, ip.To16()...)
+	}
+	req = append(req, byte(port>>8), byte(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	// Reply: version, status, reserved, and then the bound address, which is
+	// read and discarded
+	if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+		return err
+	} else if buf[0] != 5 {
+		return fmt.Errorf("unexpected protocol version %d", buf[0])
+	} else if buf[1] != 0 {
+		if msg, ok := socks5ReplyMessages[buf[1]]; ok {
+			return fmt.Errorf("unable to reach %s: %s", addr, msg)
+		}
+		return fmt.Errorf("unable to reach %s: reply code %d", addr, buf[1])
+	}
+	var boundLen int
+	switch buf[3] {
+	case 1:
+		boundLen = net.IPv4len
+	case 4:
+		boundLen = net.IPv6len
+	case 3:
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return err
+		}
+		boundLen = int(buf[0])
+	default:
+		return fmt.Errorf("unexpected address type %d in reply", buf[3])
+	}
+	_, err = io.ReadFull(conn, make([]byte, boundLen+2))
+	return err
+}
+
+var registeredSOCKS5Dialers struct {
+	sync.Mutex
+	names map[SOCKS5Proxy]string
+}
+
+func init() {
+	registeredSOCKS5Dialers.names = make(map[SOCKS5Proxy]string)
+}
+
+// DSNNetwork returns the network name to use in place of "tcp" in a
+// go-sql-driver/mysql DSN, in order to route connections through the proxy.
+// The proxy's dialer is registered with the driver as needed. Identical proxies
+// always return the same name. Since TLS is negotiated by the driver after
+// dialing, TLS options work as usual over the proxied connection.
+func (proxy SOCKS5Proxy) DSNNetwork() string {
+	registeredSOCKS5Dialers.Lock()
+	defer registeredSOCKS5Dialers.Unlock()
+	if name, already := registeredSOCKS5Dialers.names[proxy]; already {
+		return name
+	}
+	// Use a counter rather than anything derived from the proxy's fields, since
+	// the name appears in DSNs and log output
+	name := fmt.Sprintf("skeema-socks5-%d", len(registeredSOCKS5Dialers.names))
+	mysql.RegisterDialContext(name, proxy.DialContext)
+	registeredSOCKS5Dialers.names[proxy] = name
+	return name
+}
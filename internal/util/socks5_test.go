@@ -0,0 +1,263 @@
+package util
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skeema/mybase"
+)
+
+// fakeSOCKS5Server is a minimal SOCKS5 server supporting CONNECT and optional
+// username/password authentication. Rather than connecting to requested
+// destinations, it records them and forwards all connections to a single
+// upstream address.
+type fakeSOCKS5Server struct {
+	listener  net.Listener
+	user      string
+	password  string
+	upstream  string
+	requested chan string
+}
+
+func newFakeSOCKS5Server(t *testing.T, user, password, upstream string) *fakeSOCKS5Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	server := &fakeSOCKS5Server{
+		listener:  listener,
+		user:      user,
+		password:  password,
+		upstream:  upstream,
+		requested: make(chan string, 10),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.handle(conn)
+		}
+	}()
+	return server
+}
+
+func (server *fakeSOCKS5Server) Addr() string {
+	return server.listener.Addr().String()
+}
+
+func (server *fakeSOCKS5Server) Close() {
+	server.listener.Close()
+}
+
+func (server *fakeSOCKS5Server) handle(conn net.Conn) {
+	defer conn.Close()
+	buf := make([]byte, 512)
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+		return
+	}
+	if server.user != "" {
+		conn.Write([]byte{5, 2})
+		if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+			return
+		}
+		user := make([]byte, buf[1])
+		io.ReadFull(conn, user)
+		io.ReadFull(conn, buf[:1])
+		password := make([]byte, buf[0])
+		io.ReadFull(conn, password)
+		if string(user) != server.user || string(password) != server.password {
+			conn.Write([]byte{1, 1})
+			return
+		}
+		conn.Write([]byte{1, 0})
+	} else {
+		conn.Write([]byte{5, 0})
+	}
+
+	if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+		return
+	}
+	var host string
+	switch buf[3] {
+	case 1:
+		io.ReadFull(conn, buf[:4])
+		host = net.IP(buf[:4]).String()
+	case 3:
+		io.ReadFull(conn, buf[:1])
+		name := make([]byte, buf[0])
+		io.ReadFull(conn, name)
+		host = string(name)
+	default:
+		conn.Write([]byte{5, 8, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	io.ReadFull(conn, buf[:2])
+	server.requested <- fmt.Sprintf("%s:%d", host, int(buf[0])<<8|int(buf[1]))
+
+	remote, err := net.Dial("tcp", server.upstream)
+	if err != nil {
+		conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer remote.Close()
+	conn.Write([]byte{5, 0, 0, 1, 127, 0, 0, 1, 0, 0})
+	go io.Copy(remote, conn)
+	io.Copy(conn, remote)
+}
+
+func TestSOCKS5ProxyForConfig(t *testing.T) {
+	assertProxy := func(values map[string]string, expectError bool, expected *SOCKS5Proxy) {
+		t.Helper()
+		cfg := mybase.SimpleConfig(map[string]string{
+			"socks5":      values["socks5"],
+			"socks5-user": values["socks5-user"],
+			"socks5-pass": values["socks5-pass"],
+		})
+		proxy, err := SOCKS5ProxyForConfig(cfg)
+		if expectError {
+			if err == nil {
+				t.Errorf("With option values %v, expected error, but err was nil", values)
+			}
+		} else if err != nil {
+			t.Errorf("With option values %v, unexpected error %v", values, err)
+		} else if (proxy == nil) != (expected == nil) || (proxy != nil && *proxy != *expected) {
+			t.Errorf("With option values %v, expected %+v, instead found %+v", values, expected, proxy)
+		}
+	}
+	assertProxy(nil, false, nil)
+	assertProxy(map[string]string{"socks5": "proxy.host:1080"}, false, &SOCKS5Proxy{Addr: "proxy.host:1080"})
+	assertProxy(map[string]string{"socks5": "proxy.host:1080", "socks5-user": "bob", "socks5-pass": "hunter2"}, false, &SOCKS5Proxy{Addr: "proxy.host:1080", User: "bob", Password: "hunter2"})
+	assertProxy(map[string]string{"socks5": "proxy.host"}, true, nil)
+	assertProxy(map[string]string{"socks5": ":1080"}, true, nil)
+	assertProxy(map[string]string{"socks5": "proxy.host:99999"}, true, nil)
+	assertProxy(map[string]string{"socks5-user": "bob"}, true, nil)
+	assertProxy(map[string]string{"socks5": "proxy.host:1080", "socks5-pass": "hunter2"}, true, nil)
+	assertProxy(map[string]string{"socks5": "proxy.host:1080", "socks5-user": strings.Repeat("x", 256)}, true, nil)
+}
+
+func TestSOCKS5ProxyDialContext(t *testing.T) {
+	// Upstream is a simple echo server
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+	server := newFakeSOCKS5Server(t, "bob", "hunter2", echo.Addr().String())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	proxy := SOCKS5Proxy{Addr: server.Addr(), User: "bob", Password: "hunter2"}
+	conn, err := proxy.DialContext(ctx, "db.internal:3306")
+	if err != nil {
+		t.Fatalf("Unexpected error from DialContext: %v", err)
+	}
+	if requested := <-server.requested; requested != "db.internal:3306" {
+		t.Errorf("Expected proxy to receive request for db.internal:3306, instead found %s", requested)
+	}
+	fmt.Fprint(conn, "ping")
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "ping" {
+		t.Errorf("Unexpected result reading through proxy: %q, err=%v", buf, err)
+	}
+	conn.Close()
+
+	// Proxy failures should be distinguishable
+	assertProxyError := func(proxy SOCKS5Proxy, expectedSubstring string) {
+		t.Helper()
+		_, err := proxy.DialContext(ctx, "10.0.0.5:3306")
+		if proxyErr, ok := err.(*SOCKS5ProxyError); !ok {
+			t.Errorf("Expected error to be a *SOCKS5ProxyError, instead found %T %v", err, err)
+		} else if proxyErr.Proxy != proxy.Addr || !strings.Contains(proxyErr.Error(), expectedSubstring) {
+			t.Errorf("Unexpected error message: %s", proxyErr)
+		}
+	}
+	assertProxyError(SOCKS5Proxy{Addr: server.Addr(), User: "bob", Password: "wrong"}, "rejected socks5-user")
+	assertProxyError(SOCKS5Proxy{Addr: server.Addr()}, "proxy requires authentication")
+	assertProxyError(SOCKS5Proxy{Addr: echo.Addr().String()}, "SOCKS5 proxy "+echo.Addr().String()+" failed")
+}
+
+func TestSOCKS5ProxyDSNNetwork(t *testing.T) {
+	// Upstream accepts connections and then immediately closes them, which is
+	// enough to confirm that the driver dialed through the proxy
+	upstream, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	defer upstream.Close()
+	go func() {
+		for {
+			conn, err := upstream.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	server := newFakeSOCKS5Server(t, "", "", upstream.Addr().String())
+	defer server.Close()
+
+	proxy := SOCKS5Proxy{Addr: server.Addr()}
+	network := proxy.DSNNetwork()
+	if again := proxy.DSNNetwork(); again != network {
+		t.Errorf("Expected identical proxy to return same network name, instead found %s vs %s", network, again)
+	}
+	withAuth := SOCKS5Proxy{Addr: server.Addr(), User: "bob", Password: "hunter2"}
+	if other := withAuth.DSNNetwork(); other == network || !regexp.MustCompile(`^skeema-socks5-\d+$`).MatchString(other) {
+		t.Errorf("Expected distinct proxy to return distinct counter-based network name, instead found %s vs %s", network, other)
+	}
+	db, err := sql.Open("mysql", fmt.Sprintf("root@%s(db.internal:3306)/?timeout=5s", network))
+	if err != nil {
+		t.Fatalf("Unexpected error from sql.Open: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err == nil {
+		t.Error("Expected error from Ping since upstream is not a database, but err was nil")
+	}
+	select {
+	case requested := <-server.requested:
+		if requested != "db.internal:3306" {
+			t.Errorf("Expected proxy to receive request for db.internal:3306, instead found %s", requested)
+		}
+	default:
+		t.Error("Expected driver to connect through proxy, but proxy received no requests")
+	}
+
+	// A nonexistent proxy should result in a *SOCKS5ProxyError from the driver
+	badProxy := SOCKS5Proxy{Addr: upstream.Addr().String()}
+	db2, err := sql.Open("mysql", fmt.Sprintf("root@%s(db.internal:3306)/?timeout=5s", badProxy.DSNNetwork()))
+	if err != nil {
+		t.Fatalf("Unexpected error from sql.Open: %v", err)
+	}
+	defer db2.Close()
+	var proxyErr *SOCKS5ProxyError
+	if err := db2.Ping(); !errors.As(err, &proxyErr) {
+		t.Errorf("Expected Ping error to be a *SOCKS5ProxyError, instead found %T %v", err, err)
+	}
+}
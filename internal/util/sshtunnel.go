@@ -0,0 +1,199 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SSHTunnel represents a local port forward through an SSH bastion host. The
+// tunnel is implemented by running the system's ssh client as a subprocess, so
+// any ssh client configuration (~/.ssh/config, known_hosts, ssh-agent) applies.
+type SSHTunnel struct {
+	Bastion   string // bastion host spec, in format [user@]host[:port]
+	Target    string // host:port of the database, as reachable from the bastion
+	LocalAddr string // 127.0.0.1:port of the local end of the tunnel
+	cmd       *exec.Cmd
+	stderr    *bytes.Buffer
+	exited    chan struct{}
+}
+
+// SSHTunnelError represents a failure to establish or use an SSH tunnel, as
+// opposed to a failure of the database connection going through it.
+type SSHTunnelError struct {
+	Bastion string
+	Err     error
+}
+
+// Error satisfies the builtin error interface.
+func (ste *SSHTunnelError) Error() string {
+	return fmt.Sprintf("SSH tunnel via %s failed: %s", ste.Bastion, ste.Err)
+}
+
+var sshTunnelCache struct {
+	sync.Mutex
+	tunnels map[string]*SSHTunnel
+}
+
+func init() {
+	sshTunnelCache.tunnels = make(map[string]*SSHTunnel)
+}
+
+// sshTunnelTimeout is the max amount of time to wait for a tunnel to start
+// accepting connections.
+var sshTunnelTimeout = 15 * time.Second
+
+// ParseSSHBastion splits a bastion host spec in format [user@]host[:port] into
+// its components. If no port is specified, 22 is returned for port. If no user
+// is specified, user will be an empty string, meaning the ssh client's default.
+func ParseSSHBastion(spec string) (user, host string, port int, err error) {
+	hostAndPort := spec
+	if atPos := strings.LastIndex(spec, "@"); atPos >= 0 {
+		user, hostAndPort = spec[:atPos], spec[atPos+1:]
+		if user == "" {
+			return "", "", 0, fmt.Errorf("Invalid SSH bastion %q: user is empty", spec)
+		}
+	}
+	host, port = hostAndPort, 22
+	if h, p, splitErr := net.SplitHostPort(hostAndPort); splitErr == nil {
+		if port, err = strconv.Atoi(p); err != nil || port < 1 || port > 65535 {
+			return "", "", 0, fmt.Errorf("Invalid SSH bastion port %q", p)
+		}
+		host = h
+	}
+	if host == "" || strings.ContainsAny(host, " /") {
+		return "", "", 0, fmt.Errorf("Invalid SSH bastion host %q", host)
+	}
+	return user, host, port, nil
+}
+
+// sshArgs returns the command-line args for running ssh to forward localAddr
+// to target through the bastion. If keyPath is empty, the ssh client falls back
+// to its default identities, including any loaded in ssh-agent.
+func sshArgs(bastion, keyPath, localAddr, target string) ([]string, error) {
+	user, host, port, err := ParseSSHBastion(bastion)
+	if err != nil {
+		return nil, err
+	}
+	args := []string{
+		"-N",
+		"-o", "BatchMode=yes",
+		"-o", "ExitOnForwardFailure=yes",
+		"-L", localAddr + ":" + target,
+		"-p", strconv.Itoa(port),
+	}
+	if keyPath != "" {
+		args = append(args, "-i", keyPath, "-o", "IdentitiesOnly=yes")
+	}
+	if user != "" {
+		args = append(args, "-l", user)
+	}
+	return append(args, host), nil
+}
+
+// OpenSSHTunnel returns an SSHTunnel forwarding a local port to target (a
+// host:port address, resolved by the bastion) through the supplied bastion.
+// Identical requests return the same tunnel. The tunnel remains open until
+// CloseSSHTunnels is called. Any error returned is an *SSHTunnelError.
+func OpenSSHTunnel(bastion, keyPath, target string) (*SSHTunnel, error) {
+	key := bastion + "\x00" + keyPath + "\x00" + target
+	sshTunnelCache.Lock()
+	defer sshTunnelCache.Unlock()
+	if tunnel, already := sshTunnelCache.tunnels[key]; already {
+		return tunnel, nil
+	}
+	tunnel := &SSHTunnel{
+		Bastion: bastion,
+		Target:  target,
+		stderr:  &bytes.Buffer{},
+		exited:  make(chan struct{}),
+	}
+	if err := tunnel.start(keyPath); err != nil {
+		return nil, &SSHTunnelError{Bastion: bastion, Err: err}
+	}
+	sshTunnelCache.tunnels[key] = tunnel
+	return tunnel, nil
+}
+
+func (tunnel *SSHTunnel) start(keyPath string) error {
+	// Find a free local port. There's an inherent race between closing this
+	// listener and ssh binding to the port, but ExitOnForwardFailure ensures any
+	// collision results in an error rather than a misrouted connection.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	tunnel.LocalAddr = listener.Addr().String()
+	listener.Close()
+
+	args, err := sshArgs(tunnel.Bastion, keyPath, tunnel.LocalAddr, tunnel.Target)
+	if err != nil {
+		return err
+	}
+	tunnel.cmd = exec.Command("ssh", args...)
+	tunnel.cmd.Stderr = tunnel.stderr
+	log.Debugf("Opening SSH tunnel: ssh %s", strings.Join(args, " "))
+	if err := tunnel.cmd.Start(); err != nil {
+		return err
+	}
+	var waitErr error
+	go func() {
+		waitErr = tunnel.cmd.Wait()
+		close(tunnel.exited)
+	}()
+
+	// Wait for the local end of the tunnel to accept connections, or for ssh to
+	// exit prematurely
+	deadline := time.Now().Add(sshTunnelTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-tunnel.exited:
+			if msg := strings.TrimSpace(tunnel.stderr.String()); msg != "" {
+				return errors.New(msg)
+			}
+			return fmt.Errorf("ssh exited before tunnel was established: %v", waitErr)
+		default:
+		}
+		if conn, err := net.DialTimeout("tcp", tunnel.LocalAddr, time.Second); err == nil {
+			conn.Close()
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	tunnel.Close()
+	return fmt.Errorf("timed out after %s waiting for tunnel to be established", sshTunnelTimeout)
+}
+
+// Close tears down the tunnel by terminating its ssh process.
+func (tunnel *SSHTunnel) Close() error {
+	select {
+	case <-tunnel.exited:
+		return nil
+	default:
+	}
+	if err := tunnel.cmd.Process.Kill(); err != nil {
+		return err
+	}
+	<-tunnel.exited
+	return nil
+}
+
+// CloseSSHTunnels tears down all tunnels that were opened via OpenSSHTunnel.
+func CloseSSHTunnels() {
+	sshTunnelCache.Lock()
+	defer sshTunnelCache.Unlock()
+	for key, tunnel := range sshTunnelCache.tunnels {
+		if err := tunnel.Close(); err != nil {
+			log.Warnf("Unable to close SSH tunnel via %s: %s", tunnel.Bastion, err)
+		}
+		delete(sshTunnelCache.tunnels, key)
+	}
+}
@@ -0,0 +1,216 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestMain permits the test binary to stand in for the ssh client: when invoked
+// via a symlink named ssh, it behaves as a minimal fake ssh, forwarding the
+// local port from its -L arg directly to the target. This allows SSHTunnel's
+// process management and readiness logic to be tested without an SSH server.
+func TestMain(m *testing.M) {
+	if filepath.Base(os.Args[0]) == "ssh" {
+		os.Exit(fakeSSH(os.Args[1:]))
+	}
+	os.Exit(m.Run())
+}
+
+func fakeSSH(args []string) int {
+	var forward string
+	for n, arg := range args {
+		if arg == "-L" && n+1 < len(args) {
+			forward = args[n+1]
+		}
+	}
+	if host := args[len(args)-1]; strings.HasPrefix(host, "fail") {
+		fmt.Fprintf(os.Stderr, "ssh: Could not resolve hostname %s: Name or service not known\n", host)
+		return 255
+	}
+	parts := strings.SplitN(forward, ":", 3)
+	if len(parts) != 3 {
+		fmt.Fprintf(os.Stderr, "Bad local forwarding specification '%s'\n", forward)
+		return 255
+	}
+	listener, err := net.Listen("tcp", parts[0]+":"+parts[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 255
+	}
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return 255
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			remote, err := net.Dial("tcp", parts[2])
+			if err != nil {
+				return
+			}
+			defer remote.Close()
+			go io.Copy(remote, conn)
+			io.Copy(conn, remote)
+		}(conn)
+	}
+}
+
+func TestParseSSHBastion(t *testing.T) {
+	cases := []struct {
+		spec string
+		user string
+		host string
+		port int
+	}{
+		{"bastion.example.com", "", "bastion.example.com", 22},
+		{"bastion.example.com:2222", "", "bastion.example.com", 2222},
+		{"deploy@bastion", "deploy", "bastion", 22},
+		{"deploy@10.1.2.3:22022", "deploy", "10.1.2.3", 22022},
+		{"me@corp@bastion:23", "me@corp", "bastion", 23},
+		{"[::1]:2200", "", "::1", 2200},
+	}
+	for _, c := range cases {
+		user, host, port, err := ParseSSHBastion(c.spec)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %q: %v", c.spec, err)
+		} else if user != c.user || host != c.host || port != c.port {
+			t.Errorf("Unexpected result parsing %q: user=%q host=%q port=%d", c.spec, user, host, port)
+		}
+	}
+	for _, spec := range []string{"", "@bastion", "bastion:0", "bastion:abc", "user@:22", "some host"} {
+		if _, _, _, err := ParseSSHBastion(spec); err == nil {
+			t.Errorf("Expected error parsing %q, but err was nil", spec)
+		}
+	}
+}
+
+func TestSSHArgs(t *testing.T) {
+	args, err := sshArgs("deploy@bastion:2222", "/home/deploy/.ssh/id_ed25519", "127.0.0.1:45678", "db.internal:3306")
+	if err != nil {
+		t.Fatalf("Unexpected error from sshArgs: %v", err)
+	}
+	expected := []string{
+		"-N", "-o", "BatchMode=yes", "-o", "ExitOnForwardFailure=yes",
+		"-L", "127.0.0.1:45678:db.internal:3306", "-p", "2222",
+		"-i", "/home/deploy/.ssh/id_ed25519", "-o", "IdentitiesOnly=yes",
+		"-l", "deploy", "bastion",
+	}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("Unexpected result from sshArgs: %v", args)
+	}
+
+	// Without a key path or user, ssh client defaults (including agent) apply
+	args, err = sshArgs("bastion", "", "127.0.0.1:45678", "db.internal:3306")
+	if err != nil {
+		t.Fatalf("Unexpected error from sshArgs: %v", err)
+	}
+	for _, arg := range args {
+		if arg == "-i" || arg == "-l" {
+			t.Errorf("Unexpected arg %s in result %v", arg, args)
+		}
+	}
+	if _, err := sshArgs("@bastion", "", "127.0.0.1:45678", "db.internal:3306"); err == nil {
+		t.Error("Expected error from sshArgs with invalid bastion, but err was nil")
+	}
+}
+
+func TestOpenSSHTunnel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Fake ssh client requires symlink support")
+	}
+
+	// Put a symlink named ssh, pointing to this test binary, at the front of PATH
+	binDir, err := ioutil.TempDir("", "skeema-fake-ssh")
+	if err != nil {
+		t.Fatalf("Unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(binDir)
+	if err := os.Symlink(os.Args[0], filepath.Join(binDir, "ssh")); err != nil {
+		t.Fatalf("Unable to create symlink: %v", err)
+	}
+	oldPath := os.Getenv("PATH")
+	os.Setenv("PATH", binDir+string(os.PathListSeparator)+oldPath)
+	defer os.Setenv("PATH", oldPath)
+
+	// Target is a simple echo server
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unable to listen: %v", err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(conn, conn)
+				conn.Close()
+			}()
+		}
+	}()
+
+	tunnel, err := OpenSSHTunnel("deploy@bastion:2222", "", echo.Addr().String())
+	if err != nil {
+		t.Fatalf("Unexpected error from OpenSSHTunnel: %v", err)
+	}
+	conn, err := net.Dial("tcp", tunnel.LocalAddr)
+	if err != nil {
+		t.Fatalf("Unable to connect through tunnel: %v", err)
+	}
+	fmt.Fprint(conn, "ping")
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "ping" {
+		t.Errorf("Unexpected result reading through tunnel: %q, err=%v", buf, err)
+	}
+	conn.Close()
+	if again, err := OpenSSHTunnel("deploy@bastion:2222", "", echo.Addr().String()); err != nil || again != tunnel {
+		t.Errorf("Expected identical request to return same tunnel; instead found %v, err=%v", again, err)
+	}
+
+	// SSH failures should be distinguishable and include ssh's own error output
+	_, err = OpenSSHTunnel("deploy@fail.example", "", echo.Addr().String())
+	if tunnelErr, ok := err.(*SSHTunnelError); !ok {
+		t.Errorf("Expected error to be an *SSHTunnelError, instead found %T %v", err, err)
+	} else if !strings.Contains(tunnelErr.Error(), "Could not resolve hostname") || tunnelErr.Bastion != "deploy@fail.example" {
+		t.Errorf("Unexpected error message: %s", tunnelErr)
+	}
+
+	// After teardown, the local end of the tunnel should no longer accept
+	// connections
+	CloseSSHTunnels()
+	if conn, err := net.Dial("tcp", tunnel.LocalAddr); err == nil {
+		conn.Close()
+		t.Error("Expected tunnel to be closed, but connection succeeded")
+	}
+}
+
+// TestRealSSHTunnel is only run if the SKEEMA_TEST_SSH env var is set to a
+// bastion spec, and SKEEMA_TEST_SSH_TARGET is set to a host:port that is
+// reachable from the bastion. All authentication uses the local ssh client's
+// configuration and agent, unless SKEEMA_TEST_SSH_KEY is set.
+func TestRealSSHTunnel(t *testing.T) {
+	bastion, target := os.Getenv("SKEEMA_TEST_SSH"), os.Getenv("SKEEMA_TEST_SSH_TARGET")
+	if bastion == "" || target == "" {
+		t.Skip("SKEEMA_TEST_SSH and SKEEMA_TEST_SSH_TARGET env vars are not set")
+	}
+	defer CloseSSHTunnels()
+	tunnel, err := OpenSSHTunnel(bastion, os.Getenv("SKEEMA_TEST_SSH_KEY"), target)
+	if err != nil {
+		t.Fatalf("Unexpected error from OpenSSHTunnel: %v", err)
+	}
+	conn, err := net.Dial("tcp", tunnel.LocalAddr)
+	if err != nil {
+		t.Fatalf("Unable to connect through tunnel: %v", err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,168 @@
+package util
+
+import (
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/skeema/mybase"
+)
+
+// TLSOptions represents the configuration of TLS for database connections, as
+// specified by the ssl-mode, ssl-ca, ssl-cert, and ssl-key options.
+type TLSOptions struct {
+	Mode string // one of "", "disabled", "preferred", "required", "verify-ca", "verify-identity"
+	CA   string // path to PEM file of CA certs for verifying the server cert
+	Cert string // path to PEM file of client cert
+	Key  string // path to PEM file of client private key
+}
+
+// TLSOptionsForConfig returns TLSOptions based on the configuration in cfg. An
+// error is returned if the options are invalid or incompatible with each other.
+// This function does not read any of the cert or key files; see TLSConfig.
+func TLSOptionsForConfig(cfg *mybase.Config) (opts TLSOptions, err error) {
+	opts.Mode, err = cfg.GetEnum("ssl-mode", "", "disabled", "preferred", "required", "verify-ca", "verify-identity")
+	if err != nil {
+		return TLSOptions{}, err
+	}
+	opts.CA, opts.Cert, opts.Key = cfg.Get("ssl-ca"), cfg.Get("ssl-cert"), cfg.Get("ssl-key")
+	if (opts.Cert == "") != (opts.Key == "") {
+		return TLSOptions{}, errors.New("Options ssl-cert and ssl-key must be used together")
+	} else if opts.Mode == "disabled" && (opts.CA != "" || opts.Cert != "") {
+		return TLSOptions{}, errors.New("Options ssl-ca, ssl-cert, and ssl-key cannot be used with ssl-mode=disabled")
+	} else if opts.Mode == "verify-ca" && opts.CA == "" {
+		return TLSOptions{}, errors.New("Option ssl-mode=verify-ca requires ssl-ca")
+	}
+
+	// As with the mysql client, supplying a CA without an explicit ssl-mode
+	// implies verify-ca
+	if opts.Mode == "" && opts.CA != "" {
+		opts.Mode = "verify-ca"
+	}
+	return opts, nil
+}
+
+// Configured returns true if any TLS options were set, or false if the default
+// TLS behavior should be used.
+func (opts TLSOptions) Configured() bool {
+	return opts != TLSOptions{}
+}
+
+// TLSConfig returns a *tls.Config for the receiver's options, loading the CA
+// and client cert/key pair from their files. An error is returned if any file
+// cannot be loaded, or if the client cert and key do not match. The returned
+// config is nil if the options don't require a custom config, i.e. one of the
+// driver's built-in tls param values suffices.
+//
+// With a client cert or CA, the driver always requires TLS; ssl-mode=preferred
+// is treated as required in this situation.
+func (opts TLSOptions) TLSConfig() (*tls.Config, error) {
+	if opts.Mode == "disabled" || (opts.CA == "" && opts.Cert == "") {
+		return nil, nil
+	}
+	config := &tls.Config{}
+	if opts.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(opts.Cert, opts.Key)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to load ssl-cert %s and ssl-key %s: %s", opts.Cert, opts.Key, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if opts.CA != "" {
+		pem, err := ioutil.ReadFile(opts.CA)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to load ssl-ca %s: %s", opts.CA, err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("Unable to load ssl-ca %s: no PEM-encoded certificates found", opts.CA)
+		}
+	}
+
+	switch opts.Mode {
+	case "verify-identity":
+		// The driver sets ServerName to the connection's host, so the standard
+		// verification checks both the chain and the hostname
+	case "verify-ca":
+		// Verify the chain against the CA, but not the hostname. The driver only
+		// skips its own hostname logic if InsecureSkipVerify is set, so the
+		// chain verification must be done manually.
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = verifyCertChain(config.RootCAs)
+	default: // "", "preferred", "required"
+		config.InsecureSkipVerify = true
+	}
+	return config, nil
+}
+
+// verifyCertChain returns a function suitable for use in
+// tls.Config.VerifyPeerCertificate, which verifies the server's cert chain
+// against roots without verifying the server's hostname.
+func verifyCertChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("server did not present a certificate")
+		}
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for n, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs[n] = cert
+		}
+		verifyOpts := x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range certs[1:] {
+			verifyOpts.Intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(verifyOpts)
+		return err
+	}
+}
+
+var registeredTLSConfigs struct {
+	sync.Mutex
+	names map[TLSOptions]string
+}
+
+func init() {
+	registeredTLSConfigs.names = make(map[TLSOptions]string)
+}
+
+// DSNParam returns the value to use for the tls param in a go-sql-driver/mysql
+// DSN. If a custom *tls.Config is needed, it is registered with the driver, and
+// its name is returned. Identical options always return the same name.
+func (opts TLSOptions) DSNParam() (string, error) {
+	registeredTLSConfigs.Lock()
+	defer registeredTLSConfigs.Unlock()
+	if name, already := registeredTLSConfigs.names[opts]; already {
+		return name, nil
+	}
+	config, err := opts.TLSConfig()
+	if err != nil {
+		return "", err
+	} else if config == nil {
+		builtIn := map[string]string{
+			"":                "preferred",
+			"disabled":        "false",
+			"preferred":       "preferred",
+			"required":        "skip-verify",
+			"verify-identity": "true",
+		}
+		return builtIn[opts.Mode], nil
+	}
+	name := fmt.Sprintf("skeema-%x", sha1.Sum([]byte(opts.Mode+"\x00"+opts.CA+"\x00"+opts.Cert+"\x00"+opts.Key)))
+	if err := mysql.RegisterTLSConfig(name, config); err != nil {
+		return "", err
+	}
+	registeredTLSConfigs.names[opts] = name
+	return name, nil
+}
@@ -0,0 +1,167 @@
+package util
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/skeema/mybase"
+)
+
+const (
+	testCA        = "testdata/tls/ca.pem"
+	testCert      = "testdata/tls/client-cert.pem"
+	testKey       = "testdata/tls/client-key.pem"
+	testOtherCert = "testdata/tls/other-cert.pem"
+	testOtherKey  = "testdata/tls/other-key.pem"
+)
+
+func getTLSOptions(t *testing.T, cliFlags string) (TLSOptions, error) {
+	t.Helper()
+	cmd := mybase.NewCommand("tlstest", "", "", nil)
+	AddGlobalOptions(cmd)
+	cfg := mybase.ParseFakeCLI(t, cmd, "tlstest "+cliFlags)
+	return TLSOptionsForConfig(cfg)
+}
+
+func TestTLSOptionsForConfig(t *testing.T) {
+	if opts, err := getTLSOptions(t, ""); err != nil || opts.Configured() {
+		t.Errorf("Expected default options to be unconfigured, instead found %+v, %v", opts, err)
+	}
+	if opts, err := getTLSOptions(t, "--ssl-ca="+testCA); err != nil || opts.Mode != "verify-ca" {
+		t.Errorf("Expected ssl-ca to imply ssl-mode verify-ca, instead found %+v, %v", opts, err)
+	}
+	if opts, err := getTLSOptions(t, "--ssl-ca="+testCA+" --ssl-mode=verify-identity"); err != nil || opts.Mode != "verify-identity" {
+		t.Errorf("Unexpected result: %+v, %v", opts, err)
+	}
+	for _, cliFlags := range []string{
+		"--ssl-cert=" + testCert,
+		"--ssl-key=" + testKey,
+		"--ssl-mode=disabled --ssl-ca=" + testCA,
+		"--ssl-mode=verify-ca",
+	} {
+		if _, err := getTLSOptions(t, cliFlags); err == nil {
+			t.Errorf("Expected error from options %s, but err was nil", cliFlags)
+		}
+	}
+
+	// Invalid ssl-mode values are rejected when the command-line is parsed
+	cmd := mybase.NewCommand("tlstest", "", "", nil)
+	AddGlobalOptions(cmd)
+	if _, err := mybase.ParseCLI(cmd, []string{"tlstest", "--ssl-mode=bogus"}); err == nil {
+		t.Error("Expected error from --ssl-mode=bogus, but err was nil")
+	}
+}
+
+func TestTLSOptionsTLSConfig(t *testing.T) {
+	// Options that can use the driver's built-in configs
+	for _, opts := range []TLSOptions{{}, {Mode: "disabled"}, {Mode: "required"}, {Mode: "verify-identity"}} {
+		if config, err := opts.TLSConfig(); config != nil || err != nil {
+			t.Errorf("Expected %+v to return nil config, instead found %+v, %v", opts, config, err)
+		}
+	}
+
+	// Client cert without CA: certificate presented, but server cert unverified
+	opts := TLSOptions{Mode: "required", Cert: testCert, Key: testKey}
+	config, err := opts.TLSConfig()
+	if err != nil {
+		t.Fatalf("Unexpected error from TLSConfig: %v", err)
+	}
+	if len(config.Certificates) != 1 || config.RootCAs != nil || !config.InsecureSkipVerify || config.VerifyPeerCertificate != nil {
+		t.Errorf("Unexpected config returned: %+v", config)
+	}
+	leaf, err := x509.ParseCertificate(config.Certificates[0].Certificate[0])
+	if err != nil || leaf.Subject.CommonName != "skeema-test-client" {
+		t.Errorf("Unexpected client cert in config: %+v, %v", leaf, err)
+	}
+
+	// verify-identity: standard verification against the CA, with the driver
+	// supplying ServerName
+	opts = TLSOptions{Mode: "verify-identity", CA: testCA, Cert: testCert, Key: testKey}
+	if config, err = opts.TLSConfig(); err != nil {
+		t.Fatalf("Unexpected error from TLSConfig: %v", err)
+	}
+	if len(config.Certificates) != 1 || config.RootCAs == nil || config.InsecureSkipVerify || config.ServerName != "" {
+		t.Errorf("Unexpected config returned: %+v", config)
+	}
+
+	// verify-ca: chain verified against the CA via callback, hostname ignored
+	opts = TLSOptions{Mode: "verify-ca", CA: testCA}
+	if config, err = opts.TLSConfig(); err != nil {
+		t.Fatalf("Unexpected error from TLSConfig: %v", err)
+	}
+	if len(config.Certificates) != 0 || config.RootCAs == nil || !config.InsecureSkipVerify || config.VerifyPeerCertificate == nil {
+		t.Fatalf("Unexpected config returned: %+v", config)
+	}
+	if err := config.VerifyPeerCertificate([][]byte{readCertDER(t, testCert)}, nil); err != nil {
+		t.Errorf("Expected cert signed by CA to be accepted, instead found error %v", err)
+	}
+	if err := config.VerifyPeerCertificate([][]byte{readCertDER(t, testOtherCert)}, nil); err == nil {
+		t.Error("Expected cert not signed by CA to be rejected, but err was nil")
+	}
+	if err := config.VerifyPeerCertificate(nil, nil); err == nil {
+		t.Error("Expected missing server cert to be rejected, but err was nil")
+	}
+
+	// Problems loading files
+	for _, opts := range []TLSOptions{
+		{Cert: testCert, Key: testOtherKey},        // mismatched pair
+		{Cert: testCert, Key: "testdata/tls/nope"}, // missing file
+		{Cert: testCA, Key: testCA},                // not a key
+		{CA: testKey, Mode: "verify-ca"},           // no certs in CA file
+		{CA: "testdata/tls/nope", Mode: "verify-ca"},
+	} {
+		if _, err := opts.TLSConfig(); err == nil {
+			t.Errorf("Expected error from %+v, but err was nil", opts)
+		} else if !strings.HasPrefix(err.Error(), "Unable to load") {
+			t.Errorf("Unexpected error message from %+v: %v", opts, err)
+		}
+	}
+}
+
+func TestTLSOptionsDSNParam(t *testing.T) {
+	cases := map[TLSOptions]string{
+		{}:                        "preferred",
+		{Mode: "disabled"}:        "false",
+		{Mode: "preferred"}:       "preferred",
+		{Mode: "required"}:        "skip-verify",
+		{Mode: "verify-identity"}: "true",
+	}
+	for opts, expected := range cases {
+		if actual, err := opts.DSNParam(); actual != expected || err != nil {
+			t.Errorf("Expected %+v to return %q, instead found %q, %v", opts, expected, actual, err)
+		}
+	}
+
+	opts := TLSOptions{Mode: "verify-ca", CA: testCA, Cert: testCert, Key: testKey}
+	name, err := opts.DSNParam()
+	if err != nil || !strings.HasPrefix(name, "skeema-") {
+		t.Fatalf("Unexpected result from DSNParam: %q, %v", name, err)
+	}
+	if again, err := opts.DSNParam(); again != name || err != nil {
+		t.Errorf("Expected identical options to return same name %q, instead found %q, %v", name, again, err)
+	}
+	opts.Mode = "verify-identity"
+	if other, err := opts.DSNParam(); other == name || err != nil {
+		t.Errorf("Expected different options to return different name, instead found %q, %v", other, err)
+	}
+	opts.Key = testOtherKey
+	if _, err := opts.DSNParam(); err == nil {
+		t.Error("Expected error from mismatched cert and key, but err was nil")
+	}
+}
+
+func readCertDER(t *testing.T, path string) []byte {
+	t.Helper()
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unable to read %s: %v", path, err)
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		t.Fatalf("No PEM data found in %s", path)
+	}
+	return block.Bytes
+}
@@ -0,0 +1,335 @@
+package workspace
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/VividCortex/mysqlerr"
+	log "github.com/sirupsen/logrus"
+	"github.com/skeema/skeema/internal/fs"
+	"github.com/skeema/tengo"
+)
+
+// persistTableName is the name of a bookkeeping table in a persisted temp
+// schema, tracking a hash of the DDL used to create each object. It is excluded
+// from the results of TempSchema.IntrospectSchema.
+const persistTableName = "_skeema_persist"
+
+// persistFormat should be incremented whenever the hashing logic changes, to
+// force a full rebuild of any previously-persisted temp schemas.
+const persistFormat = "1"
+
+// persistentWorkspace is implemented by Workspaces that are capable of
+// retaining objects between uses, so that ExecLogicalSchema may skip executing
+// statements for objects which have not changed.
+type persistentWorkspace interface {
+	Workspace
+
+	// reuseObjects drops any previously-persisted objects which are not
+	// present, or have a different definition, in logicalSchema. It returns a
+	// set of keys for the persisted objects which remain in place and do not
+	// need to be created again.
+	reuseObjects(logicalSchema *fs.LogicalSchema, opts Options) (map[tengo.ObjectKey]bool, error)
+
+	// persistObjects records the hashes of all objects in the logical schema
+	// most recently supplied to reuseObjects, other than the failed ones.
+	persistObjects(failed []tengo.ObjectKey) error
+}
+
+// persistFingerprint returns a hash of everything, besides the DDL itself,
+// which affects how objects are created in the temp schema. A persisted temp
+// schema with a different fingerprint must be fully rebuilt.
+func (ts *TempSchema) persistFingerprint(opts Options) (string, error) {
+	db, err := ts.inst.CachedConnectionPool("", "")
+	if err != nil {
+		return "", err
+	}
+	var version, sqlMode string
+	if err := db.QueryRow("SELECT @@global.version, @@session.sql_mode").Scan(&version, &sqlMode); err != nil {
+		return "", err
+	}
+	return hashString(persistFormat, ts.inst.Flavor().String(), version, sqlMode, opts.DefaultCharacterSet, opts.DefaultCollation), nil
+}
+
+// loadPersisted returns the object hashes stored by a previous run. If the
+// temp schema has no state, has a different fingerprint, or contains objects
+// other than the ones tracked in its state (for example due to an interrupted
+// previous run, or manual manipulation), a nil map is returned, indicating the
+// temp schema must be fully rebuilt.
+func (ts *TempSchema) loadPersisted() (map[tengo.ObjectKey]string, error) {
+	db, err := ts.inst.CachedConnectionPool(ts.schemaName, "")
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Type string `db:"object_type"`
+		Name string `db:"object_name"`
+		Hash string `db:"hash"`
+	}
+	query := fmt.Sprintf("SELECT object_type, object_name, hash FROM %s", tengo.EscapeIdentifier(persistTableName))
+	if err := db.Select(&rows, query); tengo.IsDatabaseError(err, mysqlerr.ER_NO_SUCH_TABLE) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var fingerprint string
+	hashes := make(map[tengo.ObjectKey]string, len(rows))
+	for _, row := range rows {
+		if row.Type == "" {
+			fingerprint = row.Hash
+		} else {
+			hashes[tengo.ObjectKey{Type: tengo.ObjectType(row.Type), Name: row.Name}] = row.Hash
+		}
+	}
+	if fingerprint != ts.fingerprint {
+		log.Debugf("Persisted temp schema %s on %s has a different fingerprint, so it will be rebuilt", ts.schemaName, ts.inst)
+		return nil, nil
+	}
+
+	actual, err := ts.objectKeys()
+	if err != nil {
+		return nil, err
+	}
+	stale := len(actual) != len(hashes)
+	for _, key := range actual {
+		if _, ok := hashes[key]; !ok {
+			stale = true
+		}
+	}
+	if stale {
+		log.Debugf("Persisted temp schema %s on %s does not match its recorded state, so it will be rebuilt", ts.schemaName, ts.inst)
+		return nil, nil
+	}
+	return hashes, nil
+}
+
+// objectKeys returns keys for all tables, views, sequences, routines, events,
+// and triggers currently in the temp schema, other than the bookkeeping table.
+func (ts *TempSchema) objectKeys() ([]tengo.ObjectKey, error) {
+	db, err := ts.inst.CachedConnectionPool("", "")
+	if err != nil {
+		return nil, err
+	}
+	var objects []struct {
+		Name string `db:"name"`
+		Type string `db:"type"`
+	}
+	query := `
+		SELECT table_name AS name,
+		       CASE table_type WHEN 'VIEW' THEN 'view'
+		                       WHEN 'SEQUENCE' THEN 'sequence'
+		                       ELSE 'table' END AS type
+		FROM   information_schema.tables
+		WHERE  table_schema = ? AND table_name != ?
+		UNION ALL
+		SELECT routine_name AS name, LOWER(routine_type) AS type
+		FROM   information_schema.routines
+		WHERE  routine_schema = ?
+		UNION ALL
+		SELECT event_name AS name, 'event' AS type
+		FROM   information_schema.events
+		WHERE  event_schema = ?
+		UNION ALL
+		SELECT trigger_name AS name, 'trigger' AS type
+		FROM   information_schema.triggers
+		WHERE  trigger_schema = ?`
+	if err := db.Select(&objects, query, ts.schemaName, persistTableName, ts.schemaName, ts.schemaName, ts.schemaName); err != nil {
+		return nil, err
+	}
+	keys := make([]tengo.ObjectKey, len(objects))
+	for n, obj := range objects {
+		keys[n] = tengo.ObjectKey{Type: tengo.ObjectType(obj.Type), Name: obj.Name}
+	}
+	return keys, nil
+}
+
+// reuseObjects satisfies the persistentWorkspace interface. If ts is not
+// persistent, or was just rebuilt, no objects are reused.
+func (ts *TempSchema) reuseObjects(logicalSchema *fs.LogicalSchema, opts Options) (map[tengo.ObjectKey]bool, error) {
+	if !ts.persist {
+		return nil, nil
+	}
+
+	// Any ALTERs are included in the hash of the object they modify
+	statements := make(map[tengo.ObjectKey][]string, len(logicalSchema.Creates))
+	for key, stmt := range logicalSchema.Creates {
+		statements[key] = []string{paramsForStatement(stmt, opts), stmt.Body()}
+	}
+	for _, stmt := range logicalSchema.Alters {
+		key := stmt.ObjectKey()
+		statements[key] = append(statements[key], stmt.Body())
+	}
+	ts.wanted = make(map[tengo.ObjectKey]string, len(statements))
+	for key, parts := range statements {
+		ts.wanted[key] = hashString(parts...)
+	}
+
+	// Views must be re-created if any other object they may depend upon has
+	// changed, since a view's stored definition is resolved at creation time
+	reused := make(map[tengo.ObjectKey]bool, len(ts.persisted))
+	var dependencyChanged bool
+	for key, hash := range ts.persisted {
+		if ts.wanted[key] == hash {
+			reused[key] = true
+		} else if key.Type != tengo.ObjectTypeView {
+			dependencyChanged = true
+		}
+	}
+	if dependencyChanged {
+		for key := range reused {
+			if key.Type == tengo.ObjectTypeView {
+				delete(reused, key)
+			}
+		}
+	}
+
+	// Triggers are dropped along with their table, and the server orders them by
+	// creation time, so they can only be reused if no tables or triggers changed
+	var triggersChanged bool
+	for key, hash := range ts.persisted {
+		if (key.Type == tengo.ObjectTypeTable || key.Type == tengo.ObjectTypeTrigger) && ts.wanted[key] != hash {
+			triggersChanged = true
+		}
+	}
+	for key, hash := range ts.wanted {
+		if key.Type == tengo.ObjectTypeTrigger && ts.persisted[key] != hash {
+			triggersChanged = true
+		}
+	}
+	if triggersChanged {
+		for key := range reused {
+			if key.Type == tengo.ObjectTypeTrigger {
+				delete(reused, key)
+			}
+		}
+	}
+
+	// Update the recorded state before dropping anything, so that an interrupted
+	// run leaves objects which are untracked, forcing a rebuild next time
+	kept := make(map[tengo.ObjectKey]string, len(reused))
+	var drops []tengo.ObjectKey
+	for key, hash := range ts.persisted {
+		if reused[key] {
+			kept[key] = hash
+		} else {
+			drops = append(drops, key)
+		}
+	}
+	if err := ts.writePersisted(kept); err != nil {
+		return nil, err
+	}
+	if err := ts.dropObjects(drops); err != nil {
+		return nil, err
+	}
+	ts.persisted = kept
+	if len(reused) > 0 {
+		log.Debugf("Reusing %d unchanged objects in persisted temp schema %s on %s", len(reused), ts.schemaName, ts.inst)
+	}
+	return reused, nil
+}
+
+// persistObjects satisfies the persistentWorkspace interface.
+func (ts *TempSchema) persistObjects(failed []tengo.ObjectKey) error {
+	if !ts.persist {
+		return nil
+	}
+	failedKeys := make(map[tengo.ObjectKey]bool, len(failed))
+	for _, key := range failed {
+		failedKeys[key] = true
+	}
+	hashes := make(map[tengo.ObjectKey]string, len(ts.wanted))
+	for key, hash := range ts.wanted {
+		if !failedKeys[key] {
+			hashes[key] = hash
+		}
+	}
+	if err := ts.writePersisted(hashes); err != nil {
+		return fmt.Errorf("Cannot record state of persisted temp schema on %s: %s", ts.inst, err)
+	}
+	ts.persisted = hashes
+	return nil
+}
+
+// writePersisted replaces the contents of the bookkeeping table with the
+// supplied object hashes, along with ts's fingerprint.
+func (ts *TempSchema) writePersisted(hashes map[tengo.ObjectKey]string) error {
+	params := ""
+	if ts.skipBinlog {
+		params = "sql_log_bin=0"
+	}
+	db, err := ts.inst.CachedConnectionPool(ts.schemaName, params)
+	if err != nil {
+		return err
+	}
+	escapedTable := tengo.EscapeIdentifier(persistTableName)
+	create := `CREATE TABLE IF NOT EXISTS %s (
+		object_type varchar(20) NOT NULL,
+		object_name varchar(64) NOT NULL,
+		hash char(40) NOT NULL,
+		PRIMARY KEY (object_type, object_name)
+	) DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin`
+	if _, err := db.Exec(fmt.Sprintf(create, escapedTable)); err != nil {
+		return err
+	}
+	if _, err := db.Exec("DELETE FROM " + escapedTable); err != nil {
+		return err
+	}
+	placeholders := []string{"(?, ?, ?)"}
+	args := []interface{}{"", "", ts.fingerprint}
+	for key, hash := range hashes {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, string(key.Type), key.Name, hash)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (object_type, object_name, hash) VALUES %s", escapedTable, strings.Join(placeholders, ", "))
+	_, err = db.Exec(query, args...)
+	return err
+}
+
+// dropObjects drops the supplied objects from the temp schema. Views are
+// dropped first, in a single statement, since they may depend on other views.
+func (ts *TempSchema) dropObjects(keys []tengo.ObjectKey) error {
+	params := "foreign_key_checks=0"
+	if ts.skipBinlog {
+		params += "&sql_log_bin=0"
+	}
+	db, err := ts.inst.CachedConnectionPool(ts.schemaName, params)
+	if err != nil {
+		return err
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		// Triggers must be dropped before any tables, since dropping a table also
+		// drops its triggers
+		if isTriggerI, isTriggerJ := keys[i].Type == tengo.ObjectTypeTrigger, keys[j].Type == tengo.ObjectTypeTrigger; isTriggerI != isTriggerJ {
+			return isTriggerI
+		}
+		return keys[i].String() < keys[j].String()
+	})
+	var views, others []string
+	for _, key := range keys {
+		if key.Type == tengo.ObjectTypeView {
+			views = append(views, tengo.EscapeIdentifier(key.Name))
+		} else {
+			others = append(others, fmt.Sprintf("DROP %s %s", key.Type.Caps(), tengo.EscapeIdentifier(key.Name)))
+		}
+	}
+	if len(views) > 0 {
+		if _, err := db.Exec("DROP VIEW " + strings.Join(views, ", ")); err != nil {
+			return fmt.Errorf("Cannot drop views in persisted temp schema on %s: %s", ts.inst, err)
+		}
+	}
+	for _, stmt := range others {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("Cannot drop objects in persisted temp schema on %s: %s", ts.inst, err)
+		}
+	}
+	return nil
+}
+
+// hashString returns a hex-encoded SHA1 of the supplied strings.
+func hashString(parts ...string) string {
+	sum := sha1.Sum([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
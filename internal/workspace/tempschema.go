@@ -10,10 +10,16 @@ import (
 
 // TempSchema is a Workspace that exists as a schema that is created on another
 // database instance. The schema is cleaned up when done interacting with the
-// workspace.
+// workspace, unless it is persisted for reuse. A persisted temp schema retains
+// its objects between runs, along with enough state to determine which of
+// them are still valid.
 type TempSchema struct {
 	schemaName  string
 	keepSchema  bool
+	persist     bool
+	fingerprint string
+	persisted   map[tengo.ObjectKey]string // hashes of objects retained from a previous run
+	wanted      map[tengo.ObjectKey]string // hashes of objects in the current logical schema
 	concurrency int
 	skipBinlog  bool
 	inst        *tengo.Instance
@@ -28,7 +34,8 @@ func NewTempSchema(opts Options) (ts *TempSchema, err error) {
 	}
 	ts = &TempSchema{
 		schemaName:  opts.SchemaName,
-		keepSchema:  opts.CleanupAction == CleanupActionNone,
+		keepSchema:  opts.CleanupAction == CleanupActionNone || opts.CleanupAction == CleanupActionPersist,
+		persist:     opts.CleanupAction == CleanupActionPersist,
 		inst:        opts.Instance,
 		concurrency: opts.Concurrency,
 		skipBinlog:  opts.SkipBinlog,
@@ -47,14 +54,32 @@ func NewTempSchema(opts Options) (ts *TempSchema, err error) {
 		}
 	}()
 
+	if ts.persist {
+		if ts.fingerprint, err = ts.persistFingerprint(opts); err != nil {
+			return ts, fmt.Errorf("Unable to examine temp schema settings on %s: %s", ts.inst, err)
+		}
+	}
+
 	createOpts := tengo.SchemaCreationOptions{
 		DefaultCharSet:   opts.DefaultCharacterSet,
 		DefaultCollation: opts.DefaultCollation,
 		SkipBinlog:       opts.SkipBinlog,
 	}
-	if has, err := ts.inst.HasSchema(ts.schemaName); err != nil {
+	has, err := ts.inst.HasSchema(ts.schemaName)
+	if err != nil {
 		return ts, fmt.Errorf("Unable to check for existence of temp schema on %s: %s", ts.inst, err)
-	} else if has {
+	} else if has && ts.persist && !opts.Rebuild {
+		if ts.persisted, err = ts.loadPersisted(); err != nil {
+			return ts, fmt.Errorf("Unable to read state of persisted temp schema on %s: %s", ts.inst, err)
+		}
+	}
+	if has && ts.persisted == nil {
+		// The bookkeeping table of a persisted temp schema is expected to have
+		// rows, so it must be dropped before the other tables
+		if err := ts.dropPersistTable(); err != nil {
+			return ts, fmt.Errorf("Cannot drop existing temp schema tables on %s: %s", ts.inst, err)
+		}
+
 		// Attempt to drop any tables already present in tempSchema, but fail if
 		// any of them actually have 1 or more rows
 		dropOpts := tengo.BulkDropOptions{
@@ -68,12 +93,20 @@ func NewTempSchema(opts Options) (ts *TempSchema, err error) {
 		if err := ts.inst.DropRoutinesInSchema(ts.schemaName, dropOpts); err != nil {
 			return ts, fmt.Errorf("Cannot drop existing temp schema routines on %s: %s", ts.inst, err)
 		}
+		if err := ts.inst.DropViewsInSchema(ts.schemaName, dropOpts); err != nil {
+			return ts, fmt.Errorf("Cannot drop existing temp schema views on %s: %s", ts.inst, err)
+		}
+		if err := ts.inst.DropEventsInSchema(ts.schemaName, dropOpts); err != nil {
+			return ts, fmt.Errorf("Cannot drop existing temp schema events on %s: %s", ts.inst, err)
+		}
+		if err := ts.inst.DropSequencesInSchema(ts.schemaName, dropOpts); err != nil {
+			return ts, fmt.Errorf("Cannot drop existing temp schema sequences on %s: %s", ts.inst, err)
+		}
 		if err := ts.inst.AlterSchema(ts.schemaName, createOpts); err != nil {
 			return ts, fmt.Errorf("Cannot alter existing temp schema charset and collation on %s: %s", ts.inst, err)
 		}
-	} else {
-		_, err = ts.inst.CreateSchema(ts.schemaName, createOpts)
-		if err != nil {
+	} else if !has {
+		if _, err = ts.inst.CreateSchema(ts.schemaName, createOpts); err != nil {
 			return ts, fmt.Errorf("Cannot create temporary schema on %s: %s", ts.inst, err)
 		}
 	}
@@ -87,14 +120,26 @@ func (ts *TempSchema) ConnectionPool(params string) (*sqlx.DB, error) {
 }
 
 // IntrospectSchema introspects and returns the temporary workspace schema.
+// The bookkeeping table of a persisted temp schema is omitted from the result.
 func (ts *TempSchema) IntrospectSchema() (*tengo.Schema, error) {
-	return ts.inst.Schema(ts.schemaName)
+	schema, err := ts.inst.Schema(ts.schemaName)
+	if err != nil || !ts.persist {
+		return schema, err
+	}
+	tables := make([]*tengo.Table, 0, len(schema.Tables))
+	for _, table := range schema.Tables {
+		if table.Name != persistTableName {
+			tables = append(tables, table)
+		}
+	}
+	schema.Tables = tables
+	return schema, nil
 }
 
 // Cleanup either drops the temporary schema (if not using reuse-temp-schema)
-// or just drops all tables in the schema (if using reuse-temp-schema). If any
+// or just drops all objects in the schema (if using reuse-temp-schema). If any
 // tables have any rows in the temp schema, the cleanup aborts and an error is
-// returned.
+// returned. A persisted temp schema is left as-is.
 func (ts *TempSchema) Cleanup() error {
 	if ts.releaseLock == nil {
 		return errors.New("Cleanup() called multiple times on same TempSchema")
@@ -109,15 +154,41 @@ func (ts *TempSchema) Cleanup() error {
 		OnlyIfEmpty:    true,
 		SkipBinlog:     ts.skipBinlog,
 	}
-	if ts.keepSchema {
+	if ts.persist {
+		return nil
+	} else if ts.keepSchema {
 		if err := ts.inst.DropTablesInSchema(ts.schemaName, dropOpts); err != nil {
 			return fmt.Errorf("Cannot drop tables in temporary schema on %s: %s", ts.inst, err)
 		}
 		if err := ts.inst.DropRoutinesInSchema(ts.schemaName, dropOpts); err != nil {
 			return fmt.Errorf("Cannot drop routines in temporary schema on %s: %s", ts.inst, err)
 		}
+		if err := ts.inst.DropViewsInSchema(ts.schemaName, dropOpts); err != nil {
+			return fmt.Errorf("Cannot drop views in temporary schema on %s: %s", ts.inst, err)
+		}
+		if err := ts.inst.DropEventsInSchema(ts.schemaName, dropOpts); err != nil {
+			return fmt.Errorf("Cannot drop events in temporary schema on %s: %s", ts.inst, err)
+		}
+		if err := ts.inst.DropSequencesInSchema(ts.schemaName, dropOpts); err != nil {
+			return fmt.Errorf("Cannot drop sequences in temporary schema on %s: %s", ts.inst, err)
+		}
 	} else if err := ts.inst.DropSchema(ts.schemaName, dropOpts); err != nil {
 		return fmt.Errorf("Cannot drop temporary schema on %s: %s", ts.inst, err)
 	}
 	return nil
 }
+
+// dropPersistTable drops the bookkeeping table of a persisted temp schema, if
+// present.
+func (ts *TempSchema) dropPersistTable() error {
+	params := ""
+	if ts.skipBinlog {
+		params = "sql_log_bin=0"
+	}
+	db, err := ts.inst.CachedConnectionPool(ts.schemaName, params)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("DROP TABLE IF EXISTS " + tengo.EscapeIdentifier(persistTableName))
+	return err
+}
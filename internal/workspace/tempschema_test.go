@@ -1,8 +1,11 @@
 package workspace
 
 import (
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/skeema/tengo"
 )
 
 func (s WorkspaceIntegrationSuite) TestTempSchema(t *testing.T) {
@@ -102,6 +105,101 @@ func (s WorkspaceIntegrationSuite) TestTempSchemaCleanupDrop(t *testing.T) {
 	}
 }
 
+func (s WorkspaceIntegrationSuite) TestTempSchemaPersist(t *testing.T) {
+	dir := s.getParsedDir(t, "testdata/simple", "--temp-schema-persist")
+	opts, err := OptionsForDir(dir, s.d.Instance)
+	if err != nil {
+		t.Fatalf("Unexpected error from OptionsForDir: %s", err)
+	}
+	opts.LockWaitTimeout = 100 * time.Millisecond
+	logicalSchema := dir.LogicalSchemas[0]
+	execLogicalSchema := func(expectFatal bool) *Schema {
+		t.Helper()
+		wsSchema, err := ExecLogicalSchema(logicalSchema, opts)
+		if expectFatal {
+			if err == nil {
+				t.Fatal("Expected fatal error from ExecLogicalSchema, but err was nil")
+			}
+			return nil
+		} else if err != nil {
+			t.Fatalf("Unexpected error from ExecLogicalSchema: %s", err)
+		} else if len(wsSchema.Failures) > 0 {
+			t.Fatalf("Expected no StatementErrors, instead found %d", len(wsSchema.Failures))
+		} else if len(wsSchema.Tables) != 4 || wsSchema.HasTable(persistTableName) {
+			t.Fatalf("Expected exactly 4 tables, excluding %s; instead found %d", persistTableName, len(wsSchema.Tables))
+		}
+		return wsSchema
+	}
+	db, err := s.d.Connect("_skeema_tmp", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to temp schema: %s", err)
+	}
+	exec := func(query string) {
+		t.Helper()
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("Unexpected error from query %q: %s", query, err)
+		}
+	}
+	hasRows := func(tableName string) bool {
+		t.Helper()
+		has, err := s.d.TableHasRows("_skeema_tmp", tableName)
+		if err != nil {
+			t.Fatalf("Unexpected error from TableHasRows: %s", err)
+		}
+		return has
+	}
+
+	// First run creates everything, and leaves the objects in place. Insert rows
+	// into two tables, so that any re-creation of them is detectable.
+	execLogicalSchema(false)
+	exec("INSERT INTO users (name) VALUES ('someone')")
+	exec("INSERT INTO comments (post_id, user_id) VALUES (1, 1)")
+
+	// Second run, with one table modified, should only re-create that table
+	commentsKey := tengo.ObjectKey{Type: tengo.ObjectTypeTable, Name: "comments"}
+	stmt := logicalSchema.Creates[commentsKey]
+	stmt.Text = strings.Replace(stmt.Text, "`body` text,", "`body` text,\n  `edited_at` datetime DEFAULT NULL,", 1)
+	wsSchema := execLogicalSchema(false)
+	if _, ok := wsSchema.Table("comments").ColumnsByName()["edited_at"]; !ok {
+		t.Error("Expected modified table to be re-created, but it was not")
+	}
+	if hasRows("comments") {
+		t.Error("Expected modified table to be re-created without its rows, but rows still present")
+	}
+	if !hasRows("users") {
+		t.Error("Unmodified table was needlessly re-created")
+	}
+
+	// Forcing a rebuild should attempt to drop everything, which fails since a
+	// table has rows
+	opts.Rebuild = true
+	execLogicalSchema(true)
+	exec("DELETE FROM users")
+	execLogicalSchema(false)
+	opts.Rebuild = false
+
+	// Persisted state with a different fingerprint (e.g. from a different flavor
+	// or version) must cause a full rebuild
+	exec("INSERT INTO users (name) VALUES ('someone')")
+	exec("UPDATE " + persistTableName + " SET hash = 'nope' WHERE object_type = ''")
+	execLogicalSchema(true)
+	exec("DELETE FROM users")
+	execLogicalSchema(false)
+
+	// An untracked object in the temp schema makes the state stale, also
+	// requiring a full rebuild
+	exec("INSERT INTO users (name) VALUES ('someone')")
+	exec("CREATE TABLE untracked (id int)")
+	execLogicalSchema(true)
+	exec("DELETE FROM users")
+	execLogicalSchema(false)
+	if schema, err := s.d.Schema("_skeema_tmp"); err != nil {
+		t.Errorf("Unexpected error getting schema _skeema_tmp: %s", err)
+	} else if schema.HasTable("untracked") {
+		t.Error("Expected untracked table to be dropped by rebuild, but it still exists")
+	}
+}
+
 func TestTempSchemaNilInstance(t *testing.T) {
 	opts := Options{
 		Type:                TypeTempSchema,
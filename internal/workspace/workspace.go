@@ -9,6 +9,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -69,6 +70,11 @@ const (
 	// CleanupActionDestroy means to destroy the MySQL instance container in
 	// Shutdown(). Only used with TypeLocalDocker.
 	CleanupActionDestroy
+
+	// CleanupActionPersist means to leave the schema and all of its objects in
+	// place in Workspace.Cleanup(), so that subsequent runs only need to
+	// re-create objects that have changed. Only used with TypeTempSchema.
+	CleanupActionPersist
 )
 
 // Options represent different parameters controlling the workspace that is
@@ -88,6 +94,7 @@ type Options struct {
 	LockWaitTimeout     time.Duration
 	Concurrency         int
 	SkipBinlog          bool
+	Rebuild             bool // only TypeTempSchema with CleanupActionPersist
 }
 
 // New returns a pointer to a ready-to-use Workspace, using the configuration
@@ -127,7 +134,7 @@ func OptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, error) {
 		if !opts.Flavor.Known() && instance != nil {
 			opts.Flavor = instance.Flavor().Family()
 		}
-		opts.ContainerName = fmt.Sprintf("skeema-%s", strings.Replace(opts.Flavor.String(), ":", "-", -1))
+		opts.ContainerName = containerNameForFlavor(opts.Flavor)
 		if cleanup, err := dir.Config.GetEnum("docker-cleanup", "none", "stop", "destroy"); err != nil {
 			return Options{}, err
 		} else if cleanup == "stop" {
@@ -141,7 +148,10 @@ func OptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, error) {
 	} else {
 		opts.Type = TypeTempSchema
 		opts.Instance = instance
-		if !dir.Config.GetBool("reuse-temp-schema") {
+		if dir.Config.GetBool("temp-schema-persist") {
+			opts.CleanupAction = CleanupActionPersist
+			opts.Rebuild = dir.Config.GetBool("temp-schema-rebuild")
+		} else if !dir.Config.GetBool("reuse-temp-schema") {
 			opts.CleanupAction = CleanupActionDrop
 		}
 		if concurrency, err := dir.Config.GetInt("temp-schema-threads"); err != nil {
@@ -163,15 +173,46 @@ func OptionsForDir(dir *fs.Dir, instance *tengo.Instance) (Options, error) {
 	return opts, nil
 }
 
+// OptionsForDump returns Options for executing the statements of an fs.Dump,
+// based on the configuration in an fs.Dir. Since there is no database instance
+// in this situation, the workspace must be docker-based. If dir does not
+// configure a flavor, the supplied dumpFlavor (typically from the dump's
+// header) is used instead.
+func OptionsForDump(dir *fs.Dir, dumpFlavor tengo.Flavor) (Options, error) {
+	if requestedType, err := dir.Config.GetEnum("workspace", "temp-schema", "docker"); err != nil {
+		return Options{}, err
+	} else if requestedType != "docker" {
+		return Options{}, errors.New("Comparing against a dump file requires workspace=docker, since no database instance is available for a temp-schema")
+	}
+	opts, err := OptionsForDir(dir, nil)
+	if err != nil {
+		return Options{}, err
+	}
+	if !opts.Flavor.Known() {
+		opts.Flavor = dumpFlavor.Family()
+		opts.ContainerName = containerNameForFlavor(opts.Flavor)
+	}
+	if !opts.Flavor.Known() {
+		return Options{}, errors.New("Unable to determine database flavor of dump file. To set manually, use the \"flavor\" option")
+	}
+	return opts, nil
+}
+
+func containerNameForFlavor(flavor tengo.Flavor) string {
+	return fmt.Sprintf("skeema-%s", strings.Replace(flavor.String(), ":", "-", -1))
+}
+
 // AddCommandOptions adds workspace-related option definitions to the supplied
 // mybase.Command.
 func AddCommandOptions(cmd *mybase.Command) {
 	cmd.AddOptions("workspace",
 		mybase.StringOption("temp-schema", 't', "_skeema_tmp", "Name of temporary schema for intermediate operations, created and dropped each run"),
-		mybase.StringOption("temp-schema-binlog", 0, "auto", `Controls whether temp schema DDL operations are replicated (valid values: "on", "off", "auto")`),
+		mybase.EnumOption("temp-schema-binlog", 0, "auto", `Controls whether temp schema DDL operations are replicated (valid values: "on", "off", "auto")`, "on", "off", "auto"),
 		mybase.StringOption("temp-schema-threads", 0, "5", "Max number of concurrent CREATE/DROP with workspace=temp-schema"),
-		mybase.StringOption("workspace", 'w', "temp-schema", `Specifies where to run intermediate operations (valid values: "temp-schema", "docker")`),
-		mybase.StringOption("docker-cleanup", 0, "none", `With --workspace=docker, specifies how to clean up containers (valid values: "none", "stop", "destroy")`),
+		mybase.BoolOption("temp-schema-persist", 0, false, "Keep temp-schema between runs, only re-creating objects that have changed"),
+		mybase.BoolOption("temp-schema-rebuild", 0, false, "With temp-schema-persist, discard any previously persisted objects"),
+		mybase.EnumOption("workspace", 'w', "temp-schema", `Specifies where to run intermediate operations (valid values: "temp-schema", "docker")`, "temp-schema", "docker"),
+		mybase.EnumOption("docker-cleanup", 0, "none", `With --workspace=docker, specifies how to clean up containers (valid values: "none", "stop", "destroy")`, "none", "stop", "destroy"),
 		mybase.BoolOption("reuse-temp-schema", 0, false, "Do not drop temp-schema when done").Hidden(), // DEPRECATED -- hidden for this reason
 	)
 }
@@ -279,16 +320,53 @@ func ExecLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) (wsSchema
 		}
 	}()
 
-	// Run CREATEs in parallel
-	th := throttler.New(opts.Concurrency, len(logicalSchema.Creates))
-	for _, stmt := range logicalSchema.Creates {
+	// If the workspace retained objects from a previous run, statements only
+	// need to be executed for new or modified objects
+	pw, persistent := ws.(persistentWorkspace)
+	var reused map[tengo.ObjectKey]bool
+	if persistent {
+		if reused, fatalErr = pw.reuseObjects(logicalSchema, opts); fatalErr != nil {
+			return
+		}
+	}
+
+	// Run CREATEs in parallel, except for views and triggers, which are handled
+	// afterwards since they depend on other objects; and sequences, which are
+	// handled beforehand since column defaults may refer to them
+	var creates, views, triggers, sequences []*fs.Statement
+	for key, stmt := range logicalSchema.Creates {
+		if reused[key] {
+			continue
+		} else if stmt.ObjectType == tengo.ObjectTypeSequence {
+			sequences = append(sequences, stmt)
+		} else if stmt.ObjectType == tengo.ObjectTypeView {
+			views = append(views, stmt)
+		} else if stmt.ObjectType == tengo.ObjectTypeTrigger {
+			triggers = append(triggers, stmt)
+		} else {
+			creates = append(creates, stmt)
+		}
+	}
+	sequenceFailures := []*StatementError{}
+	for _, stmt := range sequences {
+		db, err := ws.ConnectionPool(paramsForStatement(stmt, opts))
+		if err != nil {
+			fatalErr = fmt.Errorf("Cannot connect to workspace: %s", err)
+			return
+		}
+		if _, err := db.Exec(stmt.Body()); err != nil {
+			sequenceFailures = append(sequenceFailures, wrapFailure(stmt, err))
+		}
+	}
+	th := throttler.New(opts.Concurrency, len(creates))
+	for _, stmt := range creates {
 		db, err := ws.ConnectionPool(paramsForStatement(stmt, opts))
 		if err != nil {
 			fatalErr = fmt.Errorf("Cannot connect to workspace: %s", err)
 			return
 		}
 		go func(db *sqlx.DB, statement *fs.Statement) {
-			_, err := db.Exec(statement.Body())
+			_, err := db.Exec(bodyForWorkspace(statement))
 			if err != nil {
 				err = wrapFailure(statement, err)
 			}
@@ -302,7 +380,7 @@ func ExecLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) (wsSchema
 	// expected from concurrent CREATEs in MySQL 8+ if FKs are present.
 	wsSchema = &Schema{
 		LogicalSchema: logicalSchema,
-		Failures:      []*StatementError{},
+		Failures:      sequenceFailures,
 	}
 	sequentialStatements := []*fs.Statement{}
 	for _, err := range th.Errs() {
@@ -316,7 +394,11 @@ func ExecLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) (wsSchema
 
 	// Run ALTERs sequentially, since foreign key manipulations don't play
 	// nice with concurrency.
-	sequentialStatements = append(sequentialStatements, logicalSchema.Alters...)
+	for _, stmt := range logicalSchema.Alters {
+		if !reused[stmt.ObjectKey()] {
+			sequentialStatements = append(sequentialStatements, stmt)
+		}
+	}
 
 	for _, statement := range sequentialStatements {
 		db, connErr := ws.ConnectionPool(paramsForStatement(statement, opts))
@@ -324,15 +406,104 @@ func ExecLogicalSchema(logicalSchema *fs.LogicalSchema, opts Options) (wsSchema
 			fatalErr = fmt.Errorf("Cannot connect to workspace: %s", connErr)
 			return
 		}
-		if _, err := db.Exec(statement.Body()); err != nil {
+		if _, err := db.Exec(bodyForWorkspace(statement)); err != nil {
 			wsSchema.Failures = append(wsSchema.Failures, wrapFailure(statement, err))
 		}
 	}
 
-	wsSchema.Schema, fatalErr = ws.IntrospectSchema()
+	// Run trigger CREATEs sequentially, in the order they appear in the
+	// filesystem, since the server orders triggers on the same table, timing,
+	// and event by creation time. Any trigger positioned relative to another
+	// trigger which does not exist yet is retried after the others.
+	sort.Slice(triggers, func(i, j int) bool {
+		if triggers[i].File != triggers[j].File {
+			return triggers[i].File < triggers[j].File
+		}
+		return triggers[i].LineNo < triggers[j].LineNo
+	})
+	var failures []*StatementError
+	if failures, fatalErr = execWithRetries(ws, triggers, opts, mysqlerr.ER_REFERENCED_TRG_DOES_NOT_EXIST, mariaRefTriggerDoesNotExist); fatalErr != nil {
+		return
+	}
+	wsSchema.Failures = append(wsSchema.Failures, failures...)
+
+	// Run view CREATEs sequentially. Views may depend on other views, so any
+	// view failing due to a missing object is retried after the others.
+	if failures, fatalErr = execWithRetries(ws, views, opts, mysqlerr.ER_NO_SUCH_TABLE); fatalErr != nil {
+		return
+	}
+	wsSchema.Failures = append(wsSchema.Failures, failures...)
+
+	if persistent {
+		if fatalErr = pw.persistObjects(wsSchema.FailedKeys()); fatalErr != nil {
+			return
+		}
+	}
+	if wsSchema.Schema, fatalErr = ws.IntrospectSchema(); fatalErr == nil {
+		restoreEventStatus(wsSchema)
+	}
 	return
 }
 
+// mariaRefTriggerDoesNotExist is MariaDB's error code for a CREATE TRIGGER
+// with a FOLLOWS or PRECEDES clause naming a nonexistent trigger, which differs
+// from MySQL's.
+const mariaRefTriggerDoesNotExist = 4031
+
+// execWithRetries runs the supplied statements sequentially in the workspace.
+// Any statement failing with one of the retryable error codes is retried after
+// the others, so long as each pass makes progress. The returned slice contains
+// the errors from any statements which ultimately failed.
+func execWithRetries(ws Workspace, statements []*fs.Statement, opts Options, retryable ...uint16) (failures []*StatementError, fatalErr error) {
+	for len(statements) > 0 {
+		var retries []*fs.Statement
+		var retryErrs []*StatementError
+		for _, statement := range statements {
+			db, connErr := ws.ConnectionPool(paramsForStatement(statement, opts))
+			if connErr != nil {
+				return nil, fmt.Errorf("Cannot connect to workspace: %s", connErr)
+			}
+			if _, err := db.Exec(bodyForWorkspace(statement)); tengo.IsDatabaseError(err, retryable...) {
+				retries = append(retries, statement)
+				retryErrs = append(retryErrs, wrapFailure(statement, err))
+			} else if err != nil {
+				failures = append(failures, wrapFailure(statement, err))
+			}
+		}
+		if len(retries) == len(statements) {
+			return append(failures, retryErrs...), nil
+		}
+		statements = retries
+	}
+	return failures, nil
+}
+
+// bodyForWorkspace returns the SQL to execute in a workspace for the supplied
+// statement. Events are always created with status DISABLE, so that the
+// workspace instance's event scheduler never runs them.
+func bodyForWorkspace(statement *fs.Statement) string {
+	body, _ := statement.DisabledEventBody()
+	return body
+}
+
+// restoreEventStatus updates the introspected events in wsSchema to reflect
+// the status from their original CREATE EVENT statements, since the events
+// were actually created in the workspace with status DISABLE.
+func restoreEventStatus(wsSchema *Schema) {
+	for _, event := range wsSchema.Events {
+		stmt := wsSchema.LogicalSchema.Creates[tengo.ObjectKey{Type: tengo.ObjectTypeEvent, Name: event.Name}]
+		if stmt == nil {
+			continue
+		}
+		if _, status := stmt.DisabledEventBody(); status != "" && status != event.Status {
+			oldClause := fmt.Sprintf(" ON COMPLETION %s %s", event.OnCompletion, event.Status)
+			newClause := fmt.Sprintf(" ON COMPLETION %s %s", event.OnCompletion, status)
+			event.CreateStatement = strings.Replace(event.CreateStatement, oldClause, newClause, 1)
+			event.Status = status
+		}
+	}
+}
+
 // paramsForStatement returns the session settings for executing the supplied
 // statement in a workspace.
 func paramsForStatement(statement *fs.Statement, opts Options) string {
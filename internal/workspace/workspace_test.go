@@ -209,6 +209,12 @@ func (s WorkspaceIntegrationSuite) TestOptionsForDir(t *testing.T) {
 		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
 	}
 
+	// Test temp-schema with persistence
+	opts = getOpts("--temp-schema-persist --temp-schema-rebuild --reuse-temp-schema")
+	if opts.Type != TypeTempSchema || opts.CleanupAction != CleanupActionPersist || !opts.Rebuild {
+		t.Errorf("Unexpected return from OptionsForDir: %+v", opts)
+	}
+
 	// Test docker with defaults, which should have no cleanup action, and match
 	// flavor of suite's DockerizedInstance
 	opts = getOpts("--workspace=docker")
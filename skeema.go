@@ -48,9 +48,13 @@ func main() {
 		Exit(NewExitValue(CodeBadConfig, err.Error()))
 	}
 
-	util.AddGlobalConfigFiles(cfg)
-	if err := util.ProcessSpecialGlobalOptions(cfg); err != nil {
-		Exit(NewExitValue(CodeBadConfig, err.Error()))
+	// Help and version output don't depend on option files, so skip processing
+	// them, which could otherwise fail or prompt for a password
+	if cfg.CLI.InfoRequested() == nil {
+		util.AddGlobalConfigFiles(cfg)
+		if err := util.ProcessSpecialGlobalOptions(cfg); err != nil {
+			Exit(NewExitValue(CodeBadConfig, err.Error()))
+		}
 	}
 
 	err = cfg.HandleCommand()
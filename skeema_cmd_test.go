@@ -3,9 +3,14 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/skeema/mybase"
 	"github.com/skeema/skeema/internal/fs"
@@ -277,6 +282,162 @@ func (s SkeemaIntegrationSuite) TestPullHandler(t *testing.T) {
 	}
 }
 
+// TestPullSplitBy confirms that pull --split-by distributes a schema's *.sql
+// files into subdirs, and that subsequent commands understand this layout.
+func (s SkeemaIntegrationSuite) TestPullSplitBy(t *testing.T) {
+	s.sourceSQL(t, "manytables.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull --split-by=firstletter")
+	if contents := fs.ReadTestFile(t, "mydb/manytables/.skeema"); !strings.Contains(contents, "split-by=firstletter") {
+		t.Error("Expected mydb/manytables/.skeema to contain split-by after pull, but it does not")
+	}
+
+	// readLayout returns a map of file path to contents for all *.sql files in
+	// the manytables dir, recursively
+	readLayout := func() map[string]string {
+		t.Helper()
+		layout := make(map[string]string)
+		err := filepath.Walk("mydb/manytables", func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() && strings.HasSuffix(path, ".sql") {
+				layout[path] = fs.ReadTestFile(t, path)
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error walking mydb/manytables: %v", err)
+		}
+		return layout
+	}
+	layout := readLayout()
+	expectPaths := []string{
+		"mydb/manytables/a/apples.sql",
+		"mydb/manytables/a/apricots.sql",
+		"mydb/manytables/b/Blueberries.sql",
+		"mydb/manytables/_/_honeydew.sql",
+		"mydb/manytables/7/7up.sql",
+		"mydb/manytables/t/tangerines.sql",
+	}
+	for _, path := range expectPaths {
+		if _, ok := layout[path]; !ok {
+			t.Errorf("Expected %s to exist after pull, but it does not", path)
+		}
+	}
+	if len(layout) != 21 {
+		t.Errorf("Expected 21 *.sql files after pull, instead found %d", len(layout))
+	}
+
+	// Other commands should treat the subdirs as part of the schema dir, and a
+	// re-pull should be a no-op
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema lint")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if relayout := readLayout(); !reflect.DeepEqual(layout, relayout) {
+		t.Errorf("Expected re-pull to be a no-op, but file layout changed:\n%v\n%v", layout, relayout)
+	}
+
+	// A new table should be pulled into the correct subdir, and a moved file
+	// should be moved back to the correct subdir
+	s.dbExec(t, "manytables", "CREATE TABLE ugli (id int unsigned NOT NULL PRIMARY KEY)")
+	if err := os.Rename("mydb/manytables/a/apples.sql", "mydb/manytables/b/apples.sql"); err != nil {
+		t.Fatalf("Unexpected error moving file: %v", err)
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if _, err := os.Stat("mydb/manytables/u/ugli.sql"); err != nil {
+		t.Errorf("Expected mydb/manytables/u/ugli.sql to exist after pull, but stat returned %v", err)
+	}
+	if _, err := os.Stat("mydb/manytables/a/apples.sql"); err != nil {
+		t.Errorf("Expected mydb/manytables/a/apples.sql to exist after pull, but stat returned %v", err)
+	}
+
+	// Changes in subdirs should be pushed
+	contents := fs.ReadTestFile(t, "mydb/manytables/k/kiwis.sql")
+	fs.WriteTestFile(t, "mydb/manytables/k/kiwis.sql", strings.Replace(contents, "  PRIMARY KEY", "  `name` varchar(30),\n  PRIMARY KEY", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.assertTableExists(t, "manytables", "kiwis", "name")
+
+	// Un-splitting should move everything back to the top level
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull --split-by=''")
+	if contents := fs.ReadTestFile(t, "mydb/manytables/.skeema"); strings.Contains(contents, "split-by") {
+		t.Error("Expected mydb/manytables/.skeema to no longer contain split-by after pull, but it does")
+	}
+	for _, path := range []string{"mydb/manytables/apples.sql", "mydb/manytables/ugli.sql", "mydb/manytables/7up.sql"} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to exist after pull, but stat returned %v", path, err)
+		}
+	}
+	if _, err := os.Stat("mydb/manytables/a"); err == nil {
+		t.Error("Expected mydb/manytables/a to be removed, but it still exists")
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+}
+
+func (s SkeemaIntegrationSuite) TestPullPreservesComments(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+
+	// Annotate posts.sql with comments inside its CREATE TABLE, and move the
+	// comments table into the top of users.sql, ahead of the users table
+	contents := fs.ReadTestFile(t, "mydb/product/posts.sql")
+	contents = strings.Replace(contents, "CREATE TABLE `posts` (\n", "CREATE TABLE `posts` (\n  -- one row per post\n", 1)
+	contents = strings.Replace(contents, " NOT NULL AUTO_INCREMENT,", " NOT NULL AUTO_INCREMENT, /* surrogate key */", 1)
+	fs.WriteTestFile(t, "mydb/product/posts.sql", contents)
+	commentsContents := fs.ReadTestFile(t, "mydb/product/comments.sql")
+	fs.RemoveTestFile(t, "mydb/product/comments.sql")
+	fs.WriteTestFile(t, "mydb/product/users.sql", "# comments live here too\n"+commentsContents+fs.ReadTestFile(t, "mydb/product/users.sql"))
+
+	// readFiles returns a map of file path to contents and modification time for
+	// all *.sql files in the product dir
+	type fileState struct {
+		contents string
+		modTime  time.Time
+	}
+	readFiles := func() map[string]fileState {
+		t.Helper()
+		files := make(map[string]fileState)
+		paths, err := filepath.Glob("mydb/product/*.sql")
+		if err != nil {
+			t.Fatalf("Unexpected error from Glob: %v", err)
+		}
+		for _, path := range paths {
+			fi, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("Unexpected error from Stat: %v", err)
+			}
+			files[path] = fileState{contents: fs.ReadTestFile(t, path), modTime: fi.ModTime()}
+		}
+		return files
+	}
+
+	// Pulling twice with no schema changes should not modify any files
+	before := readFiles()
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if after := readFiles(); !reflect.DeepEqual(before, after) {
+		t.Errorf("Expected pulls without schema changes to leave files untouched, but files changed:\n%v\n%v", before, after)
+	}
+
+	// Changing the comments table should only rewrite users.sql, regenerating
+	// the comments table in place while retaining the file's other contents
+	s.dbExec(t, "product", "ALTER TABLE comments ADD COLUMN edited_at datetime")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	after := readFiles()
+	for path, state := range before {
+		if path == "mydb/product/users.sql" {
+			continue
+		}
+		if after[path] != state {
+			t.Errorf("Expected %s to be untouched by pull, but it was modified", path)
+		}
+	}
+	contents = after["mydb/product/users.sql"].contents
+	if !strings.Contains(contents, "`edited_at` datetime") {
+		t.Errorf("Expected mydb/product/users.sql to contain new column, but it does not:\n%s", contents)
+	}
+	if !strings.HasPrefix(contents, "# comments live here too\nCREATE TABLE `comments`") || !strings.Contains(contents, ";\nCREATE TABLE `users`") {
+		t.Errorf("Expected mydb/product/users.sql to retain its comment and object ordering, but it did not:\n%s", contents)
+	}
+}
+
 func (s SkeemaIntegrationSuite) TestLintHandler(t *testing.T) {
 	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
 
@@ -285,7 +446,7 @@ func (s SkeemaIntegrationSuite) TestLintHandler(t *testing.T) {
 	s.verifyFiles(t, cfg, "../golden/init")
 
 	// Invalid options should error with CodeBadConfig
-	s.handleCommand(t, CodeBadConfig, ".", "skeema lint --workspace=doesnt-exist")
+	s.assertBadCLI(t, "skeema lint --workspace=doesnt-exist")
 	s.handleCommand(t, CodeBadConfig, "mydb/product", "skeema lint --password=wrong")
 
 	// Alter a few files in a way that is still valid SQL, but doesn't match
@@ -354,7 +515,7 @@ func (s SkeemaIntegrationSuite) TestFormatHandler(t *testing.T) {
 	s.verifyFiles(t, cfg, "../golden/init")
 
 	// Invalid options should error with CodeBadConfig
-	s.handleCommand(t, CodeBadConfig, ".", "skeema format --workspace=doesnt-exist")
+	s.assertBadCLI(t, "skeema format --workspace=doesnt-exist")
 	s.handleCommand(t, CodeBadConfig, "mydb/product", "skeema format --password=wrong")
 
 	// Alter a few files in a way that is still valid SQL, but doesn't match
@@ -417,6 +578,45 @@ func (s SkeemaIntegrationSuite) TestFormatHandler(t *testing.T) {
 	s.handleCommand(t, CodeBadConfig, ".", "skeema format")
 }
 
+// TestFormatCharsets confirms that format canonicalizes column-level
+// CHARACTER SET and COLLATE clauses: clauses redundant with the table's
+// defaults are stripped, while clauses that genuinely differ are retained.
+func (s SkeemaIntegrationSuite) TestFormatCharsets(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	fs.WriteTestFile(t, "mydb/product/charsets.sql", fs.ReadTestFile(t, "../format-charset.sql"))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema format")
+	s.handleCommand(t, CodeSuccess, ".", "skeema format")
+
+	contents := fs.ReadTestFile(t, "mydb/product/charsets.sql")
+	lines := make(map[string]string)
+	for _, line := range strings.Split(contents, "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && strings.HasPrefix(fields[0], "`") {
+			lines[strings.Trim(fields[0], "`")] = line
+		}
+	}
+	for _, colName := range []string{"redundant_cs", "redundant_both", "redundant_coll"} {
+		if strings.Contains(lines[colName], "CHARACTER SET") || strings.Contains(lines[colName], "COLLATE") {
+			t.Errorf("Expected redundant clauses of column %s to be stripped, but they were not:\n%s", colName, contents)
+		}
+	}
+	if !strings.Contains(lines["genuine_cs"], "CHARACTER SET utf8mb4") {
+		t.Errorf("Expected CHARACTER SET clause of column genuine_cs to be retained, but it was not:\n%s", contents)
+	}
+	if !strings.Contains(lines["genuine_coll"], "COLLATE latin1_general_ci") {
+		t.Errorf("Expected COLLATE clause of column genuine_coll to be retained, but it was not:\n%s", contents)
+	}
+
+	// The utf8 alias is displayed as utf8mb3 by newer server versions, and as
+	// utf8 by older ones; either way, format's output comes from the server
+	if !strings.Contains(lines["alias_cs"], "CHARACTER SET utf8 ") && !strings.Contains(lines["alias_cs"], "CHARACTER SET utf8mb3 ") {
+		t.Errorf("Expected column alias_cs to retain its CHARACTER SET clause, but it did not:\n%s", contents)
+	}
+
+	// The formatted file should be pushable, with no differences afterwards
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+}
+
 func (s SkeemaIntegrationSuite) TestDiffHandler(t *testing.T) {
 	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
 
@@ -444,7 +644,7 @@ func (s SkeemaIntegrationSuite) TestDiffHandler(t *testing.T) {
 		s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --brief")
 		outFile.Close()
 		os.Stdout = oldStdout
-		expectOut := fmt.Sprintf("%s\n", s.d.Instance)
+		expectOut := fmt.Sprintf("-- instance: %s\nanalytics.pageviews table\n", s.d.Instance)
 		actualOut := fs.ReadTestFile(t, "diff-brief.out")
 		if actualOut != expectOut {
 			t.Errorf("Unexpected output from `skeema diff --brief`\nExpected:\n%sActual:\n%s", expectOut, actualOut)
@@ -453,6 +653,106 @@ func (s SkeemaIntegrationSuite) TestDiffHandler(t *testing.T) {
 			t.Fatalf("Unable to delete diff-brief.out: %s", err)
 		}
 	}
+
+	// Differences requiring an unsafe statement should yield a distinct exit
+	// code, unless unsafe statements are permitted
+	s.dbExec(t, "analytics", "ALTER TABLE pageviews ADD COLUMN extra int")
+	s.handleCommand(t, CodeUnsafeDifferences, ".", "skeema diff")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --allow-unsafe")
+
+	// Other problems, such as an invalid CREATE in the filesystem, should yield
+	// an error code even if unsafe differences are also present
+	fs.WriteTestFile(t, "mydb/analytics/broken.sql", "CREATE TABLE broken (id int NOT NULL, lolwut)")
+	s.handleCommand(t, CodeFatalError, ".", "skeema diff")
+	fs.RemoveTestFile(t, "mydb/analytics/broken.sql")
+
+	// Connection failures should yield an error code
+	s.handleCommand(t, CodeFatalError, ".", "skeema diff --port=%d", s.d.Instance.Port-100)
+}
+
+// TestDiffOutputMigration confirms that diff --output-migration-dir writes files
+// which can be used to apply and then revert the differences.
+func (s SkeemaIntegrationSuite) TestDiffOutputMigration(t *testing.T) {
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeBadConfig, ".", "skeema push --output-migration-dir=migrations")
+
+	// Without any differences, no migration files should be written
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --output-migration-dir=migrations")
+	if _, err := os.Stat("migrations"); !os.IsNotExist(err) {
+		t.Fatalf("Expected migrations dir to not exist without differences, instead Stat returned err=%v", err)
+	}
+
+	contents := fs.ReadTestFile(t, "mydb/analytics/pageviews.sql")
+	contents = strings.Replace(contents, "  PRIMARY KEY", "  `referrer` varchar(100) DEFAULT NULL,\n  PRIMARY KEY", 1)
+	contents = strings.Replace(contents, ") ENGINE", ",\n  KEY `referrer` (`referrer`)\n) ENGINE", 1)
+	fs.WriteTestFile(t, "mydb/analytics/pageviews.sql", contents)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --output-migration-dir=migrations")
+
+	ups, _ := filepath.Glob("migrations/*_up.sql")
+	downs, _ := filepath.Glob("migrations/*_down.sql")
+	if len(ups) != 1 || len(downs) != 1 {
+		t.Fatalf("Expected one up file and one down file, instead found %v and %v", ups, downs)
+	}
+	if _, err := s.d.SourceSQL(ups[0]); err != nil {
+		t.Fatalf("Unexpected error sourcing %s: %s", ups[0], err)
+	}
+	s.assertTableExists(t, "analytics", "pageviews", "referrer")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if _, err := s.d.SourceSQL(downs[0]); err != nil {
+		t.Fatalf("Unexpected error sourcing %s: %s", downs[0], err)
+	}
+	s.assertTableExists(t, "analytics", "pageviews", "")
+	s.assertTableMissing(t, "analytics", "pageviews", "referrer")
+	if err := os.RemoveAll("migrations"); err != nil {
+		t.Fatalf("Unable to delete migrations dir: %s", err)
+	}
+}
+
+// TestDiffFromDump confirms that diffing against a dump file yields the same
+// DDL as diffing against the live instance that the dump was taken from.
+func (s SkeemaIntegrationSuite) TestDiffFromDump(t *testing.T) {
+	s.cleanData(t, "dump.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	dumpOpts := fmt.Sprintf("--from-dump=%s --workspace=docker --flavor=%s", s.testdata("dump.sql"), s.d.Flavor().Family())
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff %s", dumpOpts)
+
+	// Modify a table and drop a view in the filesystem
+	contents := fs.ReadTestFile(t, "mydb/dumpdb/customers.sql")
+	fs.WriteTestFile(t, "mydb/dumpdb/customers.sql", strings.Replace(contents, "  `email`", "  `phone` varchar(20) DEFAULT NULL,\n  `email`", 1))
+	fs.RemoveTestFile(t, "mydb/dumpdb/big_orders.sql")
+
+	// Capture DDL output, ignoring the instance header lines which necessarily
+	// differ between the two modes
+	getDiffOutput := func(extraOpts string) string {
+		t.Helper()
+		oldStdout := os.Stdout
+		outFile, err := os.Create("diff-dump.out")
+		if err != nil {
+			t.Fatalf("Unable to redirect stdout to a file: %s", err)
+		}
+		os.Stdout = outFile
+		s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --allow-unsafe %s", extraOpts)
+		outFile.Close()
+		os.Stdout = oldStdout
+		var lines []string
+		for _, line := range strings.Split(fs.ReadTestFile(t, "diff-dump.out"), "\n") {
+			if !strings.HasPrefix(line, "-- instance:") {
+				lines = append(lines, line)
+			}
+		}
+		fs.RemoveTestFile(t, "diff-dump.out")
+		return strings.Join(lines, "\n")
+	}
+	expectOut := getDiffOutput("")
+	if actualOut := getDiffOutput(dumpOpts); actualOut != expectOut {
+		t.Errorf("Unexpected output from `skeema diff --from-dump`\nExpected:\n%s\nActual:\n%s", expectOut, actualOut)
+	} else if !strings.Contains(actualOut, "phone") || !strings.Contains(actualOut, "DROP VIEW") {
+		t.Errorf("Output from `skeema diff --from-dump` lacks expected DDL:\n%s", actualOut)
+	}
+
+	// Dump files cannot be used to push, or in a temp-schema workspace
+	s.handleCommand(t, CodeBadConfig, ".", "skeema push %s", dumpOpts)
+	s.handleCommand(t, CodeBadConfig, ".", "skeema diff --from-dump=%s", s.testdata("dump.sql"))
 }
 
 func (s SkeemaIntegrationSuite) TestPushHandler(t *testing.T) {
@@ -467,8 +767,11 @@ func (s SkeemaIntegrationSuite) TestPushHandler(t *testing.T) {
 
 	// Test bad option values
 	s.handleCommand(t, CodeBadConfig, ".", "skeema push --concurrent-instances=0")
-	s.handleCommand(t, CodeBadConfig, ".", "skeema push --alter-algorithm=invalid")
-	s.handleCommand(t, CodeBadConfig, ".", "skeema push --alter-lock=invalid")
+	s.assertBadCLI(t, "skeema push --alter-algorithm=invalid")
+	s.assertBadCLI(t, "skeema push --alter-lock=invalid")
+	if flavor := s.d.Flavor(); !flavor.MySQLishMinVersion(8, 0) && !flavor.VendorMinVersion(tengo.VendorMariaDB, 10, 3) {
+		s.handleCommand(t, CodeBadConfig, ".", "skeema push --alter-algorithm=instant")
+	}
 	s.handleCommand(t, CodeBadConfig, ".", "skeema push --ignore-table='+'")
 	s.handleCommand(t, CodeBadConfig, ".", "skeema push --lint-charset=gentle-nudge")
 
@@ -565,6 +868,58 @@ func (s SkeemaIntegrationSuite) TestPushHandler(t *testing.T) {
 	s.handleCommand(t, CodeSuccess, ".", "skeema diff --lint-pk=error")
 }
 
+func (s SkeemaIntegrationSuite) TestPushHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Fake hook script requires /bin/sh")
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+
+	// Set up a hook script which records some of the environment variables it
+	// received, one line per invocation
+	hookPath, _ := filepath.Abs("hook.sh")
+	logPath, _ := filepath.Abs("hook.log")
+	fs.WriteTestFile(t, hookPath, "#!/bin/sh\necho \"$SKEEMA_HOOK_NAME $SKEEMA_HOOK_HOST $SKEEMA_HOOK_SCHEMA $SKEEMA_HOOK_STATEMENT_COUNT $SKEEMA_HOOK_STATUS\" >> "+logPath+"\n")
+	if err := os.Chmod(hookPath, 0755); err != nil {
+		t.Fatalf("Unable to chmod %s: %v", hookPath, err)
+	}
+
+	// Hooks should be run once per target with differences, and never by diff
+	fs.WriteTestFile(t, "mydb/analytics/widgets.sql", "CREATE TABLE widgets (id int) ENGINE=InnoDB;\n")
+	fs.WriteTestFile(t, "mydb/product/gadgets.sql", "CREATE TABLE gadgets (id int) ENGINE=InnoDB;\n")
+	fs.WriteTestFile(t, "mydb/product/gizmos.sql", "CREATE TABLE gizmos (id int) ENGINE=InnoDB;\n")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --pre-push-command=%s --post-push-command=%s", hookPath, hookPath)
+	if _, err := os.Stat(logPath); err == nil {
+		t.Fatal("Expected hooks to not run for `skeema diff`, but they did")
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --pre-push-command=%s --post-push-command=%s", hookPath, hookPath)
+	lines := strings.Split(strings.TrimSpace(fs.ReadTestFile(t, logPath)), "\n")
+	sort.Strings(lines)
+	expected := []string{
+		fmt.Sprintf("post-push-command %s analytics 1 success", s.d.Instance.Host),
+		fmt.Sprintf("post-push-command %s product 2 success", s.d.Instance.Host),
+		fmt.Sprintf("pre-push-command %s analytics 1 ", s.d.Instance.Host),
+		fmt.Sprintf("pre-push-command %s product 2 ", s.d.Instance.Host),
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Unexpected hook log contents; expected %v, found %v", expected, lines)
+	}
+
+	// No further differences, so no hooks should run
+	fs.RemoveTestFile(t, logPath)
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --pre-push-command=%s --post-push-command=%s", hookPath, hookPath)
+	if _, err := os.Stat(logPath); err == nil {
+		t.Error("Expected hooks to not run for targets without differences, but they did")
+	}
+
+	// A failing pre-push hook should cause the target to be skipped; a failing
+	// post-push hook should not affect the exit code
+	fs.WriteTestFile(t, "mydb/analytics/sprockets.sql", "CREATE TABLE sprockets (id int) ENGINE=InnoDB;\n")
+	s.handleCommand(t, CodeFatalError, ".", "skeema push --pre-push-command=false")
+	s.assertTableMissing(t, "analytics", "sprockets", "")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --post-push-command=false")
+	s.assertTableExists(t, "analytics", "sprockets", "")
+}
+
 func (s SkeemaIntegrationSuite) TestHelpHandler(t *testing.T) {
 	// Simple tests just to confirm the commands don't error
 	fs.WriteTestFile(t, "fake-etc/skeema", "# hello world")
@@ -640,73 +995,740 @@ func (s SkeemaIntegrationSuite) TestForeignKeys(t *testing.T) {
 	fs.WriteTestFile(t, "mydb/product/posts.sql", contents1)
 	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
 
-	// pull won't update the file unless reformatting
-	s.handleCommand(t, CodeSuccess, ".", "skeema pull --skip-format")
-	if fs.ReadTestFile(t, "mydb/product/posts.sql") != contents1 {
-		t.Error("Expected skeema pull --skip-format to leave file untouched, but it rewrote it")
+	// pull won't update the file unless reformatting
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull --skip-format")
+	if fs.ReadTestFile(t, "mydb/product/posts.sql") != contents1 {
+		t.Error("Expected skeema pull --skip-format to leave file untouched, but it rewrote it")
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if fs.ReadTestFile(t, "mydb/product/posts.sql") != oldContents {
+		t.Error("Expected skeema pull to rewrite file, but it did not")
+	}
+
+	// Renaming an FK should be considered a difference with --exact-match
+	fs.WriteTestFile(t, "mydb/product/posts.sql", contents1)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --exact-match")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --exact-match")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Changing an FK definition should not break push or pull, even though this
+	// will be two non-noop ALTERs to the same table
+	contents2 := strings.Replace(contents1,
+		"FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)",
+		"FOREIGN KEY (`user_id`, `byline`) REFERENCES `users` (`id`, `name`)",
+		1)
+	if contents2 == contents1 {
+		t.Fatal("Failed to update contents as expected")
+	}
+	fs.WriteTestFile(t, "mydb/product/posts.sql", contents2)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	fs.WriteTestFile(t, "mydb/product/posts.sql", contents1)
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull --skip-format")
+	if fs.ReadTestFile(t, "mydb/product/posts.sql") != contents2 {
+		t.Error("Expected skeema pull to rewrite file, but it did not")
+	}
+
+	// Confirm that adding foreign keys occurs after other changes: construct
+	// a scenario where we're adding an FK that needs a new index on the "parent"
+	// (referenced) table, where the parent table name is alphabetically after
+	// the child table
+	s.dbExec(t, "product", "ALTER TABLE posts DROP FOREIGN KEY usridfk")
+	s.dbExec(t, "product", "ALTER TABLE users DROP KEY idname")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+
+	// Test handling of unsafe operations combined with FK operations:
+	// Drop FK + add different FK + drop another col
+	// Confirm that if an unsafe operation is blocked, but there's also a 2nd
+	// ALTER for same table (due to splitting of drop FK + add FK into separate
+	// ALTERs) that both ALTERs are skipped.
+	contents3 := strings.Replace(oldContents, "`body` text,\n", "", 1)
+	contents3 = strings.Replace(contents3, "`body` text DEFAULT NULL,\n", "", 1) // MariaDB 10.2+
+	if strings.Contains(contents3, "`body`") || !strings.Contains(contents3, "`user_fk`") {
+		t.Fatal("Failed to update contents as expected")
+	}
+	fs.WriteTestFile(t, "mydb/product/posts.sql", contents3)
+	s.handleCommand(t, CodeFatalError, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	checkContents := fs.ReadTestFile(t, "mydb/product/posts.sql")
+	if !strings.Contains(checkContents, "`body`") || strings.Contains(checkContents, "`user_fk`") {
+		t.Error("Unsafe status did not properly affect both ALTERs on the table")
+	}
+
+	// Test adding an FK where the existing data does not meet the constraint:
+	// should fail if foreign_key_checks=1, succeed if foreign_key_checks=0
+	s.dbExec(t, "product", "ALTER TABLE posts DROP FOREIGN KEY usridfk")
+	s.dbExec(t, "product", "INSERT INTO posts (user_id, byline) VALUES (1234, 'someone')")
+	fs.WriteTestFile(t, "mydb/product/posts.sql", contents1)
+	s.handleCommand(t, CodeFatalError, ".", "skeema push --foreign-key-checks")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+}
+
+// TestForeignKeyActions confirms that changes to foreign key referential
+// actions are detected, and that NO ACTION and RESTRICT are considered
+// equivalent.
+func (s SkeemaIntegrationSuite) TestForeignKeyActions(t *testing.T) {
+	s.sourceSQL(t, "foreignkey.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	contents := fs.ReadTestFile(t, "mydb/product/posts.sql")
+	fkClause := "REFERENCES `users` (`id`)"
+	if !strings.Contains(contents, fkClause) {
+		t.Fatalf("Expected mydb/product/posts.sql to contain foreign key definition, but it did not:\n%s", contents)
+	}
+
+	// Changing an FK's action in the db should be detected, and push should
+	// revert it by dropping and re-adding the FK
+	s.dbExec(t, "product", "ALTER TABLE posts DROP FOREIGN KEY user_fk")
+	s.dbExec(t, "product", "ALTER TABLE posts ADD CONSTRAINT user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Changing from CASCADE to an explicit RESTRICT in the filesystem should be
+	// detected as well
+	fs.WriteTestFile(t, "mydb/product/posts.sql", strings.Replace(contents, fkClause, fkClause+" ON DELETE CASCADE", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	fs.WriteTestFile(t, "mydb/product/posts.sql", strings.Replace(contents, fkClause, fkClause+" ON DELETE RESTRICT", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// NO ACTION and RESTRICT are equivalent, in either direction, as is omitting
+	// the action entirely
+	fs.WriteTestFile(t, "mydb/product/posts.sql", strings.Replace(contents, fkClause, fkClause+" ON DELETE NO ACTION ON UPDATE NO ACTION", 1))
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.dbExec(t, "product", "ALTER TABLE posts DROP FOREIGN KEY user_fk")
+	s.dbExec(t, "product", "ALTER TABLE posts ADD CONSTRAINT user_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE NO ACTION ON UPDATE NO ACTION")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	fs.WriteTestFile(t, "mydb/product/posts.sql", strings.Replace(contents, fkClause, fkClause+" ON DELETE RESTRICT ON UPDATE RESTRICT", 1))
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	fs.WriteTestFile(t, "mydb/product/posts.sql", contents)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --exact-match")
+}
+
+func (s SkeemaIntegrationSuite) TestCheckConstraints(t *testing.T) {
+	if !s.d.Flavor().HasCheckConstraints() {
+		t.Skip("Test only relevant for flavors supporting check constraints")
+	}
+	isMariaDB := (s.d.Flavor().Vendor == tengo.VendorMariaDB)
+	dropNoun := "CHECK"
+	if isMariaDB {
+		dropNoun = "CONSTRAINT"
+	}
+	s.sourceSQL(t, "check.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	contents := fs.ReadTestFile(t, "mydb/product/users.sql")
+	if !strings.Contains(contents, "`credits_positive`") {
+		t.Fatalf("Expected mydb/product/users.sql to contain check constraint definition, but it did not:\n%s", contents)
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Constraint present in the filesystem but not in the db should be added
+	s.dbExec(t, "product", "ALTER TABLE users DROP "+dropNoun+" credits_positive")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Constraint present in the db but not in the filesystem should be dropped
+	s.dbExec(t, "product", "ALTER TABLE users ADD CONSTRAINT id_positive CHECK (id > 0)")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// An unnamed constraint whose auto-generated name differs, but which is
+	// otherwise identical, should only be a difference with --exact-match
+	autoName := "users_chk_1"
+	if isMariaDB {
+		autoName = "CONSTRAINT_1"
+	}
+	if !strings.Contains(contents, "`"+autoName+"`") {
+		t.Fatalf("Expected mydb/product/users.sql to contain auto-named check constraint %s, but it did not:\n%s", autoName, contents)
+	}
+	renamedAutoName := strings.Replace(autoName, "1", "5", 1)
+	s.dbExec(t, "product", "ALTER TABLE users DROP "+dropNoun+" "+autoName+", ADD CONSTRAINT "+renamedAutoName+" CHECK (char_length(name) > 0)")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --exact-match")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --exact-match")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --exact-match")
+
+	// Changing enforcement status should be detected. MariaDB does not support
+	// NOT ENFORCED, so skip this portion there.
+	if isMariaDB {
+		return
+	}
+	s.dbExec(t, "product", "ALTER TABLE users ALTER CHECK credits_positive NOT ENFORCED")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if fs.ReadTestFile(t, "mydb/product/users.sql") != contents {
+		t.Error("Expected skeema pull to leave file untouched, but it rewrote it")
+	}
+}
+
+func (s SkeemaIntegrationSuite) TestGeneratedColumns(t *testing.T) {
+	if !s.d.Flavor().GeneratedColumns() {
+		t.Skip("Test only relevant for flavors supporting generated columns")
+	}
+	s.sourceSQL(t, "generated.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	contents := fs.ReadTestFile(t, "mydb/product/users.sql")
+
+	// Switching a column from virtual to stored in the db should be reverted by
+	// push, via dropping and re-adding the column. This is considered unsafe,
+	// since it discards the column's values and rebuilds the table.
+	s.dbExec(t, "product", "ALTER TABLE users DROP COLUMN name_length, ADD COLUMN name_length int AS (char_length(name)) STORED AFTER last_modified")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeFatalError, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Switching a column from stored to virtual in the filesystem should work
+	// similarly
+	contentsVirtual := strings.Replace(contents, "(upper(`name`)) STORED", "(upper(`name`)) VIRTUAL", 1)
+	if contentsVirtual == contents {
+		t.Fatalf("Expected mydb/product/users.sql to contain stored generated column, but it did not:\n%s", contents)
+	}
+	fs.WriteTestFile(t, "mydb/product/users.sql", contentsVirtual)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if fs.ReadTestFile(t, "mydb/product/users.sql") != contentsVirtual {
+		t.Error("Expected skeema pull to leave file untouched, but it rewrote it")
+	}
+
+	// Converting a non-generated column to a virtual one is unsafe, since data
+	// would be lost
+	s.dbExec(t, "product", "ALTER TABLE users DROP COLUMN name_length, ADD COLUMN name_length int AFTER last_modified")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeFatalError, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Changing the generation expression should be a modification
+	fs.WriteTestFile(t, "mydb/product/users.sql", strings.Replace(contentsVirtual, "(char_length(`name`))", "(char_length(`name`) + 1)", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Re-adding an indexed column would drop it from the index, so this is not
+	// supported
+	s.dbExec(t, "product", "ALTER TABLE users ADD INDEX name_upper (name_upper)")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	contents = fs.ReadTestFile(t, "mydb/product/users.sql")
+	fs.WriteTestFile(t, "mydb/product/users.sql", strings.Replace(contents, "(upper(`name`)) VIRTUAL", "(upper(`name`)) STORED", 1))
+	s.handleCommand(t, CodePartialError, ".", "skeema push --allow-unsafe")
+}
+
+func (s SkeemaIntegrationSuite) TestInvisibleColumns(t *testing.T) {
+	flavor := s.d.Flavor()
+	if !flavor.MySQLishMinVersion(8, 0, 23) && !flavor.VendorMinVersion(tengo.VendorMariaDB, 10, 3) {
+		t.Skip("Test only relevant for flavors supporting invisible columns")
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	contents := fs.ReadTestFile(t, "mydb/product/users.sql")
+
+	// Adding a new invisible column should include the keyword
+	s.dbExec(t, "product", "ALTER TABLE users ADD COLUMN legacy_flag tinyint INVISIBLE")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	contentsInvis := fs.ReadTestFile(t, "mydb/product/users.sql")
+	if contentsInvis == contents || !strings.Contains(contentsInvis, "INVISIBLE") {
+		t.Fatalf("Expected mydb/product/users.sql to contain invisible column, but it did not:\n%s", contentsInvis)
+	}
+	s.dbExec(t, "product", "ALTER TABLE users DROP COLUMN legacy_flag")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Toggling visibility of an existing column, in either direction, should be
+	// detected and pushed successfully
+	s.dbExec(t, "product", "ALTER TABLE users MODIFY COLUMN legacy_flag tinyint")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.dbExec(t, "product", "ALTER TABLE users MODIFY COLUMN name varchar(30) NOT NULL INVISIBLE")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Unrelated changes to the table should not affect the invisible column
+	s.dbExec(t, "product", "ALTER TABLE users ADD COLUMN nickname varchar(30)")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if fs.ReadTestFile(t, "mydb/product/users.sql") != contentsInvis {
+		t.Error("Expected skeema pull to leave file untouched, but it rewrote it")
+	}
+}
+
+// TestInvisibleIndexes confirms that index visibility is retained in the
+// filesystem, toggled via ALTER INDEX, and unaffected by unrelated changes.
+func (s SkeemaIntegrationSuite) TestInvisibleIndexes(t *testing.T) {
+	if !s.d.Flavor().MySQLishMinVersion(8, 0) {
+		t.Skip("Test only relevant for flavors supporting invisible indexes")
+	}
+	s.sourceSQL(t, "invisindex.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	contents := fs.ReadTestFile(t, "mydb/product/orders.sql")
+	if !strings.Contains(contents, "KEY `status_old` (`status`) /*!80000 INVISIBLE */") {
+		t.Fatalf("Expected mydb/product/orders.sql to contain invisible index, but it did not:\n%s", contents)
+	}
+
+	// Adding a new invisible index in the filesystem should push it as invisible
+	contentsAdded := strings.Replace(contents, "  KEY `created`", "  KEY `status_created` (`status`,`created_at`) /*!80000 INVISIBLE */,\n  KEY `created`", 1)
+	fs.WriteTestFile(t, "mydb/product/orders.sql", contentsAdded)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if schema, err := s.d.Instance.Schema("product"); err != nil {
+		t.Fatalf("Unexpected error from Schema: %v", err)
+	} else if idx := schema.Table("orders").SecondaryIndexesByName()["status_created"]; idx == nil || !idx.Invisible {
+		t.Errorf("Expected index status_created to be invisible after push, instead found %+v", idx)
+	}
+
+	// Toggling visibility of an existing index, in either direction, should be
+	// detected and reverted by push
+	s.dbExec(t, "product", "ALTER TABLE orders ALTER INDEX status_old VISIBLE")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.dbExec(t, "product", "ALTER TABLE orders ALTER INDEX customer INVISIBLE")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Toggling visibility in the filesystem should use ALTER INDEX, rather than
+	// dropping and re-adding the index
+	contentsToggled := strings.Replace(contentsAdded, "KEY `customer` (`customer_id`)", "KEY `customer` (`customer_id`) /*!80000 INVISIBLE */", 1)
+	fs.WriteTestFile(t, "mydb/product/orders.sql", contentsToggled)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --alter-lock=none --alter-algorithm=inplace")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Unrelated changes to the table should not affect the invisible indexes
+	s.dbExec(t, "product", "ALTER TABLE orders ADD COLUMN notes varchar(100), DROP KEY created")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if fs.ReadTestFile(t, "mydb/product/orders.sql") != contentsToggled {
+		t.Error("Expected skeema pull to leave file untouched, but it rewrote it")
+	}
+}
+
+func (s SkeemaIntegrationSuite) TestColumnSRIDs(t *testing.T) {
+	if !s.d.Flavor().HasColumnSRIDs() {
+		t.Skip("Test only relevant for flavors supporting column SRIDs")
+	}
+	s.sourceSQL(t, "srid.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	contents := fs.ReadTestFile(t, "mydb/product/places.sql")
+	if !strings.Contains(contents, "`loc` point NOT NULL /*!80003 SRID 4326 */") {
+		t.Fatalf("Expected mydb/product/places.sql to contain SRID of loc column, but it did not:\n%s", contents)
+	}
+	if strings.Count(contents, "SRID") != 1 {
+		t.Errorf("Expected only the loc column to have an SRID, but found:\n%s", contents)
+	}
+
+	// Changing an SRID, or adding one, in the filesystem should modify the
+	// column accordingly
+	contents = strings.Replace(contents, "SRID 4326", "SRID 3857", 1)
+	contents = strings.Replace(contents, "`boundary` geometry DEFAULT NULL", "`boundary` geometry /*!80003 SRID 4326 */ DEFAULT NULL", 1)
+	fs.WriteTestFile(t, "mydb/product/places.sql", contents)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if schema, err := s.d.Instance.Schema("product"); err != nil {
+		t.Fatalf("Unexpected error from Schema: %v", err)
+	} else if cols := schema.Table("places").ColumnsByName(); cols["loc"].SRID != "3857" || cols["boundary"].SRID != "4326" {
+		t.Errorf("Unexpected SRIDs after push: loc=%q, boundary=%q", cols["loc"].SRID, cols["boundary"].SRID)
+	}
+
+	// Removing an SRID in the database should be detected, and pull should
+	// remove it from the file as well
+	s.dbExec(t, "product", "ALTER TABLE places MODIFY COLUMN boundary geometry DEFAULT NULL")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if contents := fs.ReadTestFile(t, "mydb/product/places.sql"); strings.Count(contents, "SRID") != 1 {
+		t.Errorf("Expected pull to remove SRID of boundary column, but file contents are:\n%s", contents)
+	}
+}
+
+func (s SkeemaIntegrationSuite) TestFunctionalIndexes(t *testing.T) {
+	if !s.d.Flavor().MySQLishMinVersion(8, 0, 13) {
+		t.Skip("Test only relevant for flavors supporting functional key parts")
+	}
+	s.sourceSQL(t, "funcindex.sql")
+
+	// The table should be introspected and diffed properly, including a purely
+	// functional index and an index mixing column parts and expression parts
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	contents := fs.ReadTestFile(t, "mydb/product/events.sql")
+	if !strings.Contains(contents, "KEY `data_x` ((") || !strings.Contains(contents, "KEY `user_data_y` (`user_id`,(") {
+		t.Fatalf("Expected mydb/product/events.sql to contain functional indexes, but it did not:\n%s", contents)
+	}
+
+	// Dropping, re-adding, and modifying functional indexes in the db should all
+	// be detected and reverted by push
+	s.dbExec(t, "product", "ALTER TABLE events DROP KEY data_x")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.dbExec(t, "product", "ALTER TABLE events ADD KEY data_z ((cast(data->>'$.z' as char(10))))")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.dbExec(t, "product", "ALTER TABLE events DROP KEY user_data_y, ADD KEY user_data_y ((cast(data->>'$.y' as char(20))) DESC, user_id)")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Adding a functional index in the filesystem should work, regardless of
+	// cosmetic differences vs the server's canonical form of the expression
+	contentsAdded := strings.Replace(contents, "  KEY `data_x`", "  KEY `data_w` ((cast(data->>'$.w' AS char(30)))),\n  KEY `data_x`", 1)
+	fs.WriteTestFile(t, "mydb/product/events.sql", contentsAdded)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+}
+
+// TestDefaultExpressions confirms that column default expressions are
+// distinguished from literal defaults, and that transitions between literals
+// and expressions are handled by push.
+func (s SkeemaIntegrationSuite) TestDefaultExpressions(t *testing.T) {
+	flavor := s.d.Flavor()
+	if !flavor.MySQLishMinVersion(8, 0, 13) && !flavor.VendorMinVersion(tengo.VendorMariaDB, 10, 2) {
+		t.Skip("Test only relevant for flavors supporting default expressions")
+	}
+	s.sourceSQL(t, "defaultexpr.sql")
+	assertDefaultExpressions := func(expected map[string]bool) {
+		t.Helper()
+		schema, err := s.d.Schema("product")
+		if err != nil || schema == nil || schema.Table("plans") == nil {
+			t.Fatalf("Unable to obtain table product.plans: %v", err)
+		}
+		for _, col := range schema.Table("plans").Columns {
+			if expectExpr, ok := expected[col.Name]; ok && col.HasDefaultExpression() != expectExpr {
+				t.Errorf("Expected column %s HasDefaultExpression()==%t, instead found %t (default %s)", col.Name, expectExpr, !expectExpr, col.Default)
+			}
+		}
+	}
+	assertDefaultExpressions(map[string]bool{"id": false, "code": false, "seats": true, "created_at": false, "renews_on": true})
+
+	// The table should be introspected and diffed properly
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Changing a literal to an expression, and one expression to another, should
+	// be detected and applied by push
+	contents := fs.ReadTestFile(t, "mydb/product/plans.sql")
+	newContents := strings.Replace(contents, "DEFAULT 'basic'", "DEFAULT (concat('ba', 'sic'))", 1)
+	newContents = strings.Replace(newContents, "1 + 1", "2 + 2", 1)
+	if newContents == contents || strings.Count(newContents, "DEFAULT (") != strings.Count(contents, "DEFAULT (")+1 {
+		t.Fatalf("Test setup assumption failed; mydb/product/plans.sql contents unexpected:\n%s", contents)
+	}
+	fs.WriteTestFile(t, "mydb/product/plans.sql", newContents)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	assertDefaultExpressions(map[string]bool{"code": true, "seats": true})
+
+	// Changing an expression back to a literal should also work
+	fs.WriteTestFile(t, "mydb/product/plans.sql", contents)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	assertDefaultExpressions(map[string]bool{"code": false, "seats": true})
+}
+
+// TestCollationChanges confirms that column-level and table-level collation
+// changes are detected and handled by push.
+func (s SkeemaIntegrationSuite) TestCollationChanges(t *testing.T) {
+	s.sourceSQL(t, "collation.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	getTable := func() *tengo.Table {
+		t.Helper()
+		schema, err := s.d.Schema("product")
+		if err != nil || schema == nil || schema.Table("labels") == nil {
+			t.Fatalf("Unable to obtain table product.labels: %v", err)
+		}
+		return schema.Table("labels")
+	}
+
+	// Changing only a column's collation, without changing its charset, should
+	// be detected and applied by push
+	contents := fs.ReadTestFile(t, "mydb/product/labels.sql")
+	fs.WriteTestFile(t, "mydb/product/labels.sql", strings.Replace(contents, "`code` char(10)", "`code` char(10) COLLATE latin1_bin", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if col := getTable().ColumnsByName()["code"]; col.Collation != "latin1_bin" {
+		t.Errorf("Expected column code to have collation latin1_bin after push, instead found %s", col.Collation)
+	}
+	fs.WriteTestFile(t, "mydb/product/labels.sql", contents)
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+
+	// Changing the table's default collation, in a way which also affects all
+	// textual columns, should use a single CONVERT TO clause instead of modifying
+	// each column
+	before := getTable()
+	s.dbExec(t, "product", "ALTER TABLE labels CONVERT TO CHARACTER SET latin1 COLLATE latin1_general_ci")
+	after := getTable()
+	mods := tengo.StatementModifiers{Flavor: s.d.Flavor()}
+	if stmt, err := tengo.NewAlterTable(before, after).Statement(mods); err != nil {
+		t.Errorf("Unexpected error from Statement: %v", err)
+	} else if !strings.Contains(stmt, "CONVERT TO CHARACTER SET latin1 COLLATE latin1_general_ci") || strings.Contains(stmt, "MODIFY COLUMN") {
+		t.Errorf("Expected statement to use CONVERT TO without MODIFY COLUMN, instead found %s", stmt)
+	}
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Same thing via the filesystem
+	fs.WriteTestFile(t, "mydb/product/labels.sql", strings.Replace(contents, "DEFAULT CHARSET=latin1;", "DEFAULT CHARSET=latin1 COLLATE=latin1_general_ci;", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	for _, col := range getTable().Columns {
+		if col.CharSet != "" && col.Collation != "latin1_general_ci" {
+			t.Errorf("Expected column %s to have collation latin1_general_ci after push, instead found %s", col.Name, col.Collation)
+		}
+	}
+}
+
+// TestCharSetAliases confirms that use of the utf8 vs utf8mb3 aliases for the
+// same character set does not result in any differences, regardless of which
+// alias the server reports.
+func (s SkeemaIntegrationSuite) TestCharSetAliases(t *testing.T) {
+	s.sourceSQL(t, "utf8mb3.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Rewrite the file to use whichever alias the server did not report. Older
+	// flavors only accept the utf8mb3 alias for charsets, not collations.
+	contents := fs.ReadTestFile(t, "mydb/product/legacy_notes.sql")
+	var rewritten string
+	if strings.Contains(contents, "utf8mb3") {
+		rewritten = regexp.MustCompile(`\butf8mb3`).ReplaceAllString(contents, "utf8")
+	} else {
+		rewritten = regexp.MustCompile(`\butf8\b`).ReplaceAllString(contents, "utf8mb3")
+	}
+	if rewritten == contents {
+		t.Fatalf("Expected file to use utf8 or utf8mb3 naming, instead found:\n%s", contents)
+	}
+	fs.WriteTestFile(t, "mydb/product/legacy_notes.sql", rewritten)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+
+	// An actual change should still be detected and pushed successfully,
+	// without any spurious changes to other columns
+	fs.WriteTestFile(t, "mydb/product/legacy_notes.sql", strings.Replace(rewritten, "`title` varchar(50)", "`title` varchar(60)", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	schema, err := s.d.Schema("product")
+	if err != nil || schema.Table("legacy_notes") == nil {
+		t.Fatalf("Unable to obtain table product.legacy_notes: %v", err)
+	}
+	cols := schema.Table("legacy_notes").ColumnsByName()
+	if cols["title"].TypeInDB != "varchar(60)" {
+		t.Errorf("Expected title column type to be varchar(60) after push, instead found %s", cols["title"].TypeInDB)
+	}
+	if !tengo.CharSetNamesEquivalent(cols["code"].Collation, "utf8_bin") {
+		t.Errorf("Expected code column collation to remain utf8_bin, instead found %s", cols["code"].Collation)
+	}
+}
+
+// TestComments confirms that table and column comments containing multibyte
+// characters, quotes, and backslashes do not result in any differences, and
+// that actual comment changes are detected and pushed correctly.
+func (s SkeemaIntegrationSuite) TestComments(t *testing.T) {
+	s.sourceSQL(t, "comments.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema format")
+
+	contents := fs.ReadTestFile(t, "mydb/product/annotated.sql")
+	newContents := strings.Replace(contents, "Customer''s display label", "Customer''s display label 🏷️", 1)
+	newContents = strings.Replace(newContents, "with 100% accuracy", "with 99.9% accuracy", 1)
+	if newContents == contents {
+		t.Fatalf("Unable to rewrite comments in file, which contains:\n%s", contents)
+	}
+	fs.WriteTestFile(t, "mydb/product/annotated.sql", newContents)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	schema, err := s.d.Schema("product")
+	if err != nil || schema.Table("annotated") == nil {
+		t.Fatalf("Unable to obtain table product.annotated: %v", err)
+	}
+	table := schema.Table("annotated")
+	if expected := "Stores l'annotation 🐬 with 99.9% accuracy"; table.Comment != expected {
+		t.Errorf("Expected table comment %q, instead found %q", expected, table.Comment)
+	}
+	cols := table.ColumnsByName()
+	expectComments := map[string]string{
+		"id":    "Surrogate key 🔑",
+		"path":  `Windows-style path, e.g. C:\Users\"name"`,
+		"label": "Customer's display label 🏷️",
+	}
+	for name, expected := range expectComments {
+		if cols[name].Comment != expected {
+			t.Errorf("Expected column %s comment %q, instead found %q", name, expected, cols[name].Comment)
+		}
+	}
+}
+
+// TestDisplayWidth confirms that int display widths in *.sql files do not
+// result in differences, regardless of whether the server omits them, while
+// display widths which are still meaningful are preserved.
+func (s SkeemaIntegrationSuite) TestDisplayWidth(t *testing.T) {
+	s.sourceSQL(t, "displaywidth.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	contents := fs.ReadTestFile(t, "mydb/product/counters.sql")
+	if !strings.Contains(contents, "`is_active` tinyint(1)") || !strings.Contains(contents, "`code` int(6) unsigned zerofill") {
+		t.Fatalf("Expected tinyint(1) and zerofill display widths to be preserved, instead found:\n%s", contents)
+	}
+
+	// Rewrite the file to use display widths, as would be output by MySQL 5.7
+	// and earlier. This should not result in any differences.
+	rewritten := strings.Replace(contents, "`id` int unsigned", "`id` int(10) unsigned", 1)
+	rewritten = strings.Replace(rewritten, "`hits` bigint", "`hits` bigint(20)", 1)
+	fs.WriteTestFile(t, "mydb/product/counters.sql", rewritten)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// An actual change should still be detected and pushed successfully
+	fs.WriteTestFile(t, "mydb/product/counters.sql", strings.Replace(rewritten, "`hits` bigint(20) NOT NULL", "`hits` bigint(20) unsigned NOT NULL", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	contents = fs.ReadTestFile(t, "mydb/product/counters.sql")
+	if !strings.Contains(contents, "`is_active` tinyint(1)") {
+		t.Errorf("Expected tinyint(1) display width to be preserved, instead found:\n%s", contents)
+	}
+}
+
+// TestRowFormat confirms that changes to ROW_FORMAT and KEY_BLOCK_SIZE are
+// detected and applied by push, and that differences in the order of table
+// options have no effect.
+func (s SkeemaIntegrationSuite) TestRowFormat(t *testing.T) {
+	// Older flavors require innodb_file_format=Barracuda for compressed tables,
+	// which isn't the default
+	if flavor := s.d.Flavor(); !flavor.MySQLishMinVersion(5, 7) && !flavor.VendorMinVersion(tengo.VendorMariaDB, 10, 2) {
+		t.Skip("Test requires a flavor defaulting to Barracuda file format; image is", s.d.Image)
+	}
+	s.sourceSQL(t, "rowformat.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	getTable := func() *tengo.Table {
+		t.Helper()
+		schema, err := s.d.Schema("product")
+		if err != nil || schema == nil || schema.Table("archived_posts") == nil {
+			t.Fatalf("Unable to obtain table product.archived_posts: %v", err)
+		}
+		return schema.Table("archived_posts")
+	}
+	contents := fs.ReadTestFile(t, "mydb/product/archived_posts.sql")
+	if !strings.Contains(contents, "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8") {
+		t.Fatalf("Expected init to retain ROW_FORMAT and KEY_BLOCK_SIZE, instead found file contents:\n%s", contents)
+	}
+
+	// Removing compression from the file should be applied by push
+	fs.WriteTestFile(t, "mydb/product/archived_posts.sql", strings.Replace(contents, " ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8", "", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if table := getTable(); table.CreateOptions != "" {
+		t.Errorf("Expected table to have no create options after push, instead found %q", table.CreateOptions)
+	}
+
+	// Re-enabling compression with a different KEY_BLOCK_SIZE, with the table
+	// options in a different order than SHOW CREATE TABLE uses
+	fs.WriteTestFile(t, "mydb/product/archived_posts.sql", strings.Replace(contents, "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8", "KEY_BLOCK_SIZE=4 ROW_FORMAT=COMPRESSED", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if table := getTable(); table.RowFormatClause() != "COMPRESSED" || !strings.Contains(table.CreateOptions, "KEY_BLOCK_SIZE=4") {
+		t.Errorf("Expected table to be compressed with KEY_BLOCK_SIZE=4 after push, instead found create options %q", table.CreateOptions)
 	}
+
+	// pull should rewrite the file using the canonical option order, without
+	// any other differences
 	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
-	if fs.ReadTestFile(t, "mydb/product/posts.sql") != oldContents {
-		t.Error("Expected skeema pull to rewrite file, but it did not")
+	if contents := fs.ReadTestFile(t, "mydb/product/archived_posts.sql"); !strings.Contains(contents, "ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=4") {
+		t.Errorf("Expected pull to reflect the new KEY_BLOCK_SIZE, instead found file contents:\n%s", contents)
 	}
-
-	// Renaming an FK should be considered a difference with --exact-match
-	fs.WriteTestFile(t, "mydb/product/posts.sql", contents1)
-	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --exact-match")
-	s.handleCommand(t, CodeSuccess, ".", "skeema push --exact-match")
 	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+}
 
-	// Changing an FK definition should not break push or pull, even though this
-	// will be two non-noop ALTERs to the same table
-	contents2 := strings.Replace(contents1,
-		"FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)",
-		"FOREIGN KEY (`user_id`, `byline`) REFERENCES `users` (`id`, `name`)",
-		1)
-	if contents2 == contents1 {
-		t.Fatal("Failed to update contents as expected")
+// TestFulltextParser confirms that the parser clause of fulltext indexes is
+// preserved by init and pull, and that parser changes are handled by push.
+func (s SkeemaIntegrationSuite) TestFulltextParser(t *testing.T) {
+	if !s.d.Flavor().MySQLishMinVersion(5, 7) {
+		t.Skip("Test only relevant for flavors supporting the ngram fulltext parser")
 	}
-	fs.WriteTestFile(t, "mydb/product/posts.sql", contents2)
+	s.sourceSQL(t, "ftparser.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	contents := fs.ReadTestFile(t, "mydb/product/articles.sql")
+	if !strings.Contains(contents, "WITH PARSER `ngram` */  COMMENT 'cjk search'") {
+		t.Fatalf("Expected mydb/product/articles.sql to contain parser clause, but it did not:\n%s", contents)
+	}
+
+	// Removing the parser in the db should be detected, and push should restore
+	// it by dropping and re-adding the index
+	s.dbExec(t, "product", "ALTER TABLE articles DROP KEY ft_body, ADD FULLTEXT KEY ft_body (body) COMMENT 'cjk search'")
 	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
 	s.handleCommand(t, CodeSuccess, ".", "skeema push")
-	fs.WriteTestFile(t, "mydb/product/posts.sql", contents1)
-	s.handleCommand(t, CodeSuccess, ".", "skeema pull --skip-format")
-	if fs.ReadTestFile(t, "mydb/product/posts.sql") != contents2 {
-		t.Error("Expected skeema pull to rewrite file, but it did not")
-	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
 
-	// Confirm that adding foreign keys occurs after other changes: construct
-	// a scenario where we're adding an FK that needs a new index on the "parent"
-	// (referenced) table, where the parent table name is alphabetically after
-	// the child table
-	s.dbExec(t, "product", "ALTER TABLE posts DROP FOREIGN KEY usridfk")
-	s.dbExec(t, "product", "ALTER TABLE users DROP KEY idname")
+	// Adding a parser to an existing index in the filesystem should be detected.
+	// Then removing it from the filesystem should also be detected, reverting the
+	// index to the default parser.
+	contentsParser := strings.Replace(contents, "FULLTEXT KEY `ft_title` (`title`)", "FULLTEXT KEY `ft_title` (`title`) /*!50100 WITH PARSER `ngram` */ ", 1)
+	fs.WriteTestFile(t, "mydb/product/articles.sql", contentsParser)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	fs.WriteTestFile(t, "mydb/product/articles.sql", contents)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
 	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if pulled := fs.ReadTestFile(t, "mydb/product/articles.sql"); pulled != contents {
+		t.Errorf("Expected pull to leave mydb/product/articles.sql unchanged, instead found:\n%s", pulled)
+	}
 
-	// Test handling of unsafe operations combined with FK operations:
-	// Drop FK + add different FK + drop another col
-	// Confirm that if an unsafe operation is blocked, but there's also a 2nd
-	// ALTER for same table (due to splitting of drop FK + add FK into separate
-	// ALTERs) that both ALTERs are skipped.
-	contents3 := strings.Replace(oldContents, "`body` text,\n", "", 1)
-	contents3 = strings.Replace(contents3, "`body` text DEFAULT NULL,\n", "", 1) // MariaDB 10.2+
-	if strings.Contains(contents3, "`body`") || !strings.Contains(contents3, "`user_fk`") {
-		t.Fatal("Failed to update contents as expected")
+	// The mecab parser is only available if its plugin has been installed
+	var mecabCount int
+	db, err := s.d.CachedConnectionPool("", "")
+	if err != nil {
+		t.Fatalf("Unable to connect to DockerizedInstance: %s", err)
 	}
-	fs.WriteTestFile(t, "mydb/product/posts.sql", contents3)
-	s.handleCommand(t, CodeFatalError, ".", "skeema push")
+	query := "SELECT COUNT(*) FROM information_schema.plugins WHERE plugin_name = 'mecab' AND plugin_status = 'ACTIVE'"
+	if err := db.QueryRow(query).Scan(&mecabCount); err != nil || mecabCount == 0 {
+		return
+	}
+	s.sourceSQL(t, "ftparser-mecab.sql")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
 	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
-	checkContents := fs.ReadTestFile(t, "mydb/product/posts.sql")
-	if !strings.Contains(checkContents, "`body`") || strings.Contains(checkContents, "`user_fk`") {
-		t.Error("Unsafe status did not properly affect both ALTERs on the table")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if contents := fs.ReadTestFile(t, "mydb/product/articles_ja.sql"); !strings.Contains(contents, "WITH PARSER `mecab`") {
+		t.Errorf("Expected mydb/product/articles_ja.sql to contain parser clause, but it did not:\n%s", contents)
 	}
-
-	// Test adding an FK where the existing data does not meet the constraint:
-	// should fail if foreign_key_checks=1, succeed if foreign_key_checks=0
-	s.dbExec(t, "product", "ALTER TABLE posts DROP FOREIGN KEY usridfk")
-	s.dbExec(t, "product", "INSERT INTO posts (user_id, byline) VALUES (1234, 'someone')")
-	fs.WriteTestFile(t, "mydb/product/posts.sql", contents1)
-	s.handleCommand(t, CodeFatalError, ".", "skeema push --foreign-key-checks")
-	s.handleCommand(t, CodeSuccess, ".", "skeema push")
 }
 
 func (s SkeemaIntegrationSuite) TestAutoInc(t *testing.T) {
@@ -732,13 +1754,38 @@ func (s SkeemaIntegrationSuite) TestAutoInc(t *testing.T) {
 	s.dbExec(t, "product", "INSERT INTO users (name) VALUES (?)", "something")
 	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
 
-	// However, if table's next auto-inc is LOWER than sqlfile's, this is a
-	// difference.
+	// If table's next auto-inc is LOWER than sqlfile's, this is still ignored
+	// by default, but is a difference with --include-auto-inc.
 	s.dbExec(t, "product", "DELETE FROM users WHERE id > 1")
 	s.dbExec(t, "product", "ALTER TABLE users AUTO_INCREMENT=2")
-	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
-	s.handleCommand(t, CodeSuccess, ".", "skeema push")
 	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --include-auto-inc")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --include-auto-inc")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --include-auto-inc")
+
+	// A new table's CREATE should omit its next auto-inc value by default, but
+	// include it with --include-auto-inc
+	getNextAutoInc := func() uint64 {
+		t.Helper()
+		schema, err := s.d.Schema("product")
+		if err != nil || schema == nil || schema.Table("users2") == nil {
+			t.Fatalf("Unable to obtain table product.users2: %v", err)
+		}
+		return schema.Table("users2").NextAutoIncrement
+	}
+	contents := fs.ReadTestFile(t, "mydb/product/users.sql")
+	fs.WriteTestFile(t, "mydb/product/users2.sql", strings.Replace(contents, "CREATE TABLE `users`", "CREATE TABLE `users2`", 1))
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	if nextAutoInc := getNextAutoInc(); nextAutoInc > 1 {
+		t.Errorf("Expected push to omit next auto-inc value from CREATE TABLE, instead found %d", nextAutoInc)
+	}
+	s.dbExec(t, "product", "DROP TABLE users2")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --include-auto-inc")
+	if nextAutoInc := getNextAutoInc(); nextAutoInc != 3 {
+		t.Errorf("Expected push --include-auto-inc to use next auto-inc value of 3 in CREATE TABLE, instead found %d", nextAutoInc)
+	}
+	s.dbExec(t, "product", "DROP TABLE users2")
+	fs.RemoveTestFile(t, "mydb/product/users2.sql")
 
 	// init with --include-auto-inc should include auto-inc values greater than 1
 	s.reinitAndVerifyFiles(t, "--include-auto-inc", "../golden/autoinc")
@@ -856,6 +1903,80 @@ func (s SkeemaIntegrationSuite) TestIgnoreOptions(t *testing.T) {
 	s.handleCommand(t, CodeBadConfig, ".", "skeema init --dir badre2 -h %s -P %d --ignore-table='+'", s.d.Instance.Host, s.d.Instance.Port)
 }
 
+func (s SkeemaIntegrationSuite) TestIgnoreAnnotations(t *testing.T) {
+	s.dbExec(t, "product", "CREATE TABLE legacy_users (id int unsigned NOT NULL PRIMARY KEY, name varchar(30))")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+
+	// Annotate legacy_users, and change its definition in the filesystem. Also
+	// add a new table with a foreign key referencing it. The new table should be
+	// pushed, but the annotated table should be left alone.
+	fs.WriteTestFile(t, "mydb/product/legacy_users.sql", "-- skeema:ignore\nCREATE TABLE legacy_users (id int unsigned NOT NULL PRIMARY KEY);\n")
+	fs.WriteTestFile(t, "mydb/product/accounts.sql", "CREATE TABLE accounts (id int unsigned NOT NULL PRIMARY KEY, legacy_user_id int unsigned, CONSTRAINT legacy_user FOREIGN KEY (legacy_user_id) REFERENCES legacy_users (id));\n")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.assertTableExists(t, "product", "accounts", "legacy_user_id")
+	s.assertTableExists(t, "product", "legacy_users", "name")
+
+	// pull must not rewrite the annotated file or strip its annotation
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if contents := fs.ReadTestFile(t, "mydb/product/legacy_users.sql"); !strings.HasPrefix(contents, "-- skeema:ignore\n") || strings.Contains(contents, "name") {
+		t.Errorf("Expected pull to leave annotated file unchanged, instead found contents:\n%s", contents)
+	}
+
+	// Once the annotation is removed, the difference in legacy_users is no
+	// longer ignored
+	fs.WriteTestFile(t, "mydb/product/legacy_users.sql", "CREATE TABLE legacy_users (id int unsigned NOT NULL PRIMARY KEY);\n")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --allow-unsafe")
+}
+
+func (s SkeemaIntegrationSuite) TestObjectTypes(t *testing.T) {
+	s.dbExec(t, "product", "CREATE FUNCTION routine1(a int) RETURNS int DETERMINISTIC RETURN a * 2")
+
+	// init: only table files should be written, and the option should persist
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d --object-types=table", s.d.Instance.Host, s.d.Instance.Port)
+	if _, err := os.Stat("mydb/product/routine1.sql"); err == nil {
+		t.Error("Expected init with --object-types=table to skip routines, but routine1.sql was written")
+	}
+	if _, err := os.Stat("mydb/product/posts.sql"); err != nil {
+		t.Errorf("Expected init with --object-types=table to write tables, but posts.sql could not be read: %v", err)
+	}
+	if !strings.Contains(fs.ReadTestFile(t, "mydb/.skeema"), "object-types=table") {
+		t.Error("Expected object-types to be persisted to mydb/.skeema, but it was not")
+	}
+
+	// diff/push: routines in the db which are absent from the filesystem should
+	// be ignored, rather than dropped
+	s.dbExec(t, "product", "CREATE PROCEDURE routine2() SELECT 1")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
+	if schema, err := s.d.Instance.Schema("product"); err != nil {
+		t.Fatalf("Unexpected error from Schema: %v", err)
+	} else if len(schema.Routines) != 2 {
+		t.Errorf("Expected push to leave the db's routines alone, instead found %d routines", len(schema.Routines))
+	}
+
+	// Routine files in the filesystem should also be ignored by diff, push, and
+	// pull
+	fs.WriteTestFile(t, "mydb/product/routine3.sql", "CREATE FUNCTION routine3() RETURNS int DETERMINISTIC RETURN 3;\n")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if _, err := os.Stat("mydb/product/routine1.sql"); err == nil {
+		t.Error("Expected pull with object-types=table to skip routines, but routine1.sql was written")
+	}
+	if _, err := os.Stat("mydb/product/routine3.sql"); err != nil {
+		t.Errorf("Expected pull to leave an unmanaged routine file alone, instead: %v", err)
+	}
+
+	// Overriding the option should cause routines to be managed again
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --object-types=''")
+
+	// Invalid object types should error
+	s.handleCommand(t, CodeBadConfig, ".", "skeema diff --object-types=table,widget")
+	s.handleCommand(t, CodeBadConfig, ".", "skeema pull --object-types=widget")
+	s.handleCommand(t, CodeBadConfig, ".", "skeema init --dir badtypes -h %s -P %d --object-types=widget", s.d.Instance.Host, s.d.Instance.Port)
+}
+
 func (s SkeemaIntegrationSuite) TestDirEdgeCases(t *testing.T) {
 	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
 
@@ -886,7 +2007,7 @@ func (s SkeemaIntegrationSuite) TestDirEdgeCases(t *testing.T) {
 	s.handleCommand(t, CodeBadConfig, ".", "skeema format undefinedenv")
 	s.handleCommand(t, CodeBadConfig, ".", "skeema lint undefinedenv")
 	s.handleCommand(t, CodeBadConfig, ".", "skeema pull undefinedenv")
-	s.handleCommand(t, CodeSuccess, ".", "skeema diff undefinedenv")
+	s.handleCommand(t, CodeBadConfig, ".", "skeema diff undefinedenv")
 
 	// Extra subdirs with .skeema files and *.sql files don't inherit "schema"
 	// option value from parent dir, and are ignored by diff/push/pull as long
@@ -913,7 +2034,7 @@ func (s SkeemaIntegrationSuite) TestDirEdgeCases(t *testing.T) {
 		t.Error("Unexpectedly found no contents in mydb/otherdb/othertable.sql")
 	}
 	fs.RemoveTestFile(t, "mydb/otherdb/othertable.sql")
-	s.handleCommand(t, CodeFatalError, ".", "skeema diff")
+	s.handleCommand(t, CodeUnsafeDifferences, ".", "skeema diff")
 	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --allow-unsafe")
 	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
 }
@@ -1013,7 +2134,7 @@ func (s SkeemaIntegrationSuite) TestReuseTempSchema(t *testing.T) {
 	}
 
 	// Invalid workspace option should error
-	s.handleCommand(t, CodeBadConfig, ".", "skeema pull --workspace=doesnt-exist --skip-format --reuse-temp-schema --temp-schema=verytemp")
+	s.assertBadCLI(t, "skeema pull --workspace=doesnt-exist --skip-format --reuse-temp-schema --temp-schema=verytemp")
 }
 
 func (s SkeemaIntegrationSuite) TestShardedSchemas(t *testing.T) {
@@ -1242,7 +2363,7 @@ END`
 		t.Fatal("Test setup incorrect")
 	}
 	s.dbExec(t, "product", create)
-	s.handleCommand(t, CodeFatalError, ".", "skeema diff")
+	s.handleCommand(t, CodeUnsafeDifferences, ".", "skeema diff")
 	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --allow-unsafe")
 	s.handleCommand(t, CodeFatalError, ".", "skeema push --safe-below-size=10000")
 	cfg = s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
@@ -1260,7 +2381,7 @@ END`
 	s.dbExec(t, "", "ALTER DATABASE product DEFAULT COLLATE = latin1_general_ci")
 	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
 	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
-	s.handleCommand(t, CodeFatalError, ".", "skeema diff --compare-metadata")
+	s.handleCommand(t, CodeUnsafeDifferences, ".", "skeema diff --compare-metadata")
 	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --compare-metadata --allow-unsafe")
 	s.handleCommand(t, CodeSuccess, ".", "skeema push --compare-metadata --allow-unsafe")
 	s.handleCommand(t, CodeSuccess, ".", "skeema diff --compare-metadata")
@@ -1341,6 +2462,40 @@ END`
 	}
 }
 
+// TestRoutineDefiner confirms that the ignore-definer option causes routines
+// differing only by DEFINER to be treated as identical, while still detecting
+// other changes to those routines.
+func (s SkeemaIntegrationSuite) TestRoutineDefiner(t *testing.T) {
+	s.dbExec(t, "product", "CREATE DEFINER=root@localhost FUNCTION routine1(a int, b int) RETURNS int DETERMINISTIC RETURN a * b")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	origContents := fs.ReadTestFile(t, "mydb/product/routine1.sql")
+
+	// Routine which differs only by definer: replacing it is only needed if
+	// definers aren't being ignored
+	contents := strings.Replace(origContents, "DEFINER=`root`@`localhost`", "DEFINER=`root`@`%`", 1)
+	if contents == origContents {
+		t.Fatal("Test setup incorrect")
+	}
+	fs.WriteTestFile(t, "mydb/product/routine1.sql", contents)
+	s.handleCommand(t, CodeUnsafeDifferences, ".", "skeema diff")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --ignore-definer")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --ignore-definer")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull --ignore-definer")
+	if actual := fs.ReadTestFile(t, "mydb/product/routine1.sql"); actual != contents {
+		t.Errorf("Expected pull --ignore-definer to leave file unchanged, but contents are now:\n%s", actual)
+	}
+
+	// Routine with a genuine body change still requires replacement, even if
+	// definers are being ignored
+	fs.WriteTestFile(t, "mydb/product/routine1.sql", strings.Replace(contents, "a * b", "b * a", 1))
+	s.handleCommand(t, CodeUnsafeDifferences, ".", "skeema diff --ignore-definer")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --ignore-definer --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --ignore-definer --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --ignore-definer")
+}
+
 func (s SkeemaIntegrationSuite) TestTempSchemaBinlog(t *testing.T) {
 	if !s.d.Flavor().MySQLishMinVersion(8, 0) {
 		t.Skip("Test only relevant for flavors that default to having binlog enabled")
@@ -1439,7 +2594,7 @@ func (s SkeemaIntegrationSuite) TestPartitioning(t *testing.T) {
 	s.handleCommand(t, CodeDifferencesFound, "mydb/analytics", "skeema diff --partitioning=keep")
 	s.handleCommand(t, CodeDifferencesFound, "mydb/analytics", "skeema diff") // default is keep
 	s.handleCommand(t, CodeDifferencesFound, "mydb/analytics", "skeema diff --partitioning=modify")
-	s.handleCommand(t, CodeBadConfig, "mydb/analytics", "skeema diff --partitioning=invalid")
+	s.assertBadCLI(t, "skeema diff --partitioning=invalid")
 
 	// At this point we haven't pushed yet, but pull should leave the file
 	// unchanged, regardless of --format vs --skip-format. Here we're simulating
@@ -1561,6 +2716,337 @@ func (s SkeemaIntegrationSuite) TestPartitioning(t *testing.T) {
 	}
 }
 
+// TestPartitionList covers handling of partition list differences, as well as
+// confirming partitioning scheme differences are still detected
+func (s SkeemaIntegrationSuite) TestPartitionList(t *testing.T) {
+	s.sourceSQL(t, "partition.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --partition-list")
+
+	// Partition list drift for RANGE partitioning is ignored by default. With
+	// --partition-list, an extra partition in the db should be dropped, which is
+	// unsafe.
+	s.dbExec(t, "analytics", "ALTER TABLE events_range ADD PARTITION (PARTITION p3 VALUES LESS THAN (4000))")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --partition-list --allow-unsafe")
+	s.handleCommand(t, CodeFatalError, ".", "skeema push --partition-list")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --partition-list --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --partition-list")
+
+	// A partition missing from the end of the list in the db should be added
+	s.dbExec(t, "analytics", "ALTER TABLE events_range DROP PARTITION p2")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --partition-list")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --partition-list")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --partition-list")
+
+	// Partition list changes cannot be combined with other changes in the same
+	// ALTER TABLE, so other changes take precedence
+	s.dbExec(t, "analytics", "ALTER TABLE events_range DROP PARTITION p2")
+	s.dbExec(t, "analytics", "ALTER TABLE events_range ADD COLUMN foo int")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --partition-list --allow-unsafe")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --partition-list")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --partition-list")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --partition-list")
+
+	// Changes to the partitioning scheme for HASH partitioning should still be
+	// detected regardless of --partition-list
+	s.dbExec(t, "analytics", "ALTER TABLE events_hash PARTITION BY HASH (id) PARTITIONS 4")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --partition-list")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --partition-list --partitioning=modify")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --partition-list --partitioning=modify")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --partition-list --partitioning=modify")
+}
+
+// TestViews confirms that views are handled by init, pull, diff, and push,
+// including when using --views-dir to store them in a separate subdir.
+func (s SkeemaIntegrationSuite) TestViews(t *testing.T) {
+	s.sourceSQL(t, "views.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d --views-dir=_views", s.d.Instance.Host, s.d.Instance.Port)
+	if contents := fs.ReadTestFile(t, "mydb/analytics/.skeema"); !strings.Contains(contents, "views-dir=_views") {
+		t.Error("Expected mydb/analytics/.skeema to contain views-dir after init, but it does not")
+	}
+	for _, path := range []string{"mydb/analytics/widgets.sql", "mydb/analytics/_views/.skeema", "mydb/analytics/_views/widget_prices.sql", "mydb/analytics/_views/cheap_widgets.sql"} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to exist after init, but stat returned %v", path, err)
+		}
+	}
+	if _, err := os.Stat("mydb/analytics/cheap_widgets.sql"); err == nil {
+		t.Error("Expected mydb/analytics/cheap_widgets.sql to not exist, since views should be in views-dir")
+	}
+	if contents := fs.ReadTestFile(t, "mydb/analytics/_views/widget_prices.sql"); strings.Contains(contents, "`analytics`.") {
+		t.Errorf("Expected view definition to not contain own-schema name qualifiers, but it does: %s", contents)
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema lint")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+
+	// Drop everything in the db; push should recreate the table before the view,
+	// and the view before the view that depends on it
+	s.dbExec(t, "analytics", "DROP VIEW cheap_widgets, widget_prices")
+	s.dbExec(t, "analytics", "DROP TABLE widgets")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Changing a view's definition should replace it
+	contents := fs.ReadTestFile(t, "mydb/analytics/_views/cheap_widgets.sql")
+	fs.WriteTestFile(t, "mydb/analytics/_views/cheap_widgets.sql", strings.Replace(contents, "100", "50", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Removing the table and views from the filesystem should drop the views
+	// before the table, which requires --allow-unsafe
+	fs.RemoveTestFile(t, "mydb/analytics/widgets.sql")
+	fs.RemoveTestFile(t, "mydb/analytics/_views/widget_prices.sql")
+	fs.RemoveTestFile(t, "mydb/analytics/_views/cheap_widgets.sql")
+	s.handleCommand(t, CodeFatalError, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Pulling without views-dir moves views back to the schema dir
+	s.sourceSQL(t, "views.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull --views-dir=''")
+	if _, err := os.Stat("mydb/analytics/cheap_widgets.sql"); err != nil {
+		t.Errorf("Expected mydb/analytics/cheap_widgets.sql to exist after pull, but stat returned %v", err)
+	}
+	if _, err := os.Stat("mydb/analytics/_views"); err == nil {
+		t.Error("Expected mydb/analytics/_views to be removed after pull, but it still exists")
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+}
+
+// TestViewAttributes confirms that changes to a view's ALGORITHM, SQL
+// SECURITY, and DEFINER are detected, and that the ignore-view-definer option
+// suppresses replacements due only to DEFINER.
+func (s SkeemaIntegrationSuite) TestViewAttributes(t *testing.T) {
+	s.sourceSQL(t, "viewattrs.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	getView := func() *tengo.View {
+		t.Helper()
+		schema, err := s.d.Schema("analytics")
+		if err != nil {
+			t.Fatalf("Unexpected error from Schema: %s", err)
+		}
+		return schema.ViewsByName()["widget_prices"]
+	}
+	origContents := fs.ReadTestFile(t, "mydb/analytics/widget_prices.sql")
+
+	// Toggling ALGORITHM and SQL SECURITY should each replace the view
+	fs.WriteTestFile(t, "mydb/analytics/widget_prices.sql", strings.Replace(origContents, "ALGORITHM=MERGE", "ALGORITHM=TEMPTABLE", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if view := getView(); view == nil || view.Algorithm != "TEMPTABLE" {
+		t.Errorf("Expected view to have ALGORITHM=TEMPTABLE after push, instead found %+v", view)
+	}
+	contents := fs.ReadTestFile(t, "mydb/analytics/widget_prices.sql")
+	fs.WriteTestFile(t, "mydb/analytics/widget_prices.sql", strings.Replace(contents, "SQL SECURITY DEFINER", "SQL SECURITY INVOKER", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if view := getView(); view == nil || view.SecurityType != "INVOKER" {
+		t.Errorf("Expected view to have SQL SECURITY INVOKER after push, instead found %+v", view)
+	}
+
+	// A view differing only by definer is only replaced if definers aren't
+	// being ignored
+	contents = fs.ReadTestFile(t, "mydb/analytics/widget_prices.sql")
+	definerContents := strings.Replace(contents, "DEFINER=`root`@`%`", "DEFINER=`root`@`localhost`", 1)
+	if definerContents == contents {
+		t.Fatal("Test setup incorrect")
+	}
+	fs.WriteTestFile(t, "mydb/analytics/widget_prices.sql", definerContents)
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff --ignore-view-definer")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull --ignore-view-definer")
+	if actual := fs.ReadTestFile(t, "mydb/analytics/widget_prices.sql"); actual != definerContents {
+		t.Errorf("Expected pull --ignore-view-definer to leave file unchanged, but contents are now:\n%s", actual)
+	}
+}
+
+// TestEvents confirms that scheduled events are handled by init, pull, diff,
+// and push, including relative schedules and status changes.
+func (s SkeemaIntegrationSuite) TestEvents(t *testing.T) {
+	s.sourceSQL(t, "events.sql")
+	getEvent := func(name string) *tengo.Event {
+		t.Helper()
+		schema, err := s.d.Schema("analytics")
+		if err != nil {
+			t.Fatalf("Unexpected error from Schema: %s", err)
+		}
+		return schema.EventsByName()[name]
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	for _, path := range []string{"mydb/analytics/purge_activity.sql", "mydb/analytics/record_rollups.sql"} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to exist after init, but stat returned %v", path, err)
+		}
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+
+	// A schedule relative to the current time should not cause a difference,
+	// even though the workspace and live events were created at different times
+	fs.WriteTestFile(t, "mydb/analytics/purge_activity.sql", "CREATE EVENT purge_activity ON SCHEDULE EVERY 1 DAY STARTS CURRENT_TIMESTAMP + INTERVAL 1 HOUR DO DELETE FROM activity WHERE ts < UNIX_TIMESTAMP() - 86400;\n")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Toggling status should use ALTER EVENT, which is not considered unsafe
+	contents := fs.ReadTestFile(t, "mydb/analytics/record_rollups.sql")
+	fs.WriteTestFile(t, "mydb/analytics/record_rollups.sql", strings.Replace(contents, " DISABLE ", " ENABLE ", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if event := getEvent("record_rollups"); event == nil || event.Status != "ENABLE" {
+		t.Errorf("Expected event record_rollups to be enabled after push, instead found %+v", event)
+	}
+
+	// Changing the schedule should also use ALTER EVENT
+	contents = fs.ReadTestFile(t, "mydb/analytics/record_rollups.sql")
+	fs.WriteTestFile(t, "mydb/analytics/record_rollups.sql", strings.Replace(contents, "EVERY 1 HOUR", "EVERY 2 HOUR", 1))
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Changing the body requires dropping and re-creating the event, which is
+	// unsafe
+	contents = fs.ReadTestFile(t, "mydb/analytics/record_rollups.sql")
+	fs.WriteTestFile(t, "mydb/analytics/record_rollups.sql", strings.Replace(contents, "value + 1", "value + 2", 1))
+	s.handleCommand(t, CodeUnsafeDifferences, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if event := getEvent("record_rollups"); event == nil || !strings.Contains(event.Body, "value + 2") || event.Status != "ENABLE" {
+		t.Errorf("Event record_rollups did not have expected body and status after push: %+v", event)
+	}
+
+	// Events changed directly in the db should be reflected by pull
+	s.dbExec(t, "analytics", "ALTER EVENT record_rollups DISABLE")
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	if contents := fs.ReadTestFile(t, "mydb/analytics/record_rollups.sql"); !strings.Contains(contents, " DISABLE ") {
+		t.Errorf("Expected pull to update record_rollups.sql with status change, instead found: %s", contents)
+	}
+
+	// Removing an event from the filesystem should drop it, with --allow-unsafe
+	fs.RemoveTestFile(t, "mydb/analytics/purge_activity.sql")
+	s.handleCommand(t, CodeFatalError, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
+	if getEvent("purge_activity") != nil {
+		t.Error("Expected event purge_activity to be dropped, but it still exists")
+	}
+}
+
+// TestTriggers confirms that triggers are handled by init, diff, and push,
+// and that multiple triggers with the same timing and event on a table are
+// created in the order expressed by the filesystem.
+func (s SkeemaIntegrationSuite) TestTriggers(t *testing.T) {
+	s.sourceSQL(t, "triggers.sql")
+	assertTriggerOrder := func(names ...string) {
+		t.Helper()
+		schema, err := s.d.Schema("product")
+		if err != nil {
+			t.Fatalf("Unexpected error from Schema: %s", err)
+		}
+		byName := schema.TriggersByName()
+		for n, name := range names {
+			if tr := byName[name]; tr == nil {
+				t.Errorf("Expected trigger %s to exist, but it does not", name)
+			} else if tr.ActionOrder != n+1 {
+				t.Errorf("Expected trigger %s to have action order %d, instead found %d", name, n+1, tr.ActionOrder)
+			}
+		}
+	}
+
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	if contents := fs.ReadTestFile(t, "mydb/product/default_credits.sql"); !strings.Contains(contents, "FOLLOWS `lowercase_name`") {
+		t.Errorf("Expected default_credits.sql to position the trigger after lowercase_name, instead found: %s", contents)
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Recreating on a fresh schema should preserve the order
+	s.dbExec(t, "product", "DROP TRIGGER default_credits")
+	s.dbExec(t, "product", "DROP TRIGGER lowercase_name")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	assertTriggerOrder("lowercase_name", "default_credits")
+
+	// Without FOLLOWS or PRECEDES clauses, the order triggers appear within a
+	// file determines their order. Reordering requires dropping and re-creating
+	// the triggers, which is unsafe.
+	fs.RemoveTestFile(t, "mydb/product/lowercase_name.sql")
+	fs.RemoveTestFile(t, "mydb/product/default_credits.sql")
+	fs.WriteTestFile(t, "mydb/product/triggers.sql", "CREATE TRIGGER default_credits BEFORE INSERT ON users FOR EACH ROW SET NEW.credits = COALESCE(NEW.credits, 5.00);\n"+
+		"CREATE TRIGGER lowercase_name BEFORE INSERT ON users FOR EACH ROW SET NEW.name = LOWER(NEW.name);\n")
+	s.handleCommand(t, CodeUnsafeDifferences, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	assertTriggerOrder("default_credits", "lowercase_name")
+}
+
+// TestSequences confirms that MariaDB sequences are supported by init, diff,
+// push, and pull, and that a sequence's next value is only compared if
+// requested.
+func (s SkeemaIntegrationSuite) TestSequences(t *testing.T) {
+	if !s.d.Flavor().HasSequences() {
+		t.Skip("Test only relevant for flavors supporting sequences")
+	}
+	s.sourceSQL(t, "sequences.sql")
+	s.handleCommand(t, CodeSuccess, ".", "skeema init --dir mydb -h %s -P %d", s.d.Instance.Host, s.d.Instance.Port)
+	getSequence := func(name string) *tengo.Sequence {
+		t.Helper()
+		schema, err := s.d.Schema("product")
+		if err != nil {
+			t.Fatalf("Unexpected error from Schema: %s", err)
+		}
+		return schema.SequencesByName()[name]
+	}
+	if contents := fs.ReadTestFile(t, "mydb/product/order_ids.sql"); !strings.Contains(contents, "CREATE SEQUENCE `order_ids`") {
+		t.Errorf("Unexpected contents of mydb/product/order_ids.sql after init: %s", contents)
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Using the sequence changes its next value, which is only a difference if
+	// requested, and restarting the sequence is considered unsafe
+	for n := 0; n < 15; n++ {
+		s.dbExec(t, "product", "SELECT NEXTVAL(order_ids)")
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	s.handleCommand(t, CodeUnsafeDifferences, ".", "skeema diff --compare-sequence-value")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff --compare-sequence-value --allow-unsafe")
+
+	// Changing the increment should be detected, and handled by ALTER SEQUENCE
+	// without affecting the next value
+	before := getSequence("order_ids")
+	contents := fs.ReadTestFile(t, "mydb/product/order_ids.sql")
+	fs.WriteTestFile(t, "mydb/product/order_ids.sql", strings.Replace(contents, "increment by 1 ", "increment by 2 ", 1))
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if after := getSequence("order_ids"); after == nil || after.Increment != 2 || after.NextValue != before.NextValue {
+		t.Errorf("Unexpected sequence state after push; before=%+v after=%+v", before, after)
+	}
+
+	// Creating a new sequence should work, and pull should be a no-op afterwards
+	fs.WriteTestFile(t, "mydb/product/invoice_ids.sql", "CREATE SEQUENCE invoice_ids START WITH 1 INCREMENT BY 5;\n")
+	s.handleCommand(t, CodeDifferencesFound, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+	if seq := getSequence("invoice_ids"); seq == nil || seq.Increment != 5 {
+		t.Errorf("Expected sequence invoice_ids to exist with increment 5, instead found %+v", seq)
+	}
+	s.handleCommand(t, CodeSuccess, ".", "skeema pull")
+	s.handleCommand(t, CodeSuccess, ".", "skeema diff")
+
+	// Dropping a sequence is unsafe
+	fs.RemoveTestFile(t, "mydb/product/invoice_ids.sql")
+	s.handleCommand(t, CodeUnsafeDifferences, ".", "skeema diff")
+	s.handleCommand(t, CodeSuccess, ".", "skeema push --allow-unsafe")
+	if seq := getSequence("invoice_ids"); seq != nil {
+		t.Errorf("Expected sequence invoice_ids to be dropped, but it still exists")
+	}
+}
+
 // TestStripPartitioning covers the --strip-partitioning supported for several
 // commands.
 func (s SkeemaIntegrationSuite) TestStripPartitioning(t *testing.T) {
@@ -183,6 +183,17 @@ func (s *SkeemaIntegrationSuite) handleCommand(t *testing.T, expectedExitCode in
 	return cfg
 }
 
+// assertBadCLI confirms that the supplied command-line fails to parse, which
+// occurs for option values that can be rejected at parse time, such as enum
+// options with values outside of their allowed set. The real program exits
+// with CodeBadConfig in this situation.
+func (s *SkeemaIntegrationSuite) assertBadCLI(t *testing.T, commandLine string) {
+	t.Helper()
+	if _, err := mybase.ParseCLI(CommandSuite, strings.Fields(commandLine)); err == nil {
+		t.Errorf("Expected `%s` to fail to parse, but it did not", commandLine)
+	}
+}
+
 // verifyFiles compares the files in testdata/.scratch to the files in the
 // specified dir, and fails the test if any differences are found.
 func (s *SkeemaIntegrationSuite) verifyFiles(t *testing.T, cfg *mybase.Config, dirExpectedBase string) {
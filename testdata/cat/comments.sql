@@ -0,0 +1,11 @@
+CREATE TABLE `comments` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `post_id` int unsigned NOT NULL,
+  `user_id` int unsigned NOT NULL,
+  `body` text,
+  PRIMARY KEY (`id`),
+  KEY `post` (`post_id`),
+  KEY `user` (`user_id`),
+  CONSTRAINT `comments_post` FOREIGN KEY (`post_id`) REFERENCES `posts` (`id`),
+  CONSTRAINT `comments_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
@@ -0,0 +1,7 @@
+DELIMITER //
+CREATE PROCEDURE `purge_comments`(IN days int)
+BEGIN
+  DELETE FROM `comments` WHERE `post_id` IN (SELECT `id` FROM `posts` WHERE `created_at` < NOW() - INTERVAL days DAY);
+  SELECT ROW_COUNT();
+END//
+DELIMITER ;
@@ -0,0 +1,3 @@
+CREATE VIEW `active_users` AS SELECT DISTINCT `user_id` FROM `recent_posts`;
+
+CREATE VIEW `recent_posts` AS SELECT `id`, `user_id`, `title` FROM `posts` WHERE `created_at` > NOW() - INTERVAL 7 DAY;
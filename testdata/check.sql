@@ -0,0 +1,4 @@
+use product
+ALTER TABLE users
+	ADD CONSTRAINT credits_positive CHECK (credits >= 0),
+	ADD CHECK (char_length(name) > 0);
@@ -0,0 +1,7 @@
+use product
+CREATE TABLE `annotated` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT COMMENT 'Surrogate key 🔑',
+  `path` varchar(255) NOT NULL COMMENT 'Windows-style path, e.g. C:\\Users\\"name"',
+  `label` varchar(50) DEFAULT NULL COMMENT 'Customer''s display label',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='Stores l''annotation 🐬 with 100% accuracy';
@@ -0,0 +1,51 @@
+# bash completion for skeematest
+# To use, run: source <(skeematest completion bash)
+
+__skeematest_complete() {
+	local cur prev word cmdpath i subcommands options valued
+	COMPREPLY=()
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+	cmdpath=""
+	for ((i=1; i < COMP_CWORD; i++)); do
+		word="${COMP_WORDS[i]}"
+		case "$cmdpath:$word" in
+		":help") cmdpath="help" ;;
+		":push") cmdpath="push" ;;
+		":version") cmdpath="version" ;;
+		esac
+	done
+	case "$cmdpath" in
+	"")
+		subcommands="help push version"
+		options="--debug --help --version"
+		valued=""
+		;;
+	"help")
+		subcommands=""
+		options="--debug --help --version"
+		valued=""
+		;;
+	"push")
+		subcommands=""
+		options="--alter-wrapper= --debug --help --password --verify --skip-verify --version"
+		valued="--alter-wrapper -x"
+		;;
+	"version")
+		subcommands=""
+		options="--debug --help --version"
+		valued=""
+		;;
+	esac
+	if [[ -n "$valued" && " $valued " == *" $prev "* ]]; then
+		COMPREPLY=( $(compgen -f -- "$cur") )
+	elif [[ "$cur" == -* ]]; then
+		COMPREPLY=( $(compgen -W "$options" -- "$cur") )
+		[[ "${COMPREPLY[0]}" == *= ]] && compopt -o nospace
+	else
+		COMPREPLY=( $(compgen -W "$subcommands" -- "$cur") )
+	fi
+	return 0
+}
+
+complete -F __skeematest_complete skeematest
@@ -0,0 +1,38 @@
+# fish completion for skeematest
+# To use, run: skeematest completion fish | source
+
+function __skeematest_complete_cmdpath
+	set -l cmdpath ""
+	for word in (commandline -opc)[2..-1]
+		switch "$cmdpath:$word"
+		case ":help"
+			set cmdpath "help"
+		case ":push"
+			set cmdpath "push"
+		case ":version"
+			set cmdpath "version"
+		end
+	end
+	echo "skeematest:$cmdpath"
+end
+
+complete -c skeematest -f
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:'" -a help -d 'Display usage information'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:'" -a push -d 'Alter tables'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:'" -a version -d 'Display program version'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:'" -l debug -d 'Enable debug logging'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:'" -l help -s '?' -d 'Display usage information for the specified command'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:'" -l version -d 'Display program version'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:help'" -l debug -d 'Enable debug logging'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:help'" -l help -s '?' -d 'Display usage information for the specified command'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:help'" -l version -d 'Display program version'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:push'" -l alter-wrapper -s 'x' -r -F -d 'External bin to shell out to for ALTER TABLE'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:push'" -l debug -d 'Enable debug logging'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:push'" -l help -s '?' -d 'Display usage information for the specified command'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:push'" -l password -s 'p' -d 'Password; omit value to prompt'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:push'" -l verify -d 'Test all generated ALTER statements'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:push'" -l skip-verify -d 'Test all generated ALTER statements'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:push'" -l version -d 'Display program version'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:version'" -l debug -d 'Enable debug logging'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:version'" -l help -s '?' -d 'Display usage information for the specified command'
+complete -c skeematest -n "test (__skeematest_complete_cmdpath) = 'skeematest:version'" -l version -d 'Display program version'
@@ -0,0 +1,9 @@
+use product
+CREATE TABLE `plans` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `code` varchar(20) NOT NULL DEFAULT 'basic',
+  `seats` int NOT NULL DEFAULT (1 + 1),
+  `created_at` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `renews_on` date DEFAULT (curdate() + interval 1 year),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
@@ -0,0 +1,8 @@
+use product
+CREATE TABLE `counters` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `hits` bigint NOT NULL DEFAULT '0',
+  `is_active` tinyint(1) NOT NULL DEFAULT '1',
+  `code` int(6) unsigned zerofill DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
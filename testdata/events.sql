@@ -0,0 +1,3 @@
+use analytics
+CREATE EVENT purge_activity ON SCHEDULE EVERY 1 DAY STARTS CURRENT_TIMESTAMP + INTERVAL 1 HOUR DO DELETE FROM activity WHERE ts < UNIX_TIMESTAMP() - 86400;
+CREATE EVENT record_rollups ON SCHEDULE EVERY 1 HOUR DISABLE COMMENT 'toggled on during backfills' DO UPDATE rollups SET value = value + 1 WHERE metric_id = 1;
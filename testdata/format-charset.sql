@@ -0,0 +1,10 @@
+CREATE TABLE `charsets` (
+  `id` int unsigned NOT NULL,
+  `redundant_cs` varchar(20) CHARACTER SET latin1,
+  `redundant_both` varchar(20) CHARACTER SET latin1 COLLATE latin1_swedish_ci,
+  `redundant_coll` varchar(20) COLLATE latin1_swedish_ci,
+  `genuine_cs` varchar(20) CHARACTER SET utf8mb4,
+  `genuine_coll` varchar(20) COLLATE latin1_general_ci,
+  `alias_cs` varchar(20) CHARACTER SET utf8,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
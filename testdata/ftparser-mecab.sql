@@ -0,0 +1,7 @@
+use product
+CREATE TABLE `articles_ja` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `body` text,
+  PRIMARY KEY (`id`),
+  FULLTEXT KEY `ft_body` (`body`) /*!50100 WITH PARSER `mecab` */
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,9 @@
+use product
+CREATE TABLE `articles` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `title` varchar(200) NOT NULL,
+  `body` text,
+  PRIMARY KEY (`id`),
+  FULLTEXT KEY `ft_title` (`title`),
+  FULLTEXT KEY `ft_body` (`body`) /*!50100 WITH PARSER `ngram` */ COMMENT 'cjk search'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,9 @@
+use product
+CREATE TABLE `events` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `user_id` bigint unsigned NOT NULL,
+  `data` json NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `data_x` ((cast(`data`->>'$.x' as char(30)))),
+  KEY `user_data_y` (`user_id`,(cast(`data`->>'$.y' as char(20))) DESC)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,4 @@
+use product
+ALTER TABLE users
+	ADD COLUMN name_length int AS (char_length(name)) VIRTUAL,
+	ADD COLUMN name_upper varchar(30) AS (upper(name)) STORED;
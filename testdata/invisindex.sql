@@ -0,0 +1,11 @@
+use product
+CREATE TABLE `orders` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `customer_id` int unsigned NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `created_at` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `customer` (`customer_id`),
+  KEY `status_old` (`status`) INVISIBLE,
+  KEY `created` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
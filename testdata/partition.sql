@@ -0,0 +1,17 @@
+use analytics
+CREATE TABLE `events_range` (
+  `id` bigint unsigned NOT NULL,
+  `ts` int unsigned NOT NULL,
+  PRIMARY KEY (`id`,`ts`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+PARTITION BY RANGE (ts) (
+  PARTITION p0 VALUES LESS THAN (1000),
+  PARTITION p1 VALUES LESS THAN (2000),
+  PARTITION p2 VALUES LESS THAN (3000)
+);
+CREATE TABLE `events_hash` (
+  `id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  PRIMARY KEY (`id`,`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1
+PARTITION BY HASH (user_id) PARTITIONS 4;
@@ -0,0 +1,6 @@
+use product
+CREATE TABLE `archived_posts` (
+  `id` bigint unsigned NOT NULL,
+  `body` text,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1 ROW_FORMAT=COMPRESSED KEY_BLOCK_SIZE=8;
@@ -0,0 +1,2 @@
+use product
+CREATE SEQUENCE order_ids START WITH 1000 INCREMENT BY 1 CACHE 10;
@@ -0,0 +1,9 @@
+use product
+CREATE TABLE `places` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(60) NOT NULL,
+  `loc` point NOT NULL SRID 4326,
+  `boundary` geometry DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  SPATIAL KEY `loc` (`loc`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
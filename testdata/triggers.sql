@@ -0,0 +1,3 @@
+use product
+CREATE TRIGGER default_credits BEFORE INSERT ON users FOR EACH ROW SET NEW.credits = COALESCE(NEW.credits, 5.00);
+CREATE TRIGGER lowercase_name BEFORE INSERT ON users FOR EACH ROW PRECEDES default_credits SET NEW.name = LOWER(NEW.name);
@@ -0,0 +1,8 @@
+use product
+CREATE TABLE `legacy_notes` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `title` varchar(50) NOT NULL,
+  `code` char(10) CHARACTER SET utf8 COLLATE utf8_bin DEFAULT NULL,
+  `body` text CHARACTER SET latin1,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -0,0 +1,15 @@
+CREATE TABLE posts (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  user_id int unsigned NOT NULL,
+  category_id int unsigned NOT NULL,
+  body text,
+  PRIMARY KEY (id),
+  CONSTRAINT posts_user FOREIGN KEY (user_id) REFERENCES users (id),
+  CONSTRAINT posts_category FOREIGN KEY (category_id) REFERENCES categories (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TRIGGER posts_before_insert BEFORE INSERT
+  ON posts FOR EACH ROW SET NEW.body = TRIM(NEW.body);
+
+CREATE TRIGGER comments_before_insert BEFORE INSERT
+  ON comments FOR EACH ROW SET NEW.body = TRIM(NEW.body);
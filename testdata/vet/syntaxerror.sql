@@ -0,0 +1,5 @@
+-- This file intentionally contains a syntax error
+CREATE TABEL tags (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (id)
+);
@@ -0,0 +1,5 @@
+-- This file intentionally defines a table which is also defined in users.sql
+CREATE TABLE users (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  PRIMARY KEY (id)
+);
@@ -0,0 +1,8 @@
+use analytics
+CREATE TABLE `widgets` (
+  `id` int unsigned NOT NULL,
+  `name` varchar(30) NOT NULL,
+  `price` int unsigned NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
+CREATE ALGORITHM=MERGE DEFINER=`root`@`%` SQL SECURITY DEFINER VIEW widget_prices AS SELECT id, name, price FROM widgets;
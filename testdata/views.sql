@@ -0,0 +1,9 @@
+use analytics
+CREATE TABLE `widgets` (
+  `id` int unsigned NOT NULL,
+  `name` varchar(30) NOT NULL,
+  `price` int unsigned NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=latin1;
+CREATE VIEW widget_prices AS SELECT id, name, price FROM widgets;
+CREATE VIEW cheap_widgets AS SELECT id, name FROM widget_prices WHERE price < 100;